@@ -0,0 +1,126 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/rameshsunkara/go-rest-api-example/internal/audit"
+	"github.com/rameshsunkara/go-rest-api-example/internal/config"
+	"github.com/rameshsunkara/go-rest-api-example/internal/db"
+	"github.com/rameshsunkara/go-rest-api-example/internal/importer"
+	"github.com/rameshsunkara/go-rest-api-example/internal/requestctx"
+	"github.com/rameshsunkara/go-rest-api-example/pkg/logger"
+	"github.com/rameshsunkara/go-rest-api-example/pkg/mongodb"
+)
+
+const (
+	importCommand = "import"
+	// importActor is the actor of the audit entries of the orders imported from the command line.
+	importActor = "cli:import"
+)
+
+// importArgs holds the parsed command line flags of the import subcommand.
+type importArgs struct {
+	file      string
+	format    string
+	dryRun    bool
+	batchSize int
+	tenant    string
+}
+
+func parseImportArgs(args []string) (*importArgs, error) {
+	fs := flag.NewFlagSet(importCommand, flag.ContinueOnError)
+	ia := &importArgs{}
+	fs.StringVar(&ia.file, "file", "", "path to the NDJSON or CSV file to import (required)")
+	fs.StringVar(&ia.format, "format", "", "file format: ndjson or csv, inferred from the file extension when empty")
+	fs.BoolVar(&ia.dryRun, "dry-run", false, "validate the file and report errors without writing to the database")
+	fs.IntVar(&ia.batchSize, "batch-size", importer.DefaultBatchSize, "number of orders written per bulk upsert")
+	fs.StringVar(&ia.tenant, "tenant", "", "tenant whose database the orders are imported into, required with tenants")
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+	if ia.file == "" {
+		return nil, errors.New("-file is required")
+	}
+	if ia.format == "" {
+		ia.format = importer.FormatFromFilename(ia.file)
+	}
+	return ia, nil
+}
+
+// runImport imports historical orders from a file and writes the import report as JSON to out.
+//
+// Usage: ecommerce-orders import -file orders.ndjson [-format ndjson|csv] [-dry-run] [-batch-size 500] [-tenant id].
+func runImport(args []string, out io.Writer) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM, syscall.SIGINT)
+	defer stop()
+
+	ia, argsErr := parseImportArgs(args)
+	if argsErr != nil {
+		return argsErr
+	}
+
+	svcEnv, envErr := config.Load()
+	if envErr != nil {
+		return envErr
+	}
+	lgr := newLogger(svcEnv, os.Stderr)
+
+	f, fErr := os.Open(ia.file)
+	if fErr != nil {
+		return fmt.Errorf("failed to open import file: %w", fErr)
+	}
+	defer f.Close()
+
+	dbConnMgr, dbErr := setupDB(svcEnv, lgr)
+	if dbErr != nil {
+		return dbErr
+	}
+	defer cleanup(lgr, dbConnMgr)
+
+	dbs, tenantErr := tenantDatabases(svcEnv, dbConnMgr, ia.tenant, false)
+	if tenantErr != nil {
+		return tenantErr
+	}
+	if dbs[0].tenant != "" {
+		lgr = lgr.With().Str("tenant", dbs[0].tenant).Logger()
+	}
+	ordersSvc, svcErr := auditedOrders(lgr, dbs[0].db)
+	if svcErr != nil {
+		return svcErr
+	}
+	imp, impErr := importer.New(lgr, ordersSvc, ia.batchSize)
+	if impErr != nil {
+		return impErr
+	}
+
+	report, runErr := imp.Run(requestctx.WithPrincipal(ctx, importActor), f, ia.format, ia.dryRun)
+	if report != nil {
+		enc := json.NewEncoder(out)
+		enc.SetIndent("", "  ")
+		if encErr := enc.Encode(report); encErr != nil {
+			return encErr
+		}
+	}
+	return runErr
+}
+
+// auditedOrders returns the orders of d recording their changes in the audit log of d, like the API does.
+func auditedOrders(lgr logger.Logger, d mongodb.MongoDatabase) (db.OrdersDataService, error) {
+	ordersRepo, err := db.NewOrdersRepo(lgr, d)
+	if err != nil {
+		return nil, err
+	}
+	auditRepo, err := db.NewAuditRepo(lgr, d)
+	if err != nil {
+		return nil, err
+	}
+	return audit.NewOrdersService(lgr, ordersRepo, auditRepo)
+}
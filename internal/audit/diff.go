@@ -0,0 +1,52 @@
+package audit
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+
+	"github.com/rameshsunkara/go-rest-api-example/internal/models/data"
+)
+
+// ignoredFields are not reported as changes, they either never change or change on every write.
+var ignoredFields = map[string]bool{
+	"orderId":   true,
+	"updatedAt": true,
+}
+
+// Diff returns the top level fields that differ between two versions of an order, sorted by field name.
+// Either side may be nil, e.g. before is nil for a create. Values are reported in their JSON form
+// so the audit log reads like the API representation of the order.
+func Diff(before, after *data.Order) []data.FieldChange {
+	b, a := toMap(before), toMap(after)
+	fields := make(map[string]bool, len(a)+len(b))
+	for k := range b {
+		fields[k] = true
+	}
+	for k := range a {
+		fields[k] = true
+	}
+
+	changes := []data.FieldChange{}
+	for f := range fields {
+		if ignoredFields[f] || reflect.DeepEqual(b[f], a[f]) {
+			continue
+		}
+		changes = append(changes, data.FieldChange{Field: f, Before: b[f], After: a[f]})
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Field < changes[j].Field })
+	return changes
+}
+
+func toMap(o *data.Order) map[string]interface{} {
+	m := map[string]interface{}{}
+	if o == nil {
+		return m
+	}
+	raw, err := json.Marshal(o)
+	if err != nil {
+		return m
+	}
+	_ = json.Unmarshal(raw, &m)
+	return m
+}
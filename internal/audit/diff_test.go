@@ -0,0 +1,32 @@
+package audit_test
+
+import (
+	"testing"
+
+	"github.com/rameshsunkara/go-rest-api-example/internal/audit"
+	"github.com/rameshsunkara/go-rest-api-example/internal/models/data"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestDiff(t *testing.T) {
+	t.Parallel()
+	id := primitive.NewObjectID()
+	before := &data.Order{ID: id, Version: 1, Status: data.OrderPending, TotalAmount: 10}
+	after := &data.Order{ID: id, Version: 2, Status: data.OrderShipped, TotalAmount: 10}
+
+	assert.Equal(t, []data.FieldChange{
+		{Field: "status", Before: "OrderPending", After: "OrderShipped"},
+		{Field: "version", Before: float64(1), After: float64(2)},
+	}, audit.Diff(before, after))
+
+	assert.Empty(t, audit.Diff(before, before))
+	assert.Empty(t, audit.Diff(nil, nil))
+
+	created := audit.Diff(nil, after)
+	assert.NotEmpty(t, created)
+	for _, c := range created {
+		assert.Nil(t, c.Before)
+		assert.NotEqual(t, "orderId", c.Field)
+	}
+}
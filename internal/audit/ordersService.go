@@ -0,0 +1,173 @@
+// Package audit records every mutating order operation in the append-only audit log.
+package audit
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/rameshsunkara/go-rest-api-example/internal/db"
+	"github.com/rameshsunkara/go-rest-api-example/internal/models/data"
+	"github.com/rameshsunkara/go-rest-api-example/internal/requestctx"
+	"github.com/rameshsunkara/go-rest-api-example/pkg/logger"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	// AnonymousActor is recorded when no authenticated principal is present in the request context.
+	AnonymousActor = "anonymous"
+)
+
+// OrdersService decorates an OrdersDataService and appends an audit entry for every successful mutation.
+// Reads and purges are passed through untouched. A failure to write the audit entry is logged
+// but does not fail the mutation, which has already been committed at that point.
+type OrdersService struct {
+	db.OrdersDataService
+	auditSvc db.AuditDataService
+	logger   logger.Logger
+}
+
+// NewOrdersService creates a new audited OrdersDataService.
+func NewOrdersService(lgr logger.Logger, orders db.OrdersDataService, auditSvc db.AuditDataService) (*OrdersService, error) {
+	if lgr == nil || orders == nil || auditSvc == nil {
+		return nil, errors.New("missing required inputs to create audited orders service")
+	}
+	return &OrdersService{OrdersDataService: orders, auditSvc: auditSvc, logger: lgr}, nil
+}
+
+// Create inserts the order and records a create entry.
+func (s *OrdersService) Create(ctx context.Context, po *data.Order) (string, error) {
+	id, err := s.OrdersDataService.Create(ctx, po)
+	if err != nil {
+		return id, err
+	}
+	after := *po
+	after.ID, _ = primitive.ObjectIDFromHex(id)
+	s.record(ctx, data.AuditCreate, after.ID, nil, &after)
+	return id, nil
+}
+
+// Update modifies the order and records a transition entry when the status changed, an update entry otherwise.
+func (s *OrdersService) Update(ctx context.Context, po *data.Order) error {
+	before := s.snapshot(ctx, po.ID)
+	if err := s.OrdersDataService.Update(ctx, po); err != nil {
+		return err
+	}
+	action := data.AuditUpdate
+	if before != nil && before.Status != po.Status {
+		action = data.AuditTransition
+	}
+	s.record(ctx, action, po.ID, before, po)
+	return nil
+}
+
+// DeleteByID soft-deletes the order and records a delete entry.
+func (s *OrdersService) DeleteByID(ctx context.Context, id primitive.ObjectID) error {
+	before := s.snapshot(ctx, id)
+	if err := s.OrdersDataService.DeleteByID(ctx, id); err != nil {
+		return err
+	}
+	s.record(ctx, data.AuditDelete, id, before, s.snapshot(ctx, id))
+	return nil
+}
+
+// Restore restores a soft-deleted order and records a restore entry.
+func (s *OrdersService) Restore(ctx context.Context, id primitive.ObjectID) error {
+	before := s.snapshot(ctx, id)
+	if err := s.OrdersDataService.Restore(ctx, id); err != nil {
+		return err
+	}
+	s.record(ctx, data.AuditRestore, id, before, s.snapshot(ctx, id))
+	return nil
+}
+
+// Transition changes the order status and records a transition entry.
+func (s *OrdersService) Transition(
+	ctx context.Context,
+	id primitive.ObjectID,
+	to data.OrderStatus,
+	note data.OrderUpdate,
+	shipment *data.Shipment,
+) (*data.Order, error) {
+	before := s.snapshot(ctx, id)
+	after, err := s.OrdersDataService.Transition(ctx, id, to, note, shipment)
+	if err != nil {
+		return after, err
+	}
+	s.record(ctx, data.AuditTransition, id, before, after)
+	return after, nil
+}
+
+// UpsertMany imports the orders and records one import entry per imported order, skipped orders are not recorded.
+func (s *OrdersService) UpsertMany(ctx context.Context, orders []data.Order) (*db.UpsertResult, error) {
+	res, err := s.OrdersDataService.UpsertMany(ctx, orders)
+	if err != nil {
+		return res, err
+	}
+	actor, requestID, now := Actor(ctx), requestctx.RequestID(ctx), time.Now()
+	entries := make([]data.AuditEntry, 0, len(orders))
+	for i := range orders {
+		id, ok := res.IDs[orders[i].ExternalRef]
+		if !ok {
+			continue
+		}
+		entries = append(entries, data.AuditEntry{
+			Action:      data.AuditImport,
+			OrderID:     id,
+			ExternalRef: orders[i].ExternalRef,
+			Actor:       actor,
+			RequestID:   requestID,
+			Changes:     Diff(nil, &orders[i]),
+			Timestamp:   now,
+		})
+	}
+	if len(entries) == 0 {
+		return res, nil
+	}
+	if appendErr := s.auditSvc.Append(ctx, entries...); appendErr != nil {
+		s.logger.Error().Err(appendErr).Int("orders", len(orders)).Msg("failed to audit order import")
+	}
+	return res, nil
+}
+
+// snapshot returns the current state of the order, including soft-deleted ones, or nil when it cannot be read.
+func (s *OrdersService) snapshot(ctx context.Context, id primitive.ObjectID) *data.Order {
+	order, err := s.OrdersDataService.GetByID(ctx, id, db.ReadOptions{IncludeDeleted: true})
+	if err != nil {
+		return nil
+	}
+	return order
+}
+
+func (s *OrdersService) record(
+	ctx context.Context,
+	action data.AuditAction,
+	orderID primitive.ObjectID,
+	before, after *data.Order,
+) {
+	entry := data.AuditEntry{
+		Action:    action,
+		OrderID:   orderID,
+		Actor:     Actor(ctx),
+		RequestID: requestctx.RequestID(ctx),
+		Changes:   Diff(before, after),
+		Timestamp: time.Now(),
+	}
+	if after != nil {
+		entry.ExternalRef = after.ExternalRef
+	}
+	if err := s.auditSvc.Append(ctx, entry); err != nil {
+		s.logger.Error().Err(err).
+			Str("action", string(action)).
+			Str("orderId", orderID.Hex()).
+			Msg("failed to audit order mutation")
+	}
+}
+
+// Actor returns the authenticated principal stored in ctx, or AnonymousActor.
+func Actor(ctx context.Context) string {
+	if p := requestctx.Principal(ctx); p != "" {
+		return p
+	}
+	return AnonymousActor
+}
@@ -0,0 +1,233 @@
+package audit_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/rameshsunkara/go-rest-api-example/internal/audit"
+	"github.com/rameshsunkara/go-rest-api-example/internal/db"
+	"github.com/rameshsunkara/go-rest-api-example/internal/db/mocks"
+	"github.com/rameshsunkara/go-rest-api-example/internal/models/data"
+	"github.com/rameshsunkara/go-rest-api-example/internal/requestctx"
+	"github.com/rameshsunkara/go-rest-api-example/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var testLgr = logger.New("debug", os.Stdout)
+
+// recorder collects appended audit entries.
+type recorder struct {
+	entries []data.AuditEntry
+	err     error
+}
+
+func (r *recorder) service() *mocks.MockAuditDataService {
+	return &mocks.MockAuditDataService{
+		AppendFunc: func(_ context.Context, entries ...data.AuditEntry) error {
+			r.entries = append(r.entries, entries...)
+			return r.err
+		},
+	}
+}
+
+func requestCtx() context.Context {
+	ctx := context.WithValue(context.Background(), requestctx.ContextKey(requestctx.RequestIdentifier), "req-1")
+	return context.WithValue(ctx, requestctx.ContextKey(requestctx.PrincipalKey), "agent@example.com")
+}
+
+func TestNewOrdersService(t *testing.T) {
+	t.Parallel()
+	_, err := audit.NewOrdersService(nil, &mocks.MockOrdersDataService{}, &mocks.MockAuditDataService{})
+	require.Error(t, err)
+	_, err = audit.NewOrdersService(testLgr, nil, &mocks.MockAuditDataService{})
+	require.Error(t, err)
+	_, err = audit.NewOrdersService(testLgr, &mocks.MockOrdersDataService{}, nil)
+	require.Error(t, err)
+	svc, err := audit.NewOrdersService(testLgr, &mocks.MockOrdersDataService{}, &mocks.MockAuditDataService{})
+	require.NoError(t, err)
+	assert.NotNil(t, svc)
+}
+
+func TestOrdersServiceCreate(t *testing.T) {
+	t.Parallel()
+	rec := &recorder{}
+	id := primitive.NewObjectID()
+	svc, err := audit.NewOrdersService(testLgr, &mocks.MockOrdersDataService{
+		CreateFunc: func(_ context.Context, _ *data.Order) (string, error) {
+			return id.Hex(), nil
+		},
+	}, rec.service())
+	require.NoError(t, err)
+
+	_, err = svc.Create(requestCtx(), &data.Order{Status: data.OrderPending, User: "u@example.com"})
+	require.NoError(t, err)
+
+	require.Len(t, rec.entries, 1)
+	e := rec.entries[0]
+	assert.Equal(t, data.AuditCreate, e.Action)
+	assert.Equal(t, id, e.OrderID)
+	assert.Equal(t, "agent@example.com", e.Actor)
+	assert.Equal(t, "req-1", e.RequestID)
+	assert.NotZero(t, e.Timestamp)
+	assert.Contains(t, e.Changes, data.FieldChange{Field: "status", Before: nil, After: "OrderPending"})
+}
+
+func TestOrdersServiceUpdate(t *testing.T) {
+	t.Parallel()
+	id := primitive.NewObjectID()
+	tests := []struct {
+		name       string
+		newStatus  data.OrderStatus
+		wantAction data.AuditAction
+	}{
+		{name: "status change is a transition", newStatus: data.OrderShipped, wantAction: data.AuditTransition},
+		{name: "same status is an update", newStatus: data.OrderPending, wantAction: data.AuditUpdate},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			rec := &recorder{}
+			svc, err := audit.NewOrdersService(testLgr, &mocks.MockOrdersDataService{
+				GetByIDFunc: func(_ context.Context, _ primitive.ObjectID, opts db.ReadOptions) (*data.Order, error) {
+					assert.True(t, opts.IncludeDeleted)
+					return &data.Order{ID: id, Status: data.OrderPending, User: "old@example.com"}, nil
+				},
+			}, rec.service())
+			require.NoError(t, err)
+
+			err = svc.Update(context.Background(), &data.Order{ID: id, Status: tt.newStatus, User: "new@example.com"})
+			require.NoError(t, err)
+
+			require.Len(t, rec.entries, 1)
+			assert.Equal(t, tt.wantAction, rec.entries[0].Action)
+			assert.Equal(t, audit.AnonymousActor, rec.entries[0].Actor)
+			assert.Contains(t, rec.entries[0].Changes,
+				data.FieldChange{Field: "user", Before: "old@example.com", After: "new@example.com"})
+		})
+	}
+}
+
+func TestOrdersServiceTransition(t *testing.T) {
+	t.Parallel()
+	rec := &recorder{}
+	id := primitive.NewObjectID()
+	svc, err := audit.NewOrdersService(testLgr, &mocks.MockOrdersDataService{
+		GetByIDFunc: func(_ context.Context, _ primitive.ObjectID, _ db.ReadOptions) (*data.Order, error) {
+			return &data.Order{ID: id, Status: data.OrderPending}, nil
+		},
+		TransitionFunc: func(
+			_ context.Context, _ primitive.ObjectID, to data.OrderStatus, _ data.OrderUpdate, _ *data.Shipment,
+		) (*data.Order, error) {
+			if to == data.OrderDelivered {
+				return nil, db.ErrInvalidTransition
+			}
+			return &data.Order{ID: id, Status: to}, nil
+		},
+	}, rec.service())
+	require.NoError(t, err)
+
+	order, err := svc.Transition(requestCtx(), id, data.OrderCancelled, data.OrderUpdate{Notes: "changed my mind"}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, data.OrderCancelled, order.Status)
+	_, err = svc.Transition(requestCtx(), id, data.OrderDelivered, data.OrderUpdate{}, nil)
+	require.ErrorIs(t, err, db.ErrInvalidTransition)
+
+	require.Len(t, rec.entries, 1)
+	assert.Equal(t, data.AuditTransition, rec.entries[0].Action)
+	assert.Contains(t, rec.entries[0].Changes,
+		data.FieldChange{Field: "status", Before: "OrderPending", After: "OrderCancelled"})
+}
+
+func TestOrdersServiceDeleteAndRestore(t *testing.T) {
+	t.Parallel()
+	rec := &recorder{}
+	id := primitive.NewObjectID()
+	deleted := false
+	svc, err := audit.NewOrdersService(testLgr, &mocks.MockOrdersDataService{
+		GetByIDFunc: func(_ context.Context, _ primitive.ObjectID, _ db.ReadOptions) (*data.Order, error) {
+			o := &data.Order{ID: id}
+			if deleted {
+				now := time.Now()
+				o.DeletedAt = &now
+			}
+			return o, nil
+		},
+		DeleteByIDFunc: func(_ context.Context, _ primitive.ObjectID) error {
+			deleted = true
+			return nil
+		},
+		RestoreFunc: func(_ context.Context, _ primitive.ObjectID) error {
+			deleted = false
+			return nil
+		},
+	}, rec.service())
+	require.NoError(t, err)
+
+	require.NoError(t, svc.DeleteByID(context.Background(), id))
+	require.NoError(t, svc.Restore(context.Background(), id))
+
+	require.Len(t, rec.entries, 2)
+	assert.Equal(t, data.AuditDelete, rec.entries[0].Action)
+	require.Len(t, rec.entries[0].Changes, 1)
+	assert.Equal(t, "deletedAt", rec.entries[0].Changes[0].Field)
+	assert.Nil(t, rec.entries[0].Changes[0].Before)
+	assert.Equal(t, data.AuditRestore, rec.entries[1].Action)
+	assert.Nil(t, rec.entries[1].Changes[0].After)
+}
+
+func TestOrdersServiceFailedMutationIsNotAudited(t *testing.T) {
+	t.Parallel()
+	rec := &recorder{}
+	svc, err := audit.NewOrdersService(testLgr, &mocks.MockOrdersDataService{
+		CreateFunc: func(_ context.Context, _ *data.Order) (string, error) {
+			return "", db.ErrFailedToCreateOrder
+		},
+		GetByIDFunc: func(_ context.Context, _ primitive.ObjectID, _ db.ReadOptions) (*data.Order, error) {
+			return nil, db.ErrPOIDNotFound
+		},
+		DeleteByIDFunc: func(_ context.Context, _ primitive.ObjectID) error {
+			return db.ErrPOIDNotFound
+		},
+	}, rec.service())
+	require.NoError(t, err)
+
+	_, err = svc.Create(context.Background(), &data.Order{})
+	require.ErrorIs(t, err, db.ErrFailedToCreateOrder)
+	require.ErrorIs(t, svc.DeleteByID(context.Background(), primitive.NewObjectID()), db.ErrPOIDNotFound)
+	assert.Empty(t, rec.entries)
+}
+
+func TestOrdersServiceUpsertMany(t *testing.T) {
+	t.Parallel()
+	rec := &recorder{err: errors.New("audit store down")}
+	id1, id2 := primitive.NewObjectID(), primitive.NewObjectID()
+	svc, err := audit.NewOrdersService(testLgr, &mocks.MockOrdersDataService{
+		UpsertManyFunc: func(_ context.Context, _ []data.Order) (*db.UpsertResult, error) {
+			return &db.UpsertResult{
+				Inserted: 1,
+				Matched:  1,
+				Skipped:  1,
+				IDs:      map[string]primitive.ObjectID{"L-1": id1, "L-2": id2},
+			}, nil
+		},
+	}, rec.service())
+	require.NoError(t, err)
+
+	// audit write failures are logged, the import itself still succeeds
+	orders := []data.Order{{ExternalRef: "L-1"}, {ExternalRef: "L-2"}, {ExternalRef: "L-3"}}
+	res, err := svc.UpsertMany(requestCtx(), orders)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), res.Inserted)
+	// the skipped soft-deleted order is not recorded
+	require.Len(t, rec.entries, 2)
+	assert.Equal(t, data.AuditImport, rec.entries[1].Action)
+	assert.Equal(t, id1, rec.entries[0].OrderID)
+	assert.Equal(t, id2, rec.entries[1].OrderID)
+	assert.Equal(t, "L-2", rec.entries[1].ExternalRef)
+	assert.Equal(t, "req-1", rec.entries[1].RequestID)
+}
@@ -0,0 +1,100 @@
+// Package catalog resolves order lines referencing SKUs into priced order products.
+package catalog
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/rameshsunkara/go-rest-api-example/internal/db"
+	"github.com/rameshsunkara/go-rest-api-example/internal/models/data"
+	"github.com/rameshsunkara/go-rest-api-example/pkg/logger"
+	"github.com/rameshsunkara/go-rest-api-example/pkg/money"
+)
+
+// ErrInvalidLine is returned when an order line references an unknown or inactive SKU, repeats a SKU,
+// or when the products of an order do not share a single currency.
+var ErrInvalidLine = errors.New("invalid order line")
+
+// Line is a requested quantity of a catalog product.
+type Line struct {
+	SKU      string
+	Quantity uint64
+}
+
+// Resolver turns order lines into order products using canonical catalog names and prices.
+type Resolver struct {
+	products db.ProductsDataService
+	logger   logger.Logger
+}
+
+// NewResolver creates a new Resolver.
+func NewResolver(lgr logger.Logger, products db.ProductsDataService) (*Resolver, error) {
+	if lgr == nil || products == nil {
+		return nil, errors.New("missing required inputs to create catalog resolver")
+	}
+	return &Resolver{products: products, logger: lgr}, nil
+}
+
+// Resolve looks up every line in the catalog and returns the order products together with their
+// currency. When currency is not empty the catalog products must be priced in it.
+func (r *Resolver) Resolve(
+	ctx context.Context,
+	lines []Line,
+	currency money.Currency,
+) ([]data.Product, money.Currency, error) {
+	if len(lines) == 0 {
+		return nil, "", fmt.Errorf("%w: at least one product is required", ErrInvalidLine)
+	}
+	skus := make([]string, len(lines))
+	seen := make(map[string]bool, len(lines))
+	for i, line := range lines {
+		sku := data.NormalizeSKU(line.SKU)
+		switch {
+		case sku == "":
+			return nil, "", fmt.Errorf("%w: line %d has no sku", ErrInvalidLine, i+1)
+		case line.Quantity == 0:
+			return nil, "", fmt.Errorf("%w: %s has no quantity", ErrInvalidLine, sku)
+		case seen[sku]:
+			return nil, "", fmt.Errorf("%w: %s is listed more than once", ErrInvalidLine, sku)
+		}
+		seen[sku] = true
+		skus[i] = sku
+	}
+
+	found, err := r.products.GetBySKUs(ctx, skus)
+	if err != nil {
+		return nil, "", err
+	}
+	bySKU := make(map[string]data.CatalogProduct, len(found))
+	for _, p := range found {
+		bySKU[p.SKU] = p
+	}
+
+	var unknown, inactive []string
+	products := make([]data.Product, len(lines))
+	for i, sku := range skus {
+		p, ok := bySKU[sku]
+		switch {
+		case !ok:
+			unknown = append(unknown, sku)
+			continue
+		case !p.Active:
+			inactive = append(inactive, sku)
+			continue
+		case currency == "":
+			currency = p.Currency
+		case p.Currency != currency:
+			return nil, "", fmt.Errorf("%w: %s is priced in %s, not %s", ErrInvalidLine, sku, p.Currency, currency)
+		}
+		products[i] = data.Product{SKU: p.SKU, Name: p.Name, Price: p.Price, Quantity: lines[i].Quantity}
+	}
+	if len(unknown) > 0 {
+		return nil, "", fmt.Errorf("%w: unknown sku %s", ErrInvalidLine, strings.Join(unknown, ", "))
+	}
+	if len(inactive) > 0 {
+		return nil, "", fmt.Errorf("%w: sku %s is not available", ErrInvalidLine, strings.Join(inactive, ", "))
+	}
+	return products, currency, nil
+}
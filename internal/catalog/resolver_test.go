@@ -0,0 +1,134 @@
+package catalog_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/rameshsunkara/go-rest-api-example/internal/catalog"
+	"github.com/rameshsunkara/go-rest-api-example/internal/db/mocks"
+	"github.com/rameshsunkara/go-rest-api-example/internal/models/data"
+	"github.com/rameshsunkara/go-rest-api-example/pkg/logger"
+	"github.com/rameshsunkara/go-rest-api-example/pkg/money"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testLgr = logger.New("debug", os.Stdout)
+
+func productStore(products ...data.CatalogProduct) *mocks.MockProductsDataService {
+	return &mocks.MockProductsDataService{
+		GetBySKUsFunc: func(_ context.Context, skus []string) ([]data.CatalogProduct, error) {
+			var found []data.CatalogProduct
+			for _, sku := range skus {
+				for _, p := range products {
+					if p.SKU == sku {
+						found = append(found, p)
+					}
+				}
+			}
+			return found, nil
+		},
+	}
+}
+
+func TestNewResolver(t *testing.T) {
+	t.Parallel()
+	_, err := catalog.NewResolver(nil, productStore())
+	require.Error(t, err)
+	_, err = catalog.NewResolver(testLgr, nil)
+	require.Error(t, err)
+	r, err := catalog.NewResolver(testLgr, productStore())
+	require.NoError(t, err)
+	assert.NotNil(t, r)
+}
+
+func TestResolver_Resolve(t *testing.T) {
+	t.Parallel()
+	store := productStore(
+		data.CatalogProduct{SKU: "MUG-1", Name: "Mug", Price: money.MustParse("9.99"), Currency: money.USD, Active: true},
+		data.CatalogProduct{SKU: "TEE-1", Name: "Tee", Price: money.MustParse("20"), Currency: money.USD, Active: true},
+		data.CatalogProduct{SKU: "OLD-1", Name: "Old", Price: money.MustParse("1"), Currency: money.USD},
+		data.CatalogProduct{SKU: "EUR-1", Name: "Euro", Price: money.MustParse("5"), Currency: money.EUR, Active: true},
+	)
+
+	tests := []struct {
+		name         string
+		lines        []catalog.Line
+		currency     money.Currency
+		wantCurrency money.Currency
+		wantProducts []data.Product
+		wantErr      string
+	}{
+		{
+			name:         "ResolvesNamesAndPrices",
+			lines:        []catalog.Line{{SKU: "mug-1", Quantity: 2}, {SKU: " TEE-1 ", Quantity: 1}},
+			wantCurrency: money.USD,
+			wantProducts: []data.Product{
+				{SKU: "MUG-1", Name: "Mug", Price: money.MustParse("9.99"), Quantity: 2},
+				{SKU: "TEE-1", Name: "Tee", Price: money.MustParse("20"), Quantity: 1},
+			},
+		},
+		{
+			name:         "MatchingCurrency",
+			lines:        []catalog.Line{{SKU: "EUR-1", Quantity: 1}},
+			currency:     money.EUR,
+			wantCurrency: money.EUR,
+			wantProducts: []data.Product{{SKU: "EUR-1", Name: "Euro", Price: money.MustParse("5"), Quantity: 1}},
+		},
+		{name: "NoLines", wantErr: "at least one product"},
+		{name: "EmptySKU", lines: []catalog.Line{{SKU: " ", Quantity: 1}}, wantErr: "line 1 has no sku"},
+		{name: "NoQuantity", lines: []catalog.Line{{SKU: "MUG-1"}}, wantErr: "MUG-1 has no quantity"},
+		{
+			name:    "DuplicateSKU",
+			lines:   []catalog.Line{{SKU: "MUG-1", Quantity: 1}, {SKU: "mug-1", Quantity: 1}},
+			wantErr: "MUG-1 is listed more than once",
+		},
+		{
+			name:    "UnknownSKU",
+			lines:   []catalog.Line{{SKU: "NOPE", Quantity: 1}, {SKU: "MUG-1", Quantity: 1}, {SKU: "GONE", Quantity: 1}},
+			wantErr: "unknown sku NOPE, GONE",
+		},
+		{name: "InactiveSKU", lines: []catalog.Line{{SKU: "OLD-1", Quantity: 1}}, wantErr: "OLD-1 is not available"},
+		{
+			name:    "MixedCurrencies",
+			lines:   []catalog.Line{{SKU: "MUG-1", Quantity: 1}, {SKU: "EUR-1", Quantity: 1}},
+			wantErr: "EUR-1 is priced in EUR, not USD",
+		},
+		{
+			name:     "CurrencyMismatch",
+			lines:    []catalog.Line{{SKU: "MUG-1", Quantity: 1}},
+			currency: money.GBP,
+			wantErr:  "MUG-1 is priced in USD, not GBP",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			r, err := catalog.NewResolver(testLgr, store)
+			require.NoError(t, err)
+			products, currency, err := r.Resolve(context.Background(), tt.lines, tt.currency)
+			if tt.wantErr != "" {
+				require.ErrorIs(t, err, catalog.ErrInvalidLine)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantCurrency, currency)
+			assert.Equal(t, tt.wantProducts, products)
+		})
+	}
+}
+
+func TestResolver_ResolveStoreError(t *testing.T) {
+	t.Parallel()
+	storeErr := errors.New("db down")
+	r, err := catalog.NewResolver(testLgr, &mocks.MockProductsDataService{
+		GetBySKUsFunc: func(context.Context, []string) ([]data.CatalogProduct, error) { return nil, storeErr },
+	})
+	require.NoError(t, err)
+	_, _, err = r.Resolve(context.Background(), []catalog.Line{{SKU: "MUG-1", Quantity: 1}}, "")
+	require.ErrorIs(t, err, storeErr)
+	assert.NotErrorIs(t, err, catalog.ErrInvalidLine)
+}
@@ -2,8 +2,14 @@ package config
 
 import (
 	"errors"
+	"fmt"
 	"os"
 	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rameshsunkara/go-rest-api-example/pkg/money"
+	"github.com/rameshsunkara/go-rest-api-example/pkg/tracing"
 )
 
 // ServiceEnvConfig holds all environmental configurations for the service.
@@ -11,6 +17,17 @@ type ServiceEnvConfig struct {
 	Environment string // environment where this service is running (dev, staging, prod, etc.)
 	Port        string // port on which this service runs, defaults to DefaultPort
 	LogLevel    string // logger level for the service
+	// Levels of named loggers that differ from LogLevel, e.g. logComponentLevels="mongodb=debug,http=info"
+	LogComponentLevels map[string]string
+	// One in RequestLogSampleEvery successful requests is logged, failed and slow ones always are, defaults to 1.
+	// RequestLogRouteSampleEvery overrides it per route, e.g. requestLogRouteSampleEvery="/healthz=100"
+	RequestLogSampleEvery      int
+	RequestLogRouteSampleEvery map[string]int
+	// Identical messages beyond LogBurst per LogBurstWindow are suppressed and counted, zero disables it.
+	// LogRouteBurst overrides it for the requests of a route, e.g. logRouteBurst="/ecommerce/v1/orders=5"
+	LogBurst       int
+	LogBurstWindow time.Duration // defaults to DefLogBurstWindow
+	LogRouteBurst  map[string]int
 
 	// DB related configurations
 	DBCredentialsSideCar string // path to find the database credentials sidecar file
@@ -18,9 +35,53 @@ type ServiceEnvConfig struct {
 	DBName               string // name of the database
 	DBPort               int    // port on which the DB is listening, defaults to 27017
 	DBLogQueries         bool   // print the DB queries that are triggered through this service, defaults to false
+	// DB commands taking at least DBSlowQueryThreshold are logged, defaults to DefDBSlowQueryThreshold
+	DBSlowQueryThreshold time.Duration
+	// Dotted paths of the fields masked in logged DB commands, e.g. dbRedactFields="user,customer.email",
+	// defaults to DefDBRedactFields
+	DBRedactFields []string
 
 	DisableAuth   bool // disables API authentication, added to make local development/testing easy
 	EnableTracing bool // enables flight recorder for slow request tracing, defaults to false
+
+	// OpenTelemetry spans of requests and DB commands go to TraceExporter, "otlp" or "stdout", empty disables them
+	TraceExporter    string
+	OTLPEndpoint     string  // OTLP/HTTP endpoint of the collector, e.g. http://localhost:4318
+	TraceFile        string  // file the stdout exporter appends spans to, empty writes them to stdout
+	TraceSampleRatio float64 // share of new traces that are recorded, defaults to DefTraceSampleRatio
+
+	// Soft-deleted orders are permanently purged once they are older than DeletedOrderRetention
+	DeletedOrderRetention time.Duration // defaults to DefDeletedOrderRetention
+	PurgeInterval         time.Duration // how often the purger runs, defaults to DefPurgeInterval
+
+	// Pending orders are cancelled and their stock released once they are older than ReservationTTL
+	ReservationTTL           time.Duration // defaults to DefReservationTTL
+	ReservationSweepInterval time.Duration // how often the sweeper runs, defaults to DefReservationSweepInterval
+
+	ReportsCacheTTL time.Duration // how long report results are cached, defaults to DefReportsCacheTTL, zero disables it
+
+	// Flat tax percentages keyed by region, e.g. taxRates="US-CA=7.25,DE=19"
+	TaxRates       map[string]money.Amount
+	DefaultTaxRate money.Amount // applied to regions without a rate, defaults to 0
+
+	// Tenant databases keyed by tenant ID, e.g. tenants="acme=ecommerce_acme,globex=ecommerce_globex".
+	// No tenants means the service runs single-tenant on DBName.
+	Tenants map[string]string
+	// Tenant IDs keyed by the host name they are served on, e.g. tenantHosts="shop.acme.com=acme"
+	TenantHosts map[string]string
+
+	// OpenAPI document requests are validated against, empty disables validation. Defaults to DefOpenAPISpec
+	OpenAPISpec       string
+	ValidateResponses bool // log responses that break OpenAPISpec, meant for dev and test, defaults to false
+
+	// Deprecated API versions, e.g. deprecatedAPIs="v1=2026-01-01" and apiSunsets="v1=2026-07-01"
+	APIDeprecations map[string]APIDeprecation
+}
+
+// APIDeprecation describes when an API version was deprecated and, optionally, when it goes away.
+type APIDeprecation struct {
+	DeprecatedAt time.Time
+	Sunset       time.Time // zero when no sunset date is announced
 }
 
 const (
@@ -29,7 +90,26 @@ const (
 	DefDatabase       = "ecommerce"
 	DefEnvironment    = "local"
 	DefDBQueryLogging = false
+
+	DefRequestLogSampleEvery = 1
+	DefLogBurstWindow        = time.Minute
+
+	DefDBSlowQueryThreshold = 100 * time.Millisecond
+	// DefDBRedactFields are the fields of orders and audit entries holding customer data
+	DefDBRedactFields = "user,customer,shippingAddress,actor"
 	DefEnableTracing  = false
+
+	DefTraceSampleRatio = 1.0
+
+	DefDeletedOrderRetention = 30 * 24 * time.Hour
+	DefPurgeInterval         = time.Hour
+
+	DefReservationTTL           = 30 * time.Minute
+	DefReservationSweepInterval = time.Minute
+
+	DefReportsCacheTTL = 5 * time.Minute
+
+	DefOpenAPISpec = "OpenApi-v1.yaml"
 )
 
 // Load reads all environmental configurations and returns a ServiceEnvConfig.
@@ -62,6 +142,11 @@ func Load() (*ServiceEnvConfig, error) {
 		printDBQueries = DefDBQueryLogging
 	}
 
+	dbRedactFields, redactSet := os.LookupEnv("dbRedactFields")
+	if !redactSet {
+		dbRedactFields = DefDBRedactFields
+	}
+
 	disableAuth, authEnvErr := strconv.ParseBool(os.Getenv("disableAuth"))
 	if authEnvErr != nil {
 		// do not disable authentication by default, added this flexibility just for local development purpose
@@ -74,22 +159,251 @@ func Load() (*ServiceEnvConfig, error) {
 		enableTracing = DefEnableTracing
 	}
 
+	traceExporter := os.Getenv("traceExporter")
+	if traceExporter != "" && traceExporter != tracing.ExporterOTLP && traceExporter != tracing.ExporterStdout {
+		return nil, fmt.Errorf("invalid traceExporter %q, expected %s or %s",
+			traceExporter, tracing.ExporterOTLP, tracing.ExporterStdout)
+	}
+	traceSampleRatio := DefTraceSampleRatio
+	if v := os.Getenv("traceSampleRatio"); v != "" {
+		ratio, ratioErr := strconv.ParseFloat(v, 64)
+		if ratioErr != nil || ratio < 0 || ratio > 1 {
+			return nil, fmt.Errorf("invalid traceSampleRatio %q, expected a number between 0 and 1", v)
+		}
+		traceSampleRatio = ratio
+	}
+
+	deletedOrderRetention := durationFromEnv("deletedOrderRetention", DefDeletedOrderRetention)
+	purgeInterval := durationFromEnv("purgeInterval", DefPurgeInterval)
+	reservationTTL := durationFromEnv("reservationTTL", DefReservationTTL)
+	reservationSweepInterval := durationFromEnv("reservationSweepInterval", DefReservationSweepInterval)
+	reportsCacheTTL := durationFromEnv("reportsCacheTTL", DefReportsCacheTTL)
+
+	taxRates, taxErr := parseTaxRates(os.Getenv("taxRates"))
+	if taxErr != nil {
+		return nil, taxErr
+	}
+	var defaultTaxRate money.Amount
+	if v := os.Getenv("defaultTaxRate"); v != "" {
+		if defaultTaxRate, taxErr = money.Parse(v); taxErr != nil {
+			return nil, fmt.Errorf("invalid defaultTaxRate %q: %w", v, taxErr)
+		}
+	}
+
+	tenants, tenantErr := parsePairs("tenants", os.Getenv("tenants"))
+	if tenantErr != nil {
+		return nil, tenantErr
+	}
+	tenantHosts, tenantErr := parsePairs("tenantHosts", os.Getenv("tenantHosts"))
+	if tenantErr != nil {
+		return nil, tenantErr
+	}
+
+	openAPISpec, specSet := os.LookupEnv("openAPISpec")
+	if !specSet {
+		openAPISpec = DefOpenAPISpec
+	}
+	validateResponses, _ := strconv.ParseBool(os.Getenv("validateResponses"))
+
+	apiDeprecations, deprecationErr := parseDeprecations(os.Getenv("deprecatedAPIs"), os.Getenv("apiSunsets"))
+	if deprecationErr != nil {
+		return nil, deprecationErr
+	}
+
 	logLevel := os.Getenv("logLevel")
 	if logLevel == "" {
 		logLevel = DefaultLogLevel
 	}
+	logComponentLevels, levelsErr := parsePairs("logComponentLevels", os.Getenv("logComponentLevels"))
+	if levelsErr != nil {
+		return nil, levelsErr
+	}
 
 	envConfigurations := &ServiceEnvConfig{
-		Environment:          envName,
-		Port:                 port,
-		DBHosts:              dbHosts,
-		DBName:               dbName,
-		DBCredentialsSideCar: dbCredentialsSideCar,
-		DisableAuth:          disableAuth,
-		EnableTracing:        enableTracing,
-		LogLevel:             logLevel,
-		DBLogQueries:         printDBQueries,
+		Environment:              envName,
+		Port:                     port,
+		DBHosts:                  dbHosts,
+		DBName:                   dbName,
+		DBCredentialsSideCar:     dbCredentialsSideCar,
+		DisableAuth:              disableAuth,
+		EnableTracing:            enableTracing,
+		TraceExporter:            traceExporter,
+		OTLPEndpoint:             os.Getenv("otlpEndpoint"),
+		TraceFile:                os.Getenv("traceFile"),
+		TraceSampleRatio:         traceSampleRatio,
+		LogLevel:                 logLevel,
+		LogComponentLevels:       logComponentLevels,
+		DBLogQueries:             printDBQueries,
+		DBSlowQueryThreshold:     durationFromEnv("dbSlowQueryThreshold", DefDBSlowQueryThreshold),
+		DBRedactFields:           splitList(dbRedactFields),
+		DeletedOrderRetention:    deletedOrderRetention,
+		PurgeInterval:            purgeInterval,
+		ReservationTTL:           reservationTTL,
+		ReservationSweepInterval: reservationSweepInterval,
+		ReportsCacheTTL:          reportsCacheTTL,
+		TaxRates:                 taxRates,
+		DefaultTaxRate:           defaultTaxRate,
+		Tenants:                  tenants,
+		TenantHosts:              tenantHosts,
+		OpenAPISpec:              openAPISpec,
+		ValidateResponses:        validateResponses,
+		APIDeprecations:          apiDeprecations,
+	}
+	if logErr := loadLogSampling(envConfigurations); logErr != nil {
+		return nil, logErr
 	}
 
 	return envConfigurations, nil
 }
+
+// loadLogSampling reads the sampling of request logs and the limits of identical messages into cfg.
+func loadLogSampling(cfg *ServiceEnvConfig) error {
+	var err error
+	if cfg.RequestLogSampleEvery, err = countFromEnv("requestLogSampleEvery", DefRequestLogSampleEvery); err != nil {
+		return err
+	}
+	if cfg.RequestLogRouteSampleEvery, err = parseCounts("requestLogRouteSampleEvery"); err != nil {
+		return err
+	}
+	if cfg.LogBurst, err = countFromEnv("logBurst", 0); err != nil {
+		return err
+	}
+	if cfg.LogRouteBurst, err = parseCounts("logRouteBurst"); err != nil {
+		return err
+	}
+	cfg.LogBurstWindow = durationFromEnv("logBurstWindow", DefLogBurstWindow)
+	return nil
+}
+
+// countFromEnv parses a non-negative integer from the named env variable, falling back to def when it is unset.
+func countFromEnv(name string, def int) (int, error) {
+	v := os.Getenv(name)
+	if v == "" {
+		return def, nil
+	}
+	n, err := strconv.Atoi(strings.TrimSpace(v))
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid %s %q, expected a non-negative integer", name, v)
+	}
+	return n, nil
+}
+
+// parseCounts parses the key=count pairs of the named env variable, e.g. "/healthz=100".
+func parseCounts(name string) (map[string]int, error) {
+	pairs, err := parsePairs(name, os.Getenv(name))
+	if err != nil {
+		return nil, err
+	}
+	counts := make(map[string]int, len(pairs))
+	for key, v := range pairs {
+		n, atoiErr := strconv.Atoi(v)
+		if atoiErr != nil || n < 0 {
+			return nil, fmt.Errorf("invalid %s entry for %s, expected a non-negative integer", name, key)
+		}
+		counts[key] = n
+	}
+	return counts, nil
+}
+
+// durationFromEnv parses a positive Go duration (e.g. "720h") from the named env variable,
+// falling back to def when it is unset or invalid.
+func durationFromEnv(name string, def time.Duration) time.Duration {
+	d, err := time.ParseDuration(os.Getenv(name))
+	if err != nil || d <= 0 {
+		return def
+	}
+	return d
+}
+
+// splitList splits a comma separated list, dropping empty entries.
+func splitList(s string) []string {
+	var items []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// parseTaxRates parses comma separated region=percent pairs such as "US-CA=7.25,DE=19".
+func parseTaxRates(s string) (map[string]money.Amount, error) {
+	rates := map[string]money.Amount{}
+	for _, pair := range strings.Split(s, ",") {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+		region, rate, ok := strings.Cut(pair, "=")
+		region = strings.TrimSpace(region)
+		if !ok || region == "" {
+			return nil, fmt.Errorf("invalid taxRates entry %q, expected region=percent", pair)
+		}
+		r, err := money.Parse(rate)
+		if err != nil {
+			return nil, fmt.Errorf("invalid taxRates entry %q: %w", pair, err)
+		}
+		rates[region] = r
+	}
+	return rates, nil
+}
+
+// parsePairs parses comma separated key=value pairs such as "acme=ecommerce_acme,globex=ecommerce_globex".
+func parsePairs(name, s string) (map[string]string, error) {
+	pairs := map[string]string{}
+	for _, pair := range strings.Split(s, ",") {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+		key, value, ok := strings.Cut(pair, "=")
+		key, value = strings.TrimSpace(key), strings.TrimSpace(value)
+		if !ok || key == "" || value == "" {
+			return nil, fmt.Errorf("invalid %s entry %q, expected key=value", name, pair)
+		}
+		pairs[key] = value
+	}
+	return pairs, nil
+}
+
+// parseDeprecations parses version=date pairs for deprecations and sunsets, dates are either
+// YYYY-MM-DD or RFC 3339. A version can only be sunset once it is deprecated.
+func parseDeprecations(deprecated, sunsets string) (map[string]APIDeprecation, error) {
+	deprecatedAt, err := parsePairs("deprecatedAPIs", deprecated)
+	if err != nil {
+		return nil, err
+	}
+	sunsetAt, err := parsePairs("apiSunsets", sunsets)
+	if err != nil {
+		return nil, err
+	}
+	deprecations := map[string]APIDeprecation{}
+	for version, v := range deprecatedAt {
+		d, dateErr := parseDate(v)
+		if dateErr != nil {
+			return nil, fmt.Errorf("invalid deprecatedAPIs entry for %s: %w", version, dateErr)
+		}
+		deprecations[version] = APIDeprecation{DeprecatedAt: d}
+	}
+	for version, v := range sunsetAt {
+		dep, ok := deprecations[version]
+		if !ok {
+			return nil, fmt.Errorf("apiSunsets entry for %s has no matching deprecatedAPIs entry", version)
+		}
+		d, dateErr := parseDate(v)
+		if dateErr != nil {
+			return nil, fmt.Errorf("invalid apiSunsets entry for %s: %w", version, dateErr)
+		}
+		if d.Before(dep.DeprecatedAt) {
+			return nil, fmt.Errorf("apiSunsets entry for %s is before its deprecation", version)
+		}
+		dep.Sunset = d
+		deprecations[version] = dep
+	}
+	return deprecations, nil
+}
+
+func parseDate(s string) (time.Time, error) {
+	if d, err := time.Parse(time.DateOnly, s); err == nil {
+		return d, nil
+	}
+	return time.Parse(time.RFC3339, s)
+}
@@ -2,8 +2,10 @@ package config_test
 
 import (
 	"testing"
+	"time"
 
 	"github.com/rameshsunkara/go-rest-api-example/internal/config"
+	"github.com/rameshsunkara/go-rest-api-example/pkg/money"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -133,3 +135,431 @@ func TestEnableTracingConfiguration(t *testing.T) {
 		})
 	}
 }
+
+func TestPurgeConfiguration(t *testing.T) {
+	tests := []struct {
+		name              string
+		retention         string
+		interval          string
+		expectedRetention time.Duration
+		expectedInterval  time.Duration
+	}{
+		{
+			name:              "defaults when not set",
+			expectedRetention: config.DefDeletedOrderRetention,
+			expectedInterval:  config.DefPurgeInterval,
+		},
+		{
+			name:              "custom values",
+			retention:         "48h",
+			interval:          "10m",
+			expectedRetention: 48 * time.Hour,
+			expectedInterval:  10 * time.Minute,
+		},
+		{
+			name:              "invalid and negative values fall back to defaults",
+			retention:         "soon",
+			interval:          "-1m",
+			expectedRetention: config.DefDeletedOrderRetention,
+			expectedInterval:  config.DefPurgeInterval,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("dbHosts", "localhost:27017")
+			t.Setenv("DBCredentialsSideCar", "/path/to/credentials")
+			t.Setenv("deletedOrderRetention", tt.retention)
+			t.Setenv("purgeInterval", tt.interval)
+
+			cfg, err := config.Load()
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedRetention, cfg.DeletedOrderRetention)
+			assert.Equal(t, tt.expectedInterval, cfg.PurgeInterval)
+		})
+	}
+}
+
+func TestReservationConfiguration(t *testing.T) {
+	tests := []struct {
+		name             string
+		ttl              string
+		interval         string
+		expectedTTL      time.Duration
+		expectedInterval time.Duration
+	}{
+		{
+			name:             "defaults when not set",
+			expectedTTL:      config.DefReservationTTL,
+			expectedInterval: config.DefReservationSweepInterval,
+		},
+		{
+			name:             "custom values",
+			ttl:              "2h",
+			interval:         "30s",
+			expectedTTL:      2 * time.Hour,
+			expectedInterval: 30 * time.Second,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("dbHosts", "localhost:27017")
+			t.Setenv("DBCredentialsSideCar", "/path/to/credentials")
+			t.Setenv("reservationTTL", tt.ttl)
+			t.Setenv("reservationSweepInterval", tt.interval)
+
+			cfg, err := config.Load()
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedTTL, cfg.ReservationTTL)
+			assert.Equal(t, tt.expectedInterval, cfg.ReservationSweepInterval)
+		})
+	}
+}
+
+func TestReportsCacheConfiguration(t *testing.T) {
+	tests := []struct {
+		name     string
+		ttl      string
+		expected time.Duration
+	}{
+		{name: "default when not set", expected: config.DefReportsCacheTTL},
+		{name: "default when invalid", ttl: "soon", expected: config.DefReportsCacheTTL},
+		{name: "custom value", ttl: "90s", expected: 90 * time.Second},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("dbHosts", "localhost:27017")
+			t.Setenv("DBCredentialsSideCar", "/path/to/credentials")
+			t.Setenv("reportsCacheTTL", tt.ttl)
+
+			cfg, err := config.Load()
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, cfg.ReportsCacheTTL)
+		})
+	}
+}
+
+func TestTaxConfiguration(t *testing.T) {
+	tests := []struct {
+		name        string
+		rates       string
+		defaultRate string
+		wantRates   map[string]money.Amount
+		wantDefault money.Amount
+		wantErr     bool
+	}{
+		{
+			name:      "defaults when not set",
+			wantRates: map[string]money.Amount{},
+		},
+		{
+			name:        "custom values",
+			rates:       "US-CA=7.25, DE=19,",
+			defaultRate: "5",
+			wantRates:   map[string]money.Amount{"US-CA": money.MustParse("7.25"), "DE": money.MustParse("19")},
+			wantDefault: money.MustParse("5"),
+		},
+		{name: "missing rate", rates: "US-CA", wantErr: true},
+		{name: "invalid rate", rates: "US-CA=abc", wantErr: true},
+		{name: "invalid default rate", defaultRate: "five", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("dbHosts", "localhost:27017")
+			t.Setenv("DBCredentialsSideCar", "/path/to/credentials")
+			t.Setenv("taxRates", tt.rates)
+			t.Setenv("defaultTaxRate", tt.defaultRate)
+
+			cfg, err := config.Load()
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantRates, cfg.TaxRates)
+			assert.Equal(t, tt.wantDefault, cfg.DefaultTaxRate)
+		})
+	}
+}
+
+func TestTenantConfiguration(t *testing.T) {
+	tests := []struct {
+		name        string
+		tenants     string
+		hosts       string
+		wantTenants map[string]string
+		wantHosts   map[string]string
+		wantErr     bool
+	}{
+		{
+			name:        "single tenant when not set",
+			wantTenants: map[string]string{},
+			wantHosts:   map[string]string{},
+		},
+		{
+			name:        "custom values",
+			tenants:     "acme=ecommerce_acme, globex=ecommerce_globex,",
+			hosts:       "shop.acme.com=acme",
+			wantTenants: map[string]string{"acme": "ecommerce_acme", "globex": "ecommerce_globex"},
+			wantHosts:   map[string]string{"shop.acme.com": "acme"},
+		},
+		{name: "missing database", tenants: "acme", wantErr: true},
+		{name: "empty database", tenants: "acme=", wantErr: true},
+		{name: "missing host tenant", hosts: "shop.acme.com", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("dbHosts", "localhost:27017")
+			t.Setenv("DBCredentialsSideCar", "/path/to/credentials")
+			t.Setenv("tenants", tt.tenants)
+			t.Setenv("tenantHosts", tt.hosts)
+
+			cfg, err := config.Load()
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantTenants, cfg.Tenants)
+			assert.Equal(t, tt.wantHosts, cfg.TenantHosts)
+		})
+	}
+}
+
+func TestAPIDeprecationConfiguration(t *testing.T) {
+	tests := []struct {
+		name       string
+		deprecated string
+		sunsets    string
+		want       map[string]config.APIDeprecation
+		wantErr    bool
+	}{
+		{name: "nothing deprecated when not set", want: map[string]config.APIDeprecation{}},
+		{
+			name:       "deprecation without sunset",
+			deprecated: "v1=2026-01-01",
+			want: map[string]config.APIDeprecation{
+				"v1": {DeprecatedAt: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)},
+			},
+		},
+		{
+			name:       "deprecation with sunset",
+			deprecated: "v1=2026-01-01",
+			sunsets:    "v1=2026-07-01T12:00:00Z",
+			want: map[string]config.APIDeprecation{
+				"v1": {
+					DeprecatedAt: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+					Sunset:       time.Date(2026, 7, 1, 12, 0, 0, 0, time.UTC),
+				},
+			},
+		},
+		{name: "invalid date", deprecated: "v1=tomorrow", wantErr: true},
+		{name: "sunset without deprecation", sunsets: "v1=2026-07-01", wantErr: true},
+		{name: "sunset before deprecation", deprecated: "v1=2026-07-01", sunsets: "v1=2026-01-01", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("dbHosts", "localhost:27017")
+			t.Setenv("DBCredentialsSideCar", "/path/to/credentials")
+			t.Setenv("deprecatedAPIs", tt.deprecated)
+			t.Setenv("apiSunsets", tt.sunsets)
+
+			cfg, err := config.Load()
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, cfg.APIDeprecations)
+		})
+	}
+}
+
+func TestOpenAPIValidationConfiguration(t *testing.T) {
+	tests := []struct {
+		name              string
+		env               map[string]string
+		wantSpec          string
+		wantValidateResps bool
+	}{
+		{name: "defaults", wantSpec: config.DefOpenAPISpec},
+		{
+			name:              "custom values",
+			env:               map[string]string{"openAPISpec": "/etc/api.yaml", "validateResponses": "true"},
+			wantSpec:          "/etc/api.yaml",
+			wantValidateResps: true,
+		},
+		{name: "disabled", env: map[string]string{"openAPISpec": ""}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("dbHosts", "localhost:27017")
+			t.Setenv("DBCredentialsSideCar", "/path/to/credentials")
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+
+			cfg, err := config.Load()
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantSpec, cfg.OpenAPISpec)
+			assert.Equal(t, tt.wantValidateResps, cfg.ValidateResponses)
+		})
+	}
+}
+
+func TestOpenTelemetryConfiguration(t *testing.T) {
+	tests := []struct {
+		name    string
+		env     map[string]string
+		want    config.ServiceEnvConfig
+		wantErr bool
+	}{
+		{name: "defaults", want: config.ServiceEnvConfig{TraceSampleRatio: config.DefTraceSampleRatio}},
+		{
+			name: "otlp",
+			env: map[string]string{
+				"traceExporter": "otlp", "otlpEndpoint": "http://collector:4318", "traceSampleRatio": "0.25",
+			},
+			want: config.ServiceEnvConfig{
+				TraceExporter: "otlp", OTLPEndpoint: "http://collector:4318", TraceSampleRatio: 0.25,
+			},
+		},
+		{
+			name: "stdout to a file",
+			env:  map[string]string{"traceExporter": "stdout", "traceFile": "traces.json"},
+			want: config.ServiceEnvConfig{
+				TraceExporter: "stdout", TraceFile: "traces.json", TraceSampleRatio: config.DefTraceSampleRatio,
+			},
+		},
+		{name: "unknown exporter", env: map[string]string{"traceExporter": "jaeger"}, wantErr: true},
+		{name: "ratio above one", env: map[string]string{"traceSampleRatio": "2"}, wantErr: true},
+		{name: "ratio not a number", env: map[string]string{"traceSampleRatio": "all"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("dbHosts", "localhost:27017")
+			t.Setenv("DBCredentialsSideCar", "/path/to/credentials")
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+
+			cfg, err := config.Load()
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want.TraceExporter, cfg.TraceExporter)
+			assert.Equal(t, tt.want.OTLPEndpoint, cfg.OTLPEndpoint)
+			assert.Equal(t, tt.want.TraceFile, cfg.TraceFile)
+			assert.InDelta(t, tt.want.TraceSampleRatio, cfg.TraceSampleRatio, 0)
+		})
+	}
+}
+
+func TestDBQueryLoggingConfiguration(t *testing.T) {
+	tests := []struct {
+		name          string
+		env           map[string]string
+		wantThreshold time.Duration
+		wantRedact    []string
+	}{
+		{
+			name:          "defaults",
+			wantThreshold: config.DefDBSlowQueryThreshold,
+			wantRedact:    []string{"user", "customer", "shippingAddress", "actor"},
+		},
+		{
+			name:          "custom values",
+			env:           map[string]string{"dbSlowQueryThreshold": "1s", "dbRedactFields": " customer.email, ,user "},
+			wantThreshold: time.Second,
+			wantRedact:    []string{"customer.email", "user"},
+		},
+		{
+			name:          "redaction disabled",
+			env:           map[string]string{"dbRedactFields": ""},
+			wantThreshold: config.DefDBSlowQueryThreshold,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("dbHosts", "localhost:27017")
+			t.Setenv("DBCredentialsSideCar", "/path/to/credentials")
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+
+			cfg, err := config.Load()
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantThreshold, cfg.DBSlowQueryThreshold)
+			assert.Equal(t, tt.wantRedact, cfg.DBRedactFields)
+		})
+	}
+}
+
+func TestLogComponentLevels(t *testing.T) {
+	t.Setenv("dbHosts", "localhost:27017")
+	t.Setenv("DBCredentialsSideCar", "/path/to/credentials")
+
+	cfg, err := config.Load()
+	require.NoError(t, err)
+	assert.Empty(t, cfg.LogComponentLevels)
+
+	t.Setenv("logComponentLevels", "mongodb=debug, http=info")
+	cfg, err = config.Load()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"mongodb": "debug", "http": "info"}, cfg.LogComponentLevels)
+
+	t.Setenv("logComponentLevels", "mongodb")
+	_, err = config.Load()
+	require.Error(t, err)
+}
+
+func TestLogSampling(t *testing.T) {
+	t.Setenv("dbHosts", "localhost:27017")
+	t.Setenv("DBCredentialsSideCar", "/path/to/credentials")
+
+	cfg, err := config.Load()
+	require.NoError(t, err)
+	assert.Equal(t, config.DefRequestLogSampleEvery, cfg.RequestLogSampleEvery)
+	assert.Empty(t, cfg.RequestLogRouteSampleEvery)
+	assert.Zero(t, cfg.LogBurst)
+	assert.Equal(t, config.DefLogBurstWindow, cfg.LogBurstWindow)
+	assert.Empty(t, cfg.LogRouteBurst)
+
+	t.Setenv("requestLogSampleEvery", "10")
+	t.Setenv("requestLogRouteSampleEvery", "/healthz=100, /ecommerce/v1/orders/:id=1")
+	t.Setenv("logBurst", "20")
+	t.Setenv("logBurstWindow", "30s")
+	t.Setenv("logRouteBurst", "/ecommerce/v1/orders=5")
+	cfg, err = config.Load()
+	require.NoError(t, err)
+	assert.Equal(t, 10, cfg.RequestLogSampleEvery)
+	assert.Equal(t, map[string]int{"/healthz": 100, "/ecommerce/v1/orders/:id": 1}, cfg.RequestLogRouteSampleEvery)
+	assert.Equal(t, 20, cfg.LogBurst)
+	assert.Equal(t, 30*time.Second, cfg.LogBurstWindow)
+	assert.Equal(t, map[string]int{"/ecommerce/v1/orders": 5}, cfg.LogRouteBurst)
+
+	for name, value := range map[string]string{
+		"requestLogSampleEvery":      "-1",
+		"requestLogRouteSampleEvery": "/healthz=often",
+		"logBurst":                   "many",
+		"logRouteBurst":              "/ecommerce/v1/orders",
+	} {
+		t.Setenv(name, value)
+		_, err = config.Load()
+		require.Error(t, err, name)
+		t.Setenv(name, "")
+	}
+}
@@ -0,0 +1,127 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/rameshsunkara/go-rest-api-example/internal/models/data"
+	"github.com/rameshsunkara/go-rest-api-example/pkg/logger"
+	"github.com/rameshsunkara/go-rest-api-example/pkg/mongodb"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	AuditCollection = "auditLog"
+)
+
+var (
+	ErrFailedToAppendAudit = errors.New("failed to append audit entry")
+	ErrUnexpectedGetAudit  = errors.New("unexpected error occurred while fetching audit entries")
+)
+
+// AuditDataService defines the interface for the append-only audit log.
+// There are intentionally no update or delete operations.
+type AuditDataService interface {
+	Append(ctx context.Context, entries ...data.AuditEntry) error
+	Search(ctx context.Context, filter AuditFilter, limit int64) (*[]data.AuditEntry, error)
+}
+
+// AuditFilter narrows down audit log searches, zero values are ignored.
+type AuditFilter struct {
+	OrderID   primitive.ObjectID
+	Actor     string
+	Action    data.AuditAction
+	RequestID string
+	From      time.Time
+	To        time.Time
+}
+
+// AuditRepo implements AuditDataService using MongoDB.
+type AuditRepo struct {
+	collection *mongo.Collection
+	logger     logger.Logger
+}
+
+// NewAuditRepo creates a new AuditRepo.
+func NewAuditRepo(lgr logger.Logger, db mongodb.MongoDatabase) (*AuditRepo, error) {
+	if lgr == nil || db == nil {
+		return nil, errors.New("missing required inputs to create AuditRepo")
+	}
+	// decode nested change values as bson.M so they serialize back to plain JSON objects
+	collOpts := options.Collection().SetBSONOptions(&options.BSONOptions{DefaultDocumentM: true})
+	return &AuditRepo{
+		collection: db.Collection(AuditCollection, collOpts),
+		logger:     lgr,
+	}, nil
+}
+
+// Append inserts the given audit entries.
+func (a *AuditRepo) Append(ctx context.Context, entries ...data.AuditEntry) error {
+	if err := validateCollection(a.collection); err != nil {
+		return err
+	}
+	if len(entries) == 0 {
+		return nil
+	}
+	docs := make([]interface{}, len(entries))
+	for i := range entries {
+		docs[i] = entries[i]
+	}
+	if _, err := a.collection.InsertMany(ctx, docs); err != nil {
+		a.logger.Error().Err(err).Int("entries", len(entries)).Msg("failed to append audit entries")
+		return ErrFailedToAppendAudit
+	}
+	return nil
+}
+
+// Search returns the most recent audit entries matching the filter, newest first.
+func (a *AuditRepo) Search(ctx context.Context, filter AuditFilter, limit int64) (*[]data.AuditEntry, error) {
+	if err := validateCollection(a.collection); err != nil {
+		return nil, err
+	}
+	findOptions := options.Find().
+		SetSort(bson.D{{Key: "timestamp", Value: -1}}).
+		SetLimit(limit)
+	cursor, err := a.collection.Find(ctx, filter.toBSON(), findOptions)
+	if err != nil {
+		a.logger.Error().Err(err).Msg("failed to find audit entries")
+		return nil, ErrUnexpectedGetAudit
+	}
+	results := []data.AuditEntry{}
+	if err = cursor.All(ctx, &results); err != nil {
+		a.logger.Error().Err(err).Msg("failed to decode audit entries")
+		return nil, ErrUnexpectedGetAudit
+	}
+	return &results, nil
+}
+
+func (f AuditFilter) toBSON() bson.D {
+	filter := bson.D{}
+	if !f.OrderID.IsZero() {
+		filter = append(filter, bson.E{Key: "orderId", Value: f.OrderID})
+	}
+	if f.Actor != "" {
+		filter = append(filter, bson.E{Key: "actor", Value: f.Actor})
+	}
+	if f.Action != "" {
+		filter = append(filter, bson.E{Key: "action", Value: f.Action})
+	}
+	if f.RequestID != "" {
+		filter = append(filter, bson.E{Key: "requestId", Value: f.RequestID})
+	}
+	timeRange := bson.D{}
+	if !f.From.IsZero() {
+		timeRange = append(timeRange, bson.E{Key: "$gte", Value: f.From})
+	}
+	if !f.To.IsZero() {
+		timeRange = append(timeRange, bson.E{Key: "$lt", Value: f.To})
+	}
+	if len(timeRange) > 0 {
+		filter = append(filter, bson.E{Key: "timestamp", Value: timeRange})
+	}
+	return filter
+}
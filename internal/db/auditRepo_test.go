@@ -0,0 +1,106 @@
+package db_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/rameshsunkara/go-rest-api-example/internal/db"
+	"github.com/rameshsunkara/go-rest-api-example/internal/db/mocks"
+	"github.com/rameshsunkara/go-rest-api-example/internal/models/data"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+)
+
+func TestNewAuditRepo(t *testing.T) {
+	t.Parallel()
+	_, err := db.NewAuditRepo(nil, &mocks.MockMongoDataBase{})
+	require.Error(t, err)
+	_, err = db.NewAuditRepo(testLgr, nil)
+	require.Error(t, err)
+	repo, err := db.NewAuditRepo(testLgr, &mocks.MockMongoDataBase{})
+	require.NoError(t, err)
+
+	// the mock database hands out nil collections
+	require.ErrorIs(t, repo.Append(context.Background(), data.AuditEntry{}), db.ErrInvalidInitialization)
+	_, err = repo.Search(context.Background(), db.AuditFilter{}, 10)
+	require.ErrorIs(t, err, db.ErrInvalidInitialization)
+}
+
+func TestAuditRepoAppend(t *testing.T) {
+	t.Parallel()
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	entry := data.AuditEntry{
+		Action:    data.AuditCreate,
+		OrderID:   primitive.NewObjectID(),
+		Actor:     "agent@example.com",
+		RequestID: "req-1",
+		Timestamp: time.Now(),
+	}
+
+	mt.Run("Success", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateSuccessResponse())
+		repo, err := db.NewAuditRepo(testLgr, mt.DB)
+		require.NoError(t, err)
+		require.NoError(t, repo.Append(context.TODO(), entry, entry))
+	})
+
+	mt.Run("NoEntries", func(mt *mtest.T) {
+		repo, err := db.NewAuditRepo(testLgr, mt.DB)
+		require.NoError(t, err)
+		require.NoError(t, repo.Append(context.TODO()))
+	})
+
+	mt.Run("InsertError", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateWriteErrorsResponse(mtest.WriteError{Code: 11000}))
+		repo, err := db.NewAuditRepo(testLgr, mt.DB)
+		require.NoError(t, err)
+		assert.Equal(t, db.ErrFailedToAppendAudit, repo.Append(context.TODO(), entry))
+	})
+}
+
+func TestAuditRepoSearch(t *testing.T) {
+	t.Parallel()
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	ns := "ordersdb.auditLog"
+	orderID := primitive.NewObjectID()
+
+	mt.Run("Success", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateCursorResponse(1, ns, mtest.FirstBatch,
+			bson.D{
+				{Key: "_id", Value: primitive.NewObjectID()},
+				{Key: "action", Value: "transition"},
+				{Key: "orderId", Value: orderID},
+				{Key: "changes", Value: bson.A{
+					bson.D{{Key: "field", Value: "status"}, {Key: "before", Value: "OrderPending"},
+						{Key: "after", Value: "OrderShipped"}},
+				}},
+			}),
+			mtest.CreateCursorResponse(0, ns, mtest.NextBatch))
+		repo, err := db.NewAuditRepo(testLgr, mt.DB)
+		require.NoError(t, err)
+		entries, err := repo.Search(context.TODO(), db.AuditFilter{
+			OrderID: orderID,
+			Actor:   "agent@example.com",
+			Action:  data.AuditTransition,
+			From:    time.Now().Add(-time.Hour),
+			To:      time.Now(),
+		}, 10)
+		require.NoError(t, err)
+		require.Len(t, *entries, 1)
+		assert.Equal(t, data.AuditTransition, (*entries)[0].Action)
+		assert.Equal(t, "OrderShipped", (*entries)[0].Changes[0].After)
+	})
+
+	mt.Run("FindError", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateCommandErrorResponse(mtest.CommandError{Code: 1, Message: "boom"}))
+		repo, err := db.NewAuditRepo(testLgr, mt.DB)
+		require.NoError(t, err)
+		_, err = repo.Search(context.TODO(), db.AuditFilter{}, 10)
+		assert.Equal(t, db.ErrUnexpectedGetAudit, err)
+	})
+}
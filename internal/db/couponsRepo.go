@@ -0,0 +1,135 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/rameshsunkara/go-rest-api-example/internal/models/data"
+	"github.com/rameshsunkara/go-rest-api-example/pkg/logger"
+	"github.com/rameshsunkara/go-rest-api-example/pkg/mongodb"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const (
+	CouponsCollection = "coupons"
+)
+
+var (
+	ErrCouponNotFound         = errors.New("coupon doesn't exist with given code")
+	ErrCouponExists           = errors.New("coupon already exists with given code")
+	ErrCouponNotRedeemable    = errors.New("coupon is expired or has reached its usage cap")
+	ErrFailedToCreateCoupon   = errors.New("failed to create coupon")
+	ErrUnexpectedGetCoupon    = errors.New("unexpected error occurred while fetching coupon")
+	ErrUnexpectedRedeemCoupon = errors.New("unexpected error occurred while redeeming coupon")
+)
+
+// CouponsDataService defines the interface for coupon data operations.
+type CouponsDataService interface {
+	Create(ctx context.Context, coupon *data.Coupon) (string, error)
+	GetByCode(ctx context.Context, code string) (*data.Coupon, error)
+	Redeem(ctx context.Context, code string, at time.Time) error
+	Release(ctx context.Context, code string) error
+}
+
+// CouponsRepo implements CouponsDataService using MongoDB.
+type CouponsRepo struct {
+	collection *mongo.Collection
+	logger     logger.Logger
+}
+
+// NewCouponsRepo creates a new CouponsRepo.
+func NewCouponsRepo(lgr logger.Logger, db mongodb.MongoDatabase) (*CouponsRepo, error) {
+	if lgr == nil || db == nil {
+		return nil, errors.New("missing required inputs to create CouponsRepo")
+	}
+	return &CouponsRepo{
+		collection: db.Collection(CouponsCollection),
+		logger:     lgr,
+	}, nil
+}
+
+// Create inserts a new coupon, codes are unique.
+func (c *CouponsRepo) Create(ctx context.Context, coupon *data.Coupon) (string, error) {
+	if err := validateCollection(c.collection); err != nil {
+		return "", err
+	}
+	coupon.Code = data.NormalizeCouponCode(coupon.Code)
+	result, err := c.collection.InsertOne(ctx, coupon)
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return "", ErrCouponExists
+		}
+		c.logger.Error().Err(err).Msg("failed to create coupon")
+		return "", ErrFailedToCreateCoupon
+	}
+	insertedID, ok := result.InsertedID.(primitive.ObjectID)
+	if !ok {
+		return "", ErrInvalidID
+	}
+	c.logger.Info().Str("code", coupon.Code).Msg("created new coupon")
+	return insertedID.Hex(), nil
+}
+
+// GetByCode retrieves a coupon by its case-insensitive code.
+func (c *CouponsRepo) GetByCode(ctx context.Context, code string) (*data.Coupon, error) {
+	if err := validateCollection(c.collection); err != nil {
+		return nil, err
+	}
+	var result data.Coupon
+	err := c.collection.FindOne(ctx, bson.D{{Key: "code", Value: data.NormalizeCouponCode(code)}}).Decode(&result)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrCouponNotFound
+		}
+		c.logger.Error().Err(err).Msg("failed to get coupon by code")
+		return nil, ErrUnexpectedGetCoupon
+	}
+	return &result, nil
+}
+
+// Redeem atomically counts one use of the coupon, provided it is valid at the given time
+// and has not reached its usage cap.
+func (c *CouponsRepo) Redeem(ctx context.Context, code string, at time.Time) error {
+	if err := validateCollection(c.collection); err != nil {
+		return err
+	}
+	filter := bson.D{
+		{Key: "code", Value: data.NormalizeCouponCode(code)},
+		{Key: "validFrom", Value: bson.D{{Key: "$lte", Value: at}}},
+		{Key: "validUntil", Value: bson.D{{Key: "$gt", Value: at}}},
+		{Key: "$or", Value: bson.A{
+			bson.D{{Key: "maxUses", Value: 0}},
+			bson.D{{Key: "$expr", Value: bson.D{{Key: "$lt", Value: bson.A{"$uses", "$maxUses"}}}}},
+		}},
+	}
+	update := bson.D{{Key: "$inc", Value: bson.D{{Key: "uses", Value: 1}}}}
+	res, err := c.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		c.logger.Error().Err(err).Msg("failed to redeem coupon")
+		return ErrUnexpectedRedeemCoupon
+	}
+	if res.MatchedCount == 0 {
+		return ErrCouponNotRedeemable
+	}
+	return nil
+}
+
+// Release gives back a use counted by Redeem, e.g. when the order could not be saved.
+func (c *CouponsRepo) Release(ctx context.Context, code string) error {
+	if err := validateCollection(c.collection); err != nil {
+		return err
+	}
+	filter := bson.D{
+		{Key: "code", Value: data.NormalizeCouponCode(code)},
+		{Key: "uses", Value: bson.D{{Key: "$gt", Value: 0}}},
+	}
+	update := bson.D{{Key: "$inc", Value: bson.D{{Key: "uses", Value: -1}}}}
+	if _, err := c.collection.UpdateOne(ctx, filter, update); err != nil {
+		c.logger.Error().Err(err).Msg("failed to release coupon")
+		return ErrUnexpectedRedeemCoupon
+	}
+	return nil
+}
@@ -0,0 +1,163 @@
+package db_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/rameshsunkara/go-rest-api-example/internal/db"
+	"github.com/rameshsunkara/go-rest-api-example/internal/db/mocks"
+	"github.com/rameshsunkara/go-rest-api-example/internal/models/data"
+	"github.com/rameshsunkara/go-rest-api-example/pkg/money"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+)
+
+func TestNewCouponsRepo(t *testing.T) {
+	t.Parallel()
+	_, err := db.NewCouponsRepo(nil, &mocks.MockMongoDataBase{})
+	require.Error(t, err)
+	_, err = db.NewCouponsRepo(testLgr, nil)
+	require.Error(t, err)
+
+	repo, err := db.NewCouponsRepo(testLgr, &mocks.MockMongoDataBase{})
+	require.NoError(t, err)
+	_, err = repo.Create(context.Background(), &data.Coupon{})
+	require.ErrorIs(t, err, db.ErrInvalidInitialization)
+	_, err = repo.GetByCode(context.Background(), "X")
+	require.ErrorIs(t, err, db.ErrInvalidInitialization)
+	require.ErrorIs(t, repo.Redeem(context.Background(), "X", time.Now()), db.ErrInvalidInitialization)
+	require.ErrorIs(t, repo.Release(context.Background(), "X"), db.ErrInvalidInitialization)
+}
+
+func TestCouponsRepoCreate(t *testing.T) {
+	t.Parallel()
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("Success", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateSuccessResponse())
+		repo, err := db.NewCouponsRepo(testLgr, mt.DB)
+		require.NoError(t, err)
+		coupon := &data.Coupon{Code: " save10 ", Kind: data.DiscountPercent, Value: money.MustParse("10")}
+		id, err := repo.Create(context.TODO(), coupon)
+		require.NoError(t, err)
+		assert.NotEmpty(t, id)
+		assert.Equal(t, "SAVE10", coupon.Code)
+	})
+
+	mt.Run("DuplicateCode", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateWriteErrorsResponse(mtest.WriteError{Code: 11000, Message: "duplicate key"}))
+		repo, err := db.NewCouponsRepo(testLgr, mt.DB)
+		require.NoError(t, err)
+		_, err = repo.Create(context.TODO(), &data.Coupon{Code: "SAVE10"})
+		assert.Equal(t, db.ErrCouponExists, err)
+	})
+
+	mt.Run("InsertError", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateCommandErrorResponse(mtest.CommandError{Code: 1, Message: "boom"}))
+		repo, err := db.NewCouponsRepo(testLgr, mt.DB)
+		require.NoError(t, err)
+		_, err = repo.Create(context.TODO(), &data.Coupon{Code: "SAVE10"})
+		assert.Equal(t, db.ErrFailedToCreateCoupon, err)
+	})
+}
+
+func TestCouponsRepoGetByCode(t *testing.T) {
+	t.Parallel()
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	ns := "ordersdb.coupons"
+
+	mt.Run("Success", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateCursorResponse(1, ns, mtest.FirstBatch, bson.D{
+			{Key: "_id", Value: primitive.NewObjectID()},
+			{Key: "code", Value: "SAVE10"},
+			{Key: "kind", Value: "percent"},
+			{Key: "value", Value: 10},
+			{Key: "maxUses", Value: int64(5)},
+		}))
+		repo, err := db.NewCouponsRepo(testLgr, mt.DB)
+		require.NoError(t, err)
+		coupon, err := repo.GetByCode(context.TODO(), "save10")
+		require.NoError(t, err)
+		assert.Equal(t, "SAVE10", coupon.Code)
+		assert.Equal(t, money.MustParse("10"), coupon.Value)
+		assert.Equal(t, int64(5), coupon.MaxUses)
+	})
+
+	mt.Run("NotFound", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, ns, mtest.FirstBatch))
+		repo, err := db.NewCouponsRepo(testLgr, mt.DB)
+		require.NoError(t, err)
+		_, err = repo.GetByCode(context.TODO(), "NOPE")
+		assert.Equal(t, db.ErrCouponNotFound, err)
+	})
+
+	mt.Run("FindError", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateCommandErrorResponse(mtest.CommandError{Code: 1, Message: "boom"}))
+		repo, err := db.NewCouponsRepo(testLgr, mt.DB)
+		require.NoError(t, err)
+		_, err = repo.GetByCode(context.TODO(), "SAVE10")
+		assert.Equal(t, db.ErrUnexpectedGetCoupon, err)
+	})
+}
+
+func TestCouponsRepoRedeem(t *testing.T) {
+	t.Parallel()
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	tests := []struct {
+		name    string
+		reply   bson.D
+		wantErr error
+	}{
+		{name: "Redeemed", reply: mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 1})},
+		{
+			name:    "NotRedeemable",
+			reply:   mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 0}),
+			wantErr: db.ErrCouponNotRedeemable,
+		},
+		{
+			name:    "UpdateError",
+			reply:   mtest.CreateCommandErrorResponse(mtest.CommandError{Code: 1, Message: "boom"}),
+			wantErr: db.ErrUnexpectedRedeemCoupon,
+		},
+	}
+	for _, tt := range tests {
+		mt.Run(tt.name, func(mt *mtest.T) {
+			mt.AddMockResponses(tt.reply)
+			repo, err := db.NewCouponsRepo(testLgr, mt.DB)
+			require.NoError(t, err)
+			err = repo.Redeem(context.TODO(), "save10", time.Now())
+			if tt.wantErr != nil {
+				assert.Equal(t, tt.wantErr, err)
+				return
+			}
+			require.NoError(t, err)
+			filter := mt.GetStartedEvent().Command.Lookup("updates", "0", "q").String()
+			assert.Contains(t, filter, `"SAVE10"`)
+			assert.Contains(t, filter, "$maxUses")
+		})
+	}
+}
+
+func TestCouponsRepoRelease(t *testing.T) {
+	t.Parallel()
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("Success", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 1}))
+		repo, err := db.NewCouponsRepo(testLgr, mt.DB)
+		require.NoError(t, err)
+		require.NoError(t, repo.Release(context.TODO(), "SAVE10"))
+	})
+
+	mt.Run("UpdateError", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateCommandErrorResponse(mtest.CommandError{Code: 1, Message: "boom"}))
+		repo, err := db.NewCouponsRepo(testLgr, mt.DB)
+		require.NoError(t, err)
+		assert.Equal(t, db.ErrUnexpectedRedeemCoupon, repo.Release(context.TODO(), "SAVE10"))
+	})
+}
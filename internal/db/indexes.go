@@ -0,0 +1,43 @@
+package db
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/rameshsunkara/go-rest-api-example/pkg/mongodb"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// indexes are the indexes of the collections of a database, the unique ones back ErrCouponExists and the
+// externalRef upserts of imports.
+var indexes = map[string][]mongo.IndexModel{
+	OrdersCollection: {
+		{Keys: bson.D{{Key: "user", Value: 1}}},
+		{Keys: bson.D{{Key: "createdAt", Value: -1}}},
+		{Keys: bson.D{{Key: "status", Value: 1}}},
+		// soft-deleted orders are purged by deletedAt once the retention period has passed
+		{Keys: bson.D{{Key: "deletedAt", Value: 1}}, Options: options.Index().SetSparse(true)},
+		{Keys: bson.D{{Key: "externalRef", Value: 1}}, Options: options.Index().SetUnique(true).SetSparse(true)},
+		// the reservation sweeper looks for pending orders with reserved stock by age
+		{Keys: bson.D{{Key: "status", Value: 1}, {Key: "stockReserved", Value: 1}, {Key: "createdAt", Value: 1}}},
+	},
+	CouponsCollection:   {{Keys: bson.D{{Key: "code", Value: 1}}, Options: options.Index().SetUnique(true)}},
+	ProductsCollection:  {{Keys: bson.D{{Key: "sku", Value: 1}}, Options: options.Index().SetUnique(true)}},
+	InventoryCollection: {{Keys: bson.D{{Key: "sku", Value: 1}}, Options: options.Index().SetUnique(true)}},
+}
+
+// EnsureIndexes creates the indexes of the collections of d, indexes that already exist are left as they are.
+func EnsureIndexes(ctx context.Context, d mongodb.MongoDatabase) error {
+	for name, models := range indexes {
+		collection := d.Collection(name)
+		if err := validateCollection(collection); err != nil {
+			return err
+		}
+		if _, err := collection.Indexes().CreateMany(ctx, models); err != nil {
+			return fmt.Errorf("creating indexes of %s: %w", name, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,16 @@
+package db_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/rameshsunkara/go-rest-api-example/internal/db"
+	"github.com/rameshsunkara/go-rest-api-example/internal/db/mocks"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEnsureIndexes(t *testing.T) {
+	t.Parallel()
+	err := db.EnsureIndexes(context.Background(), &mocks.MockMongoDataBase{Name: "ecommerce_acme"})
+	require.ErrorIs(t, err, db.ErrInvalidInitialization)
+}
@@ -0,0 +1,244 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/rameshsunkara/go-rest-api-example/internal/models/data"
+	"github.com/rameshsunkara/go-rest-api-example/pkg/logger"
+	"github.com/rameshsunkara/go-rest-api-example/pkg/mongodb"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	InventoryCollection = "inventory"
+)
+
+var (
+	ErrInsufficientStock      = errors.New("insufficient stock")
+	ErrStockNotFound          = errors.New("no stock level exists for given sku")
+	ErrInvalidStockLevel      = errors.New("stock level cannot be negative")
+	ErrUnexpectedGetStock     = errors.New("unexpected error occurred while fetching stock")
+	ErrUnexpectedSetStock     = errors.New("unexpected error occurred while setting stock")
+	ErrUnexpectedReserveStock = errors.New("unexpected error occurred while reserving stock")
+	ErrUnexpectedReleaseStock = errors.New("unexpected error occurred while releasing stock")
+)
+
+// InsufficientStockError lists the order lines that exceed the available stock, it wraps ErrInsufficientStock.
+type InsufficientStockError struct {
+	Items []data.ShortItem
+}
+
+func (e *InsufficientStockError) Error() string {
+	items := make([]string, len(e.Items))
+	for i, item := range e.Items {
+		items[i] = fmt.Sprintf("%s (requested %d, available %d)", item.SKU, item.Requested, item.Available)
+	}
+	return ErrInsufficientStock.Error() + ": " + strings.Join(items, ", ")
+}
+
+func (e *InsufficientStockError) Unwrap() error {
+	return ErrInsufficientStock
+}
+
+// InventoryDataService defines the interface for stock level operations.
+// Reserve and Release use the SKU and quantity of each product, products without a SKU are ignored.
+type InventoryDataService interface {
+	SetStock(ctx context.Context, sku string, available int64) (*data.StockLevel, error)
+	GetBySKU(ctx context.Context, sku string) (*data.StockLevel, error)
+	Reserve(ctx context.Context, products []data.Product) error
+	Release(ctx context.Context, products []data.Product) error
+}
+
+// InventoryRepo implements InventoryDataService using MongoDB.
+type InventoryRepo struct {
+	collection *mongo.Collection
+	logger     logger.Logger
+}
+
+// NewInventoryRepo creates a new InventoryRepo.
+func NewInventoryRepo(lgr logger.Logger, db mongodb.MongoDatabase) (*InventoryRepo, error) {
+	if lgr == nil || db == nil {
+		return nil, errors.New("missing required inputs to create InventoryRepo")
+	}
+	return &InventoryRepo{
+		collection: db.Collection(InventoryCollection),
+		logger:     lgr,
+	}, nil
+}
+
+// SetStock sets the available stock of a SKU, creating its stock level when needed.
+func (i *InventoryRepo) SetStock(ctx context.Context, sku string, available int64) (*data.StockLevel, error) {
+	if err := validateCollection(i.collection); err != nil {
+		return nil, err
+	}
+	if available < 0 {
+		return nil, ErrInvalidStockLevel
+	}
+	level := data.StockLevel{SKU: data.NormalizeSKU(sku), Available: available, UpdatedAt: time.Now()}
+	filter := bson.D{{Key: "sku", Value: level.SKU}}
+	update := bson.D{{Key: "$set", Value: bson.D{
+		{Key: "available", Value: level.Available},
+		{Key: "updatedAt", Value: level.UpdatedAt},
+	}}}
+	res, err := i.collection.UpdateOne(ctx, filter, update, options.Update().SetUpsert(true))
+	if err != nil {
+		i.logger.Error().Err(err).Msg("failed to set stock")
+		return nil, ErrUnexpectedSetStock
+	}
+	if res.UpsertedCount > 0 {
+		i.logger.Info().Str("sku", level.SKU).Msg("created stock level")
+	}
+	return &level, nil
+}
+
+// GetBySKU retrieves the stock level of a SKU.
+func (i *InventoryRepo) GetBySKU(ctx context.Context, sku string) (*data.StockLevel, error) {
+	if err := validateCollection(i.collection); err != nil {
+		return nil, err
+	}
+	var level data.StockLevel
+	err := i.collection.FindOne(ctx, bson.D{{Key: "sku", Value: data.NormalizeSKU(sku)}}).Decode(&level)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrStockNotFound
+		}
+		i.logger.Error().Err(err).Msg("failed to get stock level")
+		return nil, ErrUnexpectedGetStock
+	}
+	return &level, nil
+}
+
+// Reserve takes the quantities of the products from the available stock. Every SKU is decremented
+// with a conditional $inc that only matches when enough stock is left, so concurrent orders can never
+// oversell. Either all products are reserved or none: when a SKU is short, the SKUs already reserved
+// are released and an *InsufficientStockError listing every short SKU is returned.
+func (i *InventoryRepo) Reserve(ctx context.Context, products []data.Product) error {
+	if err := validateCollection(i.collection); err != nil {
+		return err
+	}
+	skus, quantities := stockLines(products)
+	reserved := make([]string, 0, len(skus))
+	var short []data.ShortItem
+	for _, sku := range skus {
+		qty := quantities[sku]
+		if qty > math.MaxInt64 {
+			short = append(short, data.ShortItem{SKU: sku, Requested: qty})
+			continue
+		}
+		filter := bson.D{
+			{Key: "sku", Value: sku},
+			{Key: "available", Value: bson.D{{Key: "$gte", Value: int64(qty)}}},
+		}
+		res, err := i.collection.UpdateOne(ctx, filter, stockUpdate(-int64(qty)))
+		if err != nil {
+			i.logger.Error().Err(err).Str("sku", sku).Msg("failed to reserve stock")
+			i.rollback(ctx, reserved, quantities)
+			return ErrUnexpectedReserveStock
+		}
+		if res.MatchedCount == 0 {
+			short = append(short, data.ShortItem{SKU: sku, Requested: qty})
+			continue
+		}
+		reserved = append(reserved, sku)
+	}
+	if len(short) == 0 {
+		return nil
+	}
+	i.rollback(ctx, reserved, quantities)
+	i.fillAvailable(ctx, short)
+	return &InsufficientStockError{Items: short}
+}
+
+// Release gives the quantities of the products back to the available stock.
+// SKUs without a stock level are skipped, every SKU is attempted even when one fails.
+func (i *InventoryRepo) Release(ctx context.Context, products []data.Product) error {
+	if err := validateCollection(i.collection); err != nil {
+		return err
+	}
+	skus, quantities := stockLines(products)
+	var failed bool
+	for _, sku := range skus {
+		if err := i.release(ctx, sku, quantities[sku]); err != nil {
+			i.logger.Error().Err(err).Str("sku", sku).Uint64("quantity", quantities[sku]).Msg("failed to release stock")
+			failed = true
+		}
+	}
+	if failed {
+		return ErrUnexpectedReleaseStock
+	}
+	return nil
+}
+
+func (i *InventoryRepo) release(ctx context.Context, sku string, qty uint64) error {
+	if qty > math.MaxInt64 {
+		return ErrInvalidStockLevel
+	}
+	_, err := i.collection.UpdateOne(ctx, bson.D{{Key: "sku", Value: sku}}, stockUpdate(int64(qty)))
+	return err
+}
+
+// rollback releases the SKUs reserved by a Reserve call that could not complete.
+func (i *InventoryRepo) rollback(ctx context.Context, skus []string, quantities map[string]uint64) {
+	for _, sku := range skus {
+		if err := i.release(ctx, sku, quantities[sku]); err != nil {
+			i.logger.Error().Err(err).Str("sku", sku).Uint64("quantity", quantities[sku]).
+				Msg("failed to roll back stock reservation")
+		}
+	}
+}
+
+// fillAvailable sets the currently available stock on the short items, it is informational only.
+func (i *InventoryRepo) fillAvailable(ctx context.Context, items []data.ShortItem) {
+	skus := make([]string, len(items))
+	for idx := range items {
+		skus[idx] = items[idx].SKU
+	}
+	cursor, err := i.collection.Find(ctx, bson.D{{Key: "sku", Value: bson.D{{Key: "$in", Value: skus}}}})
+	if err != nil {
+		i.logger.Error().Err(err).Msg("failed to read stock of short items")
+		return
+	}
+	var levels []data.StockLevel
+	if err = cursor.All(ctx, &levels); err != nil {
+		i.logger.Error().Err(err).Msg("failed to decode stock of short items")
+		return
+	}
+	for _, level := range levels {
+		for idx := range items {
+			if items[idx].SKU == level.SKU {
+				items[idx].Available = level.Available
+			}
+		}
+	}
+}
+
+// stockLines sums the quantities of the products per SKU, keeping the order in which SKUs first appear.
+func stockLines(products []data.Product) ([]string, map[string]uint64) {
+	skus := make([]string, 0, len(products))
+	quantities := make(map[string]uint64, len(products))
+	for _, p := range products {
+		sku := data.NormalizeSKU(p.SKU)
+		if sku == "" || p.Quantity == 0 {
+			continue
+		}
+		if _, seen := quantities[sku]; !seen {
+			skus = append(skus, sku)
+		}
+		quantities[sku] += p.Quantity
+	}
+	return skus, quantities
+}
+
+func stockUpdate(delta int64) bson.D {
+	return bson.D{
+		{Key: "$inc", Value: bson.D{{Key: "available", Value: delta}}},
+		{Key: "$set", Value: bson.D{{Key: "updatedAt", Value: time.Now()}}},
+	}
+}
@@ -0,0 +1,187 @@
+package db_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/rameshsunkara/go-rest-api-example/internal/db"
+	"github.com/rameshsunkara/go-rest-api-example/internal/db/mocks"
+	"github.com/rameshsunkara/go-rest-api-example/internal/models/data"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+)
+
+const inventoryNS = "ordersdb.inventory"
+
+func matched(n int32) bson.D {
+	return mtest.CreateSuccessResponse(bson.E{Key: "n", Value: n})
+}
+
+func TestNewInventoryRepo(t *testing.T) {
+	t.Parallel()
+	_, err := db.NewInventoryRepo(nil, &mocks.MockMongoDataBase{})
+	require.Error(t, err)
+	_, err = db.NewInventoryRepo(testLgr, nil)
+	require.Error(t, err)
+
+	repo, err := db.NewInventoryRepo(testLgr, &mocks.MockMongoDataBase{})
+	require.NoError(t, err)
+	ctx := context.Background()
+	_, err = repo.SetStock(ctx, "A", 1)
+	require.ErrorIs(t, err, db.ErrInvalidInitialization)
+	_, err = repo.GetBySKU(ctx, "A")
+	require.ErrorIs(t, err, db.ErrInvalidInitialization)
+	require.ErrorIs(t, repo.Reserve(ctx, nil), db.ErrInvalidInitialization)
+	require.ErrorIs(t, repo.Release(ctx, nil), db.ErrInvalidInitialization)
+}
+
+func TestInventoryRepoSetStock(t *testing.T) {
+	t.Parallel()
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("Success", func(mt *mtest.T) {
+		mt.AddMockResponses(matched(1))
+		repo, err := db.NewInventoryRepo(testLgr, mt.DB)
+		require.NoError(t, err)
+		level, err := repo.SetStock(context.TODO(), "mug-1", 5)
+		require.NoError(t, err)
+		assert.Equal(t, "MUG-1", level.SKU)
+		assert.Equal(t, int64(5), level.Available)
+		assert.True(t, mt.GetStartedEvent().Command.Lookup("updates", "0", "upsert").Boolean())
+	})
+
+	mt.Run("Negative", func(mt *mtest.T) {
+		repo, err := db.NewInventoryRepo(testLgr, mt.DB)
+		require.NoError(t, err)
+		_, err = repo.SetStock(context.TODO(), "MUG-1", -1)
+		assert.Equal(t, db.ErrInvalidStockLevel, err)
+	})
+
+	mt.Run("UpdateError", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateCommandErrorResponse(mtest.CommandError{Code: 1, Message: "boom"}))
+		repo, err := db.NewInventoryRepo(testLgr, mt.DB)
+		require.NoError(t, err)
+		_, err = repo.SetStock(context.TODO(), "MUG-1", 5)
+		assert.Equal(t, db.ErrUnexpectedSetStock, err)
+	})
+}
+
+func TestInventoryRepoGetBySKU(t *testing.T) {
+	t.Parallel()
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("Success", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateCursorResponse(1, inventoryNS, mtest.FirstBatch, bson.D{
+			{Key: "sku", Value: "MUG-1"},
+			{Key: "available", Value: int64(7)},
+		}))
+		repo, err := db.NewInventoryRepo(testLgr, mt.DB)
+		require.NoError(t, err)
+		level, err := repo.GetBySKU(context.TODO(), "mug-1")
+		require.NoError(t, err)
+		assert.Equal(t, int64(7), level.Available)
+	})
+
+	mt.Run("NotFound", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, inventoryNS, mtest.FirstBatch))
+		repo, err := db.NewInventoryRepo(testLgr, mt.DB)
+		require.NoError(t, err)
+		_, err = repo.GetBySKU(context.TODO(), "NOPE")
+		assert.Equal(t, db.ErrStockNotFound, err)
+	})
+
+	mt.Run("FindError", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateCommandErrorResponse(mtest.CommandError{Code: 1, Message: "boom"}))
+		repo, err := db.NewInventoryRepo(testLgr, mt.DB)
+		require.NoError(t, err)
+		_, err = repo.GetBySKU(context.TODO(), "MUG-1")
+		assert.Equal(t, db.ErrUnexpectedGetStock, err)
+	})
+}
+
+func TestInventoryRepoReserve(t *testing.T) {
+	t.Parallel()
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	products := []data.Product{
+		{SKU: "MUG-1", Quantity: 2},
+		{Name: "legacy line without sku", Quantity: 1},
+		{SKU: "TEE-1", Quantity: 3},
+		{SKU: "mug-1", Quantity: 1},
+	}
+
+	mt.Run("Reserved", func(mt *mtest.T) {
+		mt.AddMockResponses(matched(1), matched(1))
+		repo, err := db.NewInventoryRepo(testLgr, mt.DB)
+		require.NoError(t, err)
+		require.NoError(t, repo.Reserve(context.TODO(), products))
+
+		first := mt.GetStartedEvent().Command
+		assert.Contains(t, first.Lookup("updates", "0", "q").String(), `"$gte": {"$numberLong":"3"}`)
+		assert.Contains(t, first.Lookup("updates", "0", "u").String(), `"$inc": {"available": {"$numberLong":"-3"}}`)
+		second := mt.GetStartedEvent().Command
+		assert.Contains(t, second.Lookup("updates", "0", "q").String(), `"TEE-1"`)
+		assert.Nil(t, mt.GetStartedEvent())
+	})
+
+	mt.Run("ShortItemsAreRolledBack", func(mt *mtest.T) {
+		mt.AddMockResponses(
+			matched(1), // MUG-1 reserved
+			matched(0), // TEE-1 short
+			matched(1), // MUG-1 released
+			mtest.CreateCursorResponse(0, inventoryNS, mtest.FirstBatch, bson.D{
+				{Key: "sku", Value: "TEE-1"},
+				{Key: "available", Value: int64(1)},
+			}),
+		)
+		repo, err := db.NewInventoryRepo(testLgr, mt.DB)
+		require.NoError(t, err)
+		err = repo.Reserve(context.TODO(), products)
+		require.ErrorIs(t, err, db.ErrInsufficientStock)
+		var shortErr *db.InsufficientStockError
+		require.ErrorAs(t, err, &shortErr)
+		assert.Equal(t, []data.ShortItem{{SKU: "TEE-1", Requested: 3, Available: 1}}, shortErr.Items)
+		assert.Equal(t, "insufficient stock: TEE-1 (requested 3, available 1)", err.Error())
+
+		mt.GetStartedEvent()
+		mt.GetStartedEvent()
+		rollback := mt.GetStartedEvent().Command
+		assert.Contains(t, rollback.Lookup("updates", "0", "u").String(), `{"$numberLong":"3"}`)
+	})
+
+	mt.Run("UpdateError", func(mt *mtest.T) {
+		mt.AddMockResponses(
+			matched(1),
+			mtest.CreateCommandErrorResponse(mtest.CommandError{Code: 1, Message: "boom"}),
+			matched(1),
+		)
+		repo, err := db.NewInventoryRepo(testLgr, mt.DB)
+		require.NoError(t, err)
+		assert.Equal(t, db.ErrUnexpectedReserveStock, repo.Reserve(context.TODO(), products))
+	})
+}
+
+func TestInventoryRepoRelease(t *testing.T) {
+	t.Parallel()
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	products := []data.Product{{SKU: "MUG-1", Quantity: 2}, {SKU: "TEE-1", Quantity: 1}}
+
+	mt.Run("Released", func(mt *mtest.T) {
+		mt.AddMockResponses(matched(1), matched(1))
+		repo, err := db.NewInventoryRepo(testLgr, mt.DB)
+		require.NoError(t, err)
+		require.NoError(t, repo.Release(context.TODO(), products))
+		assert.Contains(t, mt.GetStartedEvent().Command.Lookup("updates", "0", "u").String(),
+			`"$inc": {"available": {"$numberLong":"2"}}`)
+	})
+
+	mt.Run("AttemptsEverySKU", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateCommandErrorResponse(mtest.CommandError{Code: 1, Message: "boom"}), matched(1))
+		repo, err := db.NewInventoryRepo(testLgr, mt.DB)
+		require.NoError(t, err)
+		assert.Equal(t, db.ErrUnexpectedReleaseStock, repo.Release(context.TODO(), products))
+		mt.GetStartedEvent()
+		assert.Contains(t, mt.GetStartedEvent().Command.Lookup("updates", "0", "q").String(), `"TEE-1"`)
+	})
+}
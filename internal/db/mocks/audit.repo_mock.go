@@ -0,0 +1,25 @@
+package mocks
+
+import (
+	"context"
+
+	"github.com/rameshsunkara/go-rest-api-example/internal/db"
+	"github.com/rameshsunkara/go-rest-api-example/internal/models/data"
+)
+
+type MockAuditDataService struct {
+	AppendFunc func(ctx context.Context, entries ...data.AuditEntry) error
+	SearchFunc func(ctx context.Context, filter db.AuditFilter, limit int64) (*[]data.AuditEntry, error)
+}
+
+func (m *MockAuditDataService) Append(ctx context.Context, entries ...data.AuditEntry) error {
+	return m.AppendFunc(ctx, entries...)
+}
+
+func (m *MockAuditDataService) Search(
+	ctx context.Context,
+	filter db.AuditFilter,
+	limit int64,
+) (*[]data.AuditEntry, error) {
+	return m.SearchFunc(ctx, filter, limit)
+}
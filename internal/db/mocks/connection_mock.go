@@ -18,15 +18,17 @@ func (m *MockMongoMgr) Database() mongodb.MongoDatabase {
 	return &MockMongoDataBase{}
 }
 
-func (m *MockMongoMgr) DatabaseByName(_ string) mongodb.MongoDatabase {
-	return &MockMongoDataBase{}
+func (m *MockMongoMgr) DatabaseByName(name string) mongodb.MongoDatabase {
+	return &MockMongoDataBase{Name: name}
 }
 
 func (m *MockMongoMgr) Disconnect() error {
 	return nil
 }
 
-type MockMongoDataBase struct{}
+type MockMongoDataBase struct {
+	Name string // set for databases selected with DatabaseByName
+}
 
 func (m *MockMongoDataBase) Collection(_ string, _ ...*options.CollectionOptions) *mongo.Collection {
 	return nil
@@ -0,0 +1,31 @@
+package mocks
+
+import (
+	"context"
+	"time"
+
+	"github.com/rameshsunkara/go-rest-api-example/internal/models/data"
+)
+
+type MockCouponsDataService struct {
+	CreateFunc    func(ctx context.Context, coupon *data.Coupon) (string, error)
+	GetByCodeFunc func(ctx context.Context, code string) (*data.Coupon, error)
+	RedeemFunc    func(ctx context.Context, code string, at time.Time) error
+	ReleaseFunc   func(ctx context.Context, code string) error
+}
+
+func (m *MockCouponsDataService) Create(ctx context.Context, coupon *data.Coupon) (string, error) {
+	return m.CreateFunc(ctx, coupon)
+}
+
+func (m *MockCouponsDataService) GetByCode(ctx context.Context, code string) (*data.Coupon, error) {
+	return m.GetByCodeFunc(ctx, code)
+}
+
+func (m *MockCouponsDataService) Redeem(ctx context.Context, code string, at time.Time) error {
+	return m.RedeemFunc(ctx, code, at)
+}
+
+func (m *MockCouponsDataService) Release(ctx context.Context, code string) error {
+	return m.ReleaseFunc(ctx, code)
+}
@@ -0,0 +1,32 @@
+package mocks
+
+import (
+	"context"
+
+	"github.com/rameshsunkara/go-rest-api-example/internal/models/data"
+)
+
+type MockInventoryDataService struct {
+	SetStockFunc func(ctx context.Context, sku string, available int64) (*data.StockLevel, error)
+	GetBySKUFunc func(ctx context.Context, sku string) (*data.StockLevel, error)
+	ReserveFunc  func(ctx context.Context, products []data.Product) error
+	ReleaseFunc  func(ctx context.Context, products []data.Product) error
+}
+
+func (m *MockInventoryDataService) SetStock(
+	ctx context.Context, sku string, available int64,
+) (*data.StockLevel, error) {
+	return m.SetStockFunc(ctx, sku, available)
+}
+
+func (m *MockInventoryDataService) GetBySKU(ctx context.Context, sku string) (*data.StockLevel, error) {
+	return m.GetBySKUFunc(ctx, sku)
+}
+
+func (m *MockInventoryDataService) Reserve(ctx context.Context, products []data.Product) error {
+	return m.ReserveFunc(ctx, products)
+}
+
+func (m *MockInventoryDataService) Release(ctx context.Context, products []data.Product) error {
+	return m.ReleaseFunc(ctx, products)
+}
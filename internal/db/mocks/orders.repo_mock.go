@@ -2,35 +2,91 @@ package mocks
 
 import (
 	"context"
+	"time"
 
+	"github.com/rameshsunkara/go-rest-api-example/internal/db"
 	"github.com/rameshsunkara/go-rest-api-example/internal/models/data"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 type MockOrdersDataService struct {
-	CreateFunc func(ctx context.Context, purchaseOrder *data.Order) (string, error)
-	// UpdateFunc     func(ctx context.Context, purchaseOrder *data.Order) error
-	GetAllFunc     func(ctx context.Context, limit int64) (*[]data.Order, error)
-	GetByIDFunc    func(ctx context.Context, id primitive.ObjectID) (*data.Order, error)
-	DeleteByIDFunc func(ctx context.Context, id primitive.ObjectID) error
+	CreateFunc       func(ctx context.Context, purchaseOrder *data.Order) (string, error)
+	UpdateFunc       func(ctx context.Context, purchaseOrder *data.Order) error
+	GetAllFunc       func(ctx context.Context, limit int64, opts db.ReadOptions) (*[]data.Order, error)
+	GetByIDFunc      func(ctx context.Context, id primitive.ObjectID, opts db.ReadOptions) (*data.Order, error)
+	DeleteByIDFunc   func(ctx context.Context, id primitive.ObjectID) error
+	RestoreFunc      func(ctx context.Context, id primitive.ObjectID) error
+	PurgeDeletedFunc func(ctx context.Context, deletedBefore time.Time) (int64, error)
+	UpsertManyFunc   func(ctx context.Context, orders []data.Order) (*db.UpsertResult, error)
+	TransitionFunc   func(
+		ctx context.Context, id primitive.ObjectID, to data.OrderStatus, note data.OrderUpdate, shipment *data.Shipment,
+	) (*data.Order, error)
+	GetPendingBeforeFunc func(ctx context.Context, createdBefore time.Time, limit int64) (*[]data.Order, error)
+	GetByUserFunc        func(ctx context.Context, user string, limit int64) (*[]data.Order, error)
+	SummarizeUserFunc    func(ctx context.Context, user string) (*data.CustomerSummary, error)
 }
 
 func (m *MockOrdersDataService) Create(ctx context.Context, purchaseOrder *data.Order) (string, error) {
 	return m.CreateFunc(ctx, purchaseOrder)
 }
 
-func (m *MockOrdersDataService) Update(_ context.Context, _ *data.Order) error {
-	return nil
+func (m *MockOrdersDataService) Update(ctx context.Context, purchaseOrder *data.Order) error {
+	if m.UpdateFunc == nil {
+		return nil
+	}
+	return m.UpdateFunc(ctx, purchaseOrder)
 }
 
-func (m *MockOrdersDataService) GetAll(ctx context.Context, limit int64) (*[]data.Order, error) {
-	return m.GetAllFunc(ctx, limit)
+func (m *MockOrdersDataService) GetAll(ctx context.Context, limit int64, opts db.ReadOptions) (*[]data.Order, error) {
+	return m.GetAllFunc(ctx, limit, opts)
 }
 
-func (m *MockOrdersDataService) GetByID(ctx context.Context, id primitive.ObjectID) (*data.Order, error) {
-	return m.GetByIDFunc(ctx, id)
+func (m *MockOrdersDataService) GetByID(
+	ctx context.Context,
+	id primitive.ObjectID,
+	opts db.ReadOptions,
+) (*data.Order, error) {
+	return m.GetByIDFunc(ctx, id, opts)
 }
 
 func (m *MockOrdersDataService) DeleteByID(ctx context.Context, id primitive.ObjectID) error {
 	return m.DeleteByIDFunc(ctx, id)
 }
+
+func (m *MockOrdersDataService) Restore(ctx context.Context, id primitive.ObjectID) error {
+	return m.RestoreFunc(ctx, id)
+}
+
+func (m *MockOrdersDataService) PurgeDeleted(ctx context.Context, deletedBefore time.Time) (int64, error) {
+	return m.PurgeDeletedFunc(ctx, deletedBefore)
+}
+
+func (m *MockOrdersDataService) UpsertMany(ctx context.Context, orders []data.Order) (*db.UpsertResult, error) {
+	return m.UpsertManyFunc(ctx, orders)
+}
+
+func (m *MockOrdersDataService) Transition(
+	ctx context.Context,
+	id primitive.ObjectID,
+	to data.OrderStatus,
+	note data.OrderUpdate,
+	shipment *data.Shipment,
+) (*data.Order, error) {
+	return m.TransitionFunc(ctx, id, to, note, shipment)
+}
+
+func (m *MockOrdersDataService) GetPendingBefore(
+	ctx context.Context,
+	createdBefore time.Time,
+	limit int64,
+) (*[]data.Order, error) {
+	return m.GetPendingBeforeFunc(ctx, createdBefore, limit)
+}
+
+func (m *MockOrdersDataService) GetByUser(ctx context.Context, user string, limit int64) (*[]data.Order, error) {
+	return m.GetByUserFunc(ctx, user, limit)
+}
+
+func (m *MockOrdersDataService) SummarizeUser(ctx context.Context, user string) (*data.CustomerSummary, error) {
+	return m.SummarizeUserFunc(ctx, user)
+}
@@ -0,0 +1,44 @@
+package mocks
+
+import (
+	"context"
+
+	"github.com/rameshsunkara/go-rest-api-example/internal/models/data"
+)
+
+type MockProductsDataService struct {
+	CreateFunc      func(ctx context.Context, product *data.CatalogProduct) (string, error)
+	UpdateFunc      func(ctx context.Context, product *data.CatalogProduct) error
+	GetAllFunc      func(ctx context.Context, limit int64, includeInactive bool) (*[]data.CatalogProduct, error)
+	GetBySKUFunc    func(ctx context.Context, sku string) (*data.CatalogProduct, error)
+	GetBySKUsFunc   func(ctx context.Context, skus []string) ([]data.CatalogProduct, error)
+	DeleteBySKUFunc func(ctx context.Context, sku string) error
+}
+
+func (m *MockProductsDataService) Create(ctx context.Context, product *data.CatalogProduct) (string, error) {
+	return m.CreateFunc(ctx, product)
+}
+
+func (m *MockProductsDataService) Update(ctx context.Context, product *data.CatalogProduct) error {
+	return m.UpdateFunc(ctx, product)
+}
+
+func (m *MockProductsDataService) GetAll(
+	ctx context.Context,
+	limit int64,
+	includeInactive bool,
+) (*[]data.CatalogProduct, error) {
+	return m.GetAllFunc(ctx, limit, includeInactive)
+}
+
+func (m *MockProductsDataService) GetBySKU(ctx context.Context, sku string) (*data.CatalogProduct, error) {
+	return m.GetBySKUFunc(ctx, sku)
+}
+
+func (m *MockProductsDataService) GetBySKUs(ctx context.Context, skus []string) ([]data.CatalogProduct, error) {
+	return m.GetBySKUsFunc(ctx, skus)
+}
+
+func (m *MockProductsDataService) DeleteBySKU(ctx context.Context, sku string) error {
+	return m.DeleteBySKUFunc(ctx, sku)
+}
@@ -0,0 +1,33 @@
+package mocks
+
+import (
+	"context"
+
+	"github.com/rameshsunkara/go-rest-api-example/internal/db"
+	"github.com/rameshsunkara/go-rest-api-example/internal/models/data"
+)
+
+type MockReportsDataService struct {
+	RevenueFunc        func(ctx context.Context, q db.ReportQuery) ([]data.RevenueBucket, error)
+	OrdersByStatusFunc func(ctx context.Context, q db.ReportQuery) ([]data.StatusCount, error)
+	BasketSizeFunc     func(ctx context.Context, q db.ReportQuery) ([]data.BasketStats, error)
+	TopProductsFunc    func(ctx context.Context, q db.ReportQuery, limit int64) ([]data.ProductSales, error)
+}
+
+func (m *MockReportsDataService) Revenue(ctx context.Context, q db.ReportQuery) ([]data.RevenueBucket, error) {
+	return m.RevenueFunc(ctx, q)
+}
+
+func (m *MockReportsDataService) OrdersByStatus(ctx context.Context, q db.ReportQuery) ([]data.StatusCount, error) {
+	return m.OrdersByStatusFunc(ctx, q)
+}
+
+func (m *MockReportsDataService) BasketSize(ctx context.Context, q db.ReportQuery) ([]data.BasketStats, error) {
+	return m.BasketSizeFunc(ctx, q)
+}
+
+func (m *MockReportsDataService) TopProducts(
+	ctx context.Context, q db.ReportQuery, limit int64,
+) ([]data.ProductSales, error) {
+	return m.TopProductsFunc(ctx, q, limit)
+}
@@ -0,0 +1,55 @@
+package db
+
+import (
+	"context"
+	"errors"
+
+	"github.com/rameshsunkara/go-rest-api-example/pkg/money"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+var ErrUnexpectedMoneyMigration = errors.New("unexpected error occurred while migrating order amounts")
+
+// MigrateMoney rewrites orders stored before amounts were decimal: float totals and prices are
+// converted to Decimal128 rounded to money.Scale digits, and orders without a currency get the
+// given one. It runs server side in a single update and returns the number of modified orders.
+// Documents are also readable before the migration, money.Amount decodes legacy doubles.
+func (o *OrdersRepo) MigrateMoney(ctx context.Context, currency money.Currency) (int64, error) {
+	if err := validateCollection(o.collection); err != nil {
+		return 0, err
+	}
+	if !currency.IsValid() {
+		return 0, money.ErrUnsupportedCurrency
+	}
+
+	isDouble := bson.D{{Key: "$type", Value: "double"}}
+	filter := bson.D{{Key: "$or", Value: bson.A{
+		bson.D{{Key: "totalAmount", Value: isDouble}},
+		bson.D{{Key: "products.price", Value: isDouble}},
+		bson.D{{Key: "currency", Value: bson.D{{Key: "$exists", Value: false}}}},
+	}}}
+	toDecimal := func(field string) bson.D {
+		return bson.D{{Key: "$round", Value: bson.A{bson.D{{Key: "$toDecimal", Value: field}}, money.Scale}}}
+	}
+	update := mongo.Pipeline{{{Key: "$set", Value: bson.D{
+		{Key: "totalAmount", Value: toDecimal("$totalAmount")},
+		{Key: "products", Value: bson.D{{Key: "$map", Value: bson.D{
+			{Key: "input", Value: bson.D{{Key: "$ifNull", Value: bson.A{"$products", bson.A{}}}}},
+			{Key: "as", Value: "p"},
+			{Key: "in", Value: bson.D{{Key: "$mergeObjects", Value: bson.A{
+				"$$p",
+				bson.D{{Key: "price", Value: toDecimal("$$p.price")}},
+			}}}},
+		}}}},
+		{Key: "currency", Value: bson.D{{Key: "$ifNull", Value: bson.A{"$currency", string(currency)}}}},
+	}}}}
+
+	res, err := o.collection.UpdateMany(ctx, filter, update)
+	if err != nil {
+		o.log(ctx).Error().Err(err).Msg("failed to migrate order amounts")
+		return 0, ErrUnexpectedMoneyMigration
+	}
+	o.log(ctx).Info().Int("modified", int(res.ModifiedCount)).Msg("migrated order amounts to decimal")
+	return res.ModifiedCount, nil
+}
@@ -0,0 +1,57 @@
+package db_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/rameshsunkara/go-rest-api-example/internal/db"
+	"github.com/rameshsunkara/go-rest-api-example/internal/db/mocks"
+	"github.com/rameshsunkara/go-rest-api-example/pkg/money"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+)
+
+func TestOrdersRepoMigrateMoney(t *testing.T) {
+	t.Parallel()
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("Success", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 3}, bson.E{Key: "nModified", Value: 3}))
+		repo, repoErr := db.NewOrdersRepo(testLgr, mt.DB)
+		require.NoError(t, repoErr)
+		migrated, err := repo.MigrateMoney(context.TODO(), money.EUR)
+		require.NoError(t, err)
+		assert.Equal(t, int64(3), migrated)
+
+		started := mt.GetStartedEvent()
+		require.NotNil(t, started)
+		update := started.Command.Lookup("updates", "0", "u").String()
+		assert.Contains(t, update, "$toDecimal")
+		assert.Contains(t, update, `"EUR"`)
+	})
+
+	mt.Run("UnsupportedCurrency", func(mt *mtest.T) {
+		repo, repoErr := db.NewOrdersRepo(testLgr, mt.DB)
+		require.NoError(t, repoErr)
+		_, err := repo.MigrateMoney(context.TODO(), money.Currency("XYZ"))
+		require.ErrorIs(t, err, money.ErrUnsupportedCurrency)
+	})
+
+	mt.Run("UpdateError", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateCommandErrorResponse(mtest.CommandError{Code: 1, Message: "boom"}))
+		repo, repoErr := db.NewOrdersRepo(testLgr, mt.DB)
+		require.NoError(t, repoErr)
+		_, err := repo.MigrateMoney(context.TODO(), money.USD)
+		assert.Equal(t, db.ErrUnexpectedMoneyMigration, err)
+	})
+
+	t.Run("NilCollection", func(t *testing.T) {
+		t.Parallel()
+		repo, repoErr := db.NewOrdersRepo(testLgr, &mocks.MockMongoDataBase{})
+		require.NoError(t, repoErr)
+		_, err := repo.MigrateMoney(context.TODO(), money.USD)
+		assert.Equal(t, db.ErrInvalidInitialization, err)
+	})
+}
@@ -7,6 +7,7 @@ import (
 
 	"github.com/rameshsunkara/go-rest-api-example/internal/models/data"
 	"github.com/rameshsunkara/go-rest-api-example/pkg/logger"
+	"github.com/rameshsunkara/go-rest-api-example/pkg/money"
 	"github.com/rameshsunkara/go-rest-api-example/pkg/mongodb"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
@@ -19,25 +20,75 @@ const (
 	DefaultPageSize  = 100
 )
 
+// notDeleted matches orders that have not been soft-deleted.
+var notDeleted = bson.E{Key: "deletedAt", Value: bson.D{{Key: "$exists", Value: false}}}
+
 var (
-	ErrInvalidInitialization = errors.New("invalid initialization")
-	ErrInvalidPOIDCreate     = errors.New("order id should be empty")
-	ErrInvalidPOIDUpdate     = errors.New("invalid order id")
-	ErrUnexpectedUpdateOrder = errors.New("unexpected error occurred while updating order")
-	ErrPOIDNotFound          = errors.New("purchase order doesn't exist with given id")
-	ErrFailedToCreateOrder   = errors.New("failed to create order")
-	ErrUnexpectedDeleteOrder = errors.New("unexpected error occurred while deleting order")
-	ErrUnexpectedGetOrder    = errors.New("unexpected error occurred while fetching order")
-	ErrInvalidID             = errors.New("failed to assert inserted ID as ObjectID")
+	ErrInvalidInitialization  = errors.New("invalid initialization")
+	ErrInvalidPOIDCreate      = errors.New("order id should be empty")
+	ErrInvalidPOIDUpdate      = errors.New("invalid order id")
+	ErrUnexpectedUpdateOrder  = errors.New("unexpected error occurred while updating order")
+	ErrPOIDNotFound           = errors.New("purchase order doesn't exist with given id")
+	ErrFailedToCreateOrder    = errors.New("failed to create order")
+	ErrUnexpectedDeleteOrder  = errors.New("unexpected error occurred while deleting order")
+	ErrUnexpectedGetOrder     = errors.New("unexpected error occurred while fetching order")
+	ErrInvalidID              = errors.New("failed to assert inserted ID as ObjectID")
+	ErrMissingExternalRef     = errors.New("order external reference is required for upsert")
+	ErrUnexpectedUpsertOrder  = errors.New("unexpected error occurred while upserting orders")
+	ErrUnexpectedRestoreOrder = errors.New("unexpected error occurred while restoring order")
+	ErrUnexpectedPurgeOrders  = errors.New("unexpected error occurred while purging deleted orders")
+	ErrInvalidTransition      = errors.New("order cannot move to the requested status")
+	ErrUnexpectedTransition   = errors.New("unexpected error occurred while changing order status")
+	ErrUnexpectedSummarize    = errors.New("unexpected error occurred while summarizing orders")
 )
 
 // OrdersDataService defines the interface for order data operations.
 type OrdersDataService interface {
 	Create(ctx context.Context, purchaseOrder *data.Order) (string, error)
 	Update(ctx context.Context, purchaseOrder *data.Order) error
-	GetAll(ctx context.Context, limit int64) (*[]data.Order, error)
-	GetByID(ctx context.Context, id primitive.ObjectID) (*data.Order, error)
+	GetAll(ctx context.Context, limit int64, opts ReadOptions) (*[]data.Order, error)
+	GetByID(ctx context.Context, id primitive.ObjectID, opts ReadOptions) (*data.Order, error)
 	DeleteByID(ctx context.Context, id primitive.ObjectID) error
+	Restore(ctx context.Context, id primitive.ObjectID) error
+	PurgeDeleted(ctx context.Context, deletedBefore time.Time) (int64, error)
+	UpsertMany(ctx context.Context, orders []data.Order) (*UpsertResult, error)
+	Transition(
+		ctx context.Context, id primitive.ObjectID, to data.OrderStatus, note data.OrderUpdate, shipment *data.Shipment,
+	) (*data.Order, error)
+	GetPendingBefore(ctx context.Context, createdBefore time.Time, limit int64) (*[]data.Order, error)
+	GetByUser(ctx context.Context, user string, limit int64) (*[]data.Order, error)
+	SummarizeUser(ctx context.Context, user string) (*data.CustomerSummary, error)
+}
+
+// ReadOptions controls which orders are visible to read operations.
+type ReadOptions struct {
+	IncludeDeleted bool     // include soft-deleted orders, meant for admin use only
+	Fields         []string // stored fields to return, _id is always returned and none returns whole documents
+	// After restricts GetAll to orders created after the given ID, used by cursor pagination
+	After  primitive.ObjectID
+	Offset int64 // orders GetAll skips, used by offset pagination
+}
+
+// projection returns the Mongo projection of the selected fields, nil when whole documents are read.
+func (r ReadOptions) projection() bson.D {
+	if len(r.Fields) == 0 {
+		return nil
+	}
+	p := make(bson.D, 0, len(r.Fields))
+	for _, f := range r.Fields {
+		p = append(p, bson.E{Key: f, Value: 1})
+	}
+	return p
+}
+
+// UpsertResult reports the orders UpsertMany inserted, updated and skipped.
+type UpsertResult struct {
+	Inserted int64
+	Matched  int64 // existing orders matched, whether or not the import changed them
+	Updated  int64 // matched orders the import changed
+	Skipped  int64 // soft-deleted orders left as they are
+	// IDs maps the external reference of the imported orders, inserted or matched, to their ID.
+	IDs map[string]primitive.ObjectID
 }
 
 // OrdersRepo implements OrdersDataService using MongoDB.
@@ -57,6 +108,12 @@ func NewOrdersRepo(lgr logger.Logger, db mongodb.MongoDatabase) (*OrdersRepo, er
 	}, nil
 }
 
+// log returns the logger of the request ctx belongs to, requests carry their ID and route, see
+// middleware.RequestLogMiddleware. Background jobs log through the repository logger.
+func (o *OrdersRepo) log(ctx context.Context) logger.Logger {
+	return logger.FromContextOr(ctx, o.logger)
+}
+
 // Create inserts a new order into the collection.
 func (o *OrdersRepo) Create(ctx context.Context, po *data.Order) (string, error) {
 	if err := validateCollection(o.collection); err != nil {
@@ -68,14 +125,14 @@ func (o *OrdersRepo) Create(ctx context.Context, po *data.Order) (string, error)
 
 	result, err := o.collection.InsertOne(ctx, po)
 	if err != nil {
-		o.logger.Error().Err(err).Msg("failed to create order")
+		o.log(ctx).Error().Err(err).Msg("failed to create order")
 		return "", ErrFailedToCreateOrder
 	}
 	insertedID, ok := result.InsertedID.(primitive.ObjectID)
 	if !ok {
 		return "", ErrInvalidID
 	}
-	o.logger.Info().Str("orderId", insertedID.Hex()).Msg("created new order")
+	o.log(ctx).Info().Str("orderId", insertedID.Hex()).Msg("created new order")
 	return insertedID.Hex(), nil
 }
 
@@ -94,70 +151,350 @@ func (o *OrdersRepo) Update(ctx context.Context, po *data.Order) error {
 	update := bson.D{{Key: "$set", Value: po}}
 	result, err := o.collection.UpdateOne(ctx, filter, update)
 	if err != nil {
-		o.logger.Error().Err(err).Msg("failed to update order")
+		o.log(ctx).Error().Err(err).Msg("failed to update order")
 		return ErrUnexpectedUpdateOrder
 	}
 	if result.MatchedCount == 0 {
-		o.logger.Info().Msg("order id for update not found")
+		o.log(ctx).Info().Msg("order id for update not found")
 		return ErrPOIDNotFound
 	}
 	return nil
 }
 
-// GetAll retrieves all orders up to the specified limit.
-func (o *OrdersRepo) GetAll(ctx context.Context, limit int64) (*[]data.Order, error) {
+// GetAll retrieves all orders up to the specified limit, excluding soft-deleted orders unless requested.
+func (o *OrdersRepo) GetAll(ctx context.Context, limit int64, opts ReadOptions) (*[]data.Order, error) {
 	if err := validateCollection(o.collection); err != nil {
 		return nil, err
 	}
-	findOptions := options.Find().SetLimit(limit)
-	cursor, err := o.collection.Find(ctx, bson.M{}, findOptions)
+	filter := bson.D{}
+	if !opts.IncludeDeleted {
+		filter = append(filter, notDeleted)
+	}
+	if !opts.After.IsZero() {
+		filter = append(filter, bson.E{Key: "_id", Value: bson.D{{Key: "$gt", Value: opts.After}}})
+	}
+	// ObjectIDs grow with creation time, sorting on them keeps pages stable
+	findOptions := options.Find().SetLimit(limit).SetSort(bson.D{{Key: "_id", Value: 1}})
+	if opts.Offset > 0 {
+		findOptions.SetSkip(opts.Offset)
+	}
+	if p := opts.projection(); p != nil {
+		findOptions.SetProjection(p)
+	}
+	cursor, err := o.collection.Find(ctx, filter, findOptions)
 	if err != nil {
-		o.logger.Error().Err(err).Msg("failed to find orders")
+		o.log(ctx).Error().Err(err).Msg("failed to find orders")
 		return nil, ErrUnexpectedGetOrder
 	}
 	var results []data.Order
 	if err = cursor.All(ctx, &results); err != nil {
-		o.logger.Error().Err(err).Msg("failed to decode orders")
+		o.log(ctx).Error().Err(err).Msg("failed to decode orders")
 		return nil, ErrUnexpectedGetOrder
 	}
 	return &results, nil
 }
 
-// GetByID retrieves an order by its ObjectID.
-func (o *OrdersRepo) GetByID(ctx context.Context, oID primitive.ObjectID) (*data.Order, error) {
+// GetByID retrieves an order by its ObjectID, treating soft-deleted orders as missing unless requested.
+func (o *OrdersRepo) GetByID(ctx context.Context, oID primitive.ObjectID, opts ReadOptions) (*data.Order, error) {
 	if err := validateCollection(o.collection); err != nil {
 		return nil, err
 	}
 	filter := bson.D{{Key: "_id", Value: oID}}
+	if !opts.IncludeDeleted {
+		filter = append(filter, notDeleted)
+	}
+	findOptions := options.FindOne()
+	if p := opts.projection(); p != nil {
+		findOptions.SetProjection(p)
+	}
 	var result data.Order
-	err := o.collection.FindOne(ctx, filter).Decode(&result)
+	err := o.collection.FindOne(ctx, filter, findOptions).Decode(&result)
 	if err != nil {
 		if errors.Is(err, mongo.ErrNoDocuments) {
 			return nil, ErrPOIDNotFound
 		}
-		o.logger.Error().Err(err).Msg("failed to get order by id")
+		o.log(ctx).Error().Err(err).Msg("failed to get order by id")
 		return nil, ErrUnexpectedGetOrder
 	}
 	return &result, nil
 }
 
-// DeleteByID removes an order by its ObjectID.
+// DeleteByID soft-deletes an order by setting its deletedAt marker.
+// The document is physically removed later by PurgeDeleted once the retention period has passed.
 func (o *OrdersRepo) DeleteByID(ctx context.Context, id primitive.ObjectID) error {
 	if err := validateCollection(o.collection); err != nil {
 		return err
 	}
-	filter := bson.D{{Key: "_id", Value: id}}
-	res, err := o.collection.DeleteOne(ctx, filter)
+	now := time.Now()
+	filter := bson.D{{Key: "_id", Value: id}, notDeleted}
+	update := bson.D{{Key: "$set", Value: bson.D{
+		{Key: "deletedAt", Value: now},
+		{Key: "updatedAt", Value: now},
+	}}}
+	res, err := o.collection.UpdateOne(ctx, filter, update)
 	if err != nil {
-		o.logger.Error().Err(err).Msg("failed to delete order")
+		o.log(ctx).Error().Err(err).Msg("failed to delete order")
 		return ErrUnexpectedDeleteOrder
 	}
-	if res.DeletedCount == 0 {
+	if res.MatchedCount == 0 {
+		return ErrPOIDNotFound
+	}
+	o.log(ctx).Info().Str("orderId", id.Hex()).Msg("soft deleted order")
+	return nil
+}
+
+// Restore clears the deletedAt marker of a soft-deleted order.
+func (o *OrdersRepo) Restore(ctx context.Context, id primitive.ObjectID) error {
+	if err := validateCollection(o.collection); err != nil {
+		return err
+	}
+	filter := bson.D{{Key: "_id", Value: id}, {Key: "deletedAt", Value: bson.D{{Key: "$exists", Value: true}}}}
+	update := bson.D{
+		{Key: "$unset", Value: bson.D{{Key: "deletedAt", Value: ""}}},
+		{Key: "$set", Value: bson.D{{Key: "updatedAt", Value: time.Now()}}},
+	}
+	res, err := o.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		o.log(ctx).Error().Err(err).Msg("failed to restore order")
+		return ErrUnexpectedRestoreOrder
+	}
+	if res.MatchedCount == 0 {
 		return ErrPOIDNotFound
 	}
+	o.log(ctx).Info().Str("orderId", id.Hex()).Msg("restored order")
 	return nil
 }
 
+// PurgeDeleted permanently removes orders that were soft-deleted before the given time
+// and returns the number of removed documents.
+func (o *OrdersRepo) PurgeDeleted(ctx context.Context, deletedBefore time.Time) (int64, error) {
+	if err := validateCollection(o.collection); err != nil {
+		return 0, err
+	}
+	filter := bson.D{{Key: "deletedAt", Value: bson.D{{Key: "$lt", Value: deletedBefore}}}}
+	res, err := o.collection.DeleteMany(ctx, filter)
+	if err != nil {
+		o.log(ctx).Error().Err(err).Msg("failed to purge deleted orders")
+		return 0, ErrUnexpectedPurgeOrders
+	}
+	return res.DeletedCount, nil
+}
+
+// Transition atomically moves an order to the given status and records the note in its updates.
+// It only matches orders whose current status can move to the target, so two concurrent transitions
+// never both succeed. It returns the updated order, or ErrInvalidTransition when the order exists
+// but is in a status that cannot move to the target.
+// Shipping an order stores the given shipment stamped with the time, delivering it stamps the delivery time.
+func (o *OrdersRepo) Transition(
+	ctx context.Context,
+	id primitive.ObjectID,
+	to data.OrderStatus,
+	note data.OrderUpdate,
+	shipment *data.Shipment,
+) (*data.Order, error) {
+	if err := validateCollection(o.collection); err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	note.UpdatedAt = now
+	filter := bson.D{
+		{Key: "_id", Value: id},
+		notDeleted,
+		{Key: "status", Value: bson.D{{Key: "$in", Value: data.TransitionSources(to)}}},
+	}
+	set := bson.D{{Key: "status", Value: to}, {Key: "updatedAt", Value: now}}
+	switch {
+	case to == data.OrderShipped && shipment != nil:
+		shipped := *shipment
+		shipped.ShippedAt, shipped.DeliveredAt = &now, nil
+		set = append(set, bson.E{Key: "shipment", Value: shipped})
+	case to == data.OrderDelivered:
+		set = append(set, bson.E{Key: "shipment.deliveredAt", Value: now})
+	}
+	update := bson.D{
+		{Key: "$set", Value: set},
+		{Key: "$inc", Value: bson.D{{Key: "version", Value: 1}}},
+		{Key: "$push", Value: bson.D{{Key: "updates", Value: note}}},
+	}
+	var order data.Order
+	err := o.collection.FindOneAndUpdate(ctx, filter, update,
+		options.FindOneAndUpdate().SetReturnDocument(options.After)).Decode(&order)
+	if err == nil {
+		o.log(ctx).Info().Str("orderId", id.Hex()).Str("status", string(to)).Msg("changed order status")
+		return &order, nil
+	}
+	if !errors.Is(err, mongo.ErrNoDocuments) {
+		o.log(ctx).Error().Err(err).Msg("failed to change order status")
+		return nil, ErrUnexpectedTransition
+	}
+	count, err := o.collection.CountDocuments(ctx, bson.D{{Key: "_id", Value: id}, notDeleted})
+	if err != nil {
+		o.log(ctx).Error().Err(err).Msg("failed to look up order after rejected status change")
+		return nil, ErrUnexpectedTransition
+	}
+	if count == 0 {
+		return nil, ErrPOIDNotFound
+	}
+	return nil, ErrInvalidTransition
+}
+
+// GetPendingBefore retrieves up to limit pending orders holding a stock reservation that were created
+// before the given time, oldest first.
+func (o *OrdersRepo) GetPendingBefore(
+	ctx context.Context,
+	createdBefore time.Time,
+	limit int64,
+) (*[]data.Order, error) {
+	if err := validateCollection(o.collection); err != nil {
+		return nil, err
+	}
+	filter := bson.D{
+		{Key: "status", Value: data.OrderPending},
+		{Key: "stockReserved", Value: true},
+		{Key: "createdAt", Value: bson.D{{Key: "$lt", Value: createdBefore}}},
+		notDeleted,
+	}
+	findOptions := options.Find().SetLimit(limit).SetSort(bson.D{{Key: "createdAt", Value: 1}})
+	cursor, err := o.collection.Find(ctx, filter, findOptions)
+	if err != nil {
+		o.log(ctx).Error().Err(err).Msg("failed to find pending orders")
+		return nil, ErrUnexpectedGetOrder
+	}
+	results := []data.Order{}
+	if err = cursor.All(ctx, &results); err != nil {
+		o.log(ctx).Error().Err(err).Msg("failed to decode pending orders")
+		return nil, ErrUnexpectedGetOrder
+	}
+	return &results, nil
+}
+
+// UpsertMany inserts the given orders or updates their imported fields in a single unordered bulk write,
+// matching existing documents on their external reference. Fields the service maintains, e.g. the soft-delete
+// marker and the stock reservation, are left as they are and soft-deleted orders are skipped.
+func (o *OrdersRepo) UpsertMany(ctx context.Context, orders []data.Order) (*UpsertResult, error) {
+	if err := validateCollection(o.collection); err != nil {
+		return nil, err
+	}
+	if len(orders) == 0 {
+		return &UpsertResult{}, nil
+	}
+	refs := make([]string, 0, len(orders))
+	for i := range orders {
+		if orders[i].ExternalRef == "" {
+			return nil, ErrMissingExternalRef
+		}
+		refs = append(refs, orders[i].ExternalRef)
+	}
+	existing, err := o.findByRefs(ctx, refs)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &UpsertResult{IDs: make(map[string]primitive.ObjectID, len(orders))}
+	models := make([]mongo.WriteModel, 0, len(orders))
+	modelRefs := make([]string, 0, len(orders))
+	for i := range orders {
+		ref := orders[i].ExternalRef
+		if stored, ok := existing[ref]; ok {
+			if stored.DeletedAt != nil {
+				result.Skipped++
+				continue
+			}
+			result.IDs[ref] = stored.ID
+		}
+		models = append(models, mongo.NewUpdateOneModel().
+			SetFilter(bson.D{{Key: "externalRef", Value: ref}}).
+			SetUpdate(importUpdate(&orders[i])).
+			SetUpsert(true))
+		modelRefs = append(modelRefs, ref)
+	}
+	if len(models) == 0 {
+		return result, nil
+	}
+
+	res, err := o.collection.BulkWrite(ctx, models, options.BulkWrite().SetOrdered(false))
+	if err != nil {
+		o.log(ctx).Error().Err(err).Int("batchSize", len(orders)).Msg("failed to upsert orders")
+		return nil, ErrUnexpectedUpsertOrder
+	}
+	result.Inserted, result.Matched, result.Updated = res.UpsertedCount, res.MatchedCount, res.ModifiedCount
+	for i, id := range res.UpsertedIDs {
+		if oID, ok := id.(primitive.ObjectID); ok && i >= 0 && int(i) < len(modelRefs) {
+			result.IDs[modelRefs[i]] = oID
+		}
+	}
+	o.resolveIDs(ctx, modelRefs, result.IDs)
+	o.log(ctx).Info().
+		Int64("upserted", result.Inserted).
+		Int64("matched", result.Matched).
+		Int64("modified", result.Updated).
+		Int64("skipped", result.Skipped).
+		Msg("upserted orders")
+	return result, nil
+}
+
+// resolveIDs looks up the IDs of the refs missing from ids, orders another import inserted after the lookup
+// of UpsertMany. They are left out when the lookup fails, the orders are imported already.
+func (o *OrdersRepo) resolveIDs(ctx context.Context, refs []string, ids map[string]primitive.ObjectID) {
+	var missing []string
+	for _, ref := range refs {
+		if _, ok := ids[ref]; !ok {
+			missing = append(missing, ref)
+		}
+	}
+	if len(missing) == 0 {
+		return
+	}
+	found, err := o.findByRefs(ctx, missing)
+	if err != nil {
+		return
+	}
+	for ref, order := range found {
+		ids[ref] = order.ID
+	}
+}
+
+// importUpdate sets the imported fields of order, the version counts the imports of the order like its updates.
+func importUpdate(order *data.Order) bson.D {
+	return bson.D{
+		{Key: "$set", Value: bson.D{
+			{Key: "products", Value: order.Products},
+			{Key: "user", Value: order.User},
+			{Key: "totalAmount", Value: order.TotalAmount},
+			{Key: "currency", Value: order.Currency},
+			{Key: "status", Value: order.Status},
+			{Key: "updatedAt", Value: order.UpdatedAt},
+		}},
+		{Key: "$setOnInsert", Value: bson.D{
+			{Key: "createdAt", Value: order.CreatedAt},
+			{Key: "updates", Value: order.Updates},
+		}},
+		{Key: "$inc", Value: bson.D{{Key: "version", Value: 1}}},
+	}
+}
+
+// findByRefs returns the ID, external reference and soft-delete marker of the stored orders among refs,
+// keyed by external reference.
+func (o *OrdersRepo) findByRefs(ctx context.Context, refs []string) (map[string]data.Order, error) {
+	filter := bson.D{{Key: "externalRef", Value: bson.D{{Key: "$in", Value: refs}}}}
+	projection := bson.D{{Key: "_id", Value: 1}, {Key: "externalRef", Value: 1}, {Key: "deletedAt", Value: 1}}
+	cursor, err := o.collection.Find(ctx, filter, options.Find().SetProjection(projection))
+	if err != nil {
+		o.log(ctx).Error().Err(err).Msg("failed to find imported orders")
+		return nil, ErrUnexpectedUpsertOrder
+	}
+	var found []data.Order
+	if err = cursor.All(ctx, &found); err != nil {
+		o.log(ctx).Error().Err(err).Msg("failed to decode imported orders")
+		return nil, ErrUnexpectedUpsertOrder
+	}
+	byRef := make(map[string]data.Order, len(found))
+	for i := range found {
+		byRef[found[i].ExternalRef] = found[i]
+	}
+	return byRef, nil
+}
+
 // validateCollection checks if the collection is initialized.
 func validateCollection(collection *mongo.Collection) error {
 	if collection == nil {
@@ -165,3 +502,84 @@ func validateCollection(collection *mongo.Collection) error {
 	}
 	return nil
 }
+
+// GetByUser returns the most recent orders placed by the user, newest first.
+func (o *OrdersRepo) GetByUser(ctx context.Context, user string, limit int64) (*[]data.Order, error) {
+	if err := validateCollection(o.collection); err != nil {
+		return nil, err
+	}
+	filter := bson.D{{Key: "user", Value: user}, notDeleted}
+	findOptions := options.Find().SetLimit(limit).SetSort(bson.D{{Key: "createdAt", Value: -1}})
+	cursor, err := o.collection.Find(ctx, filter, findOptions)
+	if err != nil {
+		o.log(ctx).Error().Err(err).Msg("failed to find user orders")
+		return nil, ErrUnexpectedGetOrder
+	}
+	results := []data.Order{}
+	if err = cursor.All(ctx, &results); err != nil {
+		o.log(ctx).Error().Err(err).Msg("failed to decode user orders")
+		return nil, ErrUnexpectedGetOrder
+	}
+	return &results, nil
+}
+
+// statusGroup is one row of the SummarizeUser aggregation, the orders of a user sharing a status and currency.
+type statusGroup struct {
+	ID struct {
+		Status   data.OrderStatus `bson:"status"`
+		Currency money.Currency   `bson:"currency"`
+	} `bson:"_id"`
+	Count       int64        `bson:"count"`
+	Spend       money.Amount `bson:"spend"`
+	LastOrderAt time.Time    `bson:"lastOrderAt"`
+}
+
+// SummarizeUser aggregates the orders of the user by status and currency and folds the groups into a summary.
+// A user without orders gets an empty summary.
+func (o *OrdersRepo) SummarizeUser(ctx context.Context, user string) (*data.CustomerSummary, error) {
+	if err := validateCollection(o.collection); err != nil {
+		return nil, err
+	}
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.D{{Key: "user", Value: user}, notDeleted}}},
+		{{Key: "$group", Value: bson.D{
+			{Key: "_id", Value: bson.D{{Key: "status", Value: "$status"}, {Key: "currency", Value: "$currency"}}},
+			{Key: "count", Value: bson.D{{Key: "$sum", Value: 1}}},
+			{Key: "spend", Value: bson.D{{Key: "$sum", Value: "$totalAmount"}}},
+			{Key: "lastOrderAt", Value: bson.D{{Key: "$max", Value: "$createdAt"}}},
+		}}},
+	}
+	cursor, err := o.collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		o.log(ctx).Error().Err(err).Msg("failed to aggregate user orders")
+		return nil, ErrUnexpectedSummarize
+	}
+	var groups []statusGroup
+	if err = cursor.All(ctx, &groups); err != nil {
+		o.log(ctx).Error().Err(err).Msg("failed to decode user order summary")
+		return nil, ErrUnexpectedSummarize
+	}
+
+	summary := &data.CustomerSummary{
+		User:          user,
+		LifetimeSpend: map[money.Currency]money.Amount{},
+		StatusCounts:  map[data.OrderStatus]int64{},
+	}
+	for _, g := range groups {
+		summary.OrderCount += g.Count
+		summary.StatusCounts[g.ID.Status] += g.Count
+		if g.ID.Status != data.OrderCancelled {
+			// orders stored before currencies were tracked are in the default currency
+			currency := g.ID.Currency
+			if currency == "" {
+				currency = data.DefaultCurrency
+			}
+			summary.LifetimeSpend[currency] += g.Spend
+		}
+		if summary.LastOrderAt == nil || g.LastOrderAt.After(*summary.LastOrderAt) {
+			last := g.LastOrderAt
+			summary.LastOrderAt = &last
+		}
+	}
+	return summary, nil
+}
@@ -9,8 +9,8 @@ import (
 	"github.com/rameshsunkara/go-rest-api-example/internal/db"
 	"github.com/rameshsunkara/go-rest-api-example/internal/db/mocks"
 	"github.com/rameshsunkara/go-rest-api-example/internal/models/data"
-	"github.com/rameshsunkara/go-rest-api-example/internal/utilities"
 	"github.com/rameshsunkara/go-rest-api-example/pkg/logger"
+	"github.com/rameshsunkara/go-rest-api-example/pkg/money"
 	"github.com/rameshsunkara/go-rest-api-example/pkg/mongodb"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -91,7 +91,7 @@ func TestValidate(t *testing.T) {
 		{
 			name: "GetAll with invalid initialization",
 			testFunc: func() error {
-				_, gErr := ds.GetAll(context.Background(), 10)
+				_, gErr := ds.GetAll(context.Background(), 10, db.ReadOptions{})
 				return gErr
 			},
 			wantErr: db.ErrInvalidInitialization,
@@ -99,7 +99,7 @@ func TestValidate(t *testing.T) {
 		{
 			name: "GetByID with invalid initialization",
 			testFunc: func() error {
-				_, gErr := ds.GetByID(context.Background(), primitive.NewObjectID())
+				_, gErr := ds.GetByID(context.Background(), primitive.NewObjectID(), db.ReadOptions{})
 				return gErr
 			},
 			wantErr: db.ErrInvalidInitialization,
@@ -118,6 +118,14 @@ func TestValidate(t *testing.T) {
 			},
 			wantErr: db.ErrInvalidInitialization,
 		},
+		{
+			name: "UpsertMany with invalid initialization",
+			testFunc: func() error {
+				_, uErr := ds.UpsertMany(context.Background(), []data.Order{{ExternalRef: "ref"}})
+				return uErr
+			},
+			wantErr: db.ErrInvalidInitialization,
+		},
 	}
 
 	for _, tc := range testCases {
@@ -146,10 +154,10 @@ func TestOrdersRepoCreate(t *testing.T) {
 				Version:     1,
 				CreatedAt:   time.Now(),
 				UpdatedAt:   time.Now(),
-				Products:    []data.Product{{Name: "Product 1", Price: 10.0, Quantity: 2}},
+				Products:    []data.Product{{Name: "Product 1", Price: money.MustParse("10"), Quantity: 2}},
 				User:        "test@example.com",
 				Status:      data.OrderPending,
-				TotalAmount: utilities.CalculateTotalAmount([]data.Product{{Name: "Product 1", Price: 10.0, Quantity: 2}}),
+				TotalAmount: money.MustParse("20"),
 			},
 			mock: func(mt *mtest.T) {
 				mt.AddMockResponses(mtest.CreateSuccessResponse())
@@ -170,10 +178,10 @@ func TestOrdersRepoCreate(t *testing.T) {
 				Version:     1,
 				CreatedAt:   time.Now(),
 				UpdatedAt:   time.Now(),
-				Products:    []data.Product{{Name: "Product 1", Price: 10.0, Quantity: 2}},
+				Products:    []data.Product{{Name: "Product 1", Price: money.MustParse("10"), Quantity: 2}},
 				User:        "test@example.com",
 				Status:      data.OrderPending,
-				TotalAmount: utilities.CalculateTotalAmount([]data.Product{{Name: "Product 1", Price: 10.0, Quantity: 2}}),
+				TotalAmount: money.MustParse("20"),
 			},
 			mock: func(mt *mtest.T) {
 				mt.AddMockResponses(mtest.CreateWriteErrorsResponse(mtest.WriteError{Code: 11000}))
@@ -219,10 +227,10 @@ func TestOrdersRepoUpdate(t *testing.T) {
 				Version:     1,
 				CreatedAt:   time.Now(),
 				UpdatedAt:   time.Now(),
-				Products:    []data.Product{{Name: "Product 1", Price: 10.0, Quantity: 2}},
+				Products:    []data.Product{{Name: "Product 1", Price: money.MustParse("10"), Quantity: 2}},
 				User:        "test@example.com",
 				Status:      data.OrderPending,
-				TotalAmount: utilities.CalculateTotalAmount([]data.Product{{Name: "Product 1", Price: 10.0, Quantity: 2}}),
+				TotalAmount: money.MustParse("20"),
 			},
 			mock: func(mt *mtest.T) {
 				mt.AddMockResponses(mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 1}))
@@ -236,10 +244,10 @@ func TestOrdersRepoUpdate(t *testing.T) {
 				Version:     1,
 				CreatedAt:   time.Now(),
 				UpdatedAt:   time.Now(),
-				Products:    []data.Product{{Name: "Product 1", Price: 10.0, Quantity: 2}},
+				Products:    []data.Product{{Name: "Product 1", Price: money.MustParse("10"), Quantity: 2}},
 				User:        "test@example.com",
 				Status:      data.OrderPending,
-				TotalAmount: utilities.CalculateTotalAmount([]data.Product{{Name: "Product 1", Price: 10.0, Quantity: 2}}),
+				TotalAmount: money.MustParse("20"),
 			},
 			mock: func(mt *mtest.T) {
 				mt.AddMockResponses(mtest.CreateSuccessResponse())
@@ -252,10 +260,10 @@ func TestOrdersRepoUpdate(t *testing.T) {
 				Version:     1,
 				CreatedAt:   time.Now(),
 				UpdatedAt:   time.Now(),
-				Products:    []data.Product{{Name: "Product 1", Price: 10.0, Quantity: 2}},
+				Products:    []data.Product{{Name: "Product 1", Price: money.MustParse("10"), Quantity: 2}},
 				User:        "test@example.com",
 				Status:      data.OrderPending,
-				TotalAmount: utilities.CalculateTotalAmount([]data.Product{{Name: "Product 1", Price: 10.0, Quantity: 2}}),
+				TotalAmount: money.MustParse("20"),
 			},
 			mock: func(mt *mtest.T) {
 				mt.AddMockResponses(mtest.CreateSuccessResponse())
@@ -269,10 +277,10 @@ func TestOrdersRepoUpdate(t *testing.T) {
 				Version:     1,
 				CreatedAt:   time.Now(),
 				UpdatedAt:   time.Now(),
-				Products:    []data.Product{{Name: "Product 1", Price: 10.0, Quantity: 2}},
+				Products:    []data.Product{{Name: "Product 1", Price: money.MustParse("10"), Quantity: 2}},
 				User:        "test@example.com",
 				Status:      data.OrderPending,
-				TotalAmount: utilities.CalculateTotalAmount([]data.Product{{Name: "Product 1", Price: 10.0, Quantity: 2}}),
+				TotalAmount: money.MustParse("20"),
 			},
 			mock: func(mt *mtest.T) {
 				mt.AddMockResponses(mtest.CreateWriteErrorsResponse(mtest.WriteError{Code: 11000}))
@@ -348,7 +356,7 @@ func TestOrdersRepoGetByID(t *testing.T) {
 				t.Errorf("failed to create repo")
 				return
 			}
-			result, err := repo.GetByID(context.TODO(), tt.orderID)
+			result, err := repo.GetByID(context.TODO(), tt.orderID, db.ReadOptions{})
 			if tt.wantErr != nil {
 				require.Error(t, err)
 				assert.Equal(t, tt.wantErr, err)
@@ -462,7 +470,7 @@ func TestOrdersRepoGetAll(t *testing.T) {
 				t.Errorf("failed to create repo")
 				return
 			}
-			results, err := repo.GetAll(context.TODO(), tt.limit)
+			results, err := repo.GetAll(context.TODO(), tt.limit, db.ReadOptions{})
 			if tt.wantErr != nil {
 				require.Error(t, err)
 				assert.Equal(t, tt.wantErr, err)
@@ -473,3 +481,543 @@ func TestOrdersRepoGetAll(t *testing.T) {
 		})
 	}
 }
+
+func TestOrdersRepoProjection(t *testing.T) {
+	t.Parallel()
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	oCollName := "ordersdb.orders"
+	oID := primitive.NewObjectID()
+	wantProjection := bson.D{{Key: "_id", Value: int32(1)}, {Key: "status", Value: int32(1)}}
+	opts := db.ReadOptions{Fields: []string{"_id", "status"}}
+
+	mt.Run("GetAll", func(mt *mtest.T) {
+		mt.AddMockResponses(
+			mtest.CreateCursorResponse(1, oCollName, mtest.FirstBatch, bson.D{
+				{Key: "_id", Value: oID},
+				{Key: "status", Value: data.OrderPending},
+			}),
+			mtest.CreateCursorResponse(0, oCollName, mtest.NextBatch),
+		)
+		repo, err := db.NewOrdersRepo(testLgr, mt.DB)
+		require.NoError(t, err)
+		results, err := repo.GetAll(context.TODO(), 10, opts)
+		require.NoError(t, err)
+		assert.Equal(t, data.OrderPending, (*results)[0].Status)
+		var projection bson.D
+		require.NoError(t, mt.GetStartedEvent().Command.Lookup("projection").Unmarshal(&projection))
+		assert.Equal(t, wantProjection, projection)
+	})
+
+	mt.Run("GetByID", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateCursorResponse(1, oCollName, mtest.FirstBatch, bson.D{{Key: "_id", Value: oID}}))
+		repo, err := db.NewOrdersRepo(testLgr, mt.DB)
+		require.NoError(t, err)
+		_, err = repo.GetByID(context.TODO(), oID, opts)
+		require.NoError(t, err)
+		var projection bson.D
+		require.NoError(t, mt.GetStartedEvent().Command.Lookup("projection").Unmarshal(&projection))
+		assert.Equal(t, wantProjection, projection)
+	})
+
+	mt.Run("WholeDocuments", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateCursorResponse(1, oCollName, mtest.FirstBatch, bson.D{{Key: "_id", Value: oID}}))
+		repo, err := db.NewOrdersRepo(testLgr, mt.DB)
+		require.NoError(t, err)
+		_, err = repo.GetByID(context.TODO(), oID, db.ReadOptions{})
+		require.NoError(t, err)
+		_, lookupErr := mt.GetStartedEvent().Command.LookupErr("projection")
+		require.Error(t, lookupErr)
+	})
+}
+
+func TestOrdersRepoGetAllAfter(t *testing.T) {
+	t.Parallel()
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	oCollName := "ordersdb.orders"
+	after := primitive.NewObjectID()
+
+	mt.Run("filters and sorts by ID", func(mt *mtest.T) {
+		mt.AddMockResponses(
+			mtest.CreateCursorResponse(1, oCollName, mtest.FirstBatch, bson.D{{Key: "_id", Value: primitive.NewObjectID()}}),
+			mtest.CreateCursorResponse(0, oCollName, mtest.NextBatch),
+		)
+		repo, err := db.NewOrdersRepo(testLgr, mt.DB)
+		require.NoError(t, err)
+		results, err := repo.GetAll(context.TODO(), 10, db.ReadOptions{After: after})
+		require.NoError(t, err)
+		assert.Len(t, *results, 1)
+
+		cmd := mt.GetStartedEvent().Command
+		var sort bson.D
+		require.NoError(t, cmd.Lookup("sort").Unmarshal(&sort))
+		assert.Equal(t, bson.D{{Key: "_id", Value: int32(1)}}, sort)
+		var filter bson.M
+		require.NoError(t, cmd.Lookup("filter").Unmarshal(&filter))
+		assert.Equal(t, bson.M{"$gt": after}, filter["_id"])
+	})
+}
+
+func TestOrdersRepoUpsertMany(t *testing.T) {
+	t.Parallel()
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	oCollName := "ordersdb.orders"
+
+	order := data.Order{
+		Version:     1,
+		CreatedAt:   time.Now(),
+		UpdatedAt:   time.Now(),
+		Products:    []data.Product{{Name: "Product 1", Price: money.MustParse("10"), Quantity: 2}},
+		User:        "test@example.com",
+		Status:      data.OrderDelivered,
+		ExternalRef: "legacy-1",
+	}
+	second := order
+	second.ExternalRef = "legacy-2"
+	insertedID, id1, id2 := primitive.NewObjectID(), primitive.NewObjectID(), primitive.NewObjectID()
+	tests := []struct {
+		name    string
+		orders  []data.Order
+		mock    func(mt *mtest.T)
+		want    *db.UpsertResult
+		wantErr error
+	}{
+		{
+			name:   "Success",
+			orders: []data.Order{order},
+			mock: func(mt *mtest.T) {
+				mt.AddMockResponses(
+					mtest.CreateCursorResponse(0, oCollName, mtest.FirstBatch),
+					mtest.CreateSuccessResponse(
+						bson.E{Key: "n", Value: 1},
+						bson.E{Key: "nModified", Value: 0},
+						bson.E{Key: "upserted", Value: bson.A{
+							bson.D{{Key: "index", Value: 0}, {Key: "_id", Value: insertedID}},
+						}},
+					))
+			},
+			want: &db.UpsertResult{Inserted: 1, IDs: map[string]primitive.ObjectID{"legacy-1": insertedID}},
+		},
+		{
+			name:   "MatchedUnchanged",
+			orders: []data.Order{order, second},
+			mock: func(mt *mtest.T) {
+				mt.AddMockResponses(
+					mtest.CreateCursorResponse(0, oCollName, mtest.FirstBatch,
+						bson.D{{Key: "_id", Value: id1}, {Key: "externalRef", Value: order.ExternalRef}},
+						bson.D{{Key: "_id", Value: id2}, {Key: "externalRef", Value: second.ExternalRef}}),
+					mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 2}, bson.E{Key: "nModified", Value: 1}))
+			},
+			want: &db.UpsertResult{
+				Matched: 2,
+				Updated: 1,
+				IDs:     map[string]primitive.ObjectID{"legacy-1": id1, "legacy-2": id2},
+			},
+		},
+		{
+			// legacy-2 is inserted by another import between the lookup and the write, its ID is looked up after
+			name:   "SkipsDeleted",
+			orders: []data.Order{order, second},
+			mock: func(mt *mtest.T) {
+				mt.AddMockResponses(
+					mtest.CreateCursorResponse(0, oCollName, mtest.FirstBatch, bson.D{
+						{Key: "_id", Value: id1},
+						{Key: "externalRef", Value: order.ExternalRef},
+						{Key: "deletedAt", Value: time.Now()},
+					}),
+					mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 1}, bson.E{Key: "nModified", Value: 1}),
+					mtest.CreateCursorResponse(0, oCollName, mtest.FirstBatch,
+						bson.D{{Key: "_id", Value: id2}, {Key: "externalRef", Value: second.ExternalRef}}))
+			},
+			want: &db.UpsertResult{
+				Matched: 1,
+				Updated: 1,
+				Skipped: 1,
+				IDs:     map[string]primitive.ObjectID{"legacy-2": id2},
+			},
+		},
+		{
+			name:   "AllDeleted",
+			orders: []data.Order{order},
+			mock: func(mt *mtest.T) {
+				mt.AddMockResponses(mtest.CreateCursorResponse(0, oCollName, mtest.FirstBatch, bson.D{
+					{Key: "_id", Value: id1},
+					{Key: "externalRef", Value: order.ExternalRef},
+					{Key: "deletedAt", Value: time.Now()},
+				}))
+			},
+			want: &db.UpsertResult{Skipped: 1, IDs: map[string]primitive.ObjectID{}},
+		},
+		{
+			name:   "FindError",
+			orders: []data.Order{order},
+			mock: func(mt *mtest.T) {
+				mt.AddMockResponses(mtest.CreateCommandErrorResponse(mtest.CommandError{Code: 1, Message: "boom"}))
+			},
+			wantErr: db.ErrUnexpectedUpsertOrder,
+		},
+		{
+			name:   "Empty",
+			orders: nil,
+			mock:   func(_ *mtest.T) {},
+			want:   &db.UpsertResult{},
+		},
+		{
+			name:    "MissingExternalRef",
+			orders:  []data.Order{{Version: 1}},
+			mock:    func(_ *mtest.T) {},
+			wantErr: db.ErrMissingExternalRef,
+		},
+		{
+			name:   "WriteError",
+			orders: []data.Order{order},
+			mock: func(mt *mtest.T) {
+				mt.AddMockResponses(
+					mtest.CreateCursorResponse(0, oCollName, mtest.FirstBatch),
+					mtest.CreateCommandErrorResponse(mtest.CommandError{Code: 1, Message: "boom"}))
+			},
+			wantErr: db.ErrUnexpectedUpsertOrder,
+		},
+	}
+
+	for _, tt := range tests {
+		mt.Run(tt.name, func(mt *mtest.T) {
+			tt.mock(mt)
+			repo, repoErr := db.NewOrdersRepo(testLgr, mt.DB)
+			require.NoError(t, repoErr)
+			res, err := repo.UpsertMany(context.TODO(), tt.orders)
+			if tt.wantErr != nil {
+				require.Error(t, err)
+				assert.Equal(t, tt.wantErr, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, res)
+		})
+	}
+}
+
+func TestOrdersRepoRestore(t *testing.T) {
+	t.Parallel()
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	tests := []struct {
+		name    string
+		mock    func(mt *mtest.T)
+		wantErr error
+	}{
+		{
+			name: "Success",
+			mock: func(mt *mtest.T) {
+				mt.AddMockResponses(mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 1}))
+			},
+		},
+		{
+			name: "NotDeleted",
+			mock: func(mt *mtest.T) {
+				mt.AddMockResponses(mtest.CreateSuccessResponse())
+			},
+			wantErr: db.ErrPOIDNotFound,
+		},
+		{
+			name: "UpdateError",
+			mock: func(mt *mtest.T) {
+				mt.AddMockResponses(mtest.CreateWriteErrorsResponse(mtest.WriteError{Code: 11000}))
+			},
+			wantErr: db.ErrUnexpectedRestoreOrder,
+		},
+	}
+
+	for _, tt := range tests {
+		mt.Run(tt.name, func(mt *mtest.T) {
+			tt.mock(mt)
+			repo, repoErr := db.NewOrdersRepo(testLgr, mt.DB)
+			require.NoError(t, repoErr)
+			err := repo.Restore(context.TODO(), primitive.NewObjectID())
+			if tt.wantErr != nil {
+				assert.Equal(t, tt.wantErr, err)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestOrdersRepoPurgeDeleted(t *testing.T) {
+	t.Parallel()
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("Success", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 4}))
+		repo, repoErr := db.NewOrdersRepo(testLgr, mt.DB)
+		require.NoError(t, repoErr)
+		purged, err := repo.PurgeDeleted(context.TODO(), time.Now())
+		require.NoError(t, err)
+		assert.Equal(t, int64(4), purged)
+	})
+
+	mt.Run("DeleteError", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateCommandErrorResponse(mtest.CommandError{Code: 1, Message: "boom"}))
+		repo, repoErr := db.NewOrdersRepo(testLgr, mt.DB)
+		require.NoError(t, repoErr)
+		_, err := repo.PurgeDeleted(context.TODO(), time.Now())
+		assert.Equal(t, db.ErrUnexpectedPurgeOrders, err)
+	})
+}
+
+func TestOrdersRepoTransition(t *testing.T) {
+	t.Parallel()
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	ns := "ordersdb.purchaseOrders"
+	id := primitive.NewObjectID()
+
+	tests := []struct {
+		name    string
+		replies []bson.D
+		wantErr error
+	}{
+		{
+			name: "Transitioned",
+			replies: []bson.D{mtest.CreateSuccessResponse(bson.E{Key: "value", Value: bson.D{
+				{Key: "_id", Value: id},
+				{Key: "status", Value: string(data.OrderCancelled)},
+				{Key: "version", Value: int64(2)},
+			}})},
+		},
+		{
+			name: "NotFound",
+			replies: []bson.D{
+				mtest.CreateSuccessResponse(bson.E{Key: "value", Value: nil}),
+				mtest.CreateCursorResponse(0, ns, mtest.FirstBatch),
+			},
+			wantErr: db.ErrPOIDNotFound,
+		},
+		{
+			name: "InvalidTransition",
+			replies: []bson.D{
+				mtest.CreateSuccessResponse(bson.E{Key: "value", Value: nil}),
+				mtest.CreateCursorResponse(0, ns, mtest.FirstBatch, bson.D{{Key: "n", Value: int32(1)}}),
+			},
+			wantErr: db.ErrInvalidTransition,
+		},
+		{
+			name:    "UpdateError",
+			replies: []bson.D{mtest.CreateCommandErrorResponse(mtest.CommandError{Code: 1, Message: "boom"})},
+			wantErr: db.ErrUnexpectedTransition,
+		},
+	}
+	for _, tt := range tests {
+		mt.Run(tt.name, func(mt *mtest.T) {
+			mt.AddMockResponses(tt.replies...)
+			repo, err := db.NewOrdersRepo(testLgr, mt.DB)
+			require.NoError(t, err)
+			order, err := repo.Transition(context.TODO(), id, data.OrderCancelled, data.OrderUpdate{Notes: "n"}, nil)
+			if tt.wantErr != nil {
+				assert.Equal(t, tt.wantErr, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, data.OrderCancelled, order.Status)
+			assert.Equal(t, int64(2), order.Version)
+		})
+	}
+
+	mt.Run("FiltersOnSourceStatuses", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateSuccessResponse(bson.E{Key: "value", Value: bson.D{{Key: "_id", Value: id}}}))
+		repo, err := db.NewOrdersRepo(testLgr, mt.DB)
+		require.NoError(t, err)
+		_, err = repo.Transition(context.TODO(), id, data.OrderShipped, data.OrderUpdate{}, nil)
+		require.NoError(t, err)
+		query := mt.GetStartedEvent().Command.Lookup("query").String()
+		assert.Contains(t, query, string(data.OrderProcessing))
+		assert.NotContains(t, query, string(data.OrderPending))
+	})
+
+	mt.Run("ShippingStoresShipment", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateSuccessResponse(bson.E{Key: "value", Value: bson.D{{Key: "_id", Value: id}}}))
+		repo, err := db.NewOrdersRepo(testLgr, mt.DB)
+		require.NoError(t, err)
+		_, err = repo.Transition(context.TODO(), id, data.OrderShipped, data.OrderUpdate{},
+			&data.Shipment{Carrier: "UPS", TrackingID: "1Z999"})
+		require.NoError(t, err)
+		shipment := mt.GetStartedEvent().Command.Lookup("update", "$set", "shipment").Document()
+		assert.Equal(t, "UPS", shipment.Lookup("carrier").StringValue())
+		assert.Equal(t, "1Z999", shipment.Lookup("trackingId").StringValue())
+		_, shipped := shipment.Lookup("shippedAt").DateTimeOK()
+		assert.True(t, shipped)
+	})
+
+	mt.Run("DeliveringStampsShipment", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateSuccessResponse(bson.E{Key: "value", Value: bson.D{{Key: "_id", Value: id}}}))
+		repo, err := db.NewOrdersRepo(testLgr, mt.DB)
+		require.NoError(t, err)
+		_, err = repo.Transition(context.TODO(), id, data.OrderDelivered, data.OrderUpdate{}, nil)
+		require.NoError(t, err)
+		_, delivered := mt.GetStartedEvent().Command.Lookup("update", "$set", "shipment.deliveredAt").DateTimeOK()
+		assert.True(t, delivered)
+	})
+}
+
+func TestOrdersRepoGetPendingBefore(t *testing.T) {
+	t.Parallel()
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	ns := "ordersdb.purchaseOrders"
+
+	mt.Run("Success", func(mt *mtest.T) {
+		mt.AddMockResponses(
+			mtest.CreateCursorResponse(1, ns, mtest.FirstBatch, bson.D{
+				{Key: "_id", Value: primitive.NewObjectID()},
+				{Key: "status", Value: string(data.OrderPending)},
+				{Key: "stockReserved", Value: true},
+			}),
+			mtest.CreateCursorResponse(0, ns, mtest.NextBatch),
+		)
+		repo, err := db.NewOrdersRepo(testLgr, mt.DB)
+		require.NoError(t, err)
+		orders, err := repo.GetPendingBefore(context.TODO(), time.Now(), 10)
+		require.NoError(t, err)
+		require.Len(t, *orders, 1)
+		assert.True(t, (*orders)[0].StockReserved)
+		filter := mt.GetStartedEvent().Command.Lookup("filter").String()
+		assert.Contains(t, filter, "stockReserved")
+		assert.Contains(t, filter, "deletedAt")
+	})
+
+	mt.Run("FindError", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateCommandErrorResponse(mtest.CommandError{Code: 1, Message: "boom"}))
+		repo, err := db.NewOrdersRepo(testLgr, mt.DB)
+		require.NoError(t, err)
+		_, err = repo.GetPendingBefore(context.TODO(), time.Now(), 10)
+		assert.Equal(t, db.ErrUnexpectedGetOrder, err)
+	})
+}
+
+func TestOrdersRepoGetByUser(t *testing.T) {
+	t.Parallel()
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	ns := "ordersdb.purchaseOrders"
+
+	mt.Run("Success", func(mt *mtest.T) {
+		mt.AddMockResponses(
+			mtest.CreateCursorResponse(1, ns, mtest.FirstBatch, bson.D{
+				{Key: "_id", Value: primitive.NewObjectID()},
+				{Key: "user", Value: "jane@example.com"},
+			}),
+			mtest.CreateCursorResponse(0, ns, mtest.NextBatch),
+		)
+		repo, err := db.NewOrdersRepo(testLgr, mt.DB)
+		require.NoError(t, err)
+		orders, err := repo.GetByUser(context.TODO(), "jane@example.com", 10)
+		require.NoError(t, err)
+		require.Len(t, *orders, 1)
+		assert.Equal(t, "jane@example.com", (*orders)[0].User)
+		cmd := mt.GetStartedEvent().Command
+		assert.Equal(t, "jane@example.com", cmd.Lookup("filter", "user").StringValue())
+		assert.Equal(t, int32(-1), cmd.Lookup("sort", "createdAt").Int32())
+	})
+
+	mt.Run("FindError", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateCommandErrorResponse(mtest.CommandError{Code: 1, Message: "boom"}))
+		repo, err := db.NewOrdersRepo(testLgr, mt.DB)
+		require.NoError(t, err)
+		_, err = repo.GetByUser(context.TODO(), "jane@example.com", 10)
+		assert.Equal(t, db.ErrUnexpectedGetOrder, err)
+	})
+}
+
+func TestOrdersRepoSummarizeUser(t *testing.T) {
+	t.Parallel()
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	ns := "ordersdb.purchaseOrders"
+	group := func(status data.OrderStatus, currency string, count int32, spend string, last time.Time) bson.D {
+		return bson.D{
+			{Key: "_id", Value: bson.D{{Key: "status", Value: string(status)}, {Key: "currency", Value: currency}}},
+			{Key: "count", Value: count},
+			{Key: "spend", Value: money.MustParse(spend)},
+			{Key: "lastOrderAt", Value: primitive.NewDateTimeFromTime(last)},
+		}
+	}
+	older := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	newer := older.Add(48 * time.Hour)
+
+	mt.Run("Success", func(mt *mtest.T) {
+		mt.AddMockResponses(
+			mtest.CreateCursorResponse(1, ns, mtest.FirstBatch,
+				group(data.OrderDelivered, "USD", 2, "30.50", older),
+				group(data.OrderPending, "", 1, "9.50", older),
+				group(data.OrderPending, "EUR", 1, "12", older),
+				group(data.OrderCancelled, "USD", 1, "100", newer),
+			),
+			mtest.CreateCursorResponse(0, ns, mtest.NextBatch),
+		)
+		repo, err := db.NewOrdersRepo(testLgr, mt.DB)
+		require.NoError(t, err)
+		summary, err := repo.SummarizeUser(context.TODO(), "jane@example.com")
+		require.NoError(t, err)
+		assert.Equal(t, "jane@example.com", summary.User)
+		assert.Equal(t, int64(5), summary.OrderCount)
+		assert.Equal(t, map[money.Currency]money.Amount{
+			money.USD: money.MustParse("40"),
+			money.EUR: money.MustParse("12"),
+		}, summary.LifetimeSpend)
+		assert.Equal(t, map[data.OrderStatus]int64{
+			data.OrderDelivered: 2, data.OrderPending: 2, data.OrderCancelled: 1,
+		}, summary.StatusCounts)
+		require.NotNil(t, summary.LastOrderAt)
+		assert.True(t, newer.Equal(*summary.LastOrderAt))
+		match := mt.GetStartedEvent().Command.Lookup("pipeline", "0", "$match")
+		assert.Equal(t, "jane@example.com", match.Document().Lookup("user").StringValue())
+	})
+
+	mt.Run("NoOrders", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, ns, mtest.FirstBatch))
+		repo, err := db.NewOrdersRepo(testLgr, mt.DB)
+		require.NoError(t, err)
+		summary, err := repo.SummarizeUser(context.TODO(), "nobody@example.com")
+		require.NoError(t, err)
+		assert.Zero(t, summary.OrderCount)
+		assert.Nil(t, summary.LastOrderAt)
+		assert.Empty(t, summary.LifetimeSpend)
+	})
+
+	mt.Run("AggregateError", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateCommandErrorResponse(mtest.CommandError{Code: 1, Message: "boom"}))
+		repo, err := db.NewOrdersRepo(testLgr, mt.DB)
+		require.NoError(t, err)
+		_, err = repo.SummarizeUser(context.TODO(), "jane@example.com")
+		assert.Equal(t, db.ErrUnexpectedSummarize, err)
+	})
+}
+
+func TestOrdersRepoLogsThroughRequestLogger(t *testing.T) {
+	t.Parallel()
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("request logger", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateWriteErrorsResponse(mtest.WriteError{Code: 11000}))
+		repoLgr := logger.NewRecorder()
+		repo, err := db.NewOrdersRepo(repoLgr, mt.DB)
+		require.NoError(t, err)
+
+		reqLgr := logger.NewRecorder()
+		ctx := logger.NewContext(context.Background(), reqLgr.With().Str("X-Request-ID", "req-1").Logger())
+		_, err = repo.Create(ctx, &data.Order{User: "test@example.com"})
+		require.ErrorIs(t, err, db.ErrFailedToCreateOrder)
+
+		assert.Empty(t, repoLgr.Entries())
+		entries := reqLgr.Entries()
+		require.Len(t, entries, 1)
+		assert.Equal(t, "failed to create order", entries[0].Message)
+		assert.Equal(t, "req-1", entries[0].Fields["X-Request-ID"])
+	})
+
+	mt.Run("background job", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateWriteErrorsResponse(mtest.WriteError{Code: 11000}))
+		repoLgr := logger.NewRecorder()
+		repo, err := db.NewOrdersRepo(repoLgr, mt.DB)
+		require.NoError(t, err)
+
+		_, err = repo.Create(context.Background(), &data.Order{User: "test@example.com"})
+		require.ErrorIs(t, err, db.ErrFailedToCreateOrder)
+		assert.Len(t, repoLgr.Entries(), 1)
+	})
+}
@@ -0,0 +1,190 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/rameshsunkara/go-rest-api-example/internal/models/data"
+	"github.com/rameshsunkara/go-rest-api-example/pkg/logger"
+	"github.com/rameshsunkara/go-rest-api-example/pkg/mongodb"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	ProductsCollection = "products"
+)
+
+var (
+	ErrSKUNotFound             = errors.New("product doesn't exist with given sku")
+	ErrSKUExists               = errors.New("product already exists with given sku")
+	ErrFailedToCreateProduct   = errors.New("failed to create product")
+	ErrUnexpectedGetProduct    = errors.New("unexpected error occurred while fetching product")
+	ErrUnexpectedUpdateProduct = errors.New("unexpected error occurred while updating product")
+	ErrUnexpectedDeleteProduct = errors.New("unexpected error occurred while deleting product")
+)
+
+// ProductsDataService defines the interface for product catalog data operations.
+// SKUs are matched case-insensitively.
+type ProductsDataService interface {
+	Create(ctx context.Context, product *data.CatalogProduct) (string, error)
+	Update(ctx context.Context, product *data.CatalogProduct) error
+	GetAll(ctx context.Context, limit int64, includeInactive bool) (*[]data.CatalogProduct, error)
+	GetBySKU(ctx context.Context, sku string) (*data.CatalogProduct, error)
+	GetBySKUs(ctx context.Context, skus []string) ([]data.CatalogProduct, error)
+	DeleteBySKU(ctx context.Context, sku string) error
+}
+
+// ProductsRepo implements ProductsDataService using MongoDB.
+type ProductsRepo struct {
+	collection *mongo.Collection
+	logger     logger.Logger
+}
+
+// NewProductsRepo creates a new ProductsRepo.
+func NewProductsRepo(lgr logger.Logger, db mongodb.MongoDatabase) (*ProductsRepo, error) {
+	if lgr == nil || db == nil {
+		return nil, errors.New("missing required inputs to create ProductsRepo")
+	}
+	return &ProductsRepo{
+		collection: db.Collection(ProductsCollection),
+		logger:     lgr,
+	}, nil
+}
+
+// Create inserts a new product, SKUs are unique.
+func (p *ProductsRepo) Create(ctx context.Context, product *data.CatalogProduct) (string, error) {
+	if err := validateCollection(p.collection); err != nil {
+		return "", err
+	}
+	if !product.ID.IsZero() {
+		return "", ErrInvalidPOIDCreate
+	}
+	product.SKU = data.NormalizeSKU(product.SKU)
+	result, err := p.collection.InsertOne(ctx, product)
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return "", ErrSKUExists
+		}
+		p.logger.Error().Err(err).Msg("failed to create product")
+		return "", ErrFailedToCreateProduct
+	}
+	insertedID, ok := result.InsertedID.(primitive.ObjectID)
+	if !ok {
+		return "", ErrInvalidID
+	}
+	p.logger.Info().Str("sku", product.SKU).Msg("created new product")
+	return insertedID.Hex(), nil
+}
+
+// Update replaces the name, description, price, currency and active flag of the product with the given SKU.
+func (p *ProductsRepo) Update(ctx context.Context, product *data.CatalogProduct) error {
+	if err := validateCollection(p.collection); err != nil {
+		return err
+	}
+	product.SKU = data.NormalizeSKU(product.SKU)
+	product.UpdatedAt = time.Now()
+	filter := bson.D{{Key: "sku", Value: product.SKU}}
+	update := bson.D{{Key: "$set", Value: bson.D{
+		{Key: "name", Value: product.Name},
+		{Key: "description", Value: product.Description},
+		{Key: "price", Value: product.Price},
+		{Key: "currency", Value: product.Currency},
+		{Key: "active", Value: product.Active},
+		{Key: "updatedAt", Value: product.UpdatedAt},
+	}}}
+	res, err := p.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		p.logger.Error().Err(err).Msg("failed to update product")
+		return ErrUnexpectedUpdateProduct
+	}
+	if res.MatchedCount == 0 {
+		return ErrSKUNotFound
+	}
+	return nil
+}
+
+// GetAll retrieves products sorted by SKU up to the specified limit, active ones only unless requested.
+func (p *ProductsRepo) GetAll(ctx context.Context, limit int64, includeInactive bool) (*[]data.CatalogProduct, error) {
+	if err := validateCollection(p.collection); err != nil {
+		return nil, err
+	}
+	filter := bson.D{}
+	if !includeInactive {
+		filter = append(filter, bson.E{Key: "active", Value: true})
+	}
+	findOptions := options.Find().SetLimit(limit).SetSort(bson.D{{Key: "sku", Value: 1}})
+	return p.find(ctx, filter, findOptions)
+}
+
+// GetBySKU retrieves a product by its SKU.
+func (p *ProductsRepo) GetBySKU(ctx context.Context, sku string) (*data.CatalogProduct, error) {
+	if err := validateCollection(p.collection); err != nil {
+		return nil, err
+	}
+	var result data.CatalogProduct
+	err := p.collection.FindOne(ctx, bson.D{{Key: "sku", Value: data.NormalizeSKU(sku)}}).Decode(&result)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrSKUNotFound
+		}
+		p.logger.Error().Err(err).Msg("failed to get product by sku")
+		return nil, ErrUnexpectedGetProduct
+	}
+	return &result, nil
+}
+
+// GetBySKUs retrieves the products with the given SKUs in a single query, unknown SKUs are omitted.
+func (p *ProductsRepo) GetBySKUs(ctx context.Context, skus []string) ([]data.CatalogProduct, error) {
+	if err := validateCollection(p.collection); err != nil {
+		return nil, err
+	}
+	normalized := make([]string, len(skus))
+	for i, sku := range skus {
+		normalized[i] = data.NormalizeSKU(sku)
+	}
+	filter := bson.D{{Key: "sku", Value: bson.D{{Key: "$in", Value: normalized}}}}
+	results, err := p.find(ctx, filter, options.Find())
+	if err != nil {
+		return nil, err
+	}
+	return *results, nil
+}
+
+// DeleteBySKU removes a product from the catalog. Orders keep their copy of its name and price.
+func (p *ProductsRepo) DeleteBySKU(ctx context.Context, sku string) error {
+	if err := validateCollection(p.collection); err != nil {
+		return err
+	}
+	res, err := p.collection.DeleteOne(ctx, bson.D{{Key: "sku", Value: data.NormalizeSKU(sku)}})
+	if err != nil {
+		p.logger.Error().Err(err).Msg("failed to delete product")
+		return ErrUnexpectedDeleteProduct
+	}
+	if res.DeletedCount == 0 {
+		return ErrSKUNotFound
+	}
+	p.logger.Info().Str("sku", data.NormalizeSKU(sku)).Msg("deleted product")
+	return nil
+}
+
+func (p *ProductsRepo) find(
+	ctx context.Context,
+	filter bson.D,
+	findOptions *options.FindOptions,
+) (*[]data.CatalogProduct, error) {
+	cursor, err := p.collection.Find(ctx, filter, findOptions)
+	if err != nil {
+		p.logger.Error().Err(err).Msg("failed to find products")
+		return nil, ErrUnexpectedGetProduct
+	}
+	results := []data.CatalogProduct{}
+	if err = cursor.All(ctx, &results); err != nil {
+		p.logger.Error().Err(err).Msg("failed to decode products")
+		return nil, ErrUnexpectedGetProduct
+	}
+	return &results, nil
+}
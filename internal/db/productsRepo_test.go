@@ -0,0 +1,275 @@
+package db_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/rameshsunkara/go-rest-api-example/internal/db"
+	"github.com/rameshsunkara/go-rest-api-example/internal/db/mocks"
+	"github.com/rameshsunkara/go-rest-api-example/internal/models/data"
+	"github.com/rameshsunkara/go-rest-api-example/pkg/money"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+)
+
+const productsNS = "ordersdb.products"
+
+func catalogProductDoc(sku string, active bool) bson.D {
+	return bson.D{
+		{Key: "_id", Value: primitive.NewObjectID()},
+		{Key: "sku", Value: sku},
+		{Key: "name", Value: "Widget " + sku},
+		{Key: "price", Value: "9.99"},
+		{Key: "currency", Value: "USD"},
+		{Key: "active", Value: active},
+	}
+}
+
+func TestNewProductsRepo(t *testing.T) {
+	t.Parallel()
+	_, err := db.NewProductsRepo(nil, &mocks.MockMongoDataBase{})
+	require.Error(t, err)
+	_, err = db.NewProductsRepo(testLgr, nil)
+	require.Error(t, err)
+
+	repo, err := db.NewProductsRepo(testLgr, &mocks.MockMongoDataBase{})
+	require.NoError(t, err)
+	ctx := context.Background()
+	_, err = repo.Create(ctx, &data.CatalogProduct{})
+	require.ErrorIs(t, err, db.ErrInvalidInitialization)
+	require.ErrorIs(t, repo.Update(ctx, &data.CatalogProduct{}), db.ErrInvalidInitialization)
+	_, err = repo.GetAll(ctx, 10, false)
+	require.ErrorIs(t, err, db.ErrInvalidInitialization)
+	_, err = repo.GetBySKU(ctx, "A")
+	require.ErrorIs(t, err, db.ErrInvalidInitialization)
+	_, err = repo.GetBySKUs(ctx, []string{"A"})
+	require.ErrorIs(t, err, db.ErrInvalidInitialization)
+	require.ErrorIs(t, repo.DeleteBySKU(ctx, "A"), db.ErrInvalidInitialization)
+}
+
+func TestProductsRepoCreate(t *testing.T) {
+	t.Parallel()
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	tests := []struct {
+		name    string
+		product *data.CatalogProduct
+		reply   bson.D
+		wantErr error
+	}{
+		{
+			name:    "Success",
+			product: &data.CatalogProduct{SKU: " wid-1 ", Price: money.MustParse("9.99")},
+			reply:   mtest.CreateSuccessResponse(),
+		},
+		{
+			name:    "ExistingID",
+			product: &data.CatalogProduct{ID: primitive.NewObjectID(), SKU: "WID-1"},
+			wantErr: db.ErrInvalidPOIDCreate,
+		},
+		{
+			name:    "DuplicateSKU",
+			product: &data.CatalogProduct{SKU: "WID-1"},
+			reply:   mtest.CreateWriteErrorsResponse(mtest.WriteError{Code: 11000, Message: "duplicate key"}),
+			wantErr: db.ErrSKUExists,
+		},
+		{
+			name:    "InsertError",
+			product: &data.CatalogProduct{SKU: "WID-1"},
+			reply:   mtest.CreateCommandErrorResponse(mtest.CommandError{Code: 1, Message: "boom"}),
+			wantErr: db.ErrFailedToCreateProduct,
+		},
+	}
+	for _, tt := range tests {
+		mt.Run(tt.name, func(mt *mtest.T) {
+			if tt.reply != nil {
+				mt.AddMockResponses(tt.reply)
+			}
+			repo, err := db.NewProductsRepo(testLgr, mt.DB)
+			require.NoError(t, err)
+			id, err := repo.Create(context.TODO(), tt.product)
+			if tt.wantErr != nil {
+				assert.Equal(t, tt.wantErr, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.NotEmpty(t, id)
+			assert.Equal(t, "WID-1", tt.product.SKU)
+		})
+	}
+}
+
+func TestProductsRepoUpdate(t *testing.T) {
+	t.Parallel()
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	tests := []struct {
+		name    string
+		reply   bson.D
+		wantErr error
+	}{
+		{name: "Updated", reply: mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 1})},
+		{
+			name:    "NotFound",
+			reply:   mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 0}),
+			wantErr: db.ErrSKUNotFound,
+		},
+		{
+			name:    "UpdateError",
+			reply:   mtest.CreateCommandErrorResponse(mtest.CommandError{Code: 1, Message: "boom"}),
+			wantErr: db.ErrUnexpectedUpdateProduct,
+		},
+	}
+	for _, tt := range tests {
+		mt.Run(tt.name, func(mt *mtest.T) {
+			mt.AddMockResponses(tt.reply)
+			repo, err := db.NewProductsRepo(testLgr, mt.DB)
+			require.NoError(t, err)
+			err = repo.Update(context.TODO(), &data.CatalogProduct{SKU: "wid-1", Active: true})
+			if tt.wantErr != nil {
+				assert.Equal(t, tt.wantErr, err)
+				return
+			}
+			require.NoError(t, err)
+			filter := mt.GetStartedEvent().Command.Lookup("updates", "0", "q").String()
+			assert.Contains(t, filter, `"WID-1"`)
+		})
+	}
+}
+
+func TestProductsRepoGetAll(t *testing.T) {
+	t.Parallel()
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("ActiveOnly", func(mt *mtest.T) {
+		mt.AddMockResponses(
+			mtest.CreateCursorResponse(1, productsNS, mtest.FirstBatch, catalogProductDoc("A-1", true)),
+			mtest.CreateCursorResponse(0, productsNS, mtest.NextBatch),
+		)
+		repo, err := db.NewProductsRepo(testLgr, mt.DB)
+		require.NoError(t, err)
+		products, err := repo.GetAll(context.TODO(), 10, false)
+		require.NoError(t, err)
+		require.Len(t, *products, 1)
+		assert.Equal(t, money.MustParse("9.99"), (*products)[0].Price)
+		filter := mt.GetStartedEvent().Command.Lookup("filter").String()
+		assert.Contains(t, filter, "active")
+	})
+
+	mt.Run("IncludeInactive", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, productsNS, mtest.FirstBatch))
+		repo, err := db.NewProductsRepo(testLgr, mt.DB)
+		require.NoError(t, err)
+		products, err := repo.GetAll(context.TODO(), 10, true)
+		require.NoError(t, err)
+		assert.Empty(t, *products)
+		filter := mt.GetStartedEvent().Command.Lookup("filter").String()
+		assert.NotContains(t, filter, "active")
+	})
+
+	mt.Run("FindError", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateCommandErrorResponse(mtest.CommandError{Code: 1, Message: "boom"}))
+		repo, err := db.NewProductsRepo(testLgr, mt.DB)
+		require.NoError(t, err)
+		_, err = repo.GetAll(context.TODO(), 10, false)
+		assert.Equal(t, db.ErrUnexpectedGetProduct, err)
+	})
+}
+
+func TestProductsRepoGetBySKU(t *testing.T) {
+	t.Parallel()
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("Success", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateCursorResponse(1, productsNS, mtest.FirstBatch, catalogProductDoc("A-1", true)))
+		repo, err := db.NewProductsRepo(testLgr, mt.DB)
+		require.NoError(t, err)
+		product, err := repo.GetBySKU(context.TODO(), "a-1")
+		require.NoError(t, err)
+		assert.Equal(t, "A-1", product.SKU)
+		assert.Equal(t, money.USD, product.Currency)
+	})
+
+	mt.Run("NotFound", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, productsNS, mtest.FirstBatch))
+		repo, err := db.NewProductsRepo(testLgr, mt.DB)
+		require.NoError(t, err)
+		_, err = repo.GetBySKU(context.TODO(), "NOPE")
+		assert.Equal(t, db.ErrSKUNotFound, err)
+	})
+
+	mt.Run("FindError", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateCommandErrorResponse(mtest.CommandError{Code: 1, Message: "boom"}))
+		repo, err := db.NewProductsRepo(testLgr, mt.DB)
+		require.NoError(t, err)
+		_, err = repo.GetBySKU(context.TODO(), "A-1")
+		assert.Equal(t, db.ErrUnexpectedGetProduct, err)
+	})
+}
+
+func TestProductsRepoGetBySKUs(t *testing.T) {
+	t.Parallel()
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("Success", func(mt *mtest.T) {
+		mt.AddMockResponses(
+			mtest.CreateCursorResponse(1, productsNS, mtest.FirstBatch,
+				catalogProductDoc("A-1", true), catalogProductDoc("B-2", false)),
+			mtest.CreateCursorResponse(0, productsNS, mtest.NextBatch),
+		)
+		repo, err := db.NewProductsRepo(testLgr, mt.DB)
+		require.NoError(t, err)
+		products, err := repo.GetBySKUs(context.TODO(), []string{"a-1", "b-2", "c-3"})
+		require.NoError(t, err)
+		assert.Len(t, products, 2)
+		filter := mt.GetStartedEvent().Command.Lookup("filter").String()
+		assert.Contains(t, filter, `"C-3"`)
+	})
+
+	mt.Run("FindError", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateCommandErrorResponse(mtest.CommandError{Code: 1, Message: "boom"}))
+		repo, err := db.NewProductsRepo(testLgr, mt.DB)
+		require.NoError(t, err)
+		_, err = repo.GetBySKUs(context.TODO(), []string{"A-1"})
+		assert.Equal(t, db.ErrUnexpectedGetProduct, err)
+	})
+}
+
+func TestProductsRepoDeleteBySKU(t *testing.T) {
+	t.Parallel()
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	tests := []struct {
+		name    string
+		reply   bson.D
+		wantErr error
+	}{
+		{name: "Deleted", reply: mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 1})},
+		{
+			name:    "NotFound",
+			reply:   mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 0}),
+			wantErr: db.ErrSKUNotFound,
+		},
+		{
+			name:    "DeleteError",
+			reply:   mtest.CreateCommandErrorResponse(mtest.CommandError{Code: 1, Message: "boom"}),
+			wantErr: db.ErrUnexpectedDeleteProduct,
+		},
+	}
+	for _, tt := range tests {
+		mt.Run(tt.name, func(mt *mtest.T) {
+			mt.AddMockResponses(tt.reply)
+			repo, err := db.NewProductsRepo(testLgr, mt.DB)
+			require.NoError(t, err)
+			err = repo.DeleteBySKU(context.TODO(), "A-1")
+			if tt.wantErr != nil {
+				assert.Equal(t, tt.wantErr, err)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
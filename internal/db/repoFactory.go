@@ -0,0 +1,79 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/rameshsunkara/go-rest-api-example/internal/tenant"
+	"github.com/rameshsunkara/go-rest-api-example/pkg/logger"
+	"github.com/rameshsunkara/go-rest-api-example/pkg/mongodb"
+)
+
+var ErrTenantRequired = errors.New("tenant is required to access the database")
+
+// RepoFactory builds a repository per tenant database on first use and caches it.
+type RepoFactory[T any] struct {
+	dbMgr         mongodb.MongoManager
+	build         func(mongodb.MongoDatabase) (T, error)
+	requireTenant bool
+	logger        logger.Logger
+
+	mu    sync.RWMutex
+	repos map[string]T
+}
+
+// NewRepoFactory creates a RepoFactory that builds repositories with build.
+// Contexts without a tenant use the default database unless requireTenant is set.
+func NewRepoFactory[T any](
+	lgr logger.Logger,
+	dbMgr mongodb.MongoManager,
+	requireTenant bool,
+	build func(mongodb.MongoDatabase) (T, error),
+) (*RepoFactory[T], error) {
+	if lgr == nil || dbMgr == nil || build == nil {
+		return nil, errors.New("missing required inputs to create RepoFactory")
+	}
+	return &RepoFactory[T]{
+		dbMgr:         dbMgr,
+		build:         build,
+		requireTenant: requireTenant,
+		logger:        lgr,
+		repos:         map[string]T{},
+	}, nil
+}
+
+// For returns the repository of the tenant in ctx.
+func (f *RepoFactory[T]) For(ctx context.Context) (T, error) {
+	t, ok := tenant.FromContext(ctx)
+	if !ok && f.requireTenant {
+		var zero T
+		return zero, ErrTenantRequired
+	}
+	// the default database is cached under the empty name
+	name := t.Database
+
+	f.mu.RLock()
+	repo, found := f.repos[name]
+	f.mu.RUnlock()
+	if found {
+		return repo, nil
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if repo, found = f.repos[name]; found {
+		return repo, nil
+	}
+	d := f.dbMgr.Database()
+	if name != "" {
+		d = f.dbMgr.DatabaseByName(name)
+	}
+	repo, err := f.build(d)
+	if err != nil {
+		return repo, err
+	}
+	f.repos[name] = repo
+	f.logger.Info().Str("tenant", t.ID).Str("database", name).Msg("created tenant repository")
+	return repo, nil
+}
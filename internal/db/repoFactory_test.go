@@ -0,0 +1,112 @@
+package db_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/rameshsunkara/go-rest-api-example/internal/db"
+	"github.com/rameshsunkara/go-rest-api-example/internal/db/mocks"
+	"github.com/rameshsunkara/go-rest-api-example/internal/models/data"
+	"github.com/rameshsunkara/go-rest-api-example/internal/tenant"
+	"github.com/rameshsunkara/go-rest-api-example/pkg/mongodb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	acme   = tenant.Tenant{ID: "acme", Database: "ecommerce_acme"}
+	globex = tenant.Tenant{ID: "globex", Database: "ecommerce_globex"}
+)
+
+// databaseName builds a repository that remembers the name of the database it was built for.
+func databaseName(d mongodb.MongoDatabase) (string, error) {
+	return d.(*mocks.MockMongoDataBase).Name, nil
+}
+
+func TestNewRepoFactory(t *testing.T) {
+	t.Parallel()
+	_, err := db.NewRepoFactory(nil, &mocks.MockMongoMgr{}, false, databaseName)
+	require.Error(t, err)
+	_, err = db.NewRepoFactory(testLgr, nil, false, databaseName)
+	require.Error(t, err)
+	_, err = db.NewRepoFactory[string](testLgr, &mocks.MockMongoMgr{}, false, nil)
+	require.Error(t, err)
+}
+
+func TestRepoFactoryFor(t *testing.T) {
+	t.Parallel()
+	builds := 0
+	f, err := db.NewRepoFactory(testLgr, &mocks.MockMongoMgr{}, false, func(d mongodb.MongoDatabase) (string, error) {
+		builds++
+		return databaseName(d)
+	})
+	require.NoError(t, err)
+
+	for range 2 {
+		name, forErr := f.For(tenant.NewContext(context.Background(), acme))
+		require.NoError(t, forErr)
+		assert.Equal(t, acme.Database, name)
+	}
+	name, err := f.For(tenant.NewContext(context.Background(), globex))
+	require.NoError(t, err)
+	assert.Equal(t, globex.Database, name)
+	// the default database has no name in the mock
+	name, err = f.For(context.Background())
+	require.NoError(t, err)
+	assert.Empty(t, name)
+	assert.Equal(t, 3, builds)
+}
+
+func TestRepoFactoryFor_Errors(t *testing.T) {
+	t.Parallel()
+	f, err := db.NewRepoFactory(testLgr, &mocks.MockMongoMgr{}, true, databaseName)
+	require.NoError(t, err)
+	_, err = f.For(context.Background())
+	require.ErrorIs(t, err, db.ErrTenantRequired)
+
+	buildErr := errors.New("boom")
+	fails := true
+	f, err = db.NewRepoFactory(testLgr, &mocks.MockMongoMgr{}, true, func(d mongodb.MongoDatabase) (string, error) {
+		if fails {
+			return "", buildErr
+		}
+		return databaseName(d)
+	})
+	require.NoError(t, err)
+	ctx := tenant.NewContext(context.Background(), acme)
+	_, err = f.For(ctx)
+	require.ErrorIs(t, err, buildErr)
+	// failed builds are not cached
+	fails = false
+	name, err := f.For(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, acme.Database, name)
+}
+
+func TestTenantOrdersRepo(t *testing.T) {
+	t.Parallel()
+	_, err := db.NewTenantOrdersRepo(nil)
+	require.Error(t, err)
+
+	f, err := db.NewRepoFactory(testLgr, &mocks.MockMongoMgr{}, true,
+		func(d mongodb.MongoDatabase) (db.OrdersDataService, error) {
+			name, _ := databaseName(d)
+			return &mocks.MockOrdersDataService{
+				GetByUserFunc: func(_ context.Context, user string, _ int64) (*[]data.Order, error) {
+					return &[]data.Order{{User: user, Customer: &data.Customer{Name: name}}}, nil
+				},
+			}, nil
+		})
+	require.NoError(t, err)
+	repo, err := db.NewTenantOrdersRepo(f)
+	require.NoError(t, err)
+
+	for _, tn := range []tenant.Tenant{acme, globex} {
+		orders, getErr := repo.GetByUser(tenant.NewContext(context.Background(), tn), "jane", 10)
+		require.NoError(t, getErr)
+		assert.Equal(t, tn.Database, (*orders)[0].Customer.Name)
+	}
+	_, err = repo.GetByUser(context.Background(), "jane", 10)
+	require.ErrorIs(t, err, db.ErrTenantRequired)
+}
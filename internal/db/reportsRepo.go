@@ -0,0 +1,197 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/rameshsunkara/go-rest-api-example/internal/models/data"
+	"github.com/rameshsunkara/go-rest-api-example/pkg/logger"
+	"github.com/rameshsunkara/go-rest-api-example/pkg/mongodb"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+var ErrUnexpectedReport = errors.New("unexpected error occurred while building report")
+
+// ReportsDataService defines the interface for the reporting aggregations over orders.
+// Every report covers the orders created in [From, To) that are not soft-deleted, all but
+// OrdersByStatus leave out cancelled orders.
+type ReportsDataService interface {
+	Revenue(ctx context.Context, q ReportQuery) ([]data.RevenueBucket, error)
+	OrdersByStatus(ctx context.Context, q ReportQuery) ([]data.StatusCount, error)
+	BasketSize(ctx context.Context, q ReportQuery) ([]data.BasketStats, error)
+	TopProducts(ctx context.Context, q ReportQuery, limit int64) ([]data.ProductSales, error)
+}
+
+// ReportQuery selects the orders of a report and how they are bucketed.
+// Location is the time zone of the buckets, Interval is only used by Revenue.
+type ReportQuery struct {
+	From     time.Time
+	To       time.Time
+	Location *time.Location
+	Interval data.ReportInterval
+}
+
+// ReportsRepo implements ReportsDataService using MongoDB aggregation pipelines.
+type ReportsRepo struct {
+	collection *mongo.Collection
+	logger     logger.Logger
+}
+
+// NewReportsRepo creates a new ReportsRepo reading the orders collection.
+func NewReportsRepo(lgr logger.Logger, db mongodb.MongoDatabase) (*ReportsRepo, error) {
+	if lgr == nil || db == nil {
+		return nil, errors.New("missing required inputs to create ReportsRepo")
+	}
+	return &ReportsRepo{collection: db.Collection(OrdersCollection), logger: lgr}, nil
+}
+
+// orderCurrency is the currency of an order, orders stored before currencies were tracked have none.
+var orderCurrency = bson.D{{Key: "$ifNull", Value: bson.A{"$currency", data.DefaultCurrency}}}
+
+// Revenue sums the order totals per time bucket and currency, buckets are ordered by start.
+func (r *ReportsRepo) Revenue(ctx context.Context, q ReportQuery) ([]data.RevenueBucket, error) {
+	trunc := bson.D{
+		{Key: "date", Value: "$createdAt"},
+		{Key: "unit", Value: q.Interval},
+		{Key: "timezone", Value: q.Location.String()},
+	}
+	if q.Interval == data.IntervalWeek {
+		trunc = append(trunc, bson.E{Key: "startOfWeek", Value: "monday"})
+	}
+	pipeline := mongo.Pipeline{
+		q.match(true),
+		{{Key: "$group", Value: bson.D{
+			{Key: "_id", Value: bson.D{
+				{Key: "start", Value: bson.D{{Key: "$dateTrunc", Value: trunc}}},
+				{Key: "currency", Value: orderCurrency},
+			}},
+			{Key: "orders", Value: bson.D{{Key: "$sum", Value: 1}}},
+			{Key: "revenue", Value: bson.D{{Key: "$sum", Value: "$totalAmount"}}},
+		}}},
+		project("start", "currency"),
+		{{Key: "$sort", Value: bson.D{{Key: "start", Value: 1}, {Key: "currency", Value: 1}}}},
+	}
+	buckets := []data.RevenueBucket{}
+	if err := r.aggregate(ctx, "revenue", pipeline, &buckets); err != nil {
+		return nil, err
+	}
+	for i := range buckets {
+		buckets[i].Start = buckets[i].Start.In(q.Location)
+	}
+	return buckets, nil
+}
+
+// OrdersByStatus counts the orders per status, including cancelled ones.
+func (r *ReportsRepo) OrdersByStatus(ctx context.Context, q ReportQuery) ([]data.StatusCount, error) {
+	pipeline := mongo.Pipeline{
+		q.match(false),
+		{{Key: "$group", Value: bson.D{
+			{Key: "_id", Value: bson.D{{Key: "status", Value: "$status"}}},
+			{Key: "orders", Value: bson.D{{Key: "$sum", Value: 1}}},
+		}}},
+		project("status"),
+		{{Key: "$sort", Value: bson.D{{Key: "status", Value: 1}}}},
+	}
+	counts := []data.StatusCount{}
+	if err := r.aggregate(ctx, "orders by status", pipeline, &counts); err != nil {
+		return nil, err
+	}
+	return counts, nil
+}
+
+// BasketSize averages the order totals and item counts per currency.
+func (r *ReportsRepo) BasketSize(ctx context.Context, q ReportQuery) ([]data.BasketStats, error) {
+	pipeline := mongo.Pipeline{
+		q.match(true),
+		{{Key: "$group", Value: bson.D{
+			{Key: "_id", Value: bson.D{{Key: "currency", Value: orderCurrency}}},
+			{Key: "orders", Value: bson.D{{Key: "$sum", Value: 1}}},
+			{Key: "averageTotal", Value: bson.D{{Key: "$avg", Value: "$totalAmount"}}},
+			{Key: "averageItems", Value: bson.D{{Key: "$avg", Value: bson.D{{Key: "$sum", Value: "$products.quantity"}}}}},
+		}}},
+		project("currency"),
+		{{Key: "$sort", Value: bson.D{{Key: "currency", Value: 1}}}},
+	}
+	stats := []data.BasketStats{}
+	if err := r.aggregate(ctx, "basket size", pipeline, &stats); err != nil {
+		return nil, err
+	}
+	for i := range stats {
+		stats[i].AverageTotal = stats[i].AverageTotal.Round(stats[i].Currency)
+	}
+	return stats, nil
+}
+
+// TopProducts returns the best-selling products by quantity, line revenue is net of line discounts.
+func (r *ReportsRepo) TopProducts(ctx context.Context, q ReportQuery, limit int64) ([]data.ProductSales, error) {
+	lineRevenue := bson.D{{Key: "$subtract", Value: bson.A{
+		bson.D{{Key: "$multiply", Value: bson.A{"$products.price", "$products.quantity"}}},
+		bson.D{{Key: "$ifNull", Value: bson.A{"$products.discount", 0}}},
+	}}}
+	pipeline := mongo.Pipeline{
+		q.match(true),
+		{{Key: "$unwind", Value: "$products"}},
+		{{Key: "$group", Value: bson.D{
+			{Key: "_id", Value: bson.D{
+				{Key: "product", Value: bson.D{{Key: "$ifNull", Value: bson.A{"$products.sku", "$products.name"}}}},
+				{Key: "currency", Value: orderCurrency},
+			}},
+			{Key: "sku", Value: bson.D{{Key: "$first", Value: "$products.sku"}}},
+			{Key: "name", Value: bson.D{{Key: "$last", Value: "$products.name"}}},
+			{Key: "quantity", Value: bson.D{{Key: "$sum", Value: "$products.quantity"}}},
+			{Key: "revenue", Value: bson.D{{Key: "$sum", Value: lineRevenue}}},
+		}}},
+		{{Key: "$sort", Value: bson.D{{Key: "quantity", Value: -1}, {Key: "revenue", Value: -1}}}},
+		{{Key: "$limit", Value: limit}},
+		project("currency"),
+	}
+	sales := []data.ProductSales{}
+	if err := r.aggregate(ctx, "top products", pipeline, &sales); err != nil {
+		return nil, err
+	}
+	return sales, nil
+}
+
+// match selects the orders of the report, optionally leaving out cancelled ones.
+func (q ReportQuery) match(excludeCancelled bool) bson.D {
+	filter := bson.D{
+		{Key: "createdAt", Value: bson.D{{Key: "$gte", Value: q.From}, {Key: "$lt", Value: q.To}}},
+		notDeleted,
+	}
+	if excludeCancelled {
+		filter = append(filter, bson.E{Key: "status", Value: bson.D{{Key: "$ne", Value: data.OrderCancelled}}})
+	}
+	return bson.D{{Key: "$match", Value: filter}}
+}
+
+// project copies the named group keys out of _id so the rows decode into flat report models.
+func project(keys ...string) bson.D {
+	fields := bson.D{}
+	for _, k := range keys {
+		fields = append(fields, bson.E{Key: k, Value: "$_id." + k})
+	}
+	return bson.D{{Key: "$addFields", Value: fields}}
+}
+
+func (r *ReportsRepo) aggregate(
+	ctx context.Context,
+	report string,
+	pipeline mongo.Pipeline,
+	results interface{},
+) error {
+	if err := validateCollection(r.collection); err != nil {
+		return err
+	}
+	cursor, err := r.collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		r.logger.Error().Err(err).Str("report", report).Msg("failed to aggregate report")
+		return ErrUnexpectedReport
+	}
+	if err = cursor.All(ctx, results); err != nil {
+		r.logger.Error().Err(err).Str("report", report).Msg("failed to decode report")
+		return ErrUnexpectedReport
+	}
+	return nil
+}
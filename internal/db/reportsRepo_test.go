@@ -0,0 +1,191 @@
+package db_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/rameshsunkara/go-rest-api-example/internal/db"
+	"github.com/rameshsunkara/go-rest-api-example/internal/db/mocks"
+	"github.com/rameshsunkara/go-rest-api-example/internal/models/data"
+	"github.com/rameshsunkara/go-rest-api-example/pkg/money"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+)
+
+func reportQuery(t *testing.T, interval data.ReportInterval) db.ReportQuery {
+	t.Helper()
+	loc, err := time.LoadLocation("America/New_York")
+	require.NoError(t, err)
+	from := time.Date(2024, 3, 1, 0, 0, 0, 0, loc)
+	return db.ReportQuery{From: from, To: from.AddDate(0, 1, 0), Location: loc, Interval: interval}
+}
+
+func TestNewReportsRepo(t *testing.T) {
+	t.Parallel()
+	_, err := db.NewReportsRepo(nil, &mocks.MockMongoDataBase{})
+	require.Error(t, err)
+	_, err = db.NewReportsRepo(testLgr, nil)
+	require.Error(t, err)
+	repo, err := db.NewReportsRepo(testLgr, &mocks.MockMongoDataBase{})
+	require.NoError(t, err)
+
+	// the mock database hands out nil collections
+	_, err = repo.Revenue(context.Background(), reportQuery(t, data.IntervalDay))
+	require.ErrorIs(t, err, db.ErrInvalidInitialization)
+}
+
+func TestReportsRepoRevenue(t *testing.T) {
+	t.Parallel()
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	ns := "ordersdb.purchaseOrders"
+	q := reportQuery(t, data.IntervalWeek)
+	start := time.Date(2024, 3, 4, 5, 0, 0, 0, time.UTC) // Monday midnight in New York
+
+	mt.Run("Success", func(mt *mtest.T) {
+		mt.AddMockResponses(
+			mtest.CreateCursorResponse(1, ns, mtest.FirstBatch, bson.D{
+				{Key: "start", Value: primitive.NewDateTimeFromTime(start)},
+				{Key: "currency", Value: "USD"},
+				{Key: "orders", Value: int32(3)},
+				{Key: "revenue", Value: money.MustParse("59.97")},
+			}),
+			mtest.CreateCursorResponse(0, ns, mtest.NextBatch),
+		)
+		repo, err := db.NewReportsRepo(testLgr, mt.DB)
+		require.NoError(t, err)
+		buckets, err := repo.Revenue(context.TODO(), q)
+		require.NoError(t, err)
+		require.Len(t, buckets, 1)
+		assert.Equal(t, money.USD, buckets[0].Currency)
+		assert.Equal(t, int64(3), buckets[0].Orders)
+		assert.Equal(t, money.MustParse("59.97"), buckets[0].Revenue)
+		assert.True(t, start.Equal(buckets[0].Start))
+		assert.Equal(t, q.Location, buckets[0].Start.Location())
+
+		pipeline := mt.GetStartedEvent().Command.Lookup("pipeline")
+		assert.Equal(t, string(data.OrderCancelled),
+			pipeline.Array().Index(0).Value().Document().Lookup("$match", "status", "$ne").StringValue())
+		trunc := pipeline.Array().Index(1).Value().Document().Lookup("$group", "_id", "start", "$dateTrunc").Document()
+		assert.Equal(t, "week", trunc.Lookup("unit").StringValue())
+		assert.Equal(t, "America/New_York", trunc.Lookup("timezone").StringValue())
+		assert.Equal(t, "monday", trunc.Lookup("startOfWeek").StringValue())
+	})
+
+	mt.Run("AggregateError", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateCommandErrorResponse(mtest.CommandError{Code: 1, Message: "boom"}))
+		repo, err := db.NewReportsRepo(testLgr, mt.DB)
+		require.NoError(t, err)
+		_, err = repo.Revenue(context.TODO(), q)
+		assert.Equal(t, db.ErrUnexpectedReport, err)
+	})
+}
+
+func TestReportsRepoOrdersByStatus(t *testing.T) {
+	t.Parallel()
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	ns := "ordersdb.purchaseOrders"
+
+	mt.Run("IncludesCancelled", func(mt *mtest.T) {
+		mt.AddMockResponses(
+			mtest.CreateCursorResponse(1, ns, mtest.FirstBatch,
+				bson.D{{Key: "status", Value: string(data.OrderCancelled)}, {Key: "orders", Value: int32(1)}},
+				bson.D{{Key: "status", Value: string(data.OrderPending)}, {Key: "orders", Value: int32(4)}},
+			),
+			mtest.CreateCursorResponse(0, ns, mtest.NextBatch),
+		)
+		repo, err := db.NewReportsRepo(testLgr, mt.DB)
+		require.NoError(t, err)
+		counts, err := repo.OrdersByStatus(context.TODO(), reportQuery(t, ""))
+		require.NoError(t, err)
+		assert.Equal(t, []data.StatusCount{
+			{Status: data.OrderCancelled, Orders: 1},
+			{Status: data.OrderPending, Orders: 4},
+		}, counts)
+		match := mt.GetStartedEvent().Command.Lookup("pipeline", "0", "$match").Document()
+		_, err = match.LookupErr("status")
+		require.Error(t, err)
+	})
+
+	mt.Run("Empty", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, ns, mtest.FirstBatch))
+		repo, err := db.NewReportsRepo(testLgr, mt.DB)
+		require.NoError(t, err)
+		counts, err := repo.OrdersByStatus(context.TODO(), reportQuery(t, ""))
+		require.NoError(t, err)
+		assert.NotNil(t, counts)
+		assert.Empty(t, counts)
+	})
+}
+
+func TestReportsRepoBasketSize(t *testing.T) {
+	t.Parallel()
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	ns := "ordersdb.purchaseOrders"
+
+	mt.Run("RoundsToCurrency", func(mt *mtest.T) {
+		mt.AddMockResponses(
+			mtest.CreateCursorResponse(1, ns, mtest.FirstBatch, bson.D{
+				{Key: "currency", Value: "USD"},
+				{Key: "orders", Value: int32(3)},
+				{Key: "averageTotal", Value: money.MustParse("10.3333")},
+				{Key: "averageItems", Value: 2.5},
+			}),
+			mtest.CreateCursorResponse(0, ns, mtest.NextBatch),
+		)
+		repo, err := db.NewReportsRepo(testLgr, mt.DB)
+		require.NoError(t, err)
+		stats, err := repo.BasketSize(context.TODO(), reportQuery(t, ""))
+		require.NoError(t, err)
+		require.Len(t, stats, 1)
+		assert.Equal(t, money.MustParse("10.33"), stats[0].AverageTotal)
+		assert.InDelta(t, 2.5, stats[0].AverageItems, 0.001)
+	})
+
+	mt.Run("AggregateError", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateCommandErrorResponse(mtest.CommandError{Code: 1, Message: "boom"}))
+		repo, err := db.NewReportsRepo(testLgr, mt.DB)
+		require.NoError(t, err)
+		_, err = repo.BasketSize(context.TODO(), reportQuery(t, ""))
+		assert.Equal(t, db.ErrUnexpectedReport, err)
+	})
+}
+
+func TestReportsRepoTopProducts(t *testing.T) {
+	t.Parallel()
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	ns := "ordersdb.purchaseOrders"
+
+	mt.Run("Success", func(mt *mtest.T) {
+		mt.AddMockResponses(
+			mtest.CreateCursorResponse(1, ns, mtest.FirstBatch, bson.D{
+				{Key: "sku", Value: "P-1"},
+				{Key: "name", Value: "Product 1"},
+				{Key: "currency", Value: "USD"},
+				{Key: "quantity", Value: int64(7)},
+				{Key: "revenue", Value: money.MustParse("65")},
+			}),
+			mtest.CreateCursorResponse(0, ns, mtest.NextBatch),
+		)
+		repo, err := db.NewReportsRepo(testLgr, mt.DB)
+		require.NoError(t, err)
+		sales, err := repo.TopProducts(context.TODO(), reportQuery(t, ""), 5)
+		require.NoError(t, err)
+		assert.Equal(t, []data.ProductSales{{
+			SKU: "P-1", Name: "Product 1", Currency: money.USD, Quantity: 7, Revenue: money.MustParse("65"),
+		}}, sales)
+		limit := mt.GetStartedEvent().Command.Lookup("pipeline", "4", "$limit")
+		assert.Equal(t, int64(5), limit.Int64())
+	})
+
+	mt.Run("AggregateError", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateCommandErrorResponse(mtest.CommandError{Code: 1, Message: "boom"}))
+		repo, err := db.NewReportsRepo(testLgr, mt.DB)
+		require.NoError(t, err)
+		_, err = repo.TopProducts(context.TODO(), reportQuery(t, ""), 5)
+		assert.Equal(t, db.ErrUnexpectedReport, err)
+	})
+}
@@ -0,0 +1,352 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/rameshsunkara/go-rest-api-example/internal/models/data"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var errMissingFactory = errors.New("missing repository factory")
+
+// TenantOrdersRepo implements OrdersDataService by routing every call to the repository of the tenant in ctx.
+type TenantOrdersRepo struct {
+	repos *RepoFactory[OrdersDataService]
+}
+
+// NewTenantOrdersRepo creates a new TenantOrdersRepo.
+func NewTenantOrdersRepo(repos *RepoFactory[OrdersDataService]) (*TenantOrdersRepo, error) {
+	if repos == nil {
+		return nil, errMissingFactory
+	}
+	return &TenantOrdersRepo{repos: repos}, nil
+}
+
+func (r *TenantOrdersRepo) Create(ctx context.Context, purchaseOrder *data.Order) (string, error) {
+	repo, err := r.repos.For(ctx)
+	if err != nil {
+		return "", err
+	}
+	return repo.Create(ctx, purchaseOrder)
+}
+
+func (r *TenantOrdersRepo) Update(ctx context.Context, purchaseOrder *data.Order) error {
+	repo, err := r.repos.For(ctx)
+	if err != nil {
+		return err
+	}
+	return repo.Update(ctx, purchaseOrder)
+}
+
+func (r *TenantOrdersRepo) GetAll(ctx context.Context, limit int64, opts ReadOptions) (*[]data.Order, error) {
+	repo, err := r.repos.For(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return repo.GetAll(ctx, limit, opts)
+}
+
+func (r *TenantOrdersRepo) GetByID(ctx context.Context, id primitive.ObjectID, opts ReadOptions) (*data.Order, error) {
+	repo, err := r.repos.For(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return repo.GetByID(ctx, id, opts)
+}
+
+func (r *TenantOrdersRepo) DeleteByID(ctx context.Context, id primitive.ObjectID) error {
+	repo, err := r.repos.For(ctx)
+	if err != nil {
+		return err
+	}
+	return repo.DeleteByID(ctx, id)
+}
+
+func (r *TenantOrdersRepo) Restore(ctx context.Context, id primitive.ObjectID) error {
+	repo, err := r.repos.For(ctx)
+	if err != nil {
+		return err
+	}
+	return repo.Restore(ctx, id)
+}
+
+func (r *TenantOrdersRepo) PurgeDeleted(ctx context.Context, deletedBefore time.Time) (int64, error) {
+	repo, err := r.repos.For(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return repo.PurgeDeleted(ctx, deletedBefore)
+}
+
+func (r *TenantOrdersRepo) UpsertMany(ctx context.Context, orders []data.Order) (*UpsertResult, error) {
+	repo, err := r.repos.For(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return repo.UpsertMany(ctx, orders)
+}
+
+func (r *TenantOrdersRepo) Transition(
+	ctx context.Context, id primitive.ObjectID, to data.OrderStatus, note data.OrderUpdate, shipment *data.Shipment,
+) (*data.Order, error) {
+	repo, err := r.repos.For(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return repo.Transition(ctx, id, to, note, shipment)
+}
+
+func (r *TenantOrdersRepo) GetPendingBefore(
+	ctx context.Context, createdBefore time.Time, limit int64,
+) (*[]data.Order, error) {
+	repo, err := r.repos.For(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return repo.GetPendingBefore(ctx, createdBefore, limit)
+}
+
+func (r *TenantOrdersRepo) GetByUser(ctx context.Context, user string, limit int64) (*[]data.Order, error) {
+	repo, err := r.repos.For(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return repo.GetByUser(ctx, user, limit)
+}
+
+func (r *TenantOrdersRepo) SummarizeUser(ctx context.Context, user string) (*data.CustomerSummary, error) {
+	repo, err := r.repos.For(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return repo.SummarizeUser(ctx, user)
+}
+
+// TenantAuditRepo implements AuditDataService by routing every call to the repository of the tenant in ctx.
+type TenantAuditRepo struct {
+	repos *RepoFactory[AuditDataService]
+}
+
+// NewTenantAuditRepo creates a new TenantAuditRepo.
+func NewTenantAuditRepo(repos *RepoFactory[AuditDataService]) (*TenantAuditRepo, error) {
+	if repos == nil {
+		return nil, errMissingFactory
+	}
+	return &TenantAuditRepo{repos: repos}, nil
+}
+
+func (r *TenantAuditRepo) Append(ctx context.Context, entries ...data.AuditEntry) error {
+	repo, err := r.repos.For(ctx)
+	if err != nil {
+		return err
+	}
+	return repo.Append(ctx, entries...)
+}
+
+func (r *TenantAuditRepo) Search(ctx context.Context, filter AuditFilter, limit int64) (*[]data.AuditEntry, error) {
+	repo, err := r.repos.For(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return repo.Search(ctx, filter, limit)
+}
+
+// TenantReportsRepo implements ReportsDataService by routing every call to the repository of the tenant in ctx.
+type TenantReportsRepo struct {
+	repos *RepoFactory[ReportsDataService]
+}
+
+// NewTenantReportsRepo creates a new TenantReportsRepo.
+func NewTenantReportsRepo(repos *RepoFactory[ReportsDataService]) (*TenantReportsRepo, error) {
+	if repos == nil {
+		return nil, errMissingFactory
+	}
+	return &TenantReportsRepo{repos: repos}, nil
+}
+
+func (r *TenantReportsRepo) Revenue(ctx context.Context, q ReportQuery) ([]data.RevenueBucket, error) {
+	repo, err := r.repos.For(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return repo.Revenue(ctx, q)
+}
+
+func (r *TenantReportsRepo) OrdersByStatus(ctx context.Context, q ReportQuery) ([]data.StatusCount, error) {
+	repo, err := r.repos.For(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return repo.OrdersByStatus(ctx, q)
+}
+
+func (r *TenantReportsRepo) BasketSize(ctx context.Context, q ReportQuery) ([]data.BasketStats, error) {
+	repo, err := r.repos.For(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return repo.BasketSize(ctx, q)
+}
+
+func (r *TenantReportsRepo) TopProducts(ctx context.Context, q ReportQuery, limit int64) ([]data.ProductSales, error) {
+	repo, err := r.repos.For(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return repo.TopProducts(ctx, q, limit)
+}
+
+// TenantCouponsRepo implements CouponsDataService by routing every call to the repository of the tenant in ctx.
+type TenantCouponsRepo struct {
+	repos *RepoFactory[CouponsDataService]
+}
+
+// NewTenantCouponsRepo creates a new TenantCouponsRepo.
+func NewTenantCouponsRepo(repos *RepoFactory[CouponsDataService]) (*TenantCouponsRepo, error) {
+	if repos == nil {
+		return nil, errMissingFactory
+	}
+	return &TenantCouponsRepo{repos: repos}, nil
+}
+
+func (r *TenantCouponsRepo) Create(ctx context.Context, coupon *data.Coupon) (string, error) {
+	repo, err := r.repos.For(ctx)
+	if err != nil {
+		return "", err
+	}
+	return repo.Create(ctx, coupon)
+}
+
+func (r *TenantCouponsRepo) GetByCode(ctx context.Context, code string) (*data.Coupon, error) {
+	repo, err := r.repos.For(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return repo.GetByCode(ctx, code)
+}
+
+func (r *TenantCouponsRepo) Redeem(ctx context.Context, code string, at time.Time) error {
+	repo, err := r.repos.For(ctx)
+	if err != nil {
+		return err
+	}
+	return repo.Redeem(ctx, code, at)
+}
+
+func (r *TenantCouponsRepo) Release(ctx context.Context, code string) error {
+	repo, err := r.repos.For(ctx)
+	if err != nil {
+		return err
+	}
+	return repo.Release(ctx, code)
+}
+
+// TenantProductsRepo implements ProductsDataService by routing every call to the repository of the tenant in ctx.
+type TenantProductsRepo struct {
+	repos *RepoFactory[ProductsDataService]
+}
+
+// NewTenantProductsRepo creates a new TenantProductsRepo.
+func NewTenantProductsRepo(repos *RepoFactory[ProductsDataService]) (*TenantProductsRepo, error) {
+	if repos == nil {
+		return nil, errMissingFactory
+	}
+	return &TenantProductsRepo{repos: repos}, nil
+}
+
+func (r *TenantProductsRepo) Create(ctx context.Context, product *data.CatalogProduct) (string, error) {
+	repo, err := r.repos.For(ctx)
+	if err != nil {
+		return "", err
+	}
+	return repo.Create(ctx, product)
+}
+
+func (r *TenantProductsRepo) Update(ctx context.Context, product *data.CatalogProduct) error {
+	repo, err := r.repos.For(ctx)
+	if err != nil {
+		return err
+	}
+	return repo.Update(ctx, product)
+}
+
+func (r *TenantProductsRepo) GetAll(
+	ctx context.Context, limit int64, includeInactive bool,
+) (*[]data.CatalogProduct, error) {
+	repo, err := r.repos.For(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return repo.GetAll(ctx, limit, includeInactive)
+}
+
+func (r *TenantProductsRepo) GetBySKU(ctx context.Context, sku string) (*data.CatalogProduct, error) {
+	repo, err := r.repos.For(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return repo.GetBySKU(ctx, sku)
+}
+
+func (r *TenantProductsRepo) GetBySKUs(ctx context.Context, skus []string) ([]data.CatalogProduct, error) {
+	repo, err := r.repos.For(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return repo.GetBySKUs(ctx, skus)
+}
+
+func (r *TenantProductsRepo) DeleteBySKU(ctx context.Context, sku string) error {
+	repo, err := r.repos.For(ctx)
+	if err != nil {
+		return err
+	}
+	return repo.DeleteBySKU(ctx, sku)
+}
+
+// TenantInventoryRepo implements InventoryDataService by routing every call to the repository of the tenant in ctx.
+type TenantInventoryRepo struct {
+	repos *RepoFactory[InventoryDataService]
+}
+
+// NewTenantInventoryRepo creates a new TenantInventoryRepo.
+func NewTenantInventoryRepo(repos *RepoFactory[InventoryDataService]) (*TenantInventoryRepo, error) {
+	if repos == nil {
+		return nil, errMissingFactory
+	}
+	return &TenantInventoryRepo{repos: repos}, nil
+}
+
+func (r *TenantInventoryRepo) SetStock(ctx context.Context, sku string, available int64) (*data.StockLevel, error) {
+	repo, err := r.repos.For(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return repo.SetStock(ctx, sku, available)
+}
+
+func (r *TenantInventoryRepo) GetBySKU(ctx context.Context, sku string) (*data.StockLevel, error) {
+	repo, err := r.repos.For(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return repo.GetBySKU(ctx, sku)
+}
+
+func (r *TenantInventoryRepo) Reserve(ctx context.Context, products []data.Product) error {
+	repo, err := r.repos.For(ctx)
+	if err != nil {
+		return err
+	}
+	return repo.Reserve(ctx, products)
+}
+
+func (r *TenantInventoryRepo) Release(ctx context.Context, products []data.Product) error {
+	repo, err := r.repos.For(ctx)
+	if err != nil {
+		return err
+	}
+	return repo.Release(ctx, products)
+}
@@ -9,10 +9,68 @@ const (
 	OrderGetNotFound      = prefix + "get_not_found"
 	OrdersGetServerError  = prefix + "get_server_error"
 
-	OrderCreateInvalidInput = prefix + "create_invalid_input"
-	OrderCreateServerError  = prefix + "create_server_error"
+	OrderCreateInvalidInput   = prefix + "create_invalid_input"
+	OrderCreateServerError    = prefix + "create_server_error"
+	OrderCreateInvalidCoupon  = prefix + "create_invalid_coupon"
+	OrderCreateInvalidProduct = prefix + "create_invalid_product"
+	// OrderCreateInsufficientStock responses list the short items in their details
+	OrderCreateInsufficientStock = prefix + "create_insufficient_stock"
 
 	OrderDeleteInvalidID   = prefix + "delete_invalid_order_id"
 	OrderDeleteNotFound    = prefix + "delete_not_found"
 	OrderDeleteServerError = prefix + "delete_server_error"
+
+	OrderRestoreInvalidID   = prefix + "restore_invalid_order_id"
+	OrderRestoreNotFound    = prefix + "restore_not_found"
+	OrderRestoreServerError = prefix + "restore_server_error"
+	OrderActionNotSupported = prefix + "action_not_supported"
+
+	OrderTransitionInvalidInput = prefix + "transition_invalid_input"
+	OrderTransitionNotFound     = prefix + "transition_not_found"
+	OrderTransitionConflict     = prefix + "transition_conflict"
+	OrderTransitionServerError  = prefix + "transition_server_error"
+
+	UserOrdersInvalidParams = prefix + "user_orders_invalid_params"
+	UserOrdersServerError   = prefix + "user_orders_server_error"
+	UserSummaryServerError  = prefix + "user_summary_server_error"
+
+	ReportInvalidParams = prefix + "report_invalid_params"
+	ReportServerError   = prefix + "report_server_error"
+
+	AuditGetInvalidParams = prefix + "audit_invalid_params"
+	AuditGetServerError   = prefix + "audit_server_error"
+
+	OrderImportInvalidInput = prefix + "import_invalid_input"
+	OrderImportServerError  = prefix + "import_server_error"
+
+	CouponCreateInvalidInput = prefix + "coupon_create_invalid_input"
+	CouponCreateConflict     = prefix + "coupon_create_conflict"
+	CouponCreateServerError  = prefix + "coupon_create_server_error"
+	CouponGetNotFound        = prefix + "coupon_get_not_found"
+	CouponGetServerError     = prefix + "coupon_get_server_error"
+
+	ProductGetInvalidParams   = prefix + "product_get_invalid_params"
+	ProductGetNotFound        = prefix + "product_get_not_found"
+	ProductsGetServerError    = prefix + "product_get_server_error"
+	ProductCreateInvalidInput = prefix + "product_create_invalid_input"
+	ProductCreateConflict     = prefix + "product_create_conflict"
+	ProductCreateServerError  = prefix + "product_create_server_error"
+	ProductUpdateInvalidInput = prefix + "product_update_invalid_input"
+	ProductUpdateNotFound     = prefix + "product_update_not_found"
+	ProductUpdateServerError  = prefix + "product_update_server_error"
+	ProductDeleteNotFound     = prefix + "product_delete_not_found"
+	ProductDeleteServerError  = prefix + "product_delete_server_error"
+
+	InventoryGetNotFound        = prefix + "inventory_get_not_found"
+	InventoryGetServerError     = prefix + "inventory_get_server_error"
+	InventoryUpdateInvalidInput = prefix + "inventory_update_invalid_input"
+	InventoryUpdateServerError  = prefix + "inventory_update_server_error"
+
+	LogLevelUpdateInvalidInput = prefix + "log_level_update_invalid_input"
+
+	TenantMissing = prefix + "tenant_missing"
+	TenantUnknown = prefix + "tenant_unknown"
+
+	// RequestInvalid responses list the parts of the request that break the OpenAPI spec in their details
+	RequestInvalid = prefix + "request_invalid"
 )
@@ -0,0 +1,82 @@
+package handlers
+
+import (
+	errors2 "errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rameshsunkara/go-rest-api-example/internal/db"
+	"github.com/rameshsunkara/go-rest-api-example/internal/errors"
+	"github.com/rameshsunkara/go-rest-api-example/internal/models/data"
+	"github.com/rameshsunkara/go-rest-api-example/internal/models/external"
+	"github.com/rameshsunkara/go-rest-api-example/internal/models/mapping"
+	"github.com/rameshsunkara/go-rest-api-example/pkg/logger"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// AuditHandler serves the order audit log.
+type AuditHandler struct {
+	auditSvc db.AuditDataService
+	logger   logger.Logger
+}
+
+// NewAuditHandler creates a new AuditHandler.
+func NewAuditHandler(lgr logger.Logger, aSvc db.AuditDataService) (*AuditHandler, error) {
+	if lgr == nil || aSvc == nil {
+		return nil, errors2.New("missing required parameters to create audit handler")
+	}
+	return &AuditHandler{auditSvc: aSvc, logger: lgr}, nil
+}
+
+// GetByOrderID handles GET /orders/:id/audit.
+func (a *AuditHandler) GetByOrderID(c *gin.Context) {
+	lgr, requestID := a.logger.WithReqID(c)
+	oID, err := primitive.ObjectIDFromHex(c.Param(OrderIDPath))
+	if err != nil || oID.IsZero() {
+		abortWithAPIError(c, lgr, http.StatusBadRequest, errors.AuditGetInvalidParams, "invalid order ID", requestID, err)
+		return
+	}
+	a.search(c, db.AuditFilter{OrderID: oID})
+}
+
+// Search handles GET /audit, an internal endpoint filtering by orderId, actor, action, requestId
+// and an RFC3339 [from, to) time range.
+func (a *AuditHandler) Search(c *gin.Context) {
+	lgr, requestID := a.logger.WithReqID(c)
+	q, ok := routeQuery[external.AuditSearchQuery](c, lgr, errors.AuditGetServerError, requestID)
+	if !ok {
+		return
+	}
+	filter := db.AuditFilter{
+		Actor:     q.Actor,
+		Action:    data.AuditAction(q.Action),
+		RequestID: q.RequestID,
+		From:      q.From,
+		To:        q.To,
+	}
+	if q.OrderID != "" {
+		oID, err := primitive.ObjectIDFromHex(q.OrderID)
+		if err != nil {
+			abortWithAPIError(c, lgr, http.StatusBadRequest, errors.AuditGetInvalidParams, "invalid order ID", requestID, err)
+			return
+		}
+		filter.OrderID = oID
+	}
+	a.search(c, filter)
+}
+
+func (a *AuditHandler) search(c *gin.Context, filter db.AuditFilter) {
+	lgr, requestID := a.logger.WithReqID(c)
+	q, ok := routeQuery[external.LimitQuery](c, lgr, errors.AuditGetServerError, requestID)
+	if !ok {
+		return
+	}
+	entries, err := a.auditSvc.Search(c, filter, q.Limit)
+	if err != nil {
+		abortWithAPIError(c, lgr, http.StatusInternalServerError, errors.AuditGetServerError,
+			errors.UnexpectedErrorMessage, requestID, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, mapping.AuditEntries(*entries))
+}
@@ -0,0 +1,121 @@
+package handlers_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/rameshsunkara/go-rest-api-example/internal/db"
+	"github.com/rameshsunkara/go-rest-api-example/internal/db/mocks"
+	errors2 "github.com/rameshsunkara/go-rest-api-example/internal/errors"
+	"github.com/rameshsunkara/go-rest-api-example/internal/handlers"
+	"github.com/rameshsunkara/go-rest-api-example/internal/models/data"
+	"github.com/rameshsunkara/go-rest-api-example/internal/models/external"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestNewAuditHandler(t *testing.T) {
+	t.Parallel()
+	_, err := handlers.NewAuditHandler(nil, &mocks.MockAuditDataService{})
+	require.Error(t, err)
+	_, err = handlers.NewAuditHandler(lgr, nil)
+	require.Error(t, err)
+	h, err := handlers.NewAuditHandler(lgr, &mocks.MockAuditDataService{})
+	require.NoError(t, err)
+	assert.NotNil(t, h)
+}
+
+func TestAuditHandler(t *testing.T) {
+	t.Parallel()
+	orderID := primitive.NewObjectID()
+	tests := []struct {
+		name          string
+		url           string
+		searchErr     error
+		expectedCode  int
+		expectedError string
+		wantFilter    db.AuditFilter
+	}{
+		{
+			name:         "order audit trail",
+			url:          "/orders/" + orderID.Hex() + "/audit?limit=5",
+			expectedCode: http.StatusOK,
+			wantFilter:   db.AuditFilter{OrderID: orderID},
+		},
+		{
+			name:          "order audit invalid id",
+			url:           "/orders/xyz/audit",
+			expectedCode:  http.StatusBadRequest,
+			expectedError: errors2.AuditGetInvalidParams,
+		},
+		{
+			name:         "search",
+			url:          "/audit?actor=agent&action=delete&requestId=r1&from=2024-01-01T00:00:00Z",
+			expectedCode: http.StatusOK,
+			wantFilter: db.AuditFilter{
+				Actor:     "agent",
+				Action:    data.AuditDelete,
+				RequestID: "r1",
+				From:      time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			name:          "search invalid time",
+			url:           "/audit?to=yesterday",
+			expectedCode:  http.StatusBadRequest,
+			expectedError: errors2.RequestInvalid,
+		},
+		{
+			name:          "search failure",
+			url:           "/audit",
+			searchErr:     db.ErrUnexpectedGetAudit,
+			expectedCode:  http.StatusInternalServerError,
+			expectedError: errors2.AuditGetServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			var gotFilter db.AuditFilter
+			handler, err := handlers.NewAuditHandler(lgr, &mocks.MockAuditDataService{
+				SearchFunc: func(_ context.Context, filter db.AuditFilter, _ int64) (*[]data.AuditEntry, error) {
+					gotFilter = filter
+					if tt.searchErr != nil {
+						return nil, tt.searchErr
+					}
+					return &[]data.AuditEntry{{
+						ID:        primitive.NewObjectID(),
+						Action:    data.AuditCreate,
+						OrderID:   orderID,
+						Timestamp: time.Now(),
+					}}, nil
+				},
+			})
+			require.NoError(t, err)
+
+			c, r, recorder := setupTestContext()
+			r.GET("/orders/:id/audit", parseQuery(external.LimitQuery{}), handler.GetByOrderID)
+			r.GET("/audit", parseQuery(external.AuditSearchQuery{}), handler.Search)
+			c.Request, _ = http.NewRequest(http.MethodGet, tt.url, nil)
+			r.ServeHTTP(recorder, c.Request)
+
+			assert.Equal(t, tt.expectedCode, recorder.Code)
+			if tt.expectedError != "" {
+				var apiErr external.APIError
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &apiErr))
+				assert.Equal(t, tt.expectedError, apiErr.ErrorCode)
+				return
+			}
+			assert.Equal(t, tt.wantFilter, gotFilter)
+			var entries []external.AuditEntry
+			require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &entries))
+			require.Len(t, entries, 1)
+			assert.Equal(t, orderID.Hex(), entries[0].OrderID)
+		})
+	}
+}
@@ -0,0 +1,102 @@
+package handlers
+
+import (
+	errors2 "errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rameshsunkara/go-rest-api-example/internal/db"
+	"github.com/rameshsunkara/go-rest-api-example/internal/errors"
+	"github.com/rameshsunkara/go-rest-api-example/internal/models/data"
+	"github.com/rameshsunkara/go-rest-api-example/internal/models/external"
+	"github.com/rameshsunkara/go-rest-api-example/internal/models/mapping"
+	"github.com/rameshsunkara/go-rest-api-example/pkg/logger"
+	"github.com/rameshsunkara/go-rest-api-example/pkg/money"
+)
+
+const (
+	CouponCodePath = "code"
+)
+
+// CouponsHandler handles coupon administration requests.
+type CouponsHandler struct {
+	cDataSvc db.CouponsDataService
+	logger   logger.Logger
+}
+
+// NewCouponsHandler creates a new CouponsHandler.
+func NewCouponsHandler(lgr logger.Logger, cSvc db.CouponsDataService) (*CouponsHandler, error) {
+	if lgr == nil || cSvc == nil {
+		return nil, errors2.New("missing required parameters to create coupons handler")
+	}
+	return &CouponsHandler{cDataSvc: cSvc, logger: lgr}, nil
+}
+
+// Create handles POST /coupons.
+func (h *CouponsHandler) Create(c *gin.Context) {
+	lgr, requestID := h.logger.WithReqID(c)
+	var in external.CouponInput
+	if err := c.ShouldBindJSON(&in); err != nil {
+		abortWithAPIError(c, lgr, http.StatusBadRequest, errors.CouponCreateInvalidInput,
+			"Invalid coupon request body", requestID, err)
+		return
+	}
+
+	coupon := data.Coupon{
+		Code:       data.NormalizeCouponCode(in.Code),
+		Kind:       in.Kind,
+		Value:      in.Value,
+		Products:   in.Products,
+		ValidFrom:  in.ValidFrom,
+		ValidUntil: in.ValidUntil,
+		MaxUses:    in.MaxUses,
+		CreatedAt:  time.Now(),
+	}
+	switch in.Kind {
+	case data.DiscountPercent:
+		if in.Value > money.MustParse("100") {
+			abortWithAPIError(c, lgr, http.StatusBadRequest, errors.CouponCreateInvalidInput,
+				"Percent coupons cannot exceed 100", requestID, nil)
+			return
+		}
+	case data.DiscountFixed:
+		currency, err := money.ParseCurrency(in.Currency)
+		if err != nil || !in.Value.FitsCurrency(currency) {
+			abortWithAPIError(c, lgr, http.StatusBadRequest, errors.CouponCreateInvalidInput,
+				"Fixed coupons need a supported currency and a value in its minor units", requestID, err)
+			return
+		}
+		coupon.Currency = currency
+	}
+
+	id, err := h.cDataSvc.Create(c, &coupon)
+	if err != nil {
+		if errors2.Is(err, db.ErrCouponExists) {
+			abortWithAPIError(c, lgr, http.StatusConflict, errors.CouponCreateConflict,
+				"Coupon code already exists", requestID, err)
+			return
+		}
+		abortWithAPIError(c, lgr, http.StatusInternalServerError, errors.CouponCreateServerError,
+			errors.UnexpectedErrorMessage, requestID, err)
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"id": id, "code": coupon.Code})
+}
+
+// GetByCode handles GET /coupons/:code.
+func (h *CouponsHandler) GetByCode(c *gin.Context) {
+	lgr, requestID := h.logger.WithReqID(c)
+	coupon, err := h.cDataSvc.GetByCode(c, c.Param(CouponCodePath))
+	if err != nil {
+		if errors2.Is(err, db.ErrCouponNotFound) {
+			abortWithAPIError(c, lgr, http.StatusNotFound, errors.CouponGetNotFound,
+				"coupon not found", requestID, err)
+			return
+		}
+		abortWithAPIError(c, lgr, http.StatusInternalServerError, errors.CouponGetServerError,
+			errors.UnexpectedErrorMessage, requestID, err)
+		return
+	}
+	c.JSON(http.StatusOK, mapping.Coupon(coupon))
+}
@@ -0,0 +1,198 @@
+package handlers_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/rameshsunkara/go-rest-api-example/internal/db"
+	"github.com/rameshsunkara/go-rest-api-example/internal/db/mocks"
+	errors2 "github.com/rameshsunkara/go-rest-api-example/internal/errors"
+	"github.com/rameshsunkara/go-rest-api-example/internal/handlers"
+	"github.com/rameshsunkara/go-rest-api-example/internal/models/data"
+	"github.com/rameshsunkara/go-rest-api-example/internal/models/external"
+	"github.com/rameshsunkara/go-rest-api-example/pkg/money"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewCouponsHandler(t *testing.T) {
+	t.Parallel()
+	_, err := handlers.NewCouponsHandler(nil, &mocks.MockCouponsDataService{})
+	require.Error(t, err)
+	_, err = handlers.NewCouponsHandler(lgr, nil)
+	require.Error(t, err)
+	h, err := handlers.NewCouponsHandler(lgr, &mocks.MockCouponsDataService{})
+	require.NoError(t, err)
+	assert.NotNil(t, h)
+}
+
+func TestCouponsHandler_Create(t *testing.T) {
+	t.Parallel()
+	from := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	valid := external.CouponInput{
+		Code:       "save10",
+		Kind:       data.DiscountPercent,
+		Value:      money.MustParse("10"),
+		ValidFrom:  from,
+		ValidUntil: from.Add(24 * time.Hour),
+		MaxUses:    100,
+	}
+	with := func(mutate func(in *external.CouponInput)) external.CouponInput {
+		in := valid
+		mutate(&in)
+		return in
+	}
+
+	tests := []struct {
+		name          string
+		input         external.CouponInput
+		createErr     error
+		expectedCode  int
+		expectedError string
+		wantCurrency  money.Currency
+	}{
+		{name: "percent coupon", input: valid, expectedCode: http.StatusCreated},
+		{
+			name: "fixed coupon",
+			input: with(func(in *external.CouponInput) {
+				in.Kind, in.Value, in.Currency = data.DiscountFixed, money.MustParse("5.50"), "eur"
+			}),
+			expectedCode: http.StatusCreated,
+			wantCurrency: money.EUR,
+		},
+		{
+			name:          "window ends before it starts",
+			input:         with(func(in *external.CouponInput) { in.ValidUntil = from.Add(-time.Hour) }),
+			expectedCode:  http.StatusBadRequest,
+			expectedError: errors2.CouponCreateInvalidInput,
+		},
+		{
+			name:          "unknown kind",
+			input:         with(func(in *external.CouponInput) { in.Kind = "bogo" }),
+			expectedCode:  http.StatusBadRequest,
+			expectedError: errors2.CouponCreateInvalidInput,
+		},
+		{
+			name:          "percent above 100",
+			input:         with(func(in *external.CouponInput) { in.Value = money.MustParse("150") }),
+			expectedCode:  http.StatusBadRequest,
+			expectedError: errors2.CouponCreateInvalidInput,
+		},
+		{
+			name: "fixed coupon without currency",
+			input: with(func(in *external.CouponInput) {
+				in.Kind = data.DiscountFixed
+			}),
+			expectedCode:  http.StatusBadRequest,
+			expectedError: errors2.CouponCreateInvalidInput,
+		},
+		{
+			name: "fixed coupon too precise for currency",
+			input: with(func(in *external.CouponInput) {
+				in.Kind, in.Value, in.Currency = data.DiscountFixed, money.MustParse("5.5"), "JPY"
+			}),
+			expectedCode:  http.StatusBadRequest,
+			expectedError: errors2.CouponCreateInvalidInput,
+		},
+		{
+			name:          "duplicate code",
+			input:         valid,
+			createErr:     db.ErrCouponExists,
+			expectedCode:  http.StatusConflict,
+			expectedError: errors2.CouponCreateConflict,
+		},
+		{
+			name:          "db failure",
+			input:         valid,
+			createErr:     db.ErrFailedToCreateCoupon,
+			expectedCode:  http.StatusInternalServerError,
+			expectedError: errors2.CouponCreateServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			var saved *data.Coupon
+			handler, err := handlers.NewCouponsHandler(lgr, &mocks.MockCouponsDataService{
+				CreateFunc: func(_ context.Context, coupon *data.Coupon) (string, error) {
+					saved = coupon
+					return "c1", tt.createErr
+				},
+			})
+			require.NoError(t, err)
+
+			c, r, recorder := setupTestContext()
+			r.POST("/coupons", handler.Create)
+			body, _ := json.Marshal(tt.input)
+			c.Request, _ = http.NewRequest(http.MethodPost, "/coupons", bytes.NewReader(body))
+			r.ServeHTTP(recorder, c.Request)
+
+			assert.Equal(t, tt.expectedCode, recorder.Code)
+			if tt.expectedError != "" {
+				var apiErr external.APIError
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &apiErr))
+				assert.Equal(t, tt.expectedError, apiErr.ErrorCode)
+				return
+			}
+			require.NotNil(t, saved)
+			assert.Equal(t, "SAVE10", saved.Code)
+			assert.Equal(t, tt.input.Value, saved.Value)
+			assert.Equal(t, tt.wantCurrency, saved.Currency)
+			assert.Equal(t, tt.input.MaxUses, saved.MaxUses)
+			assert.True(t, saved.ValidFrom.Equal(from))
+		})
+	}
+}
+
+func TestCouponsHandler_GetByCode(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name          string
+		getErr        error
+		expectedCode  int
+		expectedError string
+	}{
+		{name: "found", expectedCode: http.StatusOK},
+		{name: "not found", getErr: db.ErrCouponNotFound, expectedCode: http.StatusNotFound,
+			expectedError: errors2.CouponGetNotFound},
+		{name: "db failure", getErr: db.ErrUnexpectedGetCoupon, expectedCode: http.StatusInternalServerError,
+			expectedError: errors2.CouponGetServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			handler, err := handlers.NewCouponsHandler(lgr, &mocks.MockCouponsDataService{
+				GetByCodeFunc: func(_ context.Context, code string) (*data.Coupon, error) {
+					if tt.getErr != nil {
+						return nil, tt.getErr
+					}
+					return &data.Coupon{Code: code, Kind: data.DiscountPercent, Value: money.MustParse("10")}, nil
+				},
+			})
+			require.NoError(t, err)
+
+			c, r, recorder := setupTestContext()
+			r.GET("/coupons/:code", handler.GetByCode)
+			c.Request, _ = http.NewRequest(http.MethodGet, "/coupons/SAVE10", nil)
+			r.ServeHTTP(recorder, c.Request)
+
+			assert.Equal(t, tt.expectedCode, recorder.Code)
+			if tt.expectedError != "" {
+				var apiErr external.APIError
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &apiErr))
+				assert.Equal(t, tt.expectedError, apiErr.ErrorCode)
+				return
+			}
+			var coupon data.Coupon
+			require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &coupon))
+			assert.Equal(t, "SAVE10", coupon.Code)
+			assert.Equal(t, money.MustParse("10"), coupon.Value)
+		})
+	}
+}
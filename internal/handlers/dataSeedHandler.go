@@ -9,6 +9,7 @@ import (
 	"github.com/go-faker/faker/v4"
 	"github.com/rameshsunkara/go-rest-api-example/internal/db"
 	"github.com/rameshsunkara/go-rest-api-example/internal/models/data"
+	"github.com/rameshsunkara/go-rest-api-example/internal/pricing"
 	"github.com/rameshsunkara/go-rest-api-example/internal/utilities"
 	"github.com/rameshsunkara/go-rest-api-example/pkg/logger"
 )
@@ -46,6 +47,13 @@ func (s *SeedHandler) SeedDB(c *gin.Context) {
 				UpdatedAt: time.Now(),
 			},
 		}
+		total, err := pricing.Subtotal(products, data.DefaultCurrency)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
+				"message": "failed to price data",
+			})
+			return
+		}
 
 		po := &data.Order{
 			Version:     1,
@@ -54,11 +62,11 @@ func (s *SeedHandler) SeedDB(c *gin.Context) {
 			Products:    products,
 			User:        faker.Email(),
 			Status:      data.OrderPending,
-			TotalAmount: utilities.CalculateTotalAmount(products),
+			TotalAmount: total,
+			Currency:    data.DefaultCurrency,
 		}
 
-		_, err := s.oDataSvc.Create(c, po)
-		if err != nil {
+		if _, err = s.oDataSvc.Create(c, po); err != nil {
 			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
 				"message": "failed to insert data",
 			})
@@ -0,0 +1,62 @@
+package handlers
+
+import (
+	errors2 "errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rameshsunkara/go-rest-api-example/internal/db"
+	"github.com/rameshsunkara/go-rest-api-example/internal/errors"
+	"github.com/rameshsunkara/go-rest-api-example/internal/models/external"
+	"github.com/rameshsunkara/go-rest-api-example/internal/models/mapping"
+	"github.com/rameshsunkara/go-rest-api-example/pkg/logger"
+)
+
+// InventoryHandler handles stock level administration requests.
+type InventoryHandler struct {
+	iDataSvc db.InventoryDataService
+	logger   logger.Logger
+}
+
+// NewInventoryHandler creates a new InventoryHandler.
+func NewInventoryHandler(lgr logger.Logger, iSvc db.InventoryDataService) (*InventoryHandler, error) {
+	if lgr == nil || iSvc == nil {
+		return nil, errors2.New("missing required parameters to create inventory handler")
+	}
+	return &InventoryHandler{iDataSvc: iSvc, logger: lgr}, nil
+}
+
+// GetBySKU handles GET /inventory/:sku.
+func (h *InventoryHandler) GetBySKU(c *gin.Context) {
+	lgr, requestID := h.logger.WithReqID(c)
+	level, err := h.iDataSvc.GetBySKU(c, c.Param(ProductSKUPath))
+	if err != nil {
+		if errors2.Is(err, db.ErrStockNotFound) {
+			abortWithAPIError(c, lgr, http.StatusNotFound, errors.InventoryGetNotFound,
+				"no stock level found for sku", requestID, err)
+			return
+		}
+		abortWithAPIError(c, lgr, http.StatusInternalServerError, errors.InventoryGetServerError,
+			errors.UnexpectedErrorMessage, requestID, err)
+		return
+	}
+	c.JSON(http.StatusOK, mapping.StockLevel(level))
+}
+
+// SetStock handles PUT /inventory/:sku, it sets the stock available for new orders.
+func (h *InventoryHandler) SetStock(c *gin.Context) {
+	lgr, requestID := h.logger.WithReqID(c)
+	var in external.StockInput
+	if err := c.ShouldBindJSON(&in); err != nil {
+		abortWithAPIError(c, lgr, http.StatusBadRequest, errors.InventoryUpdateInvalidInput,
+			"Invalid stock request body", requestID, err)
+		return
+	}
+	level, err := h.iDataSvc.SetStock(c, c.Param(ProductSKUPath), *in.Available)
+	if err != nil {
+		abortWithAPIError(c, lgr, http.StatusInternalServerError, errors.InventoryUpdateServerError,
+			errors.UnexpectedErrorMessage, requestID, err)
+		return
+	}
+	c.JSON(http.StatusOK, mapping.StockLevel(level))
+}
@@ -0,0 +1,119 @@
+package handlers_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/rameshsunkara/go-rest-api-example/internal/db"
+	"github.com/rameshsunkara/go-rest-api-example/internal/db/mocks"
+	errors2 "github.com/rameshsunkara/go-rest-api-example/internal/errors"
+	"github.com/rameshsunkara/go-rest-api-example/internal/handlers"
+	"github.com/rameshsunkara/go-rest-api-example/internal/models/data"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewInventoryHandler(t *testing.T) {
+	t.Parallel()
+	_, err := handlers.NewInventoryHandler(nil, &mocks.MockInventoryDataService{})
+	require.Error(t, err)
+	_, err = handlers.NewInventoryHandler(lgr, nil)
+	require.Error(t, err)
+	h, err := handlers.NewInventoryHandler(lgr, &mocks.MockInventoryDataService{})
+	require.NoError(t, err)
+	assert.NotNil(t, h)
+}
+
+func TestInventoryHandler_GetBySKU(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name          string
+		getErr        error
+		expectedCode  int
+		expectedError string
+	}{
+		{name: "found", expectedCode: http.StatusOK},
+		{name: "not found", getErr: db.ErrStockNotFound, expectedCode: http.StatusNotFound,
+			expectedError: errors2.InventoryGetNotFound},
+		{name: "db failure", getErr: db.ErrUnexpectedGetStock, expectedCode: http.StatusInternalServerError,
+			expectedError: errors2.InventoryGetServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			handler, err := handlers.NewInventoryHandler(lgr, &mocks.MockInventoryDataService{
+				GetBySKUFunc: func(_ context.Context, sku string) (*data.StockLevel, error) {
+					if tt.getErr != nil {
+						return nil, tt.getErr
+					}
+					return &data.StockLevel{SKU: data.NormalizeSKU(sku), Available: 7}, nil
+				},
+			})
+			require.NoError(t, err)
+
+			c, r, recorder := setupTestContext()
+			r.GET("/inventory/:sku", handler.GetBySKU)
+			c.Request, _ = http.NewRequest(http.MethodGet, "/inventory/mug-1", nil)
+			r.ServeHTTP(recorder, c.Request)
+
+			assert.Equal(t, tt.expectedCode, recorder.Code)
+			if tt.expectedError != "" {
+				assertAPIError(t, recorder.Body.Bytes(), tt.expectedError)
+				return
+			}
+			var level data.StockLevel
+			require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &level))
+			assert.Equal(t, "MUG-1", level.SKU)
+			assert.Equal(t, int64(7), level.Available)
+		})
+	}
+}
+
+func TestInventoryHandler_SetStock(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name          string
+		body          string
+		setErr        error
+		expectedCode  int
+		expectedError string
+	}{
+		{name: "success", body: `{"available":12}`, expectedCode: http.StatusOK},
+		{name: "zero stock", body: `{"available":0}`, expectedCode: http.StatusOK},
+		{name: "missing available", body: `{}`, expectedCode: http.StatusBadRequest,
+			expectedError: errors2.InventoryUpdateInvalidInput},
+		{name: "negative available", body: `{"available":-1}`, expectedCode: http.StatusBadRequest,
+			expectedError: errors2.InventoryUpdateInvalidInput},
+		{name: "db failure", body: `{"available":1}`, setErr: db.ErrUnexpectedSetStock,
+			expectedCode: http.StatusInternalServerError, expectedError: errors2.InventoryUpdateServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			handler, err := handlers.NewInventoryHandler(lgr, &mocks.MockInventoryDataService{
+				SetStockFunc: func(_ context.Context, sku string, available int64) (*data.StockLevel, error) {
+					if tt.setErr != nil {
+						return nil, tt.setErr
+					}
+					return &data.StockLevel{SKU: data.NormalizeSKU(sku), Available: available}, nil
+				},
+			})
+			require.NoError(t, err)
+
+			c, r, recorder := setupTestContext()
+			r.PUT("/inventory/:sku", handler.SetStock)
+			c.Request, _ = http.NewRequest(http.MethodPut, "/inventory/mug-1", strings.NewReader(tt.body))
+			r.ServeHTTP(recorder, c.Request)
+
+			assert.Equal(t, tt.expectedCode, recorder.Code)
+			if tt.expectedError != "" {
+				assertAPIError(t, recorder.Body.Bytes(), tt.expectedError)
+			}
+		})
+	}
+}
@@ -0,0 +1,72 @@
+package handlers
+
+import (
+	errors2 "errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rameshsunkara/go-rest-api-example/internal/errors"
+	"github.com/rameshsunkara/go-rest-api-example/internal/models/external"
+	"github.com/rameshsunkara/go-rest-api-example/pkg/logger"
+)
+
+// LogLevelHandler handles runtime changes of the log levels.
+type LogLevelHandler struct {
+	levels *logger.Levels
+	logger logger.Logger
+}
+
+// NewLogLevelHandler creates a new LogLevelHandler changing the levels shared by lgr and its named children.
+func NewLogLevelHandler(lgr logger.Logger) (*LogLevelHandler, error) {
+	if lgr == nil || lgr.Levels() == nil {
+		return nil, errors2.New("missing required parameters to create log level handler")
+	}
+	return &LogLevelHandler{levels: lgr.Levels(), logger: lgr}, nil
+}
+
+// Get handles GET /internal/loglevel.
+func (h *LogLevelHandler) Get(c *gin.Context) {
+	c.JSON(http.StatusOK, h.current())
+}
+
+// Set handles PUT /internal/loglevel, the levels change for every request in flight at once.
+func (h *LogLevelHandler) Set(c *gin.Context) {
+	lgr, requestID := h.logger.WithReqID(c)
+	var in external.LogLevelsInput
+	if err := c.ShouldBindJSON(&in); err != nil {
+		abortWithAPIError(c, lgr, http.StatusBadRequest, errors.LogLevelUpdateInvalidInput,
+			"Invalid log level request body", requestID, err)
+		return
+	}
+	var revertAfter time.Duration
+	if in.RevertAfter != "" {
+		var err error
+		if revertAfter, err = time.ParseDuration(in.RevertAfter); err != nil || revertAfter <= 0 {
+			abortWithAPIError(c, lgr, http.StatusBadRequest, errors.LogLevelUpdateInvalidInput,
+				"revertAfter must be a positive duration, e.g. 15m", requestID, err)
+			return
+		}
+	}
+	if err := h.levels.Set(in.Level, in.Components, revertAfter); err != nil {
+		abortWithAPIError(c, lgr, http.StatusBadRequest, errors.LogLevelUpdateInvalidInput,
+			err.Error(), requestID, err)
+		return
+	}
+	levels := h.current()
+	lgr.Info().
+		Str("level", levels.Level).
+		Interface("components", levels.Components).
+		Str("revertAt", levels.RevertAt).
+		Msg("log levels changed")
+	c.JSON(http.StatusOK, levels)
+}
+
+func (h *LogLevelHandler) current() external.LogLevels {
+	level, components, revertAt := h.levels.Settings()
+	levels := external.LogLevels{Level: level, Components: components}
+	if !revertAt.IsZero() {
+		levels.RevertAt = revertAt.UTC().Format(time.RFC3339)
+	}
+	return levels
+}
@@ -0,0 +1,102 @@
+package handlers_test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	errors2 "github.com/rameshsunkara/go-rest-api-example/internal/errors"
+	"github.com/rameshsunkara/go-rest-api-example/internal/handlers"
+	"github.com/rameshsunkara/go-rest-api-example/internal/models/external"
+	"github.com/rameshsunkara/go-rest-api-example/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewLogLevelHandler(t *testing.T) {
+	t.Parallel()
+	_, err := handlers.NewLogLevelHandler(nil)
+	require.Error(t, err)
+	h, err := handlers.NewLogLevelHandler(lgr)
+	require.NoError(t, err)
+	assert.NotNil(t, h)
+}
+
+func TestLogLevelHandler_Get(t *testing.T) {
+	t.Parallel()
+	levels, err := logger.NewLevels("info", map[string]string{"mongodb": "debug"})
+	require.NoError(t, err)
+	handler, err := handlers.NewLogLevelHandler(logger.NewWithLevels(levels, io.Discard))
+	require.NoError(t, err)
+
+	c, r, recorder := setupTestContext()
+	r.GET("/loglevel", handler.Get)
+	c.Request, _ = http.NewRequest(http.MethodGet, "/loglevel", nil)
+	r.ServeHTTP(recorder, c.Request)
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	var got external.LogLevels
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &got))
+	assert.Equal(t, external.LogLevels{Level: "info", Components: map[string]string{"mongodb": "debug"}}, got)
+}
+
+func TestLogLevelHandler_Set(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name           string
+		body           string
+		expectedCode   int
+		expectedError  string
+		expectedLevels external.LogLevels
+		expectRevert   bool
+	}{
+		{name: "global level", body: `{"level":"debug"}`, expectedCode: http.StatusOK,
+			expectedLevels: external.LogLevels{Level: "debug", Components: map[string]string{"mongodb": "error"}}},
+		{name: "component levels", body: `{"components":{"http":"debug"}}`, expectedCode: http.StatusOK,
+			expectedLevels: external.LogLevels{Level: "info", Components: map[string]string{"http": "debug"}}},
+		{name: "auto revert", body: `{"level":"debug","revertAfter":"15m"}`, expectedCode: http.StatusOK,
+			expectedLevels: external.LogLevels{Level: "debug", Components: map[string]string{"mongodb": "error"}},
+			expectRevert:   true},
+		{name: "unknown level", body: `{"level":"verbose"}`, expectedCode: http.StatusBadRequest,
+			expectedError: errors2.LogLevelUpdateInvalidInput},
+		{name: "unknown component level", body: `{"components":{"http":"verbose"}}`,
+			expectedCode: http.StatusBadRequest, expectedError: errors2.LogLevelUpdateInvalidInput},
+		{name: "invalid revertAfter", body: `{"level":"debug","revertAfter":"soon"}`,
+			expectedCode: http.StatusBadRequest, expectedError: errors2.LogLevelUpdateInvalidInput},
+		{name: "negative revertAfter", body: `{"level":"debug","revertAfter":"-1m"}`,
+			expectedCode: http.StatusBadRequest, expectedError: errors2.LogLevelUpdateInvalidInput},
+		{name: "malformed body", body: `{"level":`, expectedCode: http.StatusBadRequest,
+			expectedError: errors2.LogLevelUpdateInvalidInput},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			levels, err := logger.NewLevels("info", map[string]string{"mongodb": "error"})
+			require.NoError(t, err)
+			t.Cleanup(levels.Reset)
+			handler, err := handlers.NewLogLevelHandler(logger.NewWithLevels(levels, io.Discard))
+			require.NoError(t, err)
+
+			c, r, recorder := setupTestContext()
+			r.PUT("/loglevel", handler.Set)
+			c.Request, _ = http.NewRequest(http.MethodPut, "/loglevel", strings.NewReader(tt.body))
+			r.ServeHTTP(recorder, c.Request)
+
+			assert.Equal(t, tt.expectedCode, recorder.Code)
+			if tt.expectedError != "" {
+				assertAPIError(t, recorder.Body.Bytes(), tt.expectedError)
+				level, _, _ := levels.Settings()
+				assert.Equal(t, "info", level, "levels are unchanged")
+				return
+			}
+			var got external.LogLevels
+			require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &got))
+			assert.Equal(t, tt.expectRevert, got.RevertAt != "")
+			got.RevertAt = ""
+			assert.Equal(t, tt.expectedLevels, got)
+		})
+	}
+}
@@ -1,40 +1,72 @@
 package handlers
 
 import (
+	"encoding/json"
 	errors2 "errors"
 	"fmt"
 	"net/http"
-	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/go-faker/faker/v4"
+	"github.com/rameshsunkara/go-rest-api-example/internal/audit"
+	"github.com/rameshsunkara/go-rest-api-example/internal/catalog"
 	"github.com/rameshsunkara/go-rest-api-example/internal/db"
 	"github.com/rameshsunkara/go-rest-api-example/internal/errors"
 	"github.com/rameshsunkara/go-rest-api-example/internal/models/data"
 	"github.com/rameshsunkara/go-rest-api-example/internal/models/external"
-	"github.com/rameshsunkara/go-rest-api-example/internal/utilities"
+	"github.com/rameshsunkara/go-rest-api-example/internal/models/mapping"
+	"github.com/rameshsunkara/go-rest-api-example/internal/pricing"
+	"github.com/rameshsunkara/go-rest-api-example/internal/query"
 	"github.com/rameshsunkara/go-rest-api-example/pkg/logger"
+	"github.com/rameshsunkara/go-rest-api-example/pkg/money"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 const (
 	OrderIDPath = "id"
+	UserPath    = "user"
 	MaxPageSize = 100
+
+	// MaxUserLength is the longest valid email address.
+	MaxUserLength = 254
+
+	// RestoreAction is the custom method suffix used to restore a soft-deleted order: POST /orders/{id}:restore.
+	RestoreAction = "restore"
+	// TransitionAction is the custom method suffix used to change the status of an order: POST /orders/{id}:transition.
+	TransitionAction = "transition"
 )
 
 // OrdersHandler handles order-related HTTP requests.
 type OrdersHandler struct {
 	oDataSvc db.OrdersDataService
+	catalog  *catalog.Resolver
+	pricer   *pricing.Engine
+	stock    db.InventoryDataService
 	logger   logger.Logger
+	render   func(*data.Order) any // converts an order to the representation of the API version
 }
 
 // NewOrdersHandler creates a new OrdersHandler.
-func NewOrdersHandler(lgr logger.Logger, dSvc db.OrdersDataService) (*OrdersHandler, error) {
-	if lgr == nil || dSvc == nil {
+func NewOrdersHandler(
+	lgr logger.Logger,
+	dSvc db.OrdersDataService,
+	resolver *catalog.Resolver,
+	pricer *pricing.Engine,
+	stock db.InventoryDataService,
+) (*OrdersHandler, error) {
+	if lgr == nil || dSvc == nil || resolver == nil || pricer == nil || stock == nil {
 		return nil, errors2.New("missing required parameters to create orders handler")
 	}
-	return &OrdersHandler{oDataSvc: dSvc, logger: lgr}, nil
+	return &OrdersHandler{
+		oDataSvc: dSvc,
+		catalog:  resolver,
+		pricer:   pricer,
+		stock:    stock,
+		logger:   lgr,
+		render:   func(order *data.Order) any { return mapping.Order(order) },
+	}, nil
 }
 
 // Create handles POST /orders.
@@ -42,76 +74,190 @@ func (o *OrdersHandler) Create(c *gin.Context) {
 	lgr, requestID := o.logger.WithReqID(c)
 	var orderInput external.OrderInput
 	if err := c.ShouldBindJSON(&orderInput); err != nil {
-		o.abortWithAPIError(c, lgr, http.StatusBadRequest, errors.OrderCreateInvalidInput,
+		abortWithAPIError(c, lgr, http.StatusBadRequest, errors.OrderCreateInvalidInput,
 			"Invalid order request body", requestID, err)
 		return
 	}
 
-	products := make([]data.Product, len(orderInput.Products))
+	var currency money.Currency
+	if orderInput.Currency != "" {
+		var err error
+		if currency, err = money.ParseCurrency(orderInput.Currency); err != nil {
+			abortWithAPIError(c, lgr, http.StatusBadRequest, errors.OrderCreateInvalidInput,
+				"Invalid order currency", requestID, err)
+			return
+		}
+	}
+
+	lines := make([]catalog.Line, len(orderInput.Products))
 	for i, p := range orderInput.Products {
-		products[i] = data.Product{Name: p.Name, Price: p.Price, Quantity: p.Quantity}
+		lines[i] = catalog.Line{SKU: p.SKU, Quantity: p.Quantity}
+	}
+	products, currency, err := o.catalog.Resolve(c, lines, currency)
+	if err != nil {
+		if errors2.Is(err, catalog.ErrInvalidLine) {
+			abortWithAPIError(c, lgr, http.StatusBadRequest, errors.OrderCreateInvalidProduct, err.Error(), requestID, err)
+			return
+		}
+		abortWithAPIError(c, lgr, http.StatusInternalServerError, errors.OrderCreateServerError,
+			errors.UnexpectedErrorMessage, requestID, err)
+		return
+	}
+
+	quote, err := o.pricer.Price(c, pricing.Request{
+		Products:   products,
+		Currency:   currency,
+		Region:     orderInput.Region,
+		CouponCode: orderInput.CouponCode,
+	})
+	if err != nil {
+		o.abortWithPricingError(c, lgr, requestID, err)
+		return
+	}
+
+	if err = o.stock.Reserve(c, quote.Products); err != nil {
+		var shortErr *db.InsufficientStockError
+		if errors2.As(err, &shortErr) {
+			abortWithAPIErrorDetails(c, lgr, http.StatusConflict, errors.OrderCreateInsufficientStock,
+				err.Error(), requestID, err, shortErr.Items)
+			return
+		}
+		abortWithAPIError(c, lgr, http.StatusInternalServerError, errors.OrderCreateServerError,
+			errors.UnexpectedErrorMessage, requestID, err)
+		return
+	}
+	if quote.Pricing.CouponCode != "" {
+		if err = o.pricer.Redeem(c, quote.Pricing.CouponCode); err != nil {
+			o.releaseStock(c, lgr, quote.Products)
+			o.abortWithPricingError(c, lgr, requestID, err)
+			return
+		}
 	}
 
 	order := data.Order{
-		Version:     1,
-		CreatedAt:   time.Now(),
-		UpdatedAt:   time.Now(),
-		Products:    products,
-		User:        faker.Email(), // TODO: Replace with actual user email from trusted source such as JWT token
-		TotalAmount: utilities.CalculateTotalAmount(products),
-		Status:      data.OrderPending,
+		Version:         1,
+		CreatedAt:       time.Now(),
+		UpdatedAt:       time.Now(),
+		Products:        quote.Products,
+		User:            faker.Email(), // TODO: Replace with actual user email from trusted source such as JWT token
+		TotalAmount:     quote.Pricing.Total,
+		Currency:        currency,
+		Pricing:         &quote.Pricing,
+		Status:          data.OrderPending,
+		Customer:        orderInput.Customer.ToData(),
+		ShippingAddress: orderInput.ShippingAddress.ToData(),
+		StockReserved:   true,
 	}
 
 	id, err := o.oDataSvc.Create(c, &order)
 	if err != nil {
-		o.abortWithAPIError(c, lgr, http.StatusInternalServerError, errors.OrderCreateServerError,
+		if quote.Pricing.CouponCode != "" {
+			if relErr := o.pricer.Release(c, quote.Pricing.CouponCode); relErr != nil {
+				lgr.Error().Err(relErr).Str("coupon", quote.Pricing.CouponCode).Msg("failed to release coupon")
+			}
+		}
+		o.releaseStock(c, lgr, quote.Products)
+		abortWithAPIError(c, lgr, http.StatusInternalServerError, errors.OrderCreateServerError,
 			errors.UnexpectedErrorMessage, requestID, err)
 		return
 	}
 
-	extOrder := external.Order{
-		ID:          id,
-		CreatedAt:   utilities.FormatTimeToISO(order.CreatedAt),
-		UpdatedAt:   utilities.FormatTimeToISO(order.UpdatedAt),
-		Products:    order.Products,
-		User:        order.User,
-		TotalAmount: order.TotalAmount,
-		Status:      order.Status,
-		Version:     order.Version,
+	if order.ID, err = primitive.ObjectIDFromHex(id); err != nil {
+		abortWithAPIError(c, lgr, http.StatusInternalServerError, errors.OrderCreateServerError,
+			errors.UnexpectedErrorMessage, requestID, err)
+		return
 	}
-	c.JSON(http.StatusCreated, extOrder)
+	c.JSON(http.StatusCreated, o.render(&order))
 }
 
 // GetAll handles GET /orders.
 func (o *OrdersHandler) GetAll(c *gin.Context) {
 	lgr, requestID := o.logger.WithReqID(c)
-	limit, apiErr := o.parseLimitQueryParam(c)
+	q, ok := routeQuery[external.LimitQuery](c, lgr, errors.OrdersGetServerError, requestID)
+	if !ok {
+		return
+	}
+
+	readOpts, fields, apiErr := o.parseReadOptions(c)
 	if apiErr != nil {
 		c.AbortWithStatusJSON(apiErr.HTTPStatusCode, apiErr)
 		return
 	}
+	if lq, found := query.From[external.OrderListQuery](c); found {
+		readOpts.Offset = lq.Offset
+	}
 
-	orders, err := o.oDataSvc.GetAll(c, limit)
+	orders, err := o.oDataSvc.GetAll(c, q.Limit, readOpts)
 	if err != nil {
-		o.abortWithAPIError(c, lgr, http.StatusInternalServerError, errors.OrdersGetServerError,
+		abortWithAPIError(c, lgr, http.StatusInternalServerError, errors.OrdersGetServerError,
 			errors.UnexpectedErrorMessage, requestID, err)
 		return
 	}
 
-	extOrders := make([]external.Order, 0, len(*orders))
-	for _, o := range *orders {
-		extOrders = append(extOrders, external.Order{
-			ID:          o.ID.Hex(),
-			Version:     o.Version,
-			Status:      o.Status,
-			TotalAmount: o.TotalAmount,
-			User:        o.User,
-			CreatedAt:   utilities.FormatTimeToISO(o.CreatedAt),
-			UpdatedAt:   utilities.FormatTimeToISO(o.UpdatedAt),
-			Products:    o.Products,
-		})
+	extOrders := mapping.Orders(*orders)
+	if len(fields) == 0 {
+		c.JSON(http.StatusOK, extOrders)
+		return
 	}
-	c.JSON(http.StatusOK, extOrders)
+	sparse := make([]map[string]json.RawMessage, 0, len(extOrders))
+	for _, order := range extOrders {
+		picked, pickErr := pickFields(order, fields)
+		if pickErr != nil {
+			abortWithAPIError(c, lgr, http.StatusInternalServerError, errors.OrdersGetServerError,
+				errors.UnexpectedErrorMessage, requestID, pickErr)
+			return
+		}
+		sparse = append(sparse, picked)
+	}
+	c.JSON(http.StatusOK, sparse)
+}
+
+// GetByUser handles GET /users/:user/orders, it lists the most recent orders of a customer.
+func (o *OrdersHandler) GetByUser(c *gin.Context) {
+	lgr, requestID := o.logger.WithReqID(c)
+	user, ok := o.userParam(c)
+	if !ok {
+		return
+	}
+	q, ok := routeQuery[external.LimitQuery](c, lgr, errors.UserOrdersServerError, requestID)
+	if !ok {
+		return
+	}
+	orders, err := o.oDataSvc.GetByUser(c, user, q.Limit)
+	if err != nil {
+		abortWithAPIError(c, lgr, http.StatusInternalServerError, errors.UserOrdersServerError,
+			errors.UnexpectedErrorMessage, requestID, err)
+		return
+	}
+	c.JSON(http.StatusOK, mapping.Orders(*orders))
+}
+
+// GetUserSummary handles GET /users/:user/orders/summary.
+func (o *OrdersHandler) GetUserSummary(c *gin.Context) {
+	lgr, requestID := o.logger.WithReqID(c)
+	user, ok := o.userParam(c)
+	if !ok {
+		return
+	}
+	summary, err := o.oDataSvc.SummarizeUser(c, user)
+	if err != nil {
+		abortWithAPIError(c, lgr, http.StatusInternalServerError, errors.UserSummaryServerError,
+			errors.UnexpectedErrorMessage, requestID, err)
+		return
+	}
+	c.JSON(http.StatusOK, mapping.CustomerSummary(summary))
+}
+
+// userParam returns the user path parameter, it aborts the request when the parameter is invalid.
+func (o *OrdersHandler) userParam(c *gin.Context) (string, bool) {
+	user := strings.TrimSpace(c.Param(UserPath))
+	if user == "" || len(user) > MaxUserLength {
+		lgr, requestID := o.logger.WithReqID(c)
+		abortWithAPIError(c, lgr, http.StatusBadRequest, errors.UserOrdersInvalidParams,
+			"invalid user", requestID, nil)
+		return "", false
+	}
+	return user, true
 }
 
 // GetByID handles GET /orders/:id.
@@ -120,21 +266,37 @@ func (o *OrdersHandler) GetByID(c *gin.Context) {
 	id := c.Param(OrderIDPath)
 	oID, err := primitive.ObjectIDFromHex(id)
 	if err != nil || oID.IsZero() {
-		o.abortWithAPIError(c, lgr, http.StatusBadRequest, errors.OrderGetInvalidParams, "invalid order ID", requestID, err)
+		abortWithAPIError(c, lgr, http.StatusBadRequest, errors.OrderGetInvalidParams, "invalid order ID", requestID, err)
+		return
+	}
+	readOpts, fields, apiErr := o.parseReadOptions(c)
+	if apiErr != nil {
+		c.AbortWithStatusJSON(apiErr.HTTPStatusCode, apiErr)
 		return
 	}
-	order, err := o.oDataSvc.GetByID(c, oID)
+	order, err := o.oDataSvc.GetByID(c, oID, readOpts)
 	if err != nil {
 		if errors2.Is(err, db.ErrPOIDNotFound) {
-			o.abortWithAPIError(c, lgr, http.StatusNotFound, errors.OrderGetNotFound,
+			abortWithAPIError(c, lgr, http.StatusNotFound, errors.OrderGetNotFound,
 				"order not found", requestID, err)
 			return
 		}
-		o.abortWithAPIError(c, lgr, http.StatusInternalServerError, errors.OrdersGetServerError,
+		abortWithAPIError(c, lgr, http.StatusInternalServerError, errors.OrdersGetServerError,
 			"failed to fetch order", requestID, err)
 		return
 	}
-	c.JSON(http.StatusOK, order)
+	extOrder := o.render(order)
+	if len(fields) == 0 {
+		c.JSON(http.StatusOK, extOrder)
+		return
+	}
+	picked, err := pickFields(extOrder, fields)
+	if err != nil {
+		abortWithAPIError(c, lgr, http.StatusInternalServerError, errors.OrdersGetServerError,
+			"failed to fetch order", requestID, err)
+		return
+	}
+	c.JSON(http.StatusOK, picked)
 }
 
 // DeleteByID handles DELETE /orders/:id.
@@ -143,59 +305,236 @@ func (o *OrdersHandler) DeleteByID(c *gin.Context) {
 	id := c.Param(OrderIDPath)
 	oID, err := primitive.ObjectIDFromHex(id)
 	if err != nil || oID.IsZero() {
-		o.abortWithAPIError(c, lgr, http.StatusBadRequest, errors.OrderDeleteInvalidID, "invalid order ID", requestID, err)
+		abortWithAPIError(c, lgr, http.StatusBadRequest, errors.OrderDeleteInvalidID, "invalid order ID", requestID, err)
 		return
 	}
 	if dbErr := o.oDataSvc.DeleteByID(c, oID); dbErr != nil {
 		if errors2.Is(dbErr, db.ErrPOIDNotFound) {
-			o.abortWithAPIError(c, lgr, http.StatusNotFound, errors.OrderDeleteNotFound,
+			abortWithAPIError(c, lgr, http.StatusNotFound, errors.OrderDeleteNotFound,
 				"could not delete order", requestID, dbErr)
 			return
 		}
-		o.abortWithAPIError(c, lgr, http.StatusInternalServerError, errors.OrderDeleteServerError,
+		abortWithAPIError(c, lgr, http.StatusInternalServerError, errors.OrderDeleteServerError,
 			"could not delete order", requestID, dbErr)
 		return
 	}
+	o.cancelReservation(c, lgr, oID)
 	c.Status(http.StatusNoContent)
 }
 
-// parseLimitQueryParam parses and validates the "limit" query parameter.
-func (o *OrdersHandler) parseLimitQueryParam(c *gin.Context) (int64, *external.APIError) {
+// cancelReservation cancels a deleted order that was pending with reserved stock and gives the stock back,
+// the reservation sweeper leaves deleted orders out. It runs once the order is deleted, status transitions skip
+// deleted orders so nothing moves it on meanwhile. Failures are logged, the order is deleted already.
+func (o *OrdersHandler) cancelReservation(c *gin.Context, lgr logger.Logger, id primitive.ObjectID) {
+	order, err := o.oDataSvc.GetByID(c, id, db.ReadOptions{IncludeDeleted: true})
+	if err != nil {
+		lgr.Error().Err(err).Str("orderId", id.Hex()).Msg("failed to read deleted order, its stock stays reserved")
+		return
+	}
+	if order.Status != data.OrderPending || !order.StockReserved {
+		return
+	}
+	order.Status = data.OrderCancelled
+	order.Version++
+	order.Updates = append(order.Updates,
+		data.OrderUpdate{UpdatedAt: time.Now(), Notes: "order deleted", HandledBy: audit.Actor(c)})
+	if err = o.oDataSvc.Update(c, order); err != nil {
+		lgr.Error().Err(err).Str("orderId", id.Hex()).Msg("failed to cancel deleted order, its stock stays reserved")
+		return
+	}
+	o.releaseStock(c, lgr, order.Products)
+}
+
+// Action handles custom methods on a single order, POST /orders/:id where the path segment is "{id}:{action}".
+// The supported actions are "restore" and "transition".
+func (o *OrdersHandler) Action(c *gin.Context) {
 	lgr, requestID := o.logger.WithReqID(c)
-	l := db.DefaultPageSize
-	if input, exists := c.GetQuery("limit"); exists && input != "" {
-		val, err := strconv.Atoi(input)
-		if err != nil || val < 1 || val > MaxPageSize {
-			apiErr := &external.APIError{
-				HTTPStatusCode: http.StatusBadRequest,
-				ErrorCode:      "",
-				Message:        fmt.Sprintf("Integer value within 1 and %d is expected for limit query param", MaxPageSize),
-				DebugID:        requestID,
-			}
-			lgr.Error().
-				Int("HttpStatusCode", apiErr.HTTPStatusCode).
-				Str("ErrorCode", apiErr.ErrorCode).
-				Msg(apiErr.Message)
-			return 0, apiErr
+	id, action, _ := strings.Cut(c.Param(OrderIDPath), ":")
+	switch action {
+	case RestoreAction:
+		o.restore(c, id)
+	case TransitionAction:
+		o.transition(c, id)
+	default:
+		abortWithAPIError(c, lgr, http.StatusNotFound, errors.OrderActionNotSupported,
+			"unsupported order action", requestID, nil)
+	}
+}
+
+// restore handles POST /orders/{id}:restore. Orders deleted while pending with reserved stock come back
+// cancelled, their stock was given back when they were deleted.
+func (o *OrdersHandler) restore(c *gin.Context, id string) {
+	lgr, requestID := o.logger.WithReqID(c)
+	oID, err := primitive.ObjectIDFromHex(id)
+	if err != nil || oID.IsZero() {
+		abortWithAPIError(c, lgr, http.StatusBadRequest, errors.OrderRestoreInvalidID, "invalid order ID", requestID, err)
+		return
+	}
+	if dbErr := o.oDataSvc.Restore(c, oID); dbErr != nil {
+		if errors2.Is(dbErr, db.ErrPOIDNotFound) {
+			abortWithAPIError(c, lgr, http.StatusNotFound, errors.OrderRestoreNotFound,
+				"no deleted order found with given id", requestID, dbErr)
+			return
+		}
+		abortWithAPIError(c, lgr, http.StatusInternalServerError, errors.OrderRestoreServerError,
+			"could not restore order", requestID, dbErr)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// transition handles POST /orders/{id}:transition, it moves the order to the requested status.
+// Shipping an order requires the carrier and tracking ID. Cancelling an order gives its reserved stock back.
+func (o *OrdersHandler) transition(c *gin.Context, id string) {
+	lgr, requestID := o.logger.WithReqID(c)
+	oID, err := primitive.ObjectIDFromHex(id)
+	if err != nil || oID.IsZero() {
+		abortWithAPIError(c, lgr, http.StatusBadRequest, errors.OrderTransitionInvalidInput,
+			"invalid order ID", requestID, err)
+		return
+	}
+	var in external.TransitionInput
+	if err = c.ShouldBindJSON(&in); err != nil || !in.Status.IsValid() {
+		abortWithAPIError(c, lgr, http.StatusBadRequest, errors.OrderTransitionInvalidInput,
+			"Invalid order transition request body", requestID, err)
+		return
+	}
+	shipping := in.Status == data.OrderShipped
+	if shipping != (in.Shipment != nil) {
+		abortWithAPIError(c, lgr, http.StatusBadRequest, errors.OrderTransitionInvalidInput,
+			"shipment details are required to ship an order and only allowed then", requestID, nil)
+		return
+	}
+	var shipment *data.Shipment
+	if shipping {
+		shipment = &data.Shipment{Carrier: in.Shipment.Carrier, TrackingID: in.Shipment.TrackingID}
+	}
+
+	note := data.OrderUpdate{Notes: in.Notes, HandledBy: audit.Actor(c)}
+	order, err := o.oDataSvc.Transition(c, oID, in.Status, note, shipment)
+	if err != nil {
+		switch {
+		case errors2.Is(err, db.ErrPOIDNotFound):
+			abortWithAPIError(c, lgr, http.StatusNotFound, errors.OrderTransitionNotFound,
+				"order not found", requestID, err)
+		case errors2.Is(err, db.ErrInvalidTransition):
+			abortWithAPIError(c, lgr, http.StatusConflict, errors.OrderTransitionConflict,
+				"order cannot move to status "+string(in.Status), requestID, err)
+		default:
+			abortWithAPIError(c, lgr, http.StatusInternalServerError, errors.OrderTransitionServerError,
+				"could not change order status", requestID, err)
+		}
+		return
+	}
+	if order.Status == data.OrderCancelled && order.StockReserved {
+		o.releaseStock(c, lgr, order.Products)
+	}
+	c.JSON(http.StatusOK, o.render(order))
+}
+
+// abortWithPricingError aborts an order creation that failed to price or to redeem its coupon.
+func (o *OrdersHandler) abortWithPricingError(c *gin.Context, lgr logger.Logger, requestID string, err error) {
+	if errors2.Is(err, pricing.ErrInvalidCoupon) {
+		abortWithAPIError(c, lgr, http.StatusBadRequest, errors.OrderCreateInvalidCoupon, err.Error(), requestID, err)
+		return
+	}
+	if errors2.Is(err, money.ErrOverflow) {
+		abortWithAPIError(c, lgr, http.StatusBadRequest, errors.OrderCreateInvalidInput,
+			"order total is out of range", requestID, err)
+		return
+	}
+	abortWithAPIError(c, lgr, http.StatusInternalServerError, errors.OrderCreateServerError,
+		errors.UnexpectedErrorMessage, requestID, err)
+}
+
+// releaseStock gives back the stock reserved for an order, failures are logged since the request already failed
+// or the order is already cancelled.
+func (o *OrdersHandler) releaseStock(c *gin.Context, lgr logger.Logger, products []data.Product) {
+	if err := o.stock.Release(c, products); err != nil {
+		lgr.Error().Err(err).Msg("failed to release reserved stock")
+	}
+}
+
+// parseReadOptions reads the "includeDeleted" and "fields" query parameters, routes that do not declare them
+// read whole live orders. It also returns the selected fields, none means the whole order.
+func (o *OrdersHandler) parseReadOptions(c *gin.Context) (db.ReadOptions, []string, *external.APIError) {
+	var opts db.ReadOptions
+	if q, ok := query.From[external.IncludeDeletedQuery](c); ok {
+		opts.IncludeDeleted = q.IncludeDeleted
+	}
+	fq, _ := query.From[external.FieldsQuery](c)
+	fields, err := external.ParseFields(fq.Fields, external.OrderFields)
+	if err != nil {
+		lgr, requestID := o.logger.WithReqID(c)
+		apiErr := &external.APIError{
+			HTTPStatusCode: http.StatusBadRequest,
+			ErrorCode:      errors.OrderGetInvalidParams,
+			Message:        err.Error(),
+			DebugID:        requestID,
 		}
-		l = val
+		lgr.Error().Err(err).Msg("invalid fields query param")
+		return opts, nil, apiErr
 	}
-	return int64(l), nil
+	opts.Fields = external.StoredFields(fields, external.OrderFields)
+	return opts, fields, nil
+}
+
+// pickFields returns the JSON form of v reduced to the given fields, v must encode to a JSON object.
+func pickFields(v any, fields []string) (map[string]json.RawMessage, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var all map[string]json.RawMessage
+	if err = json.Unmarshal(b, &all); err != nil {
+		return nil, err
+	}
+	picked := make(map[string]json.RawMessage, len(fields))
+	for _, f := range fields {
+		if raw, ok := all[f]; ok {
+			picked[f] = raw
+		}
+	}
+	return picked, nil
+}
+
+// routeQuery returns the query parameters parsed for the route as a T, it aborts the request when the route
+// was registered without them.
+func routeQuery[T any](c *gin.Context, lgr logger.Logger, errorCode, requestID string) (T, bool) {
+	q, ok := query.From[T](c)
+	if !ok {
+		abortWithAPIError(c, lgr, http.StatusInternalServerError, errorCode,
+			errors.UnexpectedErrorMessage, requestID, fmt.Errorf("%w: %T", query.ErrNotParsed, q))
+	}
+	return q, ok
 }
 
 // abortWithAPIError logs and aborts the request with a standardized API error response.
-func (o *OrdersHandler) abortWithAPIError(
+func abortWithAPIError(
+	c *gin.Context,
+	lgr logger.Logger,
+	status int,
+	errorCode, message, debugID string,
+	err error,
+) {
+	abortWithAPIErrorDetails(c, lgr, status, errorCode, message, debugID, err, nil)
+}
+
+// abortWithAPIErrorDetails is like abortWithAPIError and adds machine-readable details to the response.
+func abortWithAPIErrorDetails(
 	c *gin.Context,
 	lgr logger.Logger,
 	status int,
 	errorCode, message, debugID string,
 	err error,
+	details interface{},
 ) {
 	apiErr := &external.APIError{
 		HTTPStatusCode: status,
 		ErrorCode:      errorCode,
 		Message:        message,
 		DebugID:        debugID,
+		Details:        details,
 	}
 	event := lgr.Error().Int("HttpStatusCode", status).Str("ErrorCode", errorCode)
 	if err != nil {
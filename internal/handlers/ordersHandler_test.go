@@ -5,24 +5,39 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"maps"
+	"math"
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"slices"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/rameshsunkara/go-rest-api-example/internal/audit"
+	"github.com/rameshsunkara/go-rest-api-example/internal/catalog"
 	"github.com/rameshsunkara/go-rest-api-example/internal/db"
 	"github.com/rameshsunkara/go-rest-api-example/internal/db/mocks"
 	errors2 "github.com/rameshsunkara/go-rest-api-example/internal/errors"
 	"github.com/rameshsunkara/go-rest-api-example/internal/handlers"
+	"github.com/rameshsunkara/go-rest-api-example/internal/middleware"
 	"github.com/rameshsunkara/go-rest-api-example/internal/models/data"
 	"github.com/rameshsunkara/go-rest-api-example/internal/models/external"
+	"github.com/rameshsunkara/go-rest-api-example/internal/pricing"
+	"github.com/rameshsunkara/go-rest-api-example/internal/query"
 	"github.com/rameshsunkara/go-rest-api-example/pkg/logger"
+	"github.com/rameshsunkara/go-rest-api-example/pkg/money"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
+// createdOrderID is the ID the mocked repositories assign to new orders.
+const createdOrderID = "65f2c1a0e4b0a1b2c3d4e5f6"
+
 var lgr logger.Logger
 
 func TestMain(m *testing.M) {
@@ -47,33 +62,118 @@ func setupTestContext() (*gin.Context, *gin.Engine, *httptest.ResponseRecorder)
 	return c, r, recorder
 }
 
+// parseQuery parses the query declared by schema in front of a handler, as the router does.
+func parseQuery(schema any) gin.HandlerFunc {
+	return middleware.QueryParamsMiddleware(lgr, query.MustSchema(schema))
+}
+
+// newTestPricer returns a pricing engine charging 10% tax in region "TX" and none elsewhere.
+func newTestPricer(t *testing.T, coupons db.CouponsDataService) *pricing.Engine {
+	t.Helper()
+	if coupons == nil {
+		coupons = &mocks.MockCouponsDataService{}
+	}
+	tax, err := pricing.NewFlatRateTax(map[string]money.Amount{"TX": money.MustParse("10")}, 0)
+	require.NoError(t, err)
+	pricer, err := pricing.NewEngine(lgr, coupons, tax)
+	require.NoError(t, err)
+	return pricer
+}
+
+// newTestCatalog returns a resolver over a catalog of the active USD product "P-1" priced 10,
+// the inactive "OLD-1" and the EUR product "EUR-1".
+func newTestCatalog(t *testing.T) *catalog.Resolver {
+	t.Helper()
+	products := []data.CatalogProduct{
+		{SKU: "P-1", Name: "Product 1", Price: money.MustParse("10"), Currency: money.USD, Active: true},
+		{SKU: "OLD-1", Name: "Old Product", Price: money.MustParse("1"), Currency: money.USD},
+		{SKU: "EUR-1", Name: "Euro Product", Price: money.MustParse("5"), Currency: money.EUR, Active: true},
+	}
+	resolver, err := catalog.NewResolver(lgr, &mocks.MockProductsDataService{
+		GetBySKUsFunc: func(_ context.Context, skus []string) ([]data.CatalogProduct, error) {
+			var found []data.CatalogProduct
+			for _, p := range products {
+				if slices.Contains(skus, p.SKU) {
+					found = append(found, p)
+				}
+			}
+			return found, nil
+		},
+	})
+	require.NoError(t, err)
+	return resolver
+}
+
+// newTestStock returns an inventory that always has enough stock.
+func newTestStock() *mocks.MockInventoryDataService {
+	return &mocks.MockInventoryDataService{
+		ReserveFunc: func(context.Context, []data.Product) error { return nil },
+		ReleaseFunc: func(context.Context, []data.Product) error { return nil },
+	}
+}
+
 func TestNewOrdersHandler(t *testing.T) {
 	t.Parallel()
 	mockSvc := &mocks.MockOrdersDataService{}
+	resolver := newTestCatalog(t)
+	pricer := newTestPricer(t, nil)
+	stock := newTestStock()
 	tests := []struct {
-		name    string
-		lgr     logger.Logger
-		svc     db.OrdersDataService
-		wantErr bool
+		name     string
+		lgr      logger.Logger
+		svc      db.OrdersDataService
+		resolver *catalog.Resolver
+		pricer   *pricing.Engine
+		stock    db.InventoryDataService
+		wantErr  bool
 	}{
 		{
-			name:    "success",
-			lgr:     lgr,
-			svc:     mockSvc,
-			wantErr: false,
+			name:     "success",
+			lgr:      lgr,
+			svc:      mockSvc,
+			resolver: resolver,
+			pricer:   pricer,
+			stock:    stock,
+			wantErr:  false,
 		},
 		{
-			name:    "nil logger",
-			lgr:     nil,
-			svc:     mockSvc,
-			wantErr: true,
+			name:     "nil inventory",
+			lgr:      lgr,
+			svc:      mockSvc,
+			resolver: resolver,
+			pricer:   pricer,
+			wantErr:  true,
+		},
+		{
+			name:     "nil pricer",
+			lgr:      lgr,
+			svc:      mockSvc,
+			resolver: resolver,
+			wantErr:  true,
 		},
 		{
-			name:    "nil service",
+			name:    "nil catalog",
 			lgr:     lgr,
-			svc:     nil,
+			svc:     mockSvc,
+			pricer:  pricer,
 			wantErr: true,
 		},
+		{
+			name:     "nil logger",
+			lgr:      nil,
+			svc:      mockSvc,
+			resolver: resolver,
+			pricer:   pricer,
+			wantErr:  true,
+		},
+		{
+			name:     "nil service",
+			lgr:      lgr,
+			svc:      nil,
+			resolver: resolver,
+			pricer:   pricer,
+			wantErr:  true,
+		},
 		{
 			name:    "nil logger and service",
 			lgr:     nil,
@@ -85,7 +185,7 @@ func TestNewOrdersHandler(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			t.Parallel()
-			h, err := handlers.NewOrdersHandler(tt.lgr, tt.svc)
+			h, err := handlers.NewOrdersHandler(tt.lgr, tt.svc, tt.resolver, tt.pricer, tt.stock)
 			if tt.wantErr {
 				require.Error(t, err)
 				assert.Nil(t, h)
@@ -99,6 +199,12 @@ func TestNewOrdersHandler(t *testing.T) {
 
 func TestOrdersHandler_Create(t *testing.T) {
 	t.Parallel()
+	address := &external.AddressInput{
+		Recipient: "Jane Doe", Line1: "1 Main St", City: "Springfield", PostalCode: "12345", Country: "US",
+	}
+	customer := &external.CustomerInput{Name: "Jane Doe", Email: "jane@example.com", Phone: "+14155550100"}
+	badAddress := *address
+	badAddress.Country = "USA"
 	tests := []struct {
 		name           string
 		input          external.OrderInput
@@ -109,30 +215,75 @@ func TestOrdersHandler_Create(t *testing.T) {
 		{
 			name: "Success",
 			input: external.OrderInput{
-				Products: []external.ProductInput{
-					{Name: "Product 1", Price: 10.0, Quantity: 2},
-				},
+				Products: []external.ProductInput{{SKU: "p-1", Quantity: 2}},
 			},
 			mockCreateFunc: func(_ context.Context, _ *data.Order) (string, error) {
-				return "1", nil
+				return createdOrderID, nil
 			},
 			expectedCode: http.StatusCreated,
 		},
 		{
-			name: "Invalid Input",
+			name: "Success With Shipping Details",
 			input: external.OrderInput{
-				Products: []external.ProductInput{
-					{Name: "", Price: 10.0, Quantity: 2},
-				},
+				Products:        []external.ProductInput{{SKU: "p-1", Quantity: 2}},
+				Customer:        customer,
+				ShippingAddress: address,
 			},
-			mockCreateFunc: func(_ context.Context, _ *data.Order) (string, error) {
-				return "", errors.New("invalid input")
+			mockCreateFunc: func(_ context.Context, o *data.Order) (string, error) {
+				assert.Equal(t, address.ToData(), o.ShippingAddress)
+				assert.Equal(t, customer.ToData(), o.Customer)
+				return createdOrderID, nil
 			},
-			expectedCode: http.StatusInternalServerError, // use this once code is updated properly http.StatusBadRequest,
+			expectedCode: http.StatusCreated,
+		},
+		{
+			name: "Invalid Shipping Country",
+			input: external.OrderInput{
+				Products:        []external.ProductInput{{SKU: "p-1", Quantity: 2}},
+				ShippingAddress: &badAddress,
+			},
+			expectedCode: http.StatusBadRequest,
 			expectedError: &external.APIError{
-				HTTPStatusCode: http.StatusInternalServerError,
-				ErrorCode:      errors2.OrderCreateServerError,
-				Message:        "unexpected Error occurred, please try again later",
+				HTTPStatusCode: http.StatusBadRequest,
+				ErrorCode:      errors2.OrderCreateInvalidInput,
+				Message:        "Invalid order request body",
+			},
+		},
+		{
+			name: "Invalid Customer Phone",
+			input: external.OrderInput{
+				Products: []external.ProductInput{{SKU: "p-1", Quantity: 2}},
+				Customer: &external.CustomerInput{Name: "Jane Doe", Phone: "555-0100"},
+			},
+			expectedCode: http.StatusBadRequest,
+			expectedError: &external.APIError{
+				HTTPStatusCode: http.StatusBadRequest,
+				ErrorCode:      errors2.OrderCreateInvalidInput,
+				Message:        "Invalid order request body",
+			},
+		},
+		{
+			name: "Quantity Above Max",
+			input: external.OrderInput{
+				Products: []external.ProductInput{{SKU: "p-1", Quantity: math.MaxUint64}},
+			},
+			expectedCode: http.StatusBadRequest,
+			expectedError: &external.APIError{
+				HTTPStatusCode: http.StatusBadRequest,
+				ErrorCode:      errors2.OrderCreateInvalidInput,
+				Message:        "Invalid order request body",
+			},
+		},
+		{
+			name: "Invalid Input",
+			input: external.OrderInput{
+				Products: []external.ProductInput{{SKU: "", Quantity: 2}},
+			},
+			expectedCode: http.StatusBadRequest,
+			expectedError: &external.APIError{
+				HTTPStatusCode: http.StatusBadRequest,
+				ErrorCode:      errors2.OrderCreateInvalidInput,
+				Message:        "Invalid order request body",
 			},
 		},
 		{
@@ -148,12 +299,60 @@ func TestOrdersHandler_Create(t *testing.T) {
 				Message:        "Invalid order request body",
 			},
 		},
+		{
+			name: "Unsupported Currency",
+			input: external.OrderInput{
+				Products: []external.ProductInput{{SKU: "P-1", Quantity: 2}},
+				Currency: "XYZ",
+			},
+			expectedCode: http.StatusBadRequest,
+			expectedError: &external.APIError{
+				HTTPStatusCode: http.StatusBadRequest,
+				ErrorCode:      errors2.OrderCreateInvalidInput,
+				Message:        "Invalid order currency",
+			},
+		},
+		{
+			name: "Currency Not Matching Catalog",
+			input: external.OrderInput{
+				Products: []external.ProductInput{{SKU: "P-1", Quantity: 2}},
+				Currency: "eur",
+			},
+			expectedCode: http.StatusBadRequest,
+			expectedError: &external.APIError{
+				HTTPStatusCode: http.StatusBadRequest,
+				ErrorCode:      errors2.OrderCreateInvalidProduct,
+				Message:        "invalid order line: P-1 is priced in USD, not EUR",
+			},
+		},
+		{
+			name: "Unknown SKU",
+			input: external.OrderInput{
+				Products: []external.ProductInput{{SKU: "P-1", Quantity: 1}, {SKU: "nope", Quantity: 1}},
+			},
+			expectedCode: http.StatusBadRequest,
+			expectedError: &external.APIError{
+				HTTPStatusCode: http.StatusBadRequest,
+				ErrorCode:      errors2.OrderCreateInvalidProduct,
+				Message:        "invalid order line: unknown sku NOPE",
+			},
+		},
+		{
+			name: "Inactive SKU",
+			input: external.OrderInput{
+				Products: []external.ProductInput{{SKU: "OLD-1", Quantity: 1}},
+			},
+			expectedCode: http.StatusBadRequest,
+			expectedError: &external.APIError{
+				HTTPStatusCode: http.StatusBadRequest,
+				ErrorCode:      errors2.OrderCreateInvalidProduct,
+				Message:        "invalid order line: sku OLD-1 is not available",
+			},
+		},
 		{
 			name: "Internal Server Error",
 			input: external.OrderInput{
-				Products: []external.ProductInput{
-					{Name: "Product 1", Price: 10.0, Quantity: 2},
-				},
+				Products: []external.ProductInput{{SKU: "P-1", Quantity: 2}},
 			},
 			mockCreateFunc: func(_ context.Context, _ *data.Order) (string, error) {
 				return "", errors.New(errors2.UnexpectedErrorMessage)
@@ -174,7 +373,7 @@ func TestOrdersHandler_Create(t *testing.T) {
 			c, r, recorder := setupTestContext()
 			handler, err := handlers.NewOrdersHandler(lgr, &mocks.MockOrdersDataService{
 				CreateFunc: tt.mockCreateFunc,
-			})
+			}, newTestCatalog(t), newTestPricer(t, nil), newTestStock())
 			if err != nil {
 				t.Errorf("failed to create orders handler")
 				return
@@ -201,23 +400,60 @@ func TestOrdersHandler_Create(t *testing.T) {
 				assert.Equal(t, int64(1), responseOrder.Version)
 				assert.NotNil(t, responseOrder.CreatedAt)
 				assert.NotNil(t, responseOrder.UpdatedAt)
-				assert.Equal(t, tt.input.Products[0].Name, responseOrder.Products[0].Name)
-				assert.InEpsilon(t, tt.input.Products[0].Price, responseOrder.Products[0].Price, 0)
+				assert.Equal(t, "P-1", responseOrder.Products[0].SKU)
+				assert.Equal(t, "Product 1", responseOrder.Products[0].Name)
+				assert.Equal(t, money.MustParse("10"), responseOrder.Products[0].Price)
 				assert.Equal(t, tt.input.Products[0].Quantity, responseOrder.Products[0].Quantity)
-				assert.Equal(t, "1", responseOrder.ID)
-				assert.InEpsilon(t, 20.0, responseOrder.TotalAmount, 0)
+				assert.Equal(t, createdOrderID, responseOrder.ID)
+				assert.Equal(t, money.MustParse("20"), responseOrder.TotalAmount)
+				assert.Equal(t, data.DefaultCurrency, responseOrder.Currency)
 				assert.Equal(t, data.OrderPending, responseOrder.Status)
+				assert.Equal(t, (*external.Address)(tt.input.ShippingAddress.ToData()), responseOrder.ShippingAddress)
+				assert.Equal(t, (*external.Customer)(tt.input.Customer.ToData()), responseOrder.Customer)
 			}
 		})
 	}
 }
 
+func TestOrdersHandler_CreateAuditsActor(t *testing.T) {
+	t.Parallel()
+	var entries []data.AuditEntry
+	svc, err := audit.NewOrdersService(lgr, &mocks.MockOrdersDataService{
+		CreateFunc: func(context.Context, *data.Order) (string, error) { return createdOrderID, nil },
+	}, &mocks.MockAuditDataService{
+		AppendFunc: func(_ context.Context, got ...data.AuditEntry) error {
+			entries = append(entries, got...)
+			return nil
+		},
+	})
+	require.NoError(t, err)
+	handler, err := handlers.NewOrdersHandler(lgr, svc, newTestCatalog(t), newTestPricer(t, nil), newTestStock())
+	require.NoError(t, err)
+	c, r, recorder := setupTestContext()
+	// as the server's router, handlers pass *gin.Context down to the audit service
+	r.ContextWithFallback = true
+	r.POST("/orders", middleware.ReqIDMiddleware(), middleware.AuthMiddleware(), handler.Create)
+
+	body, _ := json.Marshal(external.OrderInput{Products: []external.ProductInput{{SKU: "P-1", Quantity: 1}}})
+	c.Request, _ = http.NewRequest(http.MethodPost, "/orders", bytes.NewReader(body))
+	c.Request.Header.Set(middleware.APIKeyIDHeader, "key-1")
+	c.Request.Header.Set(middleware.RequestIdentifier, "req-1")
+	r.ServeHTTP(recorder, c.Request)
+
+	assert.Equal(t, http.StatusCreated, recorder.Code)
+	require.Len(t, entries, 1)
+	assert.Equal(t, data.AuditCreate, entries[0].Action)
+	assert.Equal(t, "apikey:key-1", entries[0].Actor)
+	assert.Equal(t, "req-1", entries[0].RequestID)
+	assert.Equal(t, createdOrderID, entries[0].OrderID.Hex())
+}
+
 func TestOrdersHandler_GetAll(t *testing.T) {
 	t.Parallel()
 	tests := []struct {
 		name           string
 		limit          string
-		mockGetAllFunc func(context.Context, int64) (*[]data.Order, error)
+		mockGetAllFunc func(context.Context, int64, db.ReadOptions) (*[]data.Order, error)
 		expectedCode   int
 		expectedError  *external.APIError
 		expectedLength int
@@ -225,7 +461,7 @@ func TestOrdersHandler_GetAll(t *testing.T) {
 		{
 			name:  "Success",
 			limit: "10",
-			mockGetAllFunc: func(_ context.Context, _ int64) (*[]data.Order, error) {
+			mockGetAllFunc: func(_ context.Context, _ int64, _ db.ReadOptions) (*[]data.Order, error) {
 				dataBytes, err := os.ReadFile("../mockData/orders.json")
 				if err != nil {
 					return nil, err
@@ -239,39 +475,40 @@ func TestOrdersHandler_GetAll(t *testing.T) {
 		{
 			name:  "DB Read Failure",
 			limit: "10",
-			mockGetAllFunc: func(_ context.Context, _ int64) (*[]data.Order, error) {
+			mockGetAllFunc: func(_ context.Context, _ int64, _ db.ReadOptions) (*[]data.Order, error) {
 				return nil, errors.New("db error")
 			},
 			expectedCode: http.StatusInternalServerError,
 			expectedError: &external.APIError{
 				HTTPStatusCode: http.StatusInternalServerError,
+				ErrorCode:      errors2.OrdersGetServerError,
 				Message:        errors2.UnexpectedErrorMessage,
 			},
 		},
 		{
 			name:  "Limit Out of Bounds",
 			limit: "10000",
-			mockGetAllFunc: func(_ context.Context, _ int64) (*[]data.Order, error) {
+			mockGetAllFunc: func(_ context.Context, _ int64, _ db.ReadOptions) (*[]data.Order, error) {
 				results := make([]data.Order, 10)
 				return &results, nil
 			},
 			expectedCode: http.StatusBadRequest,
 			expectedError: &external.APIError{
 				HTTPStatusCode: http.StatusBadRequest,
-				Message:        "Integer value within 1 and 100 is expected for limit query param",
+				ErrorCode:      errors2.RequestInvalid,
 			},
 		},
 		{
 			name:  "Invalid Limit",
 			limit: "ABC",
-			mockGetAllFunc: func(_ context.Context, _ int64) (*[]data.Order, error) {
+			mockGetAllFunc: func(_ context.Context, _ int64, _ db.ReadOptions) (*[]data.Order, error) {
 				results := make([]data.Order, 10)
 				return &results, nil
 			},
 			expectedCode: http.StatusBadRequest,
 			expectedError: &external.APIError{
 				HTTPStatusCode: http.StatusBadRequest,
-				Message:        "Integer value within 1 and 100 is expected for limit query param",
+				ErrorCode:      errors2.RequestInvalid,
 			},
 		},
 	}
@@ -283,12 +520,12 @@ func TestOrdersHandler_GetAll(t *testing.T) {
 			c, r, recorder := setupTestContext()
 			handler, err := handlers.NewOrdersHandler(lgr, &mocks.MockOrdersDataService{
 				GetAllFunc: tt.mockGetAllFunc,
-			})
+			}, newTestCatalog(t), newTestPricer(t, nil), newTestStock())
 			if err != nil {
 				t.Errorf("failed to create orders handler")
 				return
 			}
-			r.GET("/orders", handler.GetAll)
+			r.GET("/orders", parseQuery(external.OrderListQuery{}), handler.GetAll)
 
 			c.Request, _ = http.NewRequest(http.MethodGet, "/orders", nil)
 			q := c.Request.URL.Query()
@@ -304,7 +541,10 @@ func TestOrdersHandler_GetAll(t *testing.T) {
 				respBodyErr := json.Unmarshal(recorder.Body.Bytes(), &apiErr)
 				require.NoError(t, respBodyErr)
 				assert.Equal(t, tt.expectedError.HTTPStatusCode, apiErr.HTTPStatusCode)
-				assert.Equal(t, tt.expectedError.Message, apiErr.Message)
+				assert.Equal(t, tt.expectedError.ErrorCode, apiErr.ErrorCode)
+				if tt.expectedError.Message != "" {
+					assert.Equal(t, tt.expectedError.Message, apiErr.Message)
+				}
 			} else {
 				var respOrders []external.Order
 				respBodyErr := json.Unmarshal(recorder.Body.Bytes(), &respOrders)
@@ -320,22 +560,33 @@ func TestOrdersHandler_GetByID(t *testing.T) {
 	tests := []struct {
 		name            string
 		orderID         string
-		mockGetByIDFunc func(ctx context.Context, oID primitive.ObjectID) (*data.Order, error)
+		mockGetByIDFunc func(ctx context.Context, oID primitive.ObjectID, opts db.ReadOptions) (*data.Order, error)
 		expectedCode    int
 		expectedError   *external.APIError
 	}{
 		{
 			name:    "Success",
 			orderID: primitive.NewObjectID().Hex(),
-			mockGetByIDFunc: func(_ context.Context, oID primitive.ObjectID) (*data.Order, error) {
+			mockGetByIDFunc: func(_ context.Context, oID primitive.ObjectID, _ db.ReadOptions) (*data.Order, error) {
 				return &data.Order{ID: oID, Status: data.OrderPending}, nil
 			},
 			expectedCode: http.StatusOK,
 		},
+		{
+			name:    "Shipped Order Exposes Tracking",
+			orderID: primitive.NewObjectID().Hex(),
+			mockGetByIDFunc: func(_ context.Context, oID primitive.ObjectID, _ db.ReadOptions) (*data.Order, error) {
+				shippedAt := time.Now()
+				return &data.Order{ID: oID, Status: data.OrderShipped, Shipment: &data.Shipment{
+					Carrier: "UPS", TrackingID: "1Z999", ShippedAt: &shippedAt,
+				}}, nil
+			},
+			expectedCode: http.StatusOK,
+		},
 		{
 			name:    "Not Found",
 			orderID: primitive.NewObjectID().Hex(),
-			mockGetByIDFunc: func(_ context.Context, _ primitive.ObjectID) (*data.Order, error) {
+			mockGetByIDFunc: func(_ context.Context, _ primitive.ObjectID, _ db.ReadOptions) (*data.Order, error) {
 				return nil, errors.New("not found")
 			},
 			expectedCode: http.StatusInternalServerError,
@@ -347,7 +598,7 @@ func TestOrdersHandler_GetByID(t *testing.T) {
 		{
 			name:    "Zero Order Cannot be fetched",
 			orderID: primitive.NilObjectID.Hex(),
-			mockGetByIDFunc: func(_ context.Context, _ primitive.ObjectID) (*data.Order, error) {
+			mockGetByIDFunc: func(_ context.Context, _ primitive.ObjectID, _ db.ReadOptions) (*data.Order, error) {
 				return nil, errors.New("not found")
 			},
 			expectedCode: http.StatusBadRequest,
@@ -365,12 +616,12 @@ func TestOrdersHandler_GetByID(t *testing.T) {
 			c, r, recorder := setupTestContext()
 			handler, err := handlers.NewOrdersHandler(lgr, &mocks.MockOrdersDataService{
 				GetByIDFunc: tt.mockGetByIDFunc,
-			})
+			}, newTestCatalog(t), newTestPricer(t, nil), newTestStock())
 			if err != nil {
 				t.Errorf("failed to create orders handler")
 				return
 			}
-			r.GET("/orders/:id", handler.GetByID)
+			r.GET("/orders/:id", parseQuery(external.FieldsQuery{}), handler.GetByID)
 
 			c.Request, _ = http.NewRequest(http.MethodGet, "/orders/"+tt.orderID, nil)
 			r.ServeHTTP(recorder, c.Request)
@@ -388,6 +639,11 @@ func TestOrdersHandler_GetByID(t *testing.T) {
 				respBodyErr := json.Unmarshal(recorder.Body.Bytes(), &responseOrder)
 				require.NoError(t, respBodyErr)
 				assert.Equal(t, tt.orderID, responseOrder.ID)
+				if responseOrder.Status == data.OrderShipped {
+					require.NotNil(t, responseOrder.Shipment)
+					assert.Equal(t, "1Z999", responseOrder.Shipment.TrackingID)
+					assert.NotNil(t, responseOrder.Shipment.ShippedAt)
+				}
 			}
 		})
 	}
@@ -441,8 +697,11 @@ func TestOrdersHandler_Delete(t *testing.T) {
 			t.Parallel()
 			c, r, recorder := setupTestContext()
 			handler, err := handlers.NewOrdersHandler(lgr, &mocks.MockOrdersDataService{
+				GetByIDFunc: func(_ context.Context, id primitive.ObjectID, _ db.ReadOptions) (*data.Order, error) {
+					return &data.Order{ID: id, Status: data.OrderProcessing}, nil
+				},
 				DeleteByIDFunc: tt.mockDeleteFunc,
-			})
+			}, newTestCatalog(t), newTestPricer(t, nil), newTestStock())
 			if err != nil {
 				t.Errorf("failed to create orders handler")
 				return
@@ -464,3 +723,884 @@ func TestOrdersHandler_Delete(t *testing.T) {
 		})
 	}
 }
+
+func TestOrdersHandler_DeletePendingReleasesStock(t *testing.T) {
+	t.Parallel()
+	products := []data.Product{{SKU: "SAMPLE-1", Quantity: 2}}
+	tests := []struct {
+		name          string
+		status        data.OrderStatus
+		stockReserved bool
+		deleteErr     error
+		updateErr     error
+		wantCode      int
+		wantCancelled bool
+		wantReleased  bool
+	}{
+		{name: "pending with reserved stock", status: data.OrderPending, stockReserved: true,
+			wantCode: http.StatusNoContent, wantCancelled: true, wantReleased: true},
+		{name: "pending without reserved stock", status: data.OrderPending, wantCode: http.StatusNoContent},
+		{name: "processing", status: data.OrderProcessing, stockReserved: true, wantCode: http.StatusNoContent},
+		{name: "delete fails", status: data.OrderPending, stockReserved: true,
+			deleteErr: errors.New("db down"), wantCode: http.StatusInternalServerError},
+		{name: "cancel fails", status: data.OrderPending, stockReserved: true,
+			updateErr: errors.New("db down"), wantCode: http.StatusNoContent, wantCancelled: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			var cancelled, released, deleted bool
+			stock := newTestStock()
+			stock.ReleaseFunc = func(_ context.Context, got []data.Product) error {
+				released = true
+				assert.Equal(t, products, got)
+				return nil
+			}
+			svc := &mocks.MockOrdersDataService{
+				DeleteByIDFunc: func(context.Context, primitive.ObjectID) error {
+					deleted = tt.deleteErr == nil
+					return tt.deleteErr
+				},
+				GetByIDFunc: func(_ context.Context, id primitive.ObjectID, opts db.ReadOptions) (*data.Order, error) {
+					// the order is read once deleted
+					assert.True(t, deleted)
+					assert.True(t, opts.IncludeDeleted)
+					return &data.Order{
+						ID: id, Version: 1, Status: tt.status, StockReserved: tt.stockReserved, Products: products,
+					}, nil
+				},
+				UpdateFunc: func(_ context.Context, o *data.Order) error {
+					cancelled = true
+					assert.Equal(t, data.OrderCancelled, o.Status)
+					assert.Equal(t, int64(2), o.Version)
+					require.Len(t, o.Updates, 1)
+					assert.Equal(t, "order deleted", o.Updates[0].Notes)
+					return tt.updateErr
+				},
+			}
+			c, r, recorder := setupTestContext()
+			handler, err := handlers.NewOrdersHandler(lgr, svc, newTestCatalog(t), newTestPricer(t, nil), stock)
+			require.NoError(t, err)
+			r.DELETE("/orders/:id", handler.DeleteByID)
+
+			c.Request, _ = http.NewRequest(http.MethodDelete, "/orders/"+primitive.NewObjectID().Hex(), nil)
+			r.ServeHTTP(recorder, c.Request)
+
+			assert.Equal(t, tt.wantCode, recorder.Code)
+			assert.Equal(t, tt.wantCancelled, cancelled)
+			assert.Equal(t, tt.wantReleased, released)
+		})
+	}
+}
+
+func TestOrdersHandler_RestoreDeletedPendingOrder(t *testing.T) {
+	t.Parallel()
+	id := primitive.NewObjectID()
+	stored := &data.Order{ID: id, Version: 1, Status: data.OrderPending, StockReserved: true,
+		Products: []data.Product{{SKU: "SAMPLE-1", Quantity: 2}}}
+	var released bool
+	stock := newTestStock()
+	stock.ReleaseFunc = func(context.Context, []data.Product) error {
+		released = true
+		return nil
+	}
+	svc := &mocks.MockOrdersDataService{
+		DeleteByIDFunc: func(context.Context, primitive.ObjectID) error {
+			now := time.Now()
+			stored.DeletedAt = &now
+			return nil
+		},
+		GetByIDFunc: func(context.Context, primitive.ObjectID, db.ReadOptions) (*data.Order, error) {
+			order := *stored
+			return &order, nil
+		},
+		UpdateFunc: func(_ context.Context, o *data.Order) error {
+			stored = o
+			return nil
+		},
+		RestoreFunc: func(context.Context, primitive.ObjectID) error {
+			stored.DeletedAt = nil
+			return nil
+		},
+	}
+	handler, err := handlers.NewOrdersHandler(lgr, svc, newTestCatalog(t), newTestPricer(t, nil), stock)
+	require.NoError(t, err)
+	_, r, _ := setupTestContext()
+	r.DELETE("/orders/:id", handler.DeleteByID)
+	r.POST("/orders/:id", handler.Action)
+
+	for _, req := range []*http.Request{
+		httptest.NewRequest(http.MethodDelete, "/orders/"+id.Hex(), nil),
+		httptest.NewRequest(http.MethodPost, "/orders/"+id.Hex()+":restore", nil),
+	} {
+		recorder := httptest.NewRecorder()
+		r.ServeHTTP(recorder, req)
+		require.Equal(t, http.StatusNoContent, recorder.Code)
+	}
+
+	// the stock was given back on delete, the restored order comes back cancelled
+	assert.True(t, released)
+	assert.Nil(t, stored.DeletedAt)
+	assert.Equal(t, data.OrderCancelled, stored.Status)
+}
+
+func TestOrdersHandler_Action(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name            string
+		path            string
+		mockRestoreFunc func(ctx context.Context, id primitive.ObjectID) error
+		expectedCode    int
+		expectedError   *external.APIError
+	}{
+		{
+			name: "Restore success",
+			path: primitive.NewObjectID().Hex() + ":restore",
+			mockRestoreFunc: func(_ context.Context, _ primitive.ObjectID) error {
+				return nil
+			},
+			expectedCode: http.StatusNoContent,
+		},
+		{
+			name: "Restore not deleted",
+			path: primitive.NewObjectID().Hex() + ":restore",
+			mockRestoreFunc: func(_ context.Context, _ primitive.ObjectID) error {
+				return db.ErrPOIDNotFound
+			},
+			expectedCode: http.StatusNotFound,
+			expectedError: &external.APIError{
+				HTTPStatusCode: http.StatusNotFound,
+				ErrorCode:      errors2.OrderRestoreNotFound,
+				Message:        "no deleted order found with given id",
+			},
+		},
+		{
+			name: "Restore failure",
+			path: primitive.NewObjectID().Hex() + ":restore",
+			mockRestoreFunc: func(_ context.Context, _ primitive.ObjectID) error {
+				return db.ErrUnexpectedRestoreOrder
+			},
+			expectedCode: http.StatusInternalServerError,
+			expectedError: &external.APIError{
+				HTTPStatusCode: http.StatusInternalServerError,
+				ErrorCode:      errors2.OrderRestoreServerError,
+				Message:        "could not restore order",
+			},
+		},
+		{
+			name:         "Invalid ID",
+			path:         "abc:restore",
+			expectedCode: http.StatusBadRequest,
+			expectedError: &external.APIError{
+				HTTPStatusCode: http.StatusBadRequest,
+				ErrorCode:      errors2.OrderRestoreInvalidID,
+				Message:        "invalid order ID",
+			},
+		},
+		{
+			name:         "Unsupported action",
+			path:         primitive.NewObjectID().Hex() + ":archive",
+			expectedCode: http.StatusNotFound,
+			expectedError: &external.APIError{
+				HTTPStatusCode: http.StatusNotFound,
+				ErrorCode:      errors2.OrderActionNotSupported,
+				Message:        "unsupported order action",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			c, r, recorder := setupTestContext()
+			handler, err := handlers.NewOrdersHandler(lgr, &mocks.MockOrdersDataService{
+				RestoreFunc: tt.mockRestoreFunc,
+			}, newTestCatalog(t), newTestPricer(t, nil), newTestStock())
+			require.NoError(t, err)
+			r.POST("/orders/:id", handler.Action)
+
+			c.Request, _ = http.NewRequest(http.MethodPost, "/orders/"+tt.path, nil)
+			r.ServeHTTP(recorder, c.Request)
+
+			assert.Equal(t, tt.expectedCode, recorder.Code)
+			if tt.expectedError != nil {
+				var apiErr external.APIError
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &apiErr))
+				assert.Equal(t, tt.expectedError.ErrorCode, apiErr.ErrorCode)
+				assert.Equal(t, tt.expectedError.Message, apiErr.Message)
+			}
+		})
+	}
+}
+
+func TestOrdersHandler_IncludeDeleted(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name         string
+		query        string
+		expectedCode int
+		wantOpts     db.ReadOptions
+	}{
+		{name: "default excludes deleted", expectedCode: http.StatusOK},
+		{name: "include deleted", query: "?includeDeleted=true", expectedCode: http.StatusOK,
+			wantOpts: db.ReadOptions{IncludeDeleted: true}},
+		{name: "invalid value", query: "?includeDeleted=yes-please", expectedCode: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			var listOpts, getOpts db.ReadOptions
+			c, r, recorder := setupTestContext()
+			handler, err := handlers.NewOrdersHandler(lgr, &mocks.MockOrdersDataService{
+				GetAllFunc: func(_ context.Context, _ int64, opts db.ReadOptions) (*[]data.Order, error) {
+					listOpts = opts
+					return &[]data.Order{}, nil
+				},
+				GetByIDFunc: func(_ context.Context, oID primitive.ObjectID, opts db.ReadOptions) (*data.Order, error) {
+					getOpts = opts
+					return &data.Order{ID: oID}, nil
+				},
+			}, newTestCatalog(t), newTestPricer(t, nil), newTestStock())
+			require.NoError(t, err)
+			r.GET("/orders", parseQuery(external.AdminOrderListQuery{}), handler.GetAll)
+			r.GET("/orders/:id", parseQuery(external.AdminOrderQuery{}), handler.GetByID)
+
+			c.Request, _ = http.NewRequest(http.MethodGet, "/orders"+tt.query, nil)
+			r.ServeHTTP(recorder, c.Request)
+			assert.Equal(t, tt.expectedCode, recorder.Code)
+			assert.Equal(t, tt.wantOpts, listOpts)
+
+			recorder = httptest.NewRecorder()
+			c.Request, _ = http.NewRequest(http.MethodGet, "/orders/"+primitive.NewObjectID().Hex()+tt.query, nil)
+			r.ServeHTTP(recorder, c.Request)
+			assert.Equal(t, tt.expectedCode, recorder.Code)
+			assert.Equal(t, tt.wantOpts, getOpts)
+		})
+	}
+}
+
+func TestOrdersHandler_Offset(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name       string
+		schema     any
+		query      string
+		wantLimit  int64
+		wantOffset int64
+	}{
+		{name: "default", schema: external.OrderListQuery{}, wantLimit: 100},
+		{name: "offset", schema: external.OrderListQuery{}, query: "?limit=10&offset=20", wantLimit: 10, wantOffset: 20},
+		{name: "internal", schema: external.AdminOrderListQuery{}, query: "?offset=5", wantLimit: 100, wantOffset: 5},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			var gotLimit int64
+			var gotOpts db.ReadOptions
+			c, r, recorder := setupTestContext()
+			handler, err := handlers.NewOrdersHandler(lgr, &mocks.MockOrdersDataService{
+				GetAllFunc: func(_ context.Context, limit int64, opts db.ReadOptions) (*[]data.Order, error) {
+					gotLimit, gotOpts = limit, opts
+					return &[]data.Order{}, nil
+				},
+			}, newTestCatalog(t), newTestPricer(t, nil), newTestStock())
+			require.NoError(t, err)
+			r.GET("/orders", parseQuery(tt.schema), handler.GetAll)
+
+			c.Request, _ = http.NewRequest(http.MethodGet, "/orders"+tt.query, nil)
+			r.ServeHTTP(recorder, c.Request)
+			assert.Equal(t, http.StatusOK, recorder.Code)
+			assert.Equal(t, tt.wantLimit, gotLimit)
+			assert.Equal(t, tt.wantOffset, gotOpts.Offset)
+		})
+	}
+}
+
+func TestOrdersHandler_SameShapeOnEveryRead(t *testing.T) {
+	t.Parallel()
+	created := time.Date(2025, time.March, 14, 9, 26, 53, 589_000_000, time.UTC)
+	order := data.Order{
+		ID:        primitive.NewObjectID(),
+		CreatedAt: created,
+		UpdatedAt: created,
+		User:      "jane@example.com",
+		Status:    data.OrderPending,
+		Products:  []data.Product{{SKU: "P-1", Name: "Widget", Price: money.MustParse("19.99"), Quantity: 1}},
+		Updates:   []data.OrderUpdate{{UpdatedAt: created, Notes: "placed"}},
+	}
+	c, r, recorder := setupTestContext()
+	handler, err := handlers.NewOrdersHandler(lgr, &mocks.MockOrdersDataService{
+		GetAllFunc: func(_ context.Context, _ int64, _ db.ReadOptions) (*[]data.Order, error) {
+			return &[]data.Order{order}, nil
+		},
+		GetByIDFunc: func(_ context.Context, _ primitive.ObjectID, _ db.ReadOptions) (*data.Order, error) {
+			return &order, nil
+		},
+		GetByUserFunc: func(_ context.Context, _ string, _ int64) (*[]data.Order, error) {
+			return &[]data.Order{order}, nil
+		},
+	}, newTestCatalog(t), newTestPricer(t, nil), newTestStock())
+	require.NoError(t, err)
+	r.GET("/orders", parseQuery(external.OrderListQuery{}), handler.GetAll)
+	r.GET("/orders/:id", parseQuery(external.FieldsQuery{}), handler.GetByID)
+	r.GET("/users/:user/orders", parseQuery(external.LimitQuery{}), handler.GetByUser)
+
+	c.Request, _ = http.NewRequest(http.MethodGet, "/orders/"+order.ID.Hex(), nil)
+	r.ServeHTTP(recorder, c.Request)
+	require.Equal(t, http.StatusOK, recorder.Code)
+	single := recorder.Body.String()
+	assert.Contains(t, single, `"createdAt":"2025-03-14T09:26:53Z"`)
+
+	for _, path := range []string{"/orders", "/users/jane@example.com/orders"} {
+		recorder = httptest.NewRecorder()
+		c.Request, _ = http.NewRequest(http.MethodGet, path, nil)
+		r.ServeHTTP(recorder, c.Request)
+		require.Equal(t, http.StatusOK, recorder.Code)
+		assert.JSONEq(t, "["+single+"]", recorder.Body.String(), path)
+	}
+}
+
+func TestOrdersHandler_SparseFields(t *testing.T) {
+	t.Parallel()
+	order := data.Order{
+		ID:          primitive.NewObjectID(),
+		Status:      data.OrderPending,
+		TotalAmount: money.MustParse("19.99"),
+		Products:    []data.Product{{Name: "Widget", Price: money.MustParse("19.99"), Quantity: 1}},
+	}
+	tests := []struct {
+		name         string
+		query        string
+		expectedCode int
+		wantFields   []string
+		wantKeys     []string
+	}{
+		{
+			name:         "selected fields",
+			query:        "?fields=orderId,status,totalAmount,status",
+			expectedCode: http.StatusOK,
+			wantFields:   []string{"_id", "status", "totalAmount"},
+			wantKeys:     []string{"orderId", "status", "totalAmount"},
+		},
+		{name: "unknown field", query: "?fields=orderId,deletedAt", expectedCode: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			var listOpts, getOpts db.ReadOptions
+			c, r, recorder := setupTestContext()
+			handler, err := handlers.NewOrdersHandler(lgr, &mocks.MockOrdersDataService{
+				GetAllFunc: func(_ context.Context, _ int64, opts db.ReadOptions) (*[]data.Order, error) {
+					listOpts = opts
+					return &[]data.Order{order}, nil
+				},
+				GetByIDFunc: func(_ context.Context, _ primitive.ObjectID, opts db.ReadOptions) (*data.Order, error) {
+					getOpts = opts
+					return &order, nil
+				},
+			}, newTestCatalog(t), newTestPricer(t, nil), newTestStock())
+			require.NoError(t, err)
+			r.GET("/orders", parseQuery(external.OrderListQuery{}), handler.GetAll)
+			r.GET("/orders/:id", parseQuery(external.FieldsQuery{}), handler.GetByID)
+
+			c.Request, _ = http.NewRequest(http.MethodGet, "/orders"+tt.query, nil)
+			r.ServeHTTP(recorder, c.Request)
+			assert.Equal(t, tt.expectedCode, recorder.Code)
+			assert.Equal(t, tt.wantFields, listOpts.Fields)
+			if tt.expectedCode == http.StatusOK {
+				var list []map[string]any
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &list))
+				require.Len(t, list, 1)
+				assert.ElementsMatch(t, tt.wantKeys, slices.Collect(maps.Keys(list[0])))
+				assert.Equal(t, "19.99", list[0]["totalAmount"])
+			}
+
+			recorder = httptest.NewRecorder()
+			c.Request, _ = http.NewRequest(http.MethodGet, "/orders/"+order.ID.Hex()+tt.query, nil)
+			r.ServeHTTP(recorder, c.Request)
+			assert.Equal(t, tt.expectedCode, recorder.Code)
+			assert.Equal(t, tt.wantFields, getOpts.Fields)
+			if tt.expectedCode == http.StatusOK {
+				var got map[string]any
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &got))
+				assert.ElementsMatch(t, tt.wantKeys, slices.Collect(maps.Keys(got)))
+				assert.Equal(t, order.ID.Hex(), got["orderId"])
+			}
+		})
+	}
+}
+
+func TestOrdersHandler_CreatePricing(t *testing.T) {
+	t.Parallel()
+	activeCoupon := func(_ context.Context, code string) (*data.Coupon, error) {
+		if code != "SAVE10" {
+			return nil, db.ErrCouponNotFound
+		}
+		return &data.Coupon{
+			Code:       "SAVE10",
+			Kind:       data.DiscountPercent,
+			Value:      money.MustParse("10"),
+			ValidFrom:  time.Now().Add(-time.Hour),
+			ValidUntil: time.Now().Add(time.Hour),
+		}, nil
+	}
+	products := []external.ProductInput{{SKU: "P-1", Quantity: 2}}
+
+	tests := []struct {
+		name          string
+		input         external.OrderInput
+		getByCodeErr  error
+		redeemErr     error
+		createErr     error
+		expectedCode  int
+		expectedError string
+		wantPricing   data.Pricing
+		wantReleased  bool
+		wantRestocked bool
+	}{
+		{
+			name:         "tax without coupon",
+			input:        external.OrderInput{Products: products, Region: "tx"},
+			expectedCode: http.StatusCreated,
+			wantPricing: data.Pricing{
+				Subtotal: money.MustParse("20"), Tax: money.MustParse("2"), Total: money.MustParse("22"), TaxRegion: "tx",
+			},
+		},
+		{
+			name:         "coupon and tax",
+			input:        external.OrderInput{Products: products, Region: "TX", CouponCode: "SAVE10"},
+			expectedCode: http.StatusCreated,
+			wantPricing: data.Pricing{
+				Subtotal:   money.MustParse("20"),
+				Discount:   money.MustParse("2"),
+				Tax:        money.MustParse("1.8"),
+				Total:      money.MustParse("19.8"),
+				CouponCode: "SAVE10",
+				TaxRegion:  "TX",
+			},
+		},
+		{
+			name:          "unknown coupon",
+			input:         external.OrderInput{Products: products, CouponCode: "NOPE"},
+			expectedCode:  http.StatusBadRequest,
+			expectedError: errors2.OrderCreateInvalidCoupon,
+		},
+		{
+			name:          "coupon used up while ordering",
+			input:         external.OrderInput{Products: products, CouponCode: "SAVE10"},
+			redeemErr:     db.ErrCouponNotRedeemable,
+			expectedCode:  http.StatusBadRequest,
+			expectedError: errors2.OrderCreateInvalidCoupon,
+			wantRestocked: true,
+		},
+		{
+			name:          "coupon lookup failure",
+			input:         external.OrderInput{Products: products, CouponCode: "SAVE10"},
+			getByCodeErr:  db.ErrUnexpectedGetCoupon,
+			expectedCode:  http.StatusInternalServerError,
+			expectedError: errors2.OrderCreateServerError,
+		},
+		{
+			name:          "coupon released when order is not saved",
+			input:         external.OrderInput{Products: products, CouponCode: "SAVE10"},
+			createErr:     db.ErrFailedToCreateOrder,
+			expectedCode:  http.StatusInternalServerError,
+			expectedError: errors2.OrderCreateServerError,
+			wantReleased:  true,
+			wantRestocked: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			released := false
+			coupons := &mocks.MockCouponsDataService{
+				GetByCodeFunc: func(ctx context.Context, code string) (*data.Coupon, error) {
+					if tt.getByCodeErr != nil {
+						return nil, tt.getByCodeErr
+					}
+					return activeCoupon(ctx, code)
+				},
+				RedeemFunc: func(_ context.Context, _ string, _ time.Time) error { return tt.redeemErr },
+				ReleaseFunc: func(_ context.Context, _ string) error {
+					released = true
+					return nil
+				},
+			}
+			restocked := false
+			stock := newTestStock()
+			stock.ReleaseFunc = func(context.Context, []data.Product) error {
+				restocked = true
+				return nil
+			}
+			var saved *data.Order
+			handler, err := handlers.NewOrdersHandler(lgr, &mocks.MockOrdersDataService{
+				CreateFunc: func(_ context.Context, o *data.Order) (string, error) {
+					saved = o
+					return createdOrderID, tt.createErr
+				},
+			}, newTestCatalog(t), newTestPricer(t, coupons), stock)
+			require.NoError(t, err)
+
+			c, r, recorder := setupTestContext()
+			r.POST("/orders", handler.Create)
+			body, _ := json.Marshal(tt.input)
+			c.Request, _ = http.NewRequest(http.MethodPost, "/orders", bytes.NewReader(body))
+			r.ServeHTTP(recorder, c.Request)
+
+			assert.Equal(t, tt.expectedCode, recorder.Code)
+			assert.Equal(t, tt.wantReleased, released)
+			assert.Equal(t, tt.wantRestocked, restocked)
+			if tt.expectedError != "" {
+				var apiErr external.APIError
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &apiErr))
+				assert.Equal(t, tt.expectedError, apiErr.ErrorCode)
+				return
+			}
+			require.NotNil(t, saved)
+			assert.True(t, saved.StockReserved)
+			assert.Equal(t, tt.wantPricing, *saved.Pricing)
+			assert.Equal(t, tt.wantPricing.Total, saved.TotalAmount)
+
+			var responseOrder external.Order
+			require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &responseOrder))
+			require.NotNil(t, responseOrder.Pricing)
+			assert.Equal(t, external.Pricing(tt.wantPricing), *responseOrder.Pricing)
+		})
+	}
+}
+
+func TestOrdersHandler_CreateInsufficientStock(t *testing.T) {
+	t.Parallel()
+	short := []data.ShortItem{{SKU: "P-1", Requested: 2, Available: 1}}
+	tests := []struct {
+		name          string
+		reserveErr    error
+		expectedCode  int
+		expectedError string
+	}{
+		{
+			name:          "short items",
+			reserveErr:    &db.InsufficientStockError{Items: short},
+			expectedCode:  http.StatusConflict,
+			expectedError: errors2.OrderCreateInsufficientStock,
+		},
+		{
+			name:          "inventory failure",
+			reserveErr:    db.ErrUnexpectedReserveStock,
+			expectedCode:  http.StatusInternalServerError,
+			expectedError: errors2.OrderCreateServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			stock := newTestStock()
+			stock.ReserveFunc = func(_ context.Context, products []data.Product) error {
+				assert.Equal(t, "P-1", products[0].SKU)
+				return tt.reserveErr
+			}
+			handler, err := handlers.NewOrdersHandler(lgr, &mocks.MockOrdersDataService{
+				CreateFunc: func(context.Context, *data.Order) (string, error) {
+					t.Error("order must not be created without stock")
+					return "", nil
+				},
+			}, newTestCatalog(t), newTestPricer(t, nil), stock)
+			require.NoError(t, err)
+
+			c, r, recorder := setupTestContext()
+			r.POST("/orders", handler.Create)
+			body, _ := json.Marshal(external.OrderInput{Products: []external.ProductInput{{SKU: "P-1", Quantity: 2}}})
+			c.Request, _ = http.NewRequest(http.MethodPost, "/orders", bytes.NewReader(body))
+			r.ServeHTTP(recorder, c.Request)
+
+			assert.Equal(t, tt.expectedCode, recorder.Code)
+			var apiErr struct {
+				ErrorCode string           `json:"errorCode"`
+				Message   string           `json:"message"`
+				Details   []data.ShortItem `json:"details"`
+			}
+			require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &apiErr))
+			assert.Equal(t, tt.expectedError, apiErr.ErrorCode)
+			if tt.expectedCode == http.StatusConflict {
+				assert.Equal(t, short, apiErr.Details)
+				assert.Equal(t, "insufficient stock: P-1 (requested 2, available 1)", apiErr.Message)
+			}
+		})
+	}
+}
+
+func TestOrdersHandler_Transition(t *testing.T) {
+	t.Parallel()
+	id := primitive.NewObjectID()
+	lines := []data.Product{{SKU: "P-1", Quantity: 2}}
+	tests := []struct {
+		name          string
+		path          string
+		body          string
+		transitionErr error
+		reserved      bool
+		expectedCode  int
+		expectedError string
+		wantRestocked bool
+		wantShipment  *data.Shipment
+	}{
+		{
+			name:         "ship with tracking",
+			path:         id.Hex() + ":transition",
+			body:         `{"status":"OrderShipped","shipment":{"carrier":"UPS","trackingId":"1Z999"}}`,
+			reserved:     true,
+			expectedCode: http.StatusOK,
+			wantShipment: &data.Shipment{Carrier: "UPS", TrackingID: "1Z999"},
+		},
+		{
+			name:          "ship without tracking",
+			path:          id.Hex() + ":transition",
+			body:          `{"status":"OrderShipped"}`,
+			expectedCode:  http.StatusBadRequest,
+			expectedError: errors2.OrderTransitionInvalidInput,
+		},
+		{
+			name:          "ship without carrier",
+			path:          id.Hex() + ":transition",
+			body:          `{"status":"OrderShipped","shipment":{"trackingId":"1Z999"}}`,
+			expectedCode:  http.StatusBadRequest,
+			expectedError: errors2.OrderTransitionInvalidInput,
+		},
+		{
+			name:          "shipment on another status",
+			path:          id.Hex() + ":transition",
+			body:          `{"status":"OrderProcessing","shipment":{"carrier":"UPS","trackingId":"1Z999"}}`,
+			expectedCode:  http.StatusBadRequest,
+			expectedError: errors2.OrderTransitionInvalidInput,
+		},
+		{
+			name:          "cancel releases reserved stock",
+			path:          id.Hex() + ":transition",
+			body:          `{"status":"OrderCancelled","notes":"changed my mind"}`,
+			reserved:      true,
+			expectedCode:  http.StatusOK,
+			wantRestocked: true,
+		},
+		{
+			name:         "cancel of an order without reservation",
+			path:         id.Hex() + ":transition",
+			body:         `{"status":"OrderCancelled"}`,
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "other transitions keep the stock",
+			path:         id.Hex() + ":transition",
+			body:         `{"status":"OrderProcessing"}`,
+			reserved:     true,
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:          "unknown status",
+			path:          id.Hex() + ":transition",
+			body:          `{"status":"OrderLost"}`,
+			expectedCode:  http.StatusBadRequest,
+			expectedError: errors2.OrderTransitionInvalidInput,
+		},
+		{
+			name:          "invalid id",
+			path:          "nope:transition",
+			body:          `{"status":"OrderCancelled"}`,
+			expectedCode:  http.StatusBadRequest,
+			expectedError: errors2.OrderTransitionInvalidInput,
+		},
+		{
+			name:          "not found",
+			path:          id.Hex() + ":transition",
+			body:          `{"status":"OrderCancelled"}`,
+			transitionErr: db.ErrPOIDNotFound,
+			expectedCode:  http.StatusNotFound,
+			expectedError: errors2.OrderTransitionNotFound,
+		},
+		{
+			name:          "not allowed from current status",
+			path:          id.Hex() + ":transition",
+			body:          `{"status":"OrderCancelled"}`,
+			transitionErr: db.ErrInvalidTransition,
+			expectedCode:  http.StatusConflict,
+			expectedError: errors2.OrderTransitionConflict,
+		},
+		{
+			name:          "db failure",
+			path:          id.Hex() + ":transition",
+			body:          `{"status":"OrderCancelled"}`,
+			transitionErr: db.ErrUnexpectedTransition,
+			expectedCode:  http.StatusInternalServerError,
+			expectedError: errors2.OrderTransitionServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			var restocked []data.Product
+			stock := newTestStock()
+			stock.ReleaseFunc = func(_ context.Context, products []data.Product) error {
+				restocked = products
+				return nil
+			}
+			handler, err := handlers.NewOrdersHandler(lgr, &mocks.MockOrdersDataService{
+				TransitionFunc: func(
+					_ context.Context, oID primitive.ObjectID, to data.OrderStatus, note data.OrderUpdate,
+					shipment *data.Shipment,
+				) (*data.Order, error) {
+					if tt.transitionErr != nil {
+						return nil, tt.transitionErr
+					}
+					assert.Equal(t, id, oID)
+					assert.Equal(t, "anonymous", note.HandledBy)
+					assert.Equal(t, tt.wantShipment, shipment)
+					order := &data.Order{ID: oID, Status: to, Products: lines, StockReserved: tt.reserved}
+					order.Shipment = shipment
+					return order, nil
+				},
+			}, newTestCatalog(t), newTestPricer(t, nil), stock)
+			require.NoError(t, err)
+
+			c, r, recorder := setupTestContext()
+			r.POST("/orders/:id", handler.Action)
+			c.Request, _ = http.NewRequest(http.MethodPost, "/orders/"+tt.path, strings.NewReader(tt.body))
+			r.ServeHTTP(recorder, c.Request)
+
+			assert.Equal(t, tt.expectedCode, recorder.Code)
+			if tt.expectedError != "" {
+				var apiErr external.APIError
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &apiErr))
+				assert.Equal(t, tt.expectedError, apiErr.ErrorCode)
+				return
+			}
+			if tt.wantRestocked {
+				assert.Equal(t, lines, restocked)
+			} else {
+				assert.Nil(t, restocked)
+			}
+			var order data.Order
+			require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &order))
+			assert.Equal(t, tt.wantShipment, order.Shipment)
+		})
+	}
+}
+
+func TestOrdersHandler_GetByUser(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name          string
+		path          string
+		getErr        error
+		expectedCode  int
+		expectedError string
+		wantLimit     int64
+	}{
+		{name: "success", path: "/users/jane@example.com/orders?limit=5", expectedCode: http.StatusOK, wantLimit: 5},
+		{name: "default limit", path: "/users/jane@example.com/orders", expectedCode: http.StatusOK,
+			wantLimit: db.DefaultPageSize},
+		{name: "blank user", path: "/users/%20/orders", expectedCode: http.StatusBadRequest,
+			expectedError: errors2.UserOrdersInvalidParams},
+		{name: "user too long", path: "/users/" + strings.Repeat("a", 255) + "/orders",
+			expectedCode: http.StatusBadRequest, expectedError: errors2.UserOrdersInvalidParams},
+		{name: "invalid limit", path: "/users/jane@example.com/orders?limit=0", expectedCode: http.StatusBadRequest},
+		{name: "max limit", path: "/users/jane@example.com/orders?limit=" + strconv.Itoa(handlers.MaxPageSize),
+			expectedCode: http.StatusOK, wantLimit: handlers.MaxPageSize},
+		{name: "limit above max", path: "/users/jane@example.com/orders?limit=" + strconv.Itoa(handlers.MaxPageSize+1),
+			expectedCode: http.StatusBadRequest, expectedError: errors2.RequestInvalid},
+		{name: "db failure", path: "/users/jane@example.com/orders", getErr: db.ErrUnexpectedGetOrder,
+			expectedCode: http.StatusInternalServerError, expectedError: errors2.UserOrdersServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			handler, err := handlers.NewOrdersHandler(lgr, &mocks.MockOrdersDataService{
+				GetByUserFunc: func(_ context.Context, user string, limit int64) (*[]data.Order, error) {
+					if tt.getErr != nil {
+						return nil, tt.getErr
+					}
+					assert.Equal(t, "jane@example.com", user)
+					assert.Equal(t, tt.wantLimit, limit)
+					return &[]data.Order{{ID: primitive.NewObjectID(), User: user, Status: data.OrderPending}}, nil
+				},
+			}, newTestCatalog(t), newTestPricer(t, nil), newTestStock())
+			require.NoError(t, err)
+
+			c, r, recorder := setupTestContext()
+			r.GET("/users/:user/orders", parseQuery(external.LimitQuery{}), handler.GetByUser)
+			c.Request, _ = http.NewRequest(http.MethodGet, tt.path, nil)
+			r.ServeHTTP(recorder, c.Request)
+
+			assert.Equal(t, tt.expectedCode, recorder.Code)
+			if tt.expectedError != "" {
+				assertAPIError(t, recorder.Body.Bytes(), tt.expectedError)
+				return
+			}
+			if tt.expectedCode == http.StatusOK {
+				var orders []external.Order
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &orders))
+				require.Len(t, orders, 1)
+				assert.Equal(t, "jane@example.com", orders[0].User)
+			}
+		})
+	}
+}
+
+func TestOrdersHandler_GetUserSummary(t *testing.T) {
+	t.Parallel()
+	last := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	tests := []struct {
+		name          string
+		summaryErr    error
+		expectedCode  int
+		expectedError string
+	}{
+		{name: "success", expectedCode: http.StatusOK},
+		{name: "db failure", summaryErr: db.ErrUnexpectedSummarize, expectedCode: http.StatusInternalServerError,
+			expectedError: errors2.UserSummaryServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			handler, err := handlers.NewOrdersHandler(lgr, &mocks.MockOrdersDataService{
+				SummarizeUserFunc: func(_ context.Context, user string) (*data.CustomerSummary, error) {
+					if tt.summaryErr != nil {
+						return nil, tt.summaryErr
+					}
+					return &data.CustomerSummary{
+						User:          user,
+						OrderCount:    3,
+						LifetimeSpend: map[money.Currency]money.Amount{money.USD: money.MustParse("42.5")},
+						LastOrderAt:   &last,
+						StatusCounts:  map[data.OrderStatus]int64{data.OrderDelivered: 3},
+					}, nil
+				},
+			}, newTestCatalog(t), newTestPricer(t, nil), newTestStock())
+			require.NoError(t, err)
+
+			c, r, recorder := setupTestContext()
+			r.GET("/users/:user/orders/summary", handler.GetUserSummary)
+			c.Request, _ = http.NewRequest(http.MethodGet, "/users/jane@example.com/orders/summary", nil)
+			r.ServeHTTP(recorder, c.Request)
+
+			assert.Equal(t, tt.expectedCode, recorder.Code)
+			if tt.expectedError != "" {
+				assertAPIError(t, recorder.Body.Bytes(), tt.expectedError)
+				return
+			}
+			assert.JSONEq(t, `{
+				"user": "jane@example.com",
+				"orderCount": 3,
+				"lifetimeSpend": {"USD": "42.5"},
+				"lastOrderAt": "2024-01-02T03:04:05Z",
+				"statusCounts": {"OrderDelivered": 3}
+			}`, recorder.Body.String())
+		})
+	}
+}
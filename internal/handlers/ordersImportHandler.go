@@ -0,0 +1,79 @@
+package handlers
+
+import (
+	errors2 "errors"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rameshsunkara/go-rest-api-example/internal/errors"
+	"github.com/rameshsunkara/go-rest-api-example/internal/importer"
+	"github.com/rameshsunkara/go-rest-api-example/internal/models/external"
+	"github.com/rameshsunkara/go-rest-api-example/pkg/logger"
+)
+
+const (
+	importFileField = "file"
+)
+
+// ImportHandler handles bulk order import requests.
+type ImportHandler struct {
+	importer *importer.Importer
+	logger   logger.Logger
+}
+
+// NewImportHandler creates a new ImportHandler.
+func NewImportHandler(lgr logger.Logger, imp *importer.Importer) (*ImportHandler, error) {
+	if lgr == nil || imp == nil {
+		return nil, errors2.New("missing required parameters to create import handler")
+	}
+	return &ImportHandler{importer: imp, logger: lgr}, nil
+}
+
+// Import handles POST /orders/import.
+// The file is read from the multipart "file" field, or from the raw request body otherwise.
+// The format is taken from the "format" query param, falling back to the uploaded file's extension.
+func (h *ImportHandler) Import(c *gin.Context) {
+	lgr, requestID := h.logger.WithReqID(c)
+
+	q, ok := routeQuery[external.ImportQuery](c, lgr, errors.OrderImportServerError, requestID)
+	if !ok {
+		return
+	}
+
+	format := q.Format
+	var body io.Reader = c.Request.Body
+	if strings.HasPrefix(c.ContentType(), "multipart/") {
+		fh, err := c.FormFile(importFileField)
+		if err != nil {
+			abortWithAPIError(c, lgr, http.StatusBadRequest, errors.OrderImportInvalidInput,
+				"multipart upload must contain a \"file\" field", requestID, err)
+			return
+		}
+		f, err := fh.Open()
+		if err != nil {
+			abortWithAPIError(c, lgr, http.StatusBadRequest, errors.OrderImportInvalidInput,
+				"unable to read uploaded file", requestID, err)
+			return
+		}
+		defer f.Close()
+		body = f
+		if format == "" {
+			format = importer.FormatFromFilename(fh.Filename)
+		}
+	}
+
+	report, err := h.importer.Run(c, body, format, q.DryRun)
+	if err != nil {
+		if errors2.Is(err, importer.ErrUnsupportedFormat) || errors2.Is(err, importer.ErrUnreadableInput) {
+			abortWithAPIError(c, lgr, http.StatusBadRequest, errors.OrderImportInvalidInput,
+				err.Error(), requestID, err)
+			return
+		}
+		abortWithAPIError(c, lgr, http.StatusInternalServerError, errors.OrderImportServerError,
+			errors.UnexpectedErrorMessage, requestID, err)
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
@@ -0,0 +1,126 @@
+package handlers_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"testing"
+
+	"github.com/rameshsunkara/go-rest-api-example/internal/db"
+	"github.com/rameshsunkara/go-rest-api-example/internal/db/mocks"
+	errors2 "github.com/rameshsunkara/go-rest-api-example/internal/errors"
+	"github.com/rameshsunkara/go-rest-api-example/internal/handlers"
+	"github.com/rameshsunkara/go-rest-api-example/internal/importer"
+	"github.com/rameshsunkara/go-rest-api-example/internal/models/data"
+	"github.com/rameshsunkara/go-rest-api-example/internal/models/external"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const importNDJSON = `{"externalRef":"L-1","products":[{"name":"P1","price":10,"quantity":2}]}
+{"externalRef":"L-2","products":[{"name":"","price":10,"quantity":2}]}
+`
+
+func TestNewImportHandler(t *testing.T) {
+	t.Parallel()
+	imp, err := importer.New(lgr, &mocks.MockOrdersDataService{}, 0)
+	require.NoError(t, err)
+
+	_, err = handlers.NewImportHandler(nil, imp)
+	require.Error(t, err)
+	_, err = handlers.NewImportHandler(lgr, nil)
+	require.Error(t, err)
+	h, err := handlers.NewImportHandler(lgr, imp)
+	require.NoError(t, err)
+	assert.NotNil(t, h)
+}
+
+func multipartBody(t *testing.T, filename, content string) (*bytes.Buffer, string) {
+	t.Helper()
+	body := &bytes.Buffer{}
+	w := multipart.NewWriter(body)
+	part, err := w.CreateFormFile("file", filename)
+	require.NoError(t, err)
+	_, err = part.Write([]byte(content))
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+	return body, w.FormDataContentType()
+}
+
+func TestImportHandler_Import(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name          string
+		query         string
+		multipart     bool
+		filename      string
+		upsertErr     error
+		expectedCode  int
+		expectedError string
+		wantWrites    bool
+	}{
+		{name: "raw body", query: "?format=ndjson", expectedCode: http.StatusOK, wantWrites: true},
+		{name: "multipart infers format", multipart: true, filename: "orders.ndjson",
+			expectedCode: http.StatusOK, wantWrites: true},
+		{name: "dry run", query: "?format=ndjson&dryRun=true", expectedCode: http.StatusOK},
+		{name: "invalid dryRun", query: "?format=ndjson&dryRun=maybe", expectedCode: http.StatusBadRequest,
+			expectedError: errors2.RequestInvalid},
+		{name: "unknown format", query: "?format=xml", expectedCode: http.StatusBadRequest,
+			expectedError: errors2.OrderImportInvalidInput},
+		{name: "db failure", query: "?format=ndjson", upsertErr: db.ErrUnexpectedUpsertOrder,
+			expectedCode: http.StatusInternalServerError, expectedError: errors2.OrderImportServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			writes := 0
+			imp, err := importer.New(lgr, &mocks.MockOrdersDataService{
+				UpsertManyFunc: func(_ context.Context, orders []data.Order) (*db.UpsertResult, error) {
+					writes += len(orders)
+					if tt.upsertErr != nil {
+						return nil, tt.upsertErr
+					}
+					return &db.UpsertResult{Inserted: int64(len(orders))}, nil
+				},
+			}, 0)
+			require.NoError(t, err)
+			handler, err := handlers.NewImportHandler(lgr, imp)
+			require.NoError(t, err)
+
+			c, r, recorder := setupTestContext()
+			r.POST("/orders/import", parseQuery(external.ImportQuery{}), handler.Import)
+
+			body := bytes.NewBufferString(importNDJSON)
+			contentType := "application/x-ndjson"
+			if tt.multipart {
+				body, contentType = multipartBody(t, tt.filename, importNDJSON)
+			}
+			c.Request, _ = http.NewRequest(http.MethodPost, "/orders/import"+tt.query, body)
+			c.Request.Header.Set("Content-Type", contentType)
+			r.ServeHTTP(recorder, c.Request)
+
+			assert.Equal(t, tt.expectedCode, recorder.Code)
+			if tt.expectedError != "" {
+				var apiErr external.APIError
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &apiErr))
+				assert.Equal(t, tt.expectedError, apiErr.ErrorCode)
+				return
+			}
+			var report external.ImportReport
+			require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &report))
+			assert.Equal(t, 2, report.TotalRows)
+			assert.Equal(t, 1, report.ValidRows)
+			require.Len(t, report.Errors, 1)
+			assert.Contains(t, report.Errors[0].Message, "Name")
+			if tt.wantWrites {
+				assert.Equal(t, 1, writes)
+				assert.Equal(t, int64(1), report.Inserted)
+			} else {
+				assert.Zero(t, writes)
+			}
+		})
+	}
+}
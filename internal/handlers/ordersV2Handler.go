@@ -0,0 +1,113 @@
+package handlers
+
+import (
+	"encoding/base64"
+	errors2 "errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rameshsunkara/go-rest-api-example/internal/catalog"
+	"github.com/rameshsunkara/go-rest-api-example/internal/db"
+	"github.com/rameshsunkara/go-rest-api-example/internal/errors"
+	"github.com/rameshsunkara/go-rest-api-example/internal/models/data"
+	"github.com/rameshsunkara/go-rest-api-example/internal/models/external"
+	"github.com/rameshsunkara/go-rest-api-example/internal/models/mapping"
+	"github.com/rameshsunkara/go-rest-api-example/internal/pricing"
+	"github.com/rameshsunkara/go-rest-api-example/pkg/logger"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// OrdersV2Handler handles order-related HTTP requests of the v2 API.
+// It renders orders as external.OrderV2 and pages listings with cursors instead of offsets.
+type OrdersV2Handler struct {
+	*OrdersHandler
+}
+
+// NewOrdersV2Handler creates a new OrdersV2Handler.
+func NewOrdersV2Handler(
+	lgr logger.Logger,
+	dSvc db.OrdersDataService,
+	resolver *catalog.Resolver,
+	pricer *pricing.Engine,
+	stock db.InventoryDataService,
+) (*OrdersV2Handler, error) {
+	h, err := NewOrdersHandler(lgr, dSvc, resolver, pricer, stock)
+	if err != nil {
+		return nil, err
+	}
+	h.render = func(order *data.Order) any { return mapping.OrderV2(order) }
+	return &OrdersV2Handler{OrdersHandler: h}, nil
+}
+
+// GetAll handles GET /v2/orders, the response carries the cursor of the next page when there is one.
+func (o *OrdersV2Handler) GetAll(c *gin.Context) {
+	lgr, requestID := o.logger.WithReqID(c)
+	q, ok := routeQuery[external.OrderPageQuery](c, lgr, errors.OrdersGetServerError, requestID)
+	if !ok {
+		return
+	}
+	limit := q.Limit
+	var opts db.ReadOptions
+	if q.Cursor != "" {
+		after, err := decodeCursor(q.Cursor)
+		if err != nil {
+			abortWithAPIError(c, lgr, http.StatusBadRequest, errors.OrderGetInvalidParams,
+				"invalid cursor", requestID, err)
+			return
+		}
+		opts.After = after
+	}
+
+	// read one extra order to learn whether another page follows
+	orders, err := o.oDataSvc.GetAll(c, limit+1, opts)
+	if err != nil {
+		abortWithAPIError(c, lgr, http.StatusInternalServerError, errors.OrdersGetServerError,
+			errors.UnexpectedErrorMessage, requestID, err)
+		return
+	}
+	page := *orders
+	var next string
+	if int64(len(page)) > limit {
+		page = page[:limit]
+		next = encodeCursor(page[len(page)-1].ID)
+	}
+	c.JSON(http.StatusOK, external.OrderPageV2{Data: mapping.OrdersV2(page), NextCursor: next})
+}
+
+// GetByUser handles GET /v2/users/:user/orders, it lists the most recent orders of a customer.
+func (o *OrdersV2Handler) GetByUser(c *gin.Context) {
+	lgr, requestID := o.logger.WithReqID(c)
+	user, ok := o.userParam(c)
+	if !ok {
+		return
+	}
+	q, ok := routeQuery[external.LimitQuery](c, lgr, errors.UserOrdersServerError, requestID)
+	if !ok {
+		return
+	}
+	orders, err := o.oDataSvc.GetByUser(c, user, q.Limit)
+	if err != nil {
+		abortWithAPIError(c, lgr, http.StatusInternalServerError, errors.UserOrdersServerError,
+			errors.UnexpectedErrorMessage, requestID, err)
+		return
+	}
+	c.JSON(http.StatusOK, external.OrderPageV2{Data: mapping.OrdersV2(*orders)})
+}
+
+// encodeCursor returns an opaque cursor pointing after the given order.
+func encodeCursor(id primitive.ObjectID) string {
+	return base64.RawURLEncoding.EncodeToString(id[:])
+}
+
+func decodeCursor(cursor string) (primitive.ObjectID, error) {
+	var id primitive.ObjectID
+	b, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return id, err
+	}
+	if len(b) != len(id) {
+		return id, errors2.New("cursor has an unexpected length")
+	}
+	copy(id[:], b)
+	return id, nil
+}
@@ -0,0 +1,149 @@
+package handlers_test
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/rameshsunkara/go-rest-api-example/internal/db"
+	"github.com/rameshsunkara/go-rest-api-example/internal/db/mocks"
+	errors2 "github.com/rameshsunkara/go-rest-api-example/internal/errors"
+	"github.com/rameshsunkara/go-rest-api-example/internal/handlers"
+	"github.com/rameshsunkara/go-rest-api-example/internal/models/data"
+	"github.com/rameshsunkara/go-rest-api-example/internal/models/external"
+	"github.com/rameshsunkara/go-rest-api-example/pkg/money"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestNewOrdersV2Handler(t *testing.T) {
+	t.Parallel()
+	_, err := handlers.NewOrdersV2Handler(lgr, nil, newTestCatalog(t), newTestPricer(t, nil), newTestStock())
+	require.Error(t, err)
+
+	h, err := handlers.NewOrdersV2Handler(lgr, &mocks.MockOrdersDataService{},
+		newTestCatalog(t), newTestPricer(t, nil), newTestStock())
+	require.NoError(t, err)
+	assert.NotNil(t, h)
+}
+
+func TestOrdersV2Handler_GetAll(t *testing.T) {
+	t.Parallel()
+	ids := []primitive.ObjectID{primitive.NewObjectID(), primitive.NewObjectID(), primitive.NewObjectID()}
+	cursor := base64.RawURLEncoding.EncodeToString(ids[0][:])
+	tests := []struct {
+		name          string
+		query         string
+		stored        int
+		expectedCode  int
+		expectedError string
+		wantAfter     primitive.ObjectID
+		wantLen       int
+		wantNext      string
+	}{
+		{name: "last page", query: "?limit=5", stored: 2, expectedCode: http.StatusOK, wantLen: 2},
+		{
+			name: "more pages", query: "?limit=1", stored: 3, expectedCode: http.StatusOK, wantLen: 1,
+			wantNext: cursor,
+		},
+		{
+			name: "next page", query: "?limit=5&cursor=" + cursor, stored: 1, expectedCode: http.StatusOK,
+			wantAfter: ids[0], wantLen: 1,
+		},
+		{
+			name: "invalid cursor", query: "?cursor=not-a-cursor", expectedCode: http.StatusBadRequest,
+			expectedError: errors2.OrderGetInvalidParams,
+		},
+		{
+			name: "short cursor", query: "?cursor=AAAA", expectedCode: http.StatusBadRequest,
+			expectedError: errors2.OrderGetInvalidParams,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			handler, err := handlers.NewOrdersV2Handler(lgr, &mocks.MockOrdersDataService{
+				GetAllFunc: func(_ context.Context, limit int64, opts db.ReadOptions) (*[]data.Order, error) {
+					assert.Equal(t, tt.wantAfter, opts.After)
+					orders := make([]data.Order, 0, tt.stored)
+					for _, id := range ids[:min(int64(tt.stored), limit)] {
+						orders = append(orders, data.Order{ID: id, Status: data.OrderPending})
+					}
+					return &orders, nil
+				},
+			}, newTestCatalog(t), newTestPricer(t, nil), newTestStock())
+			require.NoError(t, err)
+
+			c, r, recorder := setupTestContext()
+			r.GET("/orders", parseQuery(external.OrderPageQuery{}), handler.GetAll)
+			c.Request, _ = http.NewRequest(http.MethodGet, "/orders"+tt.query, nil)
+			r.ServeHTTP(recorder, c.Request)
+
+			assert.Equal(t, tt.expectedCode, recorder.Code)
+			if tt.expectedError != "" {
+				assertAPIError(t, recorder.Body.Bytes(), tt.expectedError)
+				return
+			}
+			var page external.OrderPageV2
+			require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &page))
+			assert.Len(t, page.Data, tt.wantLen)
+			assert.Equal(t, tt.wantNext, page.NextCursor)
+		})
+	}
+}
+
+func TestOrdersV2Handler_GetByID(t *testing.T) {
+	t.Parallel()
+	oID := primitive.NewObjectID()
+	handler, err := handlers.NewOrdersV2Handler(lgr, &mocks.MockOrdersDataService{
+		GetByIDFunc: func(context.Context, primitive.ObjectID, db.ReadOptions) (*data.Order, error) {
+			return &data.Order{
+				ID:          oID,
+				Status:      data.OrderPending,
+				Currency:    money.EUR,
+				TotalAmount: money.MustParse("12.50"),
+				Products:    []data.Product{{Name: "Mug", Price: money.MustParse("12.50"), Quantity: 1}},
+			}, nil
+		},
+	}, newTestCatalog(t), newTestPricer(t, nil), newTestStock())
+	require.NoError(t, err)
+
+	c, r, recorder := setupTestContext()
+	r.GET("/orders/:id", handler.GetByID)
+	c.Request, _ = http.NewRequest(http.MethodGet, "/orders/"+oID.Hex(), nil)
+	r.ServeHTTP(recorder, c.Request)
+
+	require.Equal(t, http.StatusOK, recorder.Code)
+	var order external.OrderV2
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &order))
+	assert.Equal(t, oID.Hex(), order.ID)
+	assert.Equal(t, external.Money{Amount: money.MustParse("12.50"), Currency: money.EUR}, order.Total)
+	require.Len(t, order.Products, 1)
+	assert.Equal(t, money.EUR, order.Products[0].Price.Currency)
+}
+
+func TestOrdersV2Handler_GetByUser(t *testing.T) {
+	t.Parallel()
+	handler, err := handlers.NewOrdersV2Handler(lgr, &mocks.MockOrdersDataService{
+		GetByUserFunc: func(_ context.Context, user string, _ int64) (*[]data.Order, error) {
+			return &[]data.Order{{ID: primitive.NewObjectID(), User: user, Status: data.OrderPending}}, nil
+		},
+	}, newTestCatalog(t), newTestPricer(t, nil), newTestStock())
+	require.NoError(t, err)
+
+	c, r, recorder := setupTestContext()
+	r.GET("/users/:user/orders", parseQuery(external.LimitQuery{}), handler.GetByUser)
+	c.Request, _ = http.NewRequest(http.MethodGet, "/users/jane@example.com/orders", nil)
+	r.ServeHTTP(recorder, c.Request)
+
+	require.Equal(t, http.StatusOK, recorder.Code)
+	var page external.OrderPageV2
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &page))
+	require.Len(t, page.Data, 1)
+	assert.Equal(t, "jane@example.com", page.Data[0].User)
+	assert.Empty(t, page.NextCursor)
+}
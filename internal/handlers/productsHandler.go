@@ -0,0 +1,173 @@
+package handlers
+
+import (
+	errors2 "errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rameshsunkara/go-rest-api-example/internal/db"
+	"github.com/rameshsunkara/go-rest-api-example/internal/errors"
+	"github.com/rameshsunkara/go-rest-api-example/internal/models/data"
+	"github.com/rameshsunkara/go-rest-api-example/internal/models/external"
+	"github.com/rameshsunkara/go-rest-api-example/internal/models/mapping"
+	"github.com/rameshsunkara/go-rest-api-example/pkg/logger"
+)
+
+const ProductSKUPath = "sku"
+
+// ProductsHandler handles product catalog requests.
+type ProductsHandler struct {
+	pDataSvc db.ProductsDataService
+	logger   logger.Logger
+}
+
+// NewProductsHandler creates a new ProductsHandler.
+func NewProductsHandler(lgr logger.Logger, pSvc db.ProductsDataService) (*ProductsHandler, error) {
+	if lgr == nil || pSvc == nil {
+		return nil, errors2.New("missing required parameters to create products handler")
+	}
+	return &ProductsHandler{pDataSvc: pSvc, logger: lgr}, nil
+}
+
+// Create handles POST /products.
+func (h *ProductsHandler) Create(c *gin.Context) {
+	lgr, requestID := h.logger.WithReqID(c)
+	var in external.CatalogProductInput
+	if err := c.ShouldBindJSON(&in); err != nil {
+		abortWithAPIError(c, lgr, http.StatusBadRequest, errors.ProductCreateInvalidInput,
+			"Invalid product request body", requestID, err)
+		return
+	}
+	if data.NormalizeSKU(in.SKU) == "" {
+		abortWithAPIError(c, lgr, http.StatusBadRequest, errors.ProductCreateInvalidInput,
+			"Product sku is required", requestID, nil)
+		return
+	}
+	product, err := toCatalogProduct(in.SKU, &in)
+	if err != nil {
+		abortWithAPIError(c, lgr, http.StatusBadRequest, errors.ProductCreateInvalidInput,
+			"Invalid product currency or price", requestID, err)
+		return
+	}
+
+	product.CreatedAt = product.UpdatedAt
+	id, err := h.pDataSvc.Create(c, product)
+	if err != nil {
+		if errors2.Is(err, db.ErrSKUExists) {
+			abortWithAPIError(c, lgr, http.StatusConflict, errors.ProductCreateConflict,
+				"Product sku already exists", requestID, err)
+			return
+		}
+		abortWithAPIError(c, lgr, http.StatusInternalServerError, errors.ProductCreateServerError,
+			errors.UnexpectedErrorMessage, requestID, err)
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"id": id, "sku": product.SKU})
+}
+
+// GetAll handles GET /products.
+func (h *ProductsHandler) GetAll(c *gin.Context) {
+	lgr, requestID := h.logger.WithReqID(c)
+	q, ok := routeQuery[external.ProductListQuery](c, lgr, errors.ProductsGetServerError, requestID)
+	if !ok {
+		return
+	}
+
+	products, err := h.pDataSvc.GetAll(c, q.Limit, q.IncludeInactive)
+	if err != nil {
+		abortWithAPIError(c, lgr, http.StatusInternalServerError, errors.ProductsGetServerError,
+			errors.UnexpectedErrorMessage, requestID, err)
+		return
+	}
+	c.JSON(http.StatusOK, mapping.CatalogProducts(*products))
+}
+
+// GetBySKU handles GET /products/:sku.
+func (h *ProductsHandler) GetBySKU(c *gin.Context) {
+	lgr, requestID := h.logger.WithReqID(c)
+	product, err := h.pDataSvc.GetBySKU(c, c.Param(ProductSKUPath))
+	if err != nil {
+		if errors2.Is(err, db.ErrSKUNotFound) {
+			abortWithAPIError(c, lgr, http.StatusNotFound, errors.ProductGetNotFound,
+				"product not found", requestID, err)
+			return
+		}
+		abortWithAPIError(c, lgr, http.StatusInternalServerError, errors.ProductsGetServerError,
+			errors.UnexpectedErrorMessage, requestID, err)
+		return
+	}
+	c.JSON(http.StatusOK, mapping.CatalogProduct(product))
+}
+
+// Update handles PUT /products/:sku. The SKU of a product cannot change.
+func (h *ProductsHandler) Update(c *gin.Context) {
+	lgr, requestID := h.logger.WithReqID(c)
+	sku := data.NormalizeSKU(c.Param(ProductSKUPath))
+	var in external.CatalogProductInput
+	if err := c.ShouldBindJSON(&in); err != nil {
+		abortWithAPIError(c, lgr, http.StatusBadRequest, errors.ProductUpdateInvalidInput,
+			"Invalid product request body", requestID, err)
+		return
+	}
+	if in.SKU != "" && data.NormalizeSKU(in.SKU) != sku {
+		abortWithAPIError(c, lgr, http.StatusBadRequest, errors.ProductUpdateInvalidInput,
+			"Product sku cannot be changed", requestID, nil)
+		return
+	}
+	product, err := toCatalogProduct(sku, &in)
+	if err != nil {
+		abortWithAPIError(c, lgr, http.StatusBadRequest, errors.ProductUpdateInvalidInput,
+			"Invalid product currency or price", requestID, err)
+		return
+	}
+
+	if err = h.pDataSvc.Update(c, product); err != nil {
+		if errors2.Is(err, db.ErrSKUNotFound) {
+			abortWithAPIError(c, lgr, http.StatusNotFound, errors.ProductUpdateNotFound,
+				"product not found", requestID, err)
+			return
+		}
+		abortWithAPIError(c, lgr, http.StatusInternalServerError, errors.ProductUpdateServerError,
+			errors.UnexpectedErrorMessage, requestID, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// DeleteBySKU handles DELETE /products/:sku.
+func (h *ProductsHandler) DeleteBySKU(c *gin.Context) {
+	lgr, requestID := h.logger.WithReqID(c)
+	if err := h.pDataSvc.DeleteBySKU(c, c.Param(ProductSKUPath)); err != nil {
+		if errors2.Is(err, db.ErrSKUNotFound) {
+			abortWithAPIError(c, lgr, http.StatusNotFound, errors.ProductDeleteNotFound,
+				"could not delete product", requestID, err)
+			return
+		}
+		abortWithAPIError(c, lgr, http.StatusInternalServerError, errors.ProductDeleteServerError,
+			"could not delete product", requestID, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// toCatalogProduct converts the input into a storage model, products are active unless stated otherwise.
+func toCatalogProduct(sku string, in *external.CatalogProductInput) (*data.CatalogProduct, error) {
+	currency, err := in.ResolveCurrency()
+	if err != nil {
+		return nil, err
+	}
+	active := true
+	if in.Active != nil {
+		active = *in.Active
+	}
+	return &data.CatalogProduct{
+		SKU:         data.NormalizeSKU(sku),
+		Name:        in.Name,
+		Description: in.Description,
+		Price:       in.Price,
+		Currency:    currency,
+		Active:      active,
+		UpdatedAt:   time.Now(),
+	}, nil
+}
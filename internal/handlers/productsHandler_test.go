@@ -0,0 +1,343 @@
+package handlers_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/rameshsunkara/go-rest-api-example/internal/db"
+	"github.com/rameshsunkara/go-rest-api-example/internal/db/mocks"
+	errors2 "github.com/rameshsunkara/go-rest-api-example/internal/errors"
+	"github.com/rameshsunkara/go-rest-api-example/internal/handlers"
+	"github.com/rameshsunkara/go-rest-api-example/internal/models/data"
+	"github.com/rameshsunkara/go-rest-api-example/internal/models/external"
+	"github.com/rameshsunkara/go-rest-api-example/pkg/money"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func assertAPIError(t *testing.T, body []byte, expectedError string) {
+	t.Helper()
+	var apiErr external.APIError
+	require.NoError(t, json.Unmarshal(body, &apiErr))
+	assert.Equal(t, expectedError, apiErr.ErrorCode)
+}
+
+func TestNewProductsHandler(t *testing.T) {
+	t.Parallel()
+	_, err := handlers.NewProductsHandler(nil, &mocks.MockProductsDataService{})
+	require.Error(t, err)
+	_, err = handlers.NewProductsHandler(lgr, nil)
+	require.Error(t, err)
+	h, err := handlers.NewProductsHandler(lgr, &mocks.MockProductsDataService{})
+	require.NoError(t, err)
+	assert.NotNil(t, h)
+}
+
+func TestProductsHandler_Create(t *testing.T) {
+	t.Parallel()
+	inactive := false
+	valid := external.CatalogProductInput{SKU: "mug-1", Name: "Mug", Price: money.MustParse("9.99")}
+	with := func(mutate func(in *external.CatalogProductInput)) external.CatalogProductInput {
+		in := valid
+		mutate(&in)
+		return in
+	}
+
+	tests := []struct {
+		name          string
+		input         external.CatalogProductInput
+		createErr     error
+		expectedCode  int
+		expectedError string
+		wantCurrency  money.Currency
+		wantActive    bool
+	}{
+		{name: "default currency", input: valid, expectedCode: http.StatusCreated, wantCurrency: money.USD,
+			wantActive: true},
+		{
+			name: "inactive euro product",
+			input: with(func(in *external.CatalogProductInput) {
+				in.Currency, in.Active = "eur", &inactive
+			}),
+			expectedCode: http.StatusCreated,
+			wantCurrency: money.EUR,
+		},
+		{
+			name:          "missing sku",
+			input:         with(func(in *external.CatalogProductInput) { in.SKU = " " }),
+			expectedCode:  http.StatusBadRequest,
+			expectedError: errors2.ProductCreateInvalidInput,
+		},
+		{
+			name:          "missing price",
+			input:         with(func(in *external.CatalogProductInput) { in.Price = 0 }),
+			expectedCode:  http.StatusBadRequest,
+			expectedError: errors2.ProductCreateInvalidInput,
+		},
+		{
+			name:          "price too precise for currency",
+			input:         with(func(in *external.CatalogProductInput) { in.Currency = "JPY" }),
+			expectedCode:  http.StatusBadRequest,
+			expectedError: errors2.ProductCreateInvalidInput,
+		},
+		{
+			name:          "duplicate sku",
+			input:         valid,
+			createErr:     db.ErrSKUExists,
+			expectedCode:  http.StatusConflict,
+			expectedError: errors2.ProductCreateConflict,
+		},
+		{
+			name:          "db failure",
+			input:         valid,
+			createErr:     db.ErrFailedToCreateProduct,
+			expectedCode:  http.StatusInternalServerError,
+			expectedError: errors2.ProductCreateServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			var saved *data.CatalogProduct
+			handler, err := handlers.NewProductsHandler(lgr, &mocks.MockProductsDataService{
+				CreateFunc: func(_ context.Context, product *data.CatalogProduct) (string, error) {
+					saved = product
+					return "p1", tt.createErr
+				},
+			})
+			require.NoError(t, err)
+
+			c, r, recorder := setupTestContext()
+			r.POST("/products", handler.Create)
+			body, _ := json.Marshal(tt.input)
+			c.Request, _ = http.NewRequest(http.MethodPost, "/products", bytes.NewReader(body))
+			r.ServeHTTP(recorder, c.Request)
+
+			assert.Equal(t, tt.expectedCode, recorder.Code)
+			if tt.expectedError != "" {
+				assertAPIError(t, recorder.Body.Bytes(), tt.expectedError)
+				return
+			}
+			require.NotNil(t, saved)
+			assert.Equal(t, "MUG-1", saved.SKU)
+			assert.Equal(t, tt.input.Price, saved.Price)
+			assert.Equal(t, tt.wantCurrency, saved.Currency)
+			assert.Equal(t, tt.wantActive, saved.Active)
+			assert.False(t, saved.CreatedAt.IsZero())
+		})
+	}
+}
+
+func TestProductsHandler_GetAll(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name            string
+		query           string
+		getErr          error
+		expectedCode    int
+		expectedError   string
+		wantLimit       int64
+		includeInactive bool
+	}{
+		{name: "defaults", expectedCode: http.StatusOK, wantLimit: db.DefaultPageSize},
+		{name: "include inactive", query: "?limit=5&includeInactive=true", expectedCode: http.StatusOK, wantLimit: 5,
+			includeInactive: true},
+		{name: "bad includeInactive", query: "?includeInactive=maybe", expectedCode: http.StatusBadRequest,
+			expectedError: errors2.RequestInvalid},
+		{name: "bad limit", query: "?limit=0", expectedCode: http.StatusBadRequest},
+		{name: "db failure", getErr: db.ErrUnexpectedGetProduct, expectedCode: http.StatusInternalServerError,
+			expectedError: errors2.ProductsGetServerError, wantLimit: db.DefaultPageSize},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			handler, err := handlers.NewProductsHandler(lgr, &mocks.MockProductsDataService{
+				GetAllFunc: func(_ context.Context, limit int64, includeInactive bool) (*[]data.CatalogProduct, error) {
+					assert.Equal(t, tt.wantLimit, limit)
+					assert.Equal(t, tt.includeInactive, includeInactive)
+					if tt.getErr != nil {
+						return nil, tt.getErr
+					}
+					return &[]data.CatalogProduct{{SKU: "MUG-1", Price: money.MustParse("9.99"), Active: true}}, nil
+				},
+			})
+			require.NoError(t, err)
+
+			c, r, recorder := setupTestContext()
+			r.GET("/products", parseQuery(external.ProductListQuery{}), handler.GetAll)
+			c.Request, _ = http.NewRequest(http.MethodGet, "/products"+tt.query, nil)
+			r.ServeHTTP(recorder, c.Request)
+
+			assert.Equal(t, tt.expectedCode, recorder.Code)
+			if tt.expectedError != "" {
+				assertAPIError(t, recorder.Body.Bytes(), tt.expectedError)
+				return
+			}
+			if tt.expectedCode != http.StatusOK {
+				return
+			}
+			var products []data.CatalogProduct
+			require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &products))
+			require.Len(t, products, 1)
+			assert.Equal(t, "MUG-1", products[0].SKU)
+		})
+	}
+}
+
+func TestProductsHandler_GetBySKU(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name          string
+		getErr        error
+		expectedCode  int
+		expectedError string
+	}{
+		{name: "found", expectedCode: http.StatusOK},
+		{name: "not found", getErr: db.ErrSKUNotFound, expectedCode: http.StatusNotFound,
+			expectedError: errors2.ProductGetNotFound},
+		{name: "db failure", getErr: db.ErrUnexpectedGetProduct, expectedCode: http.StatusInternalServerError,
+			expectedError: errors2.ProductsGetServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			handler, err := handlers.NewProductsHandler(lgr, &mocks.MockProductsDataService{
+				GetBySKUFunc: func(_ context.Context, sku string) (*data.CatalogProduct, error) {
+					if tt.getErr != nil {
+						return nil, tt.getErr
+					}
+					return &data.CatalogProduct{SKU: data.NormalizeSKU(sku), Price: money.MustParse("9.99")}, nil
+				},
+			})
+			require.NoError(t, err)
+
+			c, r, recorder := setupTestContext()
+			r.GET("/products/:sku", handler.GetBySKU)
+			c.Request, _ = http.NewRequest(http.MethodGet, "/products/mug-1", nil)
+			r.ServeHTTP(recorder, c.Request)
+
+			assert.Equal(t, tt.expectedCode, recorder.Code)
+			if tt.expectedError != "" {
+				assertAPIError(t, recorder.Body.Bytes(), tt.expectedError)
+				return
+			}
+			var product data.CatalogProduct
+			require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &product))
+			assert.Equal(t, "MUG-1", product.SKU)
+			assert.Equal(t, money.MustParse("9.99"), product.Price)
+		})
+	}
+}
+
+func TestProductsHandler_Update(t *testing.T) {
+	t.Parallel()
+	valid := external.CatalogProductInput{Name: "Mug", Price: money.MustParse("12")}
+	tests := []struct {
+		name          string
+		input         external.CatalogProductInput
+		updateErr     error
+		expectedCode  int
+		expectedError string
+	}{
+		{name: "updated", input: valid, expectedCode: http.StatusNoContent},
+		{
+			name:         "matching sku in body",
+			input:        external.CatalogProductInput{SKU: "Mug-1", Name: "Mug", Price: money.MustParse("12")},
+			expectedCode: http.StatusNoContent,
+		},
+		{
+			name:          "sku change",
+			input:         external.CatalogProductInput{SKU: "CUP-1", Name: "Mug", Price: money.MustParse("12")},
+			expectedCode:  http.StatusBadRequest,
+			expectedError: errors2.ProductUpdateInvalidInput,
+		},
+		{
+			name:          "missing name",
+			input:         external.CatalogProductInput{Price: money.MustParse("12")},
+			expectedCode:  http.StatusBadRequest,
+			expectedError: errors2.ProductUpdateInvalidInput,
+		},
+		{
+			name:          "unknown currency",
+			input:         external.CatalogProductInput{Name: "Mug", Price: money.MustParse("12"), Currency: "XYZ"},
+			expectedCode:  http.StatusBadRequest,
+			expectedError: errors2.ProductUpdateInvalidInput,
+		},
+		{name: "not found", input: valid, updateErr: db.ErrSKUNotFound, expectedCode: http.StatusNotFound,
+			expectedError: errors2.ProductUpdateNotFound},
+		{name: "db failure", input: valid, updateErr: db.ErrUnexpectedUpdateProduct,
+			expectedCode: http.StatusInternalServerError, expectedError: errors2.ProductUpdateServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			var saved *data.CatalogProduct
+			handler, err := handlers.NewProductsHandler(lgr, &mocks.MockProductsDataService{
+				UpdateFunc: func(_ context.Context, product *data.CatalogProduct) error {
+					saved = product
+					return tt.updateErr
+				},
+			})
+			require.NoError(t, err)
+
+			c, r, recorder := setupTestContext()
+			r.PUT("/products/:sku", handler.Update)
+			body, _ := json.Marshal(tt.input)
+			c.Request, _ = http.NewRequest(http.MethodPut, "/products/mug-1", bytes.NewReader(body))
+			r.ServeHTTP(recorder, c.Request)
+
+			assert.Equal(t, tt.expectedCode, recorder.Code)
+			if tt.expectedError != "" {
+				assertAPIError(t, recorder.Body.Bytes(), tt.expectedError)
+				return
+			}
+			require.NotNil(t, saved)
+			assert.Equal(t, "MUG-1", saved.SKU)
+			assert.Equal(t, money.MustParse("12"), saved.Price)
+			assert.True(t, saved.Active)
+		})
+	}
+}
+
+func TestProductsHandler_DeleteBySKU(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name          string
+		deleteErr     error
+		expectedCode  int
+		expectedError string
+	}{
+		{name: "deleted", expectedCode: http.StatusNoContent},
+		{name: "not found", deleteErr: db.ErrSKUNotFound, expectedCode: http.StatusNotFound,
+			expectedError: errors2.ProductDeleteNotFound},
+		{name: "db failure", deleteErr: db.ErrUnexpectedDeleteProduct, expectedCode: http.StatusInternalServerError,
+			expectedError: errors2.ProductDeleteServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			handler, err := handlers.NewProductsHandler(lgr, &mocks.MockProductsDataService{
+				DeleteBySKUFunc: func(context.Context, string) error { return tt.deleteErr },
+			})
+			require.NoError(t, err)
+
+			c, r, recorder := setupTestContext()
+			r.DELETE("/products/:sku", handler.DeleteBySKU)
+			c.Request, _ = http.NewRequest(http.MethodDelete, "/products/MUG-1", nil)
+			r.ServeHTTP(recorder, c.Request)
+
+			assert.Equal(t, tt.expectedCode, recorder.Code)
+			if tt.expectedError != "" {
+				assertAPIError(t, recorder.Body.Bytes(), tt.expectedError)
+			}
+		})
+	}
+}
@@ -0,0 +1,222 @@
+package handlers
+
+import (
+	"encoding/csv"
+	errors2 "errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rameshsunkara/go-rest-api-example/internal/db"
+	"github.com/rameshsunkara/go-rest-api-example/internal/errors"
+	"github.com/rameshsunkara/go-rest-api-example/internal/models/data"
+	"github.com/rameshsunkara/go-rest-api-example/internal/models/external"
+	"github.com/rameshsunkara/go-rest-api-example/pkg/logger"
+)
+
+const (
+	ReportFormatJSON = "json"
+	ReportFormatCSV  = "csv"
+
+	// DefaultReportDays is the length of the report range when from is not given.
+	DefaultReportDays = 30
+	// MaxReportRange bounds the orders scanned by a single report.
+	MaxReportRange = 366 * 24 * time.Hour
+	// DefaultTopProducts is the number of products returned by the top products report without a limit.
+	DefaultTopProducts = 10
+
+	reportDateLayout = time.DateOnly
+)
+
+// ReportsHandler serves the reporting endpoints. Every report accepts the query params
+// from and to (YYYY-MM-DD in tz or RFC3339, to is exclusive and defaults to the end of today),
+// tz (an IANA time zone, defaults to UTC) and format (json or csv).
+type ReportsHandler struct {
+	reports db.ReportsDataService
+	logger  logger.Logger
+}
+
+// NewReportsHandler creates a new ReportsHandler.
+func NewReportsHandler(lgr logger.Logger, rSvc db.ReportsDataService) (*ReportsHandler, error) {
+	if lgr == nil || rSvc == nil {
+		return nil, errors2.New("missing required parameters to create reports handler")
+	}
+	return &ReportsHandler{reports: rSvc, logger: lgr}, nil
+}
+
+// Revenue handles GET /reports/revenue, it accepts an interval of day (default), week or month.
+func (h *ReportsHandler) Revenue(c *gin.Context) {
+	q, format, ok := h.parseQuery(c)
+	if !ok {
+		return
+	}
+	rq, ok := reportQuery[external.RevenueReportQuery](h, c)
+	if !ok {
+		return
+	}
+	q.Interval = data.ReportInterval(rq.Interval)
+	buckets, err := h.reports.Revenue(c, q)
+	if err != nil {
+		h.abortServerError(c, err)
+		return
+	}
+	rows := make([][]string, 0, len(buckets))
+	for _, b := range buckets {
+		rows = append(rows, []string{
+			b.Start.Format(time.RFC3339), string(b.Currency), strconv.FormatInt(b.Orders, 10), b.Revenue.String(),
+		})
+	}
+	h.write(c, format, "revenue", buckets, []string{"start", "currency", "orders", "revenue"}, rows)
+}
+
+// OrdersByStatus handles GET /reports/orders-by-status.
+func (h *ReportsHandler) OrdersByStatus(c *gin.Context) {
+	q, format, ok := h.parseQuery(c)
+	if !ok {
+		return
+	}
+	counts, err := h.reports.OrdersByStatus(c, q)
+	if err != nil {
+		h.abortServerError(c, err)
+		return
+	}
+	rows := make([][]string, 0, len(counts))
+	for _, sc := range counts {
+		rows = append(rows, []string{string(sc.Status), strconv.FormatInt(sc.Orders, 10)})
+	}
+	h.write(c, format, "orders-by-status", counts, []string{"status", "orders"}, rows)
+}
+
+// BasketSize handles GET /reports/basket-size.
+func (h *ReportsHandler) BasketSize(c *gin.Context) {
+	q, format, ok := h.parseQuery(c)
+	if !ok {
+		return
+	}
+	stats, err := h.reports.BasketSize(c, q)
+	if err != nil {
+		h.abortServerError(c, err)
+		return
+	}
+	rows := make([][]string, 0, len(stats))
+	for _, s := range stats {
+		rows = append(rows, []string{
+			string(s.Currency), strconv.FormatInt(s.Orders, 10), s.AverageTotal.String(),
+			strconv.FormatFloat(s.AverageItems, 'f', 2, 64),
+		})
+	}
+	h.write(c, format, "basket-size", stats, []string{"currency", "orders", "averageTotal", "averageItems"}, rows)
+}
+
+// TopProducts handles GET /reports/top-products, limit defaults to DefaultTopProducts.
+func (h *ReportsHandler) TopProducts(c *gin.Context) {
+	q, format, ok := h.parseQuery(c)
+	if !ok {
+		return
+	}
+	rq, ok := reportQuery[external.TopProductsReportQuery](h, c)
+	if !ok {
+		return
+	}
+	sales, err := h.reports.TopProducts(c, q, rq.Limit)
+	if err != nil {
+		h.abortServerError(c, err)
+		return
+	}
+	rows := make([][]string, 0, len(sales))
+	for _, s := range sales {
+		rows = append(rows, []string{
+			s.SKU, s.Name, string(s.Currency), strconv.FormatInt(s.Quantity, 10), s.Revenue.String(),
+		})
+	}
+	h.write(c, format, "top-products", sales, []string{"sku", "name", "currency", "quantity", "revenue"}, rows)
+}
+
+// parseQuery parses the query params shared by every report, it aborts the request when they are invalid.
+func (h *ReportsHandler) parseQuery(c *gin.Context) (db.ReportQuery, string, bool) {
+	var q db.ReportQuery
+	rq, ok := reportQuery[external.ReportQuery](h, c)
+	if !ok {
+		return q, "", false
+	}
+	loc, err := time.LoadLocation(rq.TZ)
+	if err != nil || rq.TZ == "Local" {
+		h.abortInvalid(c, "tz must be an IANA time zone such as Europe/Berlin", err)
+		return q, "", false
+	}
+	q.Location = loc
+
+	y, m, d := time.Now().In(loc).Date()
+	q.To = time.Date(y, m, d+1, 0, 0, 0, 0, loc)
+	q.From = q.To.AddDate(0, 0, -DefaultReportDays)
+	parseTime := func(param, v string, dst *time.Time) bool {
+		if v == "" {
+			return true
+		}
+		if *dst, err = parseReportTime(v, loc); err != nil {
+			h.abortInvalid(c, "YYYY-MM-DD date or RFC3339 timestamp is expected for "+param+" query param", err)
+			return false
+		}
+		return true
+	}
+	if !parseTime("from", rq.From, &q.From) || !parseTime("to", rq.To, &q.To) {
+		return q, "", false
+	}
+	if !q.From.Before(q.To) || q.To.Sub(q.From) > MaxReportRange {
+		h.abortInvalid(c, "from must be before to and the range cannot exceed 366 days", nil)
+		return q, "", false
+	}
+	return q, rq.Format, true
+}
+
+// reportQuery returns the query parameters parsed for the report as a T.
+func reportQuery[T any](h *ReportsHandler, c *gin.Context) (T, bool) {
+	lgr, requestID := h.logger.WithReqID(c)
+	return routeQuery[T](c, lgr, errors.ReportServerError, requestID)
+}
+
+// parseReportTime parses a date at midnight in loc or an RFC3339 timestamp.
+func parseReportTime(v string, loc *time.Location) (time.Time, error) {
+	if t, err := time.ParseInLocation(reportDateLayout, v, loc); err == nil {
+		return t, nil
+	}
+	return time.Parse(time.RFC3339, v)
+}
+
+// write responds with the report as JSON or as a CSV attachment made of header and rows.
+func (h *ReportsHandler) write(
+	c *gin.Context,
+	format, name string,
+	report interface{},
+	header []string,
+	rows [][]string,
+) {
+	if format == ReportFormatJSON {
+		c.JSON(http.StatusOK, report)
+		return
+	}
+	c.Header("Content-Type", "text/csv; charset=utf-8")
+	c.Header("Content-Disposition", `attachment; filename="`+name+`.csv"`)
+	c.Status(http.StatusOK)
+	w := csv.NewWriter(c.Writer)
+	err := w.Write(header)
+	if err == nil {
+		err = w.WriteAll(rows)
+	}
+	if err != nil {
+		lgr, _ := h.logger.WithReqID(c)
+		lgr.Error().Err(err).Str("report", name).Msg("failed to write csv report")
+	}
+}
+
+func (h *ReportsHandler) abortInvalid(c *gin.Context, message string, err error) {
+	lgr, requestID := h.logger.WithReqID(c)
+	abortWithAPIError(c, lgr, http.StatusBadRequest, errors.ReportInvalidParams, message, requestID, err)
+}
+
+func (h *ReportsHandler) abortServerError(c *gin.Context, err error) {
+	lgr, requestID := h.logger.WithReqID(c)
+	abortWithAPIError(c, lgr, http.StatusInternalServerError, errors.ReportServerError,
+		errors.UnexpectedErrorMessage, requestID, err)
+}
@@ -0,0 +1,220 @@
+package handlers_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/rameshsunkara/go-rest-api-example/internal/db"
+	"github.com/rameshsunkara/go-rest-api-example/internal/db/mocks"
+	errors2 "github.com/rameshsunkara/go-rest-api-example/internal/errors"
+	"github.com/rameshsunkara/go-rest-api-example/internal/handlers"
+	"github.com/rameshsunkara/go-rest-api-example/internal/models/data"
+	"github.com/rameshsunkara/go-rest-api-example/internal/models/external"
+	"github.com/rameshsunkara/go-rest-api-example/pkg/money"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewReportsHandler(t *testing.T) {
+	t.Parallel()
+	_, err := handlers.NewReportsHandler(nil, &mocks.MockReportsDataService{})
+	require.Error(t, err)
+	_, err = handlers.NewReportsHandler(lgr, nil)
+	require.Error(t, err)
+	h, err := handlers.NewReportsHandler(lgr, &mocks.MockReportsDataService{})
+	require.NoError(t, err)
+	assert.NotNil(t, h)
+}
+
+func TestReportsHandler_Revenue(t *testing.T) {
+	t.Parallel()
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	require.NoError(t, err)
+	tests := []struct {
+		name          string
+		query         string
+		revenueErr    error
+		expectedCode  int
+		expectedError string
+		wantQuery     func(t *testing.T, q db.ReportQuery)
+		wantBody      string
+		wantCSV       string
+	}{
+		{
+			name:         "dates in time zone",
+			query:        "?from=2024-03-01&to=2024-04-01&tz=Europe/Berlin&interval=week",
+			expectedCode: http.StatusOK,
+			wantQuery: func(t *testing.T, q db.ReportQuery) {
+				assert.True(t, time.Date(2024, 3, 1, 0, 0, 0, 0, berlin).Equal(q.From))
+				assert.True(t, time.Date(2024, 4, 1, 0, 0, 0, 0, berlin).Equal(q.To))
+				assert.Equal(t, "Europe/Berlin", q.Location.String())
+				assert.Equal(t, data.IntervalWeek, q.Interval)
+			},
+			wantBody: `[{"start":"2024-03-01T00:00:00Z","currency":"USD","orders":2,"revenue":"30.5"}]`,
+		},
+		{
+			name:         "defaults to the last 30 days by day in UTC",
+			expectedCode: http.StatusOK,
+			wantQuery: func(t *testing.T, q db.ReportQuery) {
+				assert.Equal(t, time.UTC, q.Location)
+				assert.Equal(t, data.IntervalDay, q.Interval)
+				assert.Equal(t, q.To.AddDate(0, 0, -handlers.DefaultReportDays), q.From)
+				assert.True(t, q.To.After(time.Now()))
+				assert.Zero(t, q.To.Hour())
+			},
+		},
+		{
+			name:         "rfc3339 range",
+			query:        "?from=2024-03-01T10:00:00Z&to=2024-03-02T10:00:00Z",
+			expectedCode: http.StatusOK,
+			wantQuery: func(t *testing.T, q db.ReportQuery) {
+				assert.Equal(t, 24*time.Hour, q.To.Sub(q.From))
+			},
+		},
+		{
+			name:         "csv",
+			query:        "?from=2024-03-01&to=2024-04-01&format=csv",
+			expectedCode: http.StatusOK,
+			wantCSV:      "start,currency,orders,revenue\n2024-03-01T00:00:00Z,USD,2,30.5\n",
+		},
+		{name: "unknown interval", query: "?interval=year", expectedCode: http.StatusBadRequest,
+			expectedError: errors2.RequestInvalid},
+		{name: "unknown format", query: "?format=xml", expectedCode: http.StatusBadRequest,
+			expectedError: errors2.RequestInvalid},
+		{name: "unknown time zone", query: "?tz=Mars/Olympus", expectedCode: http.StatusBadRequest,
+			expectedError: errors2.ReportInvalidParams},
+		{name: "server time zone", query: "?tz=Local", expectedCode: http.StatusBadRequest,
+			expectedError: errors2.ReportInvalidParams},
+		{name: "invalid date", query: "?from=yesterday", expectedCode: http.StatusBadRequest,
+			expectedError: errors2.ReportInvalidParams},
+		{name: "empty range", query: "?from=2024-03-01&to=2024-03-01", expectedCode: http.StatusBadRequest,
+			expectedError: errors2.ReportInvalidParams},
+		{name: "range too long", query: "?from=2022-01-01&to=2024-01-01", expectedCode: http.StatusBadRequest,
+			expectedError: errors2.ReportInvalidParams},
+		{name: "db failure", revenueErr: db.ErrUnexpectedReport, expectedCode: http.StatusInternalServerError,
+			expectedError: errors2.ReportServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			handler, err := handlers.NewReportsHandler(lgr, &mocks.MockReportsDataService{
+				RevenueFunc: func(_ context.Context, q db.ReportQuery) ([]data.RevenueBucket, error) {
+					if tt.revenueErr != nil {
+						return nil, tt.revenueErr
+					}
+					if tt.wantQuery != nil {
+						tt.wantQuery(t, q)
+					}
+					return []data.RevenueBucket{{
+						Start:    time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+						Currency: money.USD,
+						Orders:   2,
+						Revenue:  money.MustParse("30.50"),
+					}}, nil
+				},
+			})
+			require.NoError(t, err)
+
+			c, r, recorder := setupTestContext()
+			r.GET("/reports/revenue", parseQuery(external.RevenueReportQuery{}), handler.Revenue)
+			c.Request, _ = http.NewRequest(http.MethodGet, "/reports/revenue"+tt.query, nil)
+			r.ServeHTTP(recorder, c.Request)
+
+			assert.Equal(t, tt.expectedCode, recorder.Code)
+			switch {
+			case tt.expectedError != "":
+				assertAPIError(t, recorder.Body.Bytes(), tt.expectedError)
+			case tt.wantCSV != "":
+				assert.Equal(t, "text/csv; charset=utf-8", recorder.Header().Get("Content-Type"))
+				assert.Contains(t, recorder.Header().Get("Content-Disposition"), `filename="revenue.csv"`)
+				assert.Equal(t, tt.wantCSV, recorder.Body.String())
+			case tt.wantBody != "":
+				assert.JSONEq(t, tt.wantBody, recorder.Body.String())
+			}
+		})
+	}
+}
+
+func TestReportsHandler_Reports(t *testing.T) {
+	t.Parallel()
+	svc := &mocks.MockReportsDataService{
+		OrdersByStatusFunc: func(context.Context, db.ReportQuery) ([]data.StatusCount, error) {
+			return []data.StatusCount{{Status: data.OrderPending, Orders: 4}}, nil
+		},
+		BasketSizeFunc: func(context.Context, db.ReportQuery) ([]data.BasketStats, error) {
+			return []data.BasketStats{{
+				Currency: money.EUR, Orders: 3, AverageTotal: money.MustParse("12.5"), AverageItems: 1.5,
+			}}, nil
+		},
+		TopProductsFunc: func(_ context.Context, _ db.ReportQuery, limit int64) ([]data.ProductSales, error) {
+			return []data.ProductSales{{
+				SKU: "P-1", Name: "Mug, large", Currency: money.USD, Quantity: limit, Revenue: money.MustParse("9"),
+			}}, nil
+		},
+	}
+	handler, err := handlers.NewReportsHandler(lgr, svc)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name         string
+		path         string
+		expectedCode int
+		wantBody     string
+	}{
+		{
+			name:         "orders by status csv",
+			path:         "/reports/orders-by-status?format=csv",
+			expectedCode: http.StatusOK,
+			wantBody:     "status,orders\nOrderPending,4\n",
+		},
+		{
+			name:         "basket size csv",
+			path:         "/reports/basket-size?format=csv",
+			expectedCode: http.StatusOK,
+			wantBody:     "currency,orders,averageTotal,averageItems\nEUR,3,12.5,1.50\n",
+		},
+		{
+			name:         "top products defaults to ten",
+			path:         "/reports/top-products?format=csv",
+			expectedCode: http.StatusOK,
+			wantBody:     "sku,name,currency,quantity,revenue\nP-1,\"Mug, large\",USD,10,9\n",
+		},
+		{
+			name:         "top products json with limit",
+			path:         "/reports/top-products?limit=3",
+			expectedCode: http.StatusOK,
+			wantBody:     `[{"sku":"P-1","name":"Mug, large","currency":"USD","quantity":3,"revenue":"9"}]`,
+		},
+		{
+			name:         "top products invalid limit",
+			path:         "/reports/top-products?limit=0",
+			expectedCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			c, r, recorder := setupTestContext()
+			r.GET("/reports/orders-by-status", parseQuery(external.ReportQuery{}), handler.OrdersByStatus)
+			r.GET("/reports/basket-size", parseQuery(external.ReportQuery{}), handler.BasketSize)
+			r.GET("/reports/top-products", parseQuery(external.TopProductsReportQuery{}), handler.TopProducts)
+			c.Request, _ = http.NewRequest(http.MethodGet, tt.path, nil)
+			r.ServeHTTP(recorder, c.Request)
+
+			assert.Equal(t, tt.expectedCode, recorder.Code)
+			if tt.expectedCode != http.StatusOK {
+				return
+			}
+			if json.Valid([]byte(tt.wantBody)) {
+				assert.JSONEq(t, tt.wantBody, recorder.Body.String())
+				return
+			}
+			assert.Equal(t, tt.wantBody, recorder.Body.String())
+		})
+	}
+}
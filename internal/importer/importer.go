@@ -0,0 +1,193 @@
+// Package importer loads historical orders from NDJSON or CSV files into the orders collection.
+package importer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin/binding"
+	"github.com/rameshsunkara/go-rest-api-example/internal/db"
+	"github.com/rameshsunkara/go-rest-api-example/internal/models/data"
+	"github.com/rameshsunkara/go-rest-api-example/internal/models/external"
+	"github.com/rameshsunkara/go-rest-api-example/internal/pricing"
+	"github.com/rameshsunkara/go-rest-api-example/pkg/logger"
+)
+
+// Supported import file formats.
+const (
+	FormatNDJSON = "ndjson"
+	FormatCSV    = "csv"
+)
+
+const (
+	// DefaultBatchSize is the number of orders written per bulk upsert.
+	DefaultBatchSize = 500
+)
+
+var (
+	ErrUnsupportedFormat = errors.New("unsupported import format, expected ndjson or csv")
+	ErrUnreadableInput   = errors.New("unable to read import input")
+)
+
+// Importer validates import rows and upserts valid orders in batches.
+type Importer struct {
+	oDataSvc  db.OrdersDataService
+	logger    logger.Logger
+	batchSize int
+}
+
+// New creates a new Importer. A batchSize of 0 falls back to DefaultBatchSize.
+func New(lgr logger.Logger, dSvc db.OrdersDataService, batchSize int) (*Importer, error) {
+	if lgr == nil || dSvc == nil {
+		return nil, errors.New("missing required inputs to create order importer")
+	}
+	if batchSize <= 0 {
+		batchSize = DefaultBatchSize
+	}
+	return &Importer{oDataSvc: dSvc, logger: lgr, batchSize: batchSize}, nil
+}
+
+// FormatFromFilename infers the import format from a file extension, returning "" when unknown.
+func FormatFromFilename(name string) string {
+	switch {
+	case strings.HasSuffix(name, ".csv"):
+		return FormatCSV
+	case strings.HasSuffix(name, ".ndjson"), strings.HasSuffix(name, ".jsonl"):
+		return FormatNDJSON
+	default:
+		return ""
+	}
+}
+
+// Run parses r in the given format, validates every row and, unless dryRun is set,
+// upserts the valid orders. Invalid rows are reported and skipped; they never abort the import.
+func (i *Importer) Run(ctx context.Context, r io.Reader, format string, dryRun bool) (*external.ImportReport, error) {
+	var rows []row
+	var err error
+	switch strings.ToLower(format) {
+	case FormatNDJSON:
+		rows, err = parseNDJSON(r)
+	case FormatCSV:
+		rows, err = parseCSV(r)
+	default:
+		return nil, ErrUnsupportedFormat
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	report := &external.ImportReport{
+		Format:    strings.ToLower(format),
+		DryRun:    dryRun,
+		TotalRows: len(rows),
+		Errors:    []external.ImportRowError{},
+	}
+
+	now := time.Now()
+	orders := make([]data.Order, 0, len(rows))
+	for _, rw := range rows {
+		order, vErr := toOrder(rw, now)
+		if vErr != nil {
+			report.Errors = append(report.Errors, external.ImportRowError{
+				Row:         rw.line,
+				ExternalRef: rw.input.ExternalRef,
+				Message:     vErr.Error(),
+			})
+			continue
+		}
+		orders = append(orders, order)
+	}
+	report.ValidRows = len(orders)
+
+	if dryRun {
+		i.logger.Info().
+			Int("totalRows", report.TotalRows).
+			Int("invalidRows", len(report.Errors)).
+			Msg("order import dry run completed")
+		return report, nil
+	}
+
+	for start := 0; start < len(orders); start += i.batchSize {
+		end := min(start+i.batchSize, len(orders))
+		res, uErr := i.oDataSvc.UpsertMany(ctx, orders[start:end])
+		if uErr != nil {
+			return report, fmt.Errorf("failed to import batch starting at order %d: %w", start, uErr)
+		}
+		report.Inserted += res.Inserted
+		report.Matched += res.Matched
+		report.Updated += res.Updated
+		report.Skipped += res.Skipped
+	}
+	i.logger.Info().
+		Int("totalRows", report.TotalRows).
+		Int("invalidRows", len(report.Errors)).
+		Int("inserted", int(report.Inserted)).
+		Int("matched", int(report.Matched)).
+		Int("updated", int(report.Updated)).
+		Int("skipped", int(report.Skipped)).
+		Msg("order import completed")
+	return report, nil
+}
+
+// toOrder validates a parsed row against the ImportOrderInput rules and converts it into a storage model.
+func toOrder(rw row, now time.Time) (data.Order, error) {
+	if rw.err != nil {
+		return data.Order{}, rw.err
+	}
+	in := rw.input
+	if err := binding.Validator.ValidateStruct(&in); err != nil {
+		return data.Order{}, err
+	}
+	if len(in.Products) == 0 {
+		return data.Order{}, errors.New("at least one product is required")
+	}
+	products := make([]data.Product, len(in.Products))
+	for idx := range in.Products {
+		if err := binding.Validator.ValidateStruct(&in.Products[idx]); err != nil {
+			return data.Order{}, fmt.Errorf("product %d: %w", idx+1, err)
+		}
+		p := in.Products[idx]
+		products[idx] = data.Product{SKU: data.NormalizeSKU(p.SKU), Name: p.Name, Price: p.Price, Quantity: p.Quantity}
+	}
+	currency, err := in.ResolveCurrency()
+	if err != nil {
+		return data.Order{}, err
+	}
+
+	status := in.Status
+	if status == "" {
+		status = data.OrderPending
+	} else if !status.IsValid() {
+		return data.Order{}, fmt.Errorf("unknown order status %q", status)
+	}
+
+	total, err := pricing.Subtotal(products, currency)
+	if err != nil {
+		return data.Order{}, err
+	}
+
+	createdAt := now
+	if in.CreatedAt != "" {
+		t, err := time.Parse(time.RFC3339, in.CreatedAt)
+		if err != nil {
+			return data.Order{}, fmt.Errorf("createdAt must be an RFC3339 timestamp: %w", err)
+		}
+		createdAt = t
+	}
+
+	return data.Order{
+		Version:     1,
+		CreatedAt:   createdAt,
+		UpdatedAt:   now,
+		Products:    products,
+		User:        in.User,
+		TotalAmount: total,
+		Currency:    currency,
+		Status:      status,
+		ExternalRef: in.ExternalRef,
+	}, nil
+}
@@ -0,0 +1,205 @@
+package importer_test
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rameshsunkara/go-rest-api-example/internal/db"
+	"github.com/rameshsunkara/go-rest-api-example/internal/db/mocks"
+	"github.com/rameshsunkara/go-rest-api-example/internal/importer"
+	"github.com/rameshsunkara/go-rest-api-example/internal/models/data"
+	"github.com/rameshsunkara/go-rest-api-example/pkg/logger"
+	"github.com/rameshsunkara/go-rest-api-example/pkg/money"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testLgr = logger.New("debug", os.Stdout)
+
+const ndjsonInput = `{"externalRef":"L-1","user":"a@example.com","status":"OrderDelivered","createdAt":"2019-03-01T10:00:00Z","products":[{"name":"P1","price":10,"quantity":2}]}
+{"externalRef":"L-2","products":[{"name":"P2","price":5,"quantity":1}]}
+
+{"externalRef":"L-3","products":[{"name":"","price":5,"quantity":1}]}
+{"externalRef":"L-4","status":"Lost","products":[{"name":"P4","price":5,"quantity":1}]}
+{"products":[{"name":"P5","price":5,"quantity":1}]}
+not json
+`
+
+const csvInput = `externalRef,user,status,createdAt,productName,price,quantity
+L-1,a@example.com,OrderShipped,2020-01-02T03:04:05Z,P1,10,2
+L-1,a@example.com,OrderShipped,2020-01-02T03:04:05Z,P2,2.5,4
+L-2,b@example.com,,,P3,1,1
+L-3,c@example.com,,yesterday,P4,1,1
+L-4,d@example.com,,,P5,abc,1
+`
+
+func recordingService(batches *[][]data.Order) *mocks.MockOrdersDataService {
+	return &mocks.MockOrdersDataService{
+		UpsertManyFunc: func(_ context.Context, orders []data.Order) (*db.UpsertResult, error) {
+			*batches = append(*batches, append([]data.Order(nil), orders...))
+			return &db.UpsertResult{Inserted: int64(len(orders))}, nil
+		},
+	}
+}
+
+func TestNew(t *testing.T) {
+	t.Parallel()
+	_, err := importer.New(nil, &mocks.MockOrdersDataService{}, 0)
+	require.Error(t, err)
+	_, err = importer.New(testLgr, nil, 0)
+	require.Error(t, err)
+	imp, err := importer.New(testLgr, &mocks.MockOrdersDataService{}, 0)
+	require.NoError(t, err)
+	assert.NotNil(t, imp)
+}
+
+func TestRunNDJSON(t *testing.T) {
+	t.Parallel()
+	var batches [][]data.Order
+	imp, err := importer.New(testLgr, recordingService(&batches), 1)
+	require.NoError(t, err)
+
+	report, err := imp.Run(context.Background(), strings.NewReader(ndjsonInput), importer.FormatNDJSON, false)
+	require.NoError(t, err)
+
+	assert.Equal(t, 6, report.TotalRows)
+	assert.Equal(t, 2, report.ValidRows)
+	assert.Equal(t, int64(2), report.Inserted)
+	require.Len(t, report.Errors, 4)
+	assert.Equal(t, 4, report.Errors[0].Row)
+	assert.Equal(t, "L-3", report.Errors[0].ExternalRef)
+	assert.Equal(t, 5, report.Errors[1].Row)
+	assert.Contains(t, report.Errors[1].Message, "unknown order status")
+	assert.Equal(t, 7, report.Errors[3].Row)
+
+	// batch size of 1 yields one bulk write per valid order
+	require.Len(t, batches, 2)
+	first := batches[0][0]
+	assert.Equal(t, "L-1", first.ExternalRef)
+	assert.Equal(t, data.OrderDelivered, first.Status)
+	assert.Equal(t, time.Date(2019, 3, 1, 10, 0, 0, 0, time.UTC), first.CreatedAt.UTC())
+	assert.Equal(t, money.MustParse("20"), first.TotalAmount)
+	assert.Equal(t, money.USD, first.Currency)
+	assert.Equal(t, data.OrderPending, batches[1][0].Status)
+}
+
+func TestRunCSV(t *testing.T) {
+	t.Parallel()
+	var batches [][]data.Order
+	imp, err := importer.New(testLgr, recordingService(&batches), 0)
+	require.NoError(t, err)
+
+	report, err := imp.Run(context.Background(), strings.NewReader(csvInput), importer.FormatCSV, false)
+	require.NoError(t, err)
+
+	assert.Equal(t, 4, report.TotalRows)
+	assert.Equal(t, 2, report.ValidRows)
+	require.Len(t, report.Errors, 2)
+	assert.Equal(t, "L-3", report.Errors[0].ExternalRef)
+	assert.Equal(t, "L-4", report.Errors[1].ExternalRef)
+
+	require.Len(t, batches, 1)
+	require.Len(t, batches[0], 2)
+	grouped := batches[0][0]
+	assert.Len(t, grouped.Products, 2)
+	assert.Equal(t, money.MustParse("30"), grouped.TotalAmount)
+	assert.Equal(t, data.OrderShipped, grouped.Status)
+}
+
+func TestRunDryRun(t *testing.T) {
+	t.Parallel()
+	imp, err := importer.New(testLgr, &mocks.MockOrdersDataService{
+		UpsertManyFunc: func(_ context.Context, _ []data.Order) (*db.UpsertResult, error) {
+			t.Error("dry run must not write to the database")
+			return nil, nil
+		},
+	}, 0)
+	require.NoError(t, err)
+
+	report, err := imp.Run(context.Background(), strings.NewReader(ndjsonInput), importer.FormatNDJSON, true)
+	require.NoError(t, err)
+	assert.True(t, report.DryRun)
+	assert.Equal(t, 2, report.ValidRows)
+	assert.Zero(t, report.Inserted)
+}
+
+func TestRunErrors(t *testing.T) {
+	t.Parallel()
+	imp, err := importer.New(testLgr, &mocks.MockOrdersDataService{
+		UpsertManyFunc: func(_ context.Context, _ []data.Order) (*db.UpsertResult, error) {
+			return nil, db.ErrUnexpectedUpsertOrder
+		},
+	}, 0)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name    string
+		input   string
+		format  string
+		wantErr error
+	}{
+		{name: "unsupported format", input: ndjsonInput, format: "xml", wantErr: importer.ErrUnsupportedFormat},
+		{name: "missing CSV column", input: "externalRef,price\nL-1,1\n", format: importer.FormatCSV,
+			wantErr: importer.ErrUnreadableInput},
+		{name: "upsert failure", input: ndjsonInput, format: importer.FormatNDJSON, wantErr: db.ErrUnexpectedUpsertOrder},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			_, runErr := imp.Run(context.Background(), strings.NewReader(tt.input), tt.format, false)
+			require.Error(t, runErr)
+			assert.ErrorIs(t, runErr, tt.wantErr)
+		})
+	}
+}
+
+func TestRunRejectsOutOfRangeTotals(t *testing.T) {
+	t.Parallel()
+	imp, err := importer.New(testLgr, &mocks.MockOrdersDataService{}, 0)
+	require.NoError(t, err)
+	input := `{"externalRef":"L-1","products":[{"name":"P1","price":"900000000000000","quantity":2}]}
+{"externalRef":"L-2","products":[{"name":"P2","price":1,"quantity":20000}]}
+`
+
+	report, err := imp.Run(context.Background(), strings.NewReader(input), importer.FormatNDJSON, true)
+	require.NoError(t, err)
+
+	assert.Zero(t, report.ValidRows)
+	require.Len(t, report.Errors, 2)
+	assert.Contains(t, report.Errors[0].Message, money.ErrOverflow.Error())
+	assert.Equal(t, "L-2", report.Errors[1].ExternalRef)
+}
+
+func TestFormatFromFilename(t *testing.T) {
+	t.Parallel()
+	assert.Equal(t, importer.FormatCSV, importer.FormatFromFilename("orders.csv"))
+	assert.Equal(t, importer.FormatNDJSON, importer.FormatFromFilename("orders.ndjson"))
+	assert.Equal(t, importer.FormatNDJSON, importer.FormatFromFilename("orders.jsonl"))
+	assert.Empty(t, importer.FormatFromFilename("orders.txt"))
+}
+
+func TestRunCurrency(t *testing.T) {
+	t.Parallel()
+	var batches [][]data.Order
+	imp, err := importer.New(testLgr, recordingService(&batches), 0)
+	require.NoError(t, err)
+
+	input := `{"externalRef":"C-1","currency":"eur","products":[{"name":"P1","price":"0.1","quantity":3}]}
+{"externalRef":"C-2","currency":"JPY","products":[{"name":"P2","price":"10.5","quantity":1}]}
+{"externalRef":"C-3","currency":"ABC","products":[{"name":"P3","price":"1","quantity":1}]}
+`
+	report, err := imp.Run(context.Background(), strings.NewReader(input), importer.FormatNDJSON, false)
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, report.ValidRows)
+	require.Len(t, report.Errors, 2)
+	assert.Contains(t, report.Errors[0].Message, "precision")
+	assert.Contains(t, report.Errors[1].Message, "unsupported currency")
+
+	require.Len(t, batches, 1)
+	assert.Equal(t, money.EUR, batches[0][0].Currency)
+	assert.Equal(t, "0.3", batches[0][0].TotalAmount.String())
+}
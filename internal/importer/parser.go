@@ -0,0 +1,152 @@
+package importer
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/rameshsunkara/go-rest-api-example/internal/models/data"
+	"github.com/rameshsunkara/go-rest-api-example/internal/models/external"
+	"github.com/rameshsunkara/go-rest-api-example/pkg/money"
+)
+
+const maxNDJSONLineBytes = 1 << 20
+
+// Required and optional CSV column names. Each CSV row describes one product line;
+// rows sharing the same externalRef are grouped into a single order.
+const (
+	csvColExternalRef = "externalRef"
+	csvColUser        = "user"
+	csvColStatus      = "status"
+	csvColCreatedAt   = "createdAt"
+	csvColProductName = "productName"
+	csvColPrice       = "price"
+	csvColQuantity    = "quantity"
+	csvColCurrency    = "currency"
+)
+
+var requiredCSVColumns = []string{csvColExternalRef, csvColProductName, csvColPrice, csvColQuantity}
+
+// row is a single parsed order together with the line it was read from.
+type row struct {
+	line  int
+	input external.ImportOrderInput
+	err   error
+}
+
+// parseNDJSON reads one order per non-empty line.
+func parseNDJSON(r io.Reader) ([]row, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, bufio.MaxScanTokenSize), maxNDJSONLineBytes)
+
+	var rows []row
+	line := 0
+	for scanner.Scan() {
+		line++
+		raw := bytes.TrimSpace(scanner.Bytes())
+		if len(raw) == 0 {
+			continue
+		}
+		var input external.ImportOrderInput
+		dec := json.NewDecoder(bytes.NewReader(raw))
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(&input); err != nil {
+			rows = append(rows, row{line: line, err: fmt.Errorf("malformed JSON: %w", err)})
+			continue
+		}
+		rows = append(rows, row{line: line, input: input})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrUnreadableInput, err)
+	}
+	return rows, nil
+}
+
+// parseCSV reads product lines and groups them by externalRef, preserving the order in which
+// each externalRef first appears. The reported line of a grouped order is that of its first row.
+func parseCSV(r io.Reader) ([]row, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("%w: %w", ErrUnreadableInput, err)
+	}
+	cols := make(map[string]int, len(header))
+	for i, h := range header {
+		cols[strings.TrimSpace(h)] = i
+	}
+	for _, c := range requiredCSVColumns {
+		if _, ok := cols[c]; !ok {
+			return nil, fmt.Errorf("%w: missing CSV column %q", ErrUnreadableInput, c)
+		}
+	}
+	get := func(rec []string, col string) string {
+		if i, ok := cols[col]; ok && i < len(rec) {
+			return strings.TrimSpace(rec[i])
+		}
+		return ""
+	}
+
+	var rows []row
+	byRef := make(map[string]int)
+	line := 1
+	for {
+		rec, readErr := reader.Read()
+		if errors.Is(readErr, io.EOF) {
+			break
+		}
+		line++
+		if readErr != nil {
+			rows = append(rows, row{line: line, err: fmt.Errorf("malformed CSV: %w", readErr)})
+			continue
+		}
+
+		ref := get(rec, csvColExternalRef)
+		product, pErr := parseCSVProduct(get(rec, csvColProductName), get(rec, csvColPrice), get(rec, csvColQuantity))
+		if pErr != nil {
+			pErr = fmt.Errorf("line %d: %w", line, pErr)
+		}
+
+		// a bad product line invalidates the whole order it belongs to
+		if idx, seen := byRef[ref]; seen && ref != "" {
+			if pErr != nil && rows[idx].err == nil {
+				rows[idx].err = pErr
+			}
+			rows[idx].input.Products = append(rows[idx].input.Products, product)
+			continue
+		}
+		input := external.ImportOrderInput{
+			Products:    []external.ImportProductInput{product},
+			Currency:    get(rec, csvColCurrency),
+			ExternalRef: ref,
+			User:        get(rec, csvColUser),
+			Status:      data.OrderStatus(get(rec, csvColStatus)),
+			CreatedAt:   get(rec, csvColCreatedAt),
+		}
+		byRef[ref] = len(rows)
+		rows = append(rows, row{line: line, input: input, err: pErr})
+	}
+	return rows, nil
+}
+
+func parseCSVProduct(name, price, quantity string) (external.ImportProductInput, error) {
+	p, err := money.Parse(price)
+	if err != nil {
+		return external.ImportProductInput{}, fmt.Errorf("invalid price %q", price)
+	}
+	q, err := strconv.ParseUint(quantity, 10, 64)
+	if err != nil {
+		return external.ImportProductInput{}, fmt.Errorf("invalid quantity %q", quantity)
+	}
+	return external.ImportProductInput{Name: name, Price: p, Quantity: q}, nil
+}
@@ -0,0 +1,68 @@
+// Package jobs contains background workers that run alongside the HTTP server.
+package jobs
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/rameshsunkara/go-rest-api-example/internal/db"
+	"github.com/rameshsunkara/go-rest-api-example/pkg/logger"
+)
+
+// OrderPurger periodically hard-deletes orders that have been soft-deleted for longer than the retention period.
+type OrderPurger struct {
+	oDataSvc  db.OrdersDataService
+	logger    logger.Logger
+	retention time.Duration
+	interval  time.Duration
+}
+
+// NewOrderPurger creates a new OrderPurger.
+func NewOrderPurger(
+	lgr logger.Logger,
+	dSvc db.OrdersDataService,
+	retention, interval time.Duration,
+) (*OrderPurger, error) {
+	if lgr == nil || dSvc == nil {
+		return nil, errors.New("missing required inputs to create order purger")
+	}
+	if retention <= 0 || interval <= 0 {
+		return nil, errors.New("order purger retention and interval must be positive")
+	}
+	return &OrderPurger{oDataSvc: dSvc, logger: lgr, retention: retention, interval: interval}, nil
+}
+
+// Run purges expired orders every interval until ctx is cancelled. It blocks, so callers run it in a goroutine.
+func (p *OrderPurger) Run(ctx context.Context) {
+	p.logger.Info().
+		Dur("retention", p.retention).
+		Dur("interval", p.interval).
+		Msg("starting deleted orders purger")
+
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+	for {
+		p.PurgeOnce(ctx)
+		select {
+		case <-ctx.Done():
+			p.logger.Info().Msg("stopping deleted orders purger")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// PurgeOnce removes all orders soft-deleted before now minus the retention period.
+func (p *OrderPurger) PurgeOnce(ctx context.Context) {
+	cutoff := time.Now().Add(-p.retention)
+	purged, err := p.oDataSvc.PurgeDeleted(ctx, cutoff)
+	if err != nil {
+		p.logger.Error().Err(err).Msg("failed to purge deleted orders")
+		return
+	}
+	if purged > 0 {
+		p.logger.Info().Int("purged", int(purged)).Str("deletedBefore", cutoff.UTC().Format(time.RFC3339)).
+			Msg("purged deleted orders")
+	}
+}
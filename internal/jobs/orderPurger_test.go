@@ -0,0 +1,92 @@
+package jobs_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/rameshsunkara/go-rest-api-example/internal/db"
+	"github.com/rameshsunkara/go-rest-api-example/internal/db/mocks"
+	"github.com/rameshsunkara/go-rest-api-example/internal/jobs"
+	"github.com/rameshsunkara/go-rest-api-example/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testLgr = logger.New("debug", os.Stdout)
+
+func TestNewOrderPurger(t *testing.T) {
+	t.Parallel()
+	svc := &mocks.MockOrdersDataService{}
+	tests := []struct {
+		name      string
+		lgr       logger.Logger
+		svc       db.OrdersDataService
+		retention time.Duration
+		interval  time.Duration
+		wantErr   bool
+	}{
+		{name: "success", lgr: testLgr, svc: svc, retention: time.Hour, interval: time.Minute},
+		{name: "nil logger", svc: svc, retention: time.Hour, interval: time.Minute, wantErr: true},
+		{name: "nil service", lgr: testLgr, retention: time.Hour, interval: time.Minute, wantErr: true},
+		{name: "zero retention", lgr: testLgr, svc: svc, interval: time.Minute, wantErr: true},
+		{name: "zero interval", lgr: testLgr, svc: svc, retention: time.Hour, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			p, err := jobs.NewOrderPurger(tt.lgr, tt.svc, tt.retention, tt.interval)
+			if tt.wantErr {
+				require.Error(t, err)
+				assert.Nil(t, p)
+				return
+			}
+			require.NoError(t, err)
+			assert.NotNil(t, p)
+		})
+	}
+}
+
+func TestOrderPurgerPurgeOnce(t *testing.T) {
+	t.Parallel()
+	var gotCutoff time.Time
+	p, err := jobs.NewOrderPurger(testLgr, &mocks.MockOrdersDataService{
+		PurgeDeletedFunc: func(_ context.Context, deletedBefore time.Time) (int64, error) {
+			gotCutoff = deletedBefore
+			return 3, nil
+		},
+	}, 24*time.Hour, time.Minute)
+	require.NoError(t, err)
+
+	p.PurgeOnce(context.Background())
+	assert.WithinDuration(t, time.Now().Add(-24*time.Hour), gotCutoff, time.Second)
+}
+
+func TestOrderPurgerRun(t *testing.T) {
+	t.Parallel()
+	var calls atomic.Int32
+	p, err := jobs.NewOrderPurger(testLgr, &mocks.MockOrdersDataService{
+		PurgeDeletedFunc: func(_ context.Context, _ time.Time) (int64, error) {
+			calls.Add(1)
+			return 0, errors.New("db down")
+		},
+	}, time.Hour, 10*time.Millisecond)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		p.Run(ctx)
+		close(done)
+	}()
+	assert.Eventually(t, func() bool { return calls.Load() >= 2 }, time.Second, 5*time.Millisecond)
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("purger did not stop after context cancellation")
+	}
+}
@@ -0,0 +1,101 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/rameshsunkara/go-rest-api-example/internal/db"
+	"github.com/rameshsunkara/go-rest-api-example/internal/models/data"
+	"github.com/rameshsunkara/go-rest-api-example/pkg/logger"
+)
+
+const (
+	// SweeperActor is recorded as the handler of the orders cancelled by the ReservationSweeper.
+	SweeperActor = "reservation-sweeper"
+
+	sweepBatchSize = 100
+)
+
+// ReservationSweeper periodically cancels orders that stayed in OrderPending for longer than the
+// reservation TTL and gives their reserved stock back to the inventory.
+type ReservationSweeper struct {
+	oDataSvc db.OrdersDataService
+	stock    db.InventoryDataService
+	logger   logger.Logger
+	ttl      time.Duration
+	interval time.Duration
+}
+
+// NewReservationSweeper creates a new ReservationSweeper.
+func NewReservationSweeper(
+	lgr logger.Logger,
+	dSvc db.OrdersDataService,
+	stock db.InventoryDataService,
+	ttl, interval time.Duration,
+) (*ReservationSweeper, error) {
+	if lgr == nil || dSvc == nil || stock == nil {
+		return nil, errors.New("missing required inputs to create reservation sweeper")
+	}
+	if ttl <= 0 || interval <= 0 {
+		return nil, errors.New("reservation sweeper ttl and interval must be positive")
+	}
+	return &ReservationSweeper{oDataSvc: dSvc, stock: stock, logger: lgr, ttl: ttl, interval: interval}, nil
+}
+
+// Run sweeps expired reservations every interval until ctx is cancelled. It blocks, so callers run it in a goroutine.
+func (s *ReservationSweeper) Run(ctx context.Context) {
+	s.logger.Info().
+		Dur("ttl", s.ttl).
+		Dur("interval", s.interval).
+		Msg("starting reservation sweeper")
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+	for {
+		s.SweepOnce(ctx)
+		select {
+		case <-ctx.Done():
+			s.logger.Info().Msg("stopping reservation sweeper")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// SweepOnce cancels every pending order created before now minus the ttl, in batches.
+// Orders that changed status meanwhile are skipped, the transition only matches pending ones.
+func (s *ReservationSweeper) SweepOnce(ctx context.Context) {
+	cutoff := time.Now().Add(-s.ttl)
+	note := data.OrderUpdate{Notes: "stock reservation expired", HandledBy: SweeperActor}
+	cancelled := 0
+	for {
+		orders, err := s.oDataSvc.GetPendingBefore(ctx, cutoff, sweepBatchSize)
+		if err != nil {
+			s.logger.Error().Err(err).Msg("failed to find expired reservations")
+			break
+		}
+		progressed := false
+		for _, order := range *orders {
+			if _, err = s.oDataSvc.Transition(ctx, order.ID, data.OrderCancelled, note, nil); err != nil {
+				if !errors.Is(err, db.ErrInvalidTransition) && !errors.Is(err, db.ErrPOIDNotFound) {
+					s.logger.Error().Err(err).Str("orderId", order.ID.Hex()).Msg("failed to cancel expired order")
+				}
+				continue
+			}
+			progressed = true
+			cancelled++
+			if err = s.stock.Release(ctx, order.Products); err != nil {
+				s.logger.Error().Err(err).Str("orderId", order.ID.Hex()).Msg("failed to release expired reservation")
+			}
+		}
+		// a short batch is the last one, a batch without progress would be returned again
+		if len(*orders) < sweepBatchSize || !progressed {
+			break
+		}
+	}
+	if cancelled > 0 {
+		s.logger.Info().Int("cancelled", cancelled).Str("createdBefore", cutoff.UTC().Format(time.RFC3339)).
+			Msg("cancelled orders with expired reservations")
+	}
+}
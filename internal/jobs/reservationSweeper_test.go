@@ -0,0 +1,118 @@
+package jobs_test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/rameshsunkara/go-rest-api-example/internal/db"
+	"github.com/rameshsunkara/go-rest-api-example/internal/db/mocks"
+	"github.com/rameshsunkara/go-rest-api-example/internal/jobs"
+	"github.com/rameshsunkara/go-rest-api-example/internal/models/data"
+	"github.com/rameshsunkara/go-rest-api-example/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestNewReservationSweeper(t *testing.T) {
+	t.Parallel()
+	svc := &mocks.MockOrdersDataService{}
+	stock := &mocks.MockInventoryDataService{}
+	tests := []struct {
+		name     string
+		lgr      logger.Logger
+		svc      db.OrdersDataService
+		stock    db.InventoryDataService
+		ttl      time.Duration
+		interval time.Duration
+		wantErr  bool
+	}{
+		{name: "success", lgr: testLgr, svc: svc, stock: stock, ttl: time.Hour, interval: time.Minute},
+		{name: "nil logger", svc: svc, stock: stock, ttl: time.Hour, interval: time.Minute, wantErr: true},
+		{name: "nil service", lgr: testLgr, stock: stock, ttl: time.Hour, interval: time.Minute, wantErr: true},
+		{name: "nil inventory", lgr: testLgr, svc: svc, ttl: time.Hour, interval: time.Minute, wantErr: true},
+		{name: "zero ttl", lgr: testLgr, svc: svc, stock: stock, interval: time.Minute, wantErr: true},
+		{name: "zero interval", lgr: testLgr, svc: svc, stock: stock, ttl: time.Hour, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			s, err := jobs.NewReservationSweeper(tt.lgr, tt.svc, tt.stock, tt.ttl, tt.interval)
+			if tt.wantErr {
+				require.Error(t, err)
+				assert.Nil(t, s)
+				return
+			}
+			require.NoError(t, err)
+			assert.NotNil(t, s)
+		})
+	}
+}
+
+func TestReservationSweeperSweepOnce(t *testing.T) {
+	t.Parallel()
+	expired := primitive.NewObjectID()
+	raced := primitive.NewObjectID()
+	products := []data.Product{{SKU: "P-1", Quantity: 2}}
+	var gotCutoff time.Time
+	var cancelled []primitive.ObjectID
+	var released [][]data.Product
+	s, err := jobs.NewReservationSweeper(testLgr, &mocks.MockOrdersDataService{
+		GetPendingBeforeFunc: func(_ context.Context, createdBefore time.Time, _ int64) (*[]data.Order, error) {
+			gotCutoff = createdBefore
+			return &[]data.Order{{ID: expired, Products: products}, {ID: raced}}, nil
+		},
+		TransitionFunc: func(
+			_ context.Context, id primitive.ObjectID, to data.OrderStatus, note data.OrderUpdate, shipment *data.Shipment,
+		) (*data.Order, error) {
+			assert.Equal(t, data.OrderCancelled, to)
+			assert.Nil(t, shipment)
+			assert.Equal(t, jobs.SweeperActor, note.HandledBy)
+			if id == raced {
+				return nil, db.ErrInvalidTransition
+			}
+			cancelled = append(cancelled, id)
+			return &data.Order{ID: id, Status: to}, nil
+		},
+	}, &mocks.MockInventoryDataService{
+		ReleaseFunc: func(_ context.Context, p []data.Product) error {
+			released = append(released, p)
+			return nil
+		},
+	}, 30*time.Minute, time.Minute)
+	require.NoError(t, err)
+
+	s.SweepOnce(context.Background())
+	assert.WithinDuration(t, time.Now().Add(-30*time.Minute), gotCutoff, time.Second)
+	assert.Equal(t, []primitive.ObjectID{expired}, cancelled)
+	assert.Equal(t, [][]data.Product{products}, released)
+}
+
+func TestReservationSweeperRun(t *testing.T) {
+	t.Parallel()
+	var calls atomic.Int32
+	s, err := jobs.NewReservationSweeper(testLgr, &mocks.MockOrdersDataService{
+		GetPendingBeforeFunc: func(context.Context, time.Time, int64) (*[]data.Order, error) {
+			calls.Add(1)
+			return nil, errors.New("db down")
+		},
+	}, &mocks.MockInventoryDataService{}, time.Hour, 10*time.Millisecond)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		s.Run(ctx)
+		close(done)
+	}()
+	assert.Eventually(t, func() bool { return calls.Load() >= 2 }, time.Second, 5*time.Millisecond)
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("sweeper did not stop after context cancellation")
+	}
+}
@@ -0,0 +1,65 @@
+// Package metrics holds the Prometheus collectors of the service, they are registered on a dedicated registry
+// served on /metrics so tests can create their own and inspect it.
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+)
+
+// Metrics are the collectors of the service. Routes are labelled by their template and tenants by their
+// configured ID, so requests cannot grow the cardinality of a metric.
+type Metrics struct {
+	Registry *prometheus.Registry
+
+	HTTPRequests     *prometheus.CounterVec   // route, method, status
+	HTTPDuration     *prometheus.HistogramVec // route, method, status
+	HTTPInFlight     *prometheus.GaugeVec     // route, method
+	TenantRequests   *prometheus.CounterVec   // tenant, status
+	OrdersCreated    *prometheus.CounterVec   // source, api or import
+	OrderTransitions *prometheus.CounterVec   // status the orders moved to
+}
+
+// New returns the collectors of the service registered on a new registry, along with the Go runtime and
+// process collectors.
+func New() *Metrics {
+	m := &Metrics{
+		Registry: prometheus.NewRegistry(),
+		HTTPRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "http_requests_total",
+			Help: "Requests served per route, method and response status.",
+		}, []string{"route", "method", "status"}),
+		HTTPDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "http_request_duration_seconds",
+			Help:    "Time taken to serve requests per route, method and response status.",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"route", "method", "status"}),
+		HTTPInFlight: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "http_requests_in_flight",
+			Help: "Requests being served per route and method.",
+		}, []string{"route", "method"}),
+		TenantRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "ecommerce_tenant_requests_total",
+			Help: "Requests served per tenant and response status.",
+		}, []string{"tenant", "status"}),
+		OrdersCreated: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "ecommerce_orders_created_total",
+			Help: "Orders created through the API or inserted by imports.",
+		}, []string{"source"}),
+		OrderTransitions: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "ecommerce_order_status_transitions_total",
+			Help: "Order status changes per status the orders moved to.",
+		}, []string{"status"}),
+	}
+	m.Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.HTTPRequests,
+		m.HTTPDuration,
+		m.HTTPInFlight,
+		m.TenantRequests,
+		m.OrdersCreated,
+		m.OrderTransitions,
+	)
+	return m
+}
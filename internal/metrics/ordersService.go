@@ -0,0 +1,64 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+
+	"github.com/rameshsunkara/go-rest-api-example/internal/db"
+	"github.com/rameshsunkara/go-rest-api-example/internal/models/data"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Sources of created orders.
+const (
+	SourceAPI    = "api"
+	SourceImport = "import"
+)
+
+// OrdersService decorates an OrdersDataService and counts the orders created and the status changes that
+// succeeded. Every other call is passed through untouched.
+type OrdersService struct {
+	db.OrdersDataService
+	metrics *Metrics
+}
+
+// NewOrdersService creates a new counted OrdersDataService.
+func NewOrdersService(m *Metrics, orders db.OrdersDataService) (*OrdersService, error) {
+	if m == nil || orders == nil {
+		return nil, errors.New("missing required inputs to create counted orders service")
+	}
+	return &OrdersService{OrdersDataService: orders, metrics: m}, nil
+}
+
+// Create inserts the order and counts it.
+func (s *OrdersService) Create(ctx context.Context, po *data.Order) (string, error) {
+	id, err := s.OrdersDataService.Create(ctx, po)
+	if err == nil {
+		s.metrics.OrdersCreated.WithLabelValues(SourceAPI).Inc()
+	}
+	return id, err
+}
+
+// UpsertMany imports the orders and counts the inserted ones.
+func (s *OrdersService) UpsertMany(ctx context.Context, orders []data.Order) (*db.UpsertResult, error) {
+	res, err := s.OrdersDataService.UpsertMany(ctx, orders)
+	if err == nil && res != nil {
+		s.metrics.OrdersCreated.WithLabelValues(SourceImport).Add(float64(res.Inserted))
+	}
+	return res, err
+}
+
+// Transition changes the order status and counts the change.
+func (s *OrdersService) Transition(
+	ctx context.Context,
+	id primitive.ObjectID,
+	to data.OrderStatus,
+	note data.OrderUpdate,
+	shipment *data.Shipment,
+) (*data.Order, error) {
+	after, err := s.OrdersDataService.Transition(ctx, id, to, note, shipment)
+	if err == nil {
+		s.metrics.OrderTransitions.WithLabelValues(string(to)).Inc()
+	}
+	return after, err
+}
@@ -0,0 +1,71 @@
+package metrics_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/rameshsunkara/go-rest-api-example/internal/db"
+	"github.com/rameshsunkara/go-rest-api-example/internal/db/mocks"
+	"github.com/rameshsunkara/go-rest-api-example/internal/metrics"
+	"github.com/rameshsunkara/go-rest-api-example/internal/models/data"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var errWrite = errors.New("write failed")
+
+func TestNewOrdersService(t *testing.T) {
+	t.Parallel()
+	_, err := metrics.NewOrdersService(nil, &mocks.MockOrdersDataService{})
+	require.Error(t, err)
+	_, err = metrics.NewOrdersService(metrics.New(), nil)
+	require.Error(t, err)
+	svc, err := metrics.NewOrdersService(metrics.New(), &mocks.MockOrdersDataService{})
+	require.NoError(t, err)
+	assert.NotNil(t, svc)
+}
+
+func TestOrdersService(t *testing.T) {
+	t.Parallel()
+	m := metrics.New()
+	var fail bool
+	svc, err := metrics.NewOrdersService(m, &mocks.MockOrdersDataService{
+		CreateFunc: func(_ context.Context, _ *data.Order) (string, error) {
+			if fail {
+				return "", errWrite
+			}
+			return primitive.NewObjectID().Hex(), nil
+		},
+		UpsertManyFunc: func(_ context.Context, _ []data.Order) (*db.UpsertResult, error) {
+			if fail {
+				return nil, errWrite
+			}
+			return &db.UpsertResult{Inserted: 2, Updated: 1}, nil
+		},
+		TransitionFunc: func(
+			_ context.Context, _ primitive.ObjectID, to data.OrderStatus, _ data.OrderUpdate, _ *data.Shipment,
+		) (*data.Order, error) {
+			if fail {
+				return nil, errWrite
+			}
+			return &data.Order{Status: to}, nil
+		},
+	})
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	for _, fail = range []bool{false, true} {
+		_, _ = svc.Create(ctx, &data.Order{})
+		_, _ = svc.UpsertMany(ctx, make([]data.Order, 3))
+		_, _ = svc.Transition(ctx, primitive.NewObjectID(), data.OrderShipped, data.OrderUpdate{}, nil)
+		_, _ = svc.Transition(ctx, primitive.NewObjectID(), data.OrderCancelled, data.OrderUpdate{}, nil)
+	}
+
+	assert.InDelta(t, 1, testutil.ToFloat64(m.OrdersCreated.WithLabelValues(metrics.SourceAPI)), 0)
+	assert.InDelta(t, 2, testutil.ToFloat64(m.OrdersCreated.WithLabelValues(metrics.SourceImport)), 0)
+	assert.InDelta(t, 1, testutil.ToFloat64(m.OrderTransitions.WithLabelValues(string(data.OrderShipped))), 0)
+	assert.InDelta(t, 1, testutil.ToFloat64(m.OrderTransitions.WithLabelValues(string(data.OrderCancelled))), 0)
+}
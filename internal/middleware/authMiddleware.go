@@ -1,10 +1,49 @@
 package middleware
 
-import "github.com/gin-gonic/gin"
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/rameshsunkara/go-rest-api-example/internal/requestctx"
+	"github.com/rameshsunkara/go-rest-api-example/pkg/logger"
+)
 
+const (
+	// PrincipalKey is the request context key (as ContextKey) holding the authenticated caller's identity.
+	PrincipalKey = requestctx.PrincipalKey
+	// SubjectClaim is the token claim naming the authenticated caller.
+	SubjectClaim = "sub"
+	// APIKeyIDHeader is the header the gateway sets to the ID of the API key it verified, requests carrying a
+	// client set value must be stripped of it by the gateway.
+	APIKeyIDHeader = "X-API-Key-ID"
+
+	apiKeyPrincipalPrefix = "apikey:"
+)
+
+// AuthMiddleware stores the identity of the caller under PrincipalKey in the request context, audit entries
+// record it as their actor and the request logger gets a principal field. It is the subject of the verified
+// token claims, then the API key ID set by the gateway, requests with neither are anonymous.
 func AuthMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// TODO: Generally we would valid JWT token here
+		if p := principal(c); p != "" {
+			ctx := requestctx.WithPrincipal(c.Request.Context(), p)
+			// the request logger, see logger.FromContext, is attached ahead of the auth middleware
+			if l := logger.FromContextOr(ctx, nil); l != nil {
+				ctx = logger.NewContext(ctx, l.With().Str(PrincipalKey, p).Logger())
+			}
+			c.Request = c.Request.WithContext(ctx)
+		}
 		c.Next()
 	}
 }
+
+func principal(c *gin.Context) string {
+	if claims, ok := c.Request.Context().Value(ContextKey(ClaimsKey)).(map[string]any); ok {
+		if sub, isStr := claims[SubjectClaim].(string); isStr && sub != "" {
+			return sub
+		}
+	}
+	if keyID := c.GetHeader(APIKeyIDHeader); keyID != "" {
+		return apiKeyPrincipalPrefix + keyID
+	}
+	return ""
+}
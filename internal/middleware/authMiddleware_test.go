@@ -1,12 +1,14 @@
 package middleware_test
 
 import (
+	"context"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 
 	"github.com/gin-gonic/gin"
 	"github.com/rameshsunkara/go-rest-api-example/internal/middleware"
+	"github.com/rameshsunkara/go-rest-api-example/internal/requestctx"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -58,3 +60,43 @@ func TestAuthMiddleware_WithNext(t *testing.T) {
 	// Ensure that Next() was called
 	assert.True(t, nextCalled, "Next() should be called")
 }
+
+func TestAuthMiddlewarePrincipal(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name   string
+		claims map[string]any
+		keyID  string
+		want   string
+	}{
+		{name: "token subject", claims: map[string]any{"sub": "jane@example.com"}, keyID: "key-1",
+			want: "jane@example.com"},
+		{name: "api key", claims: map[string]any{"tenant": "acme"}, keyID: "key-1", want: "apikey:key-1"},
+		{name: "anonymous"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			router := gin.New()
+			router.Use(func(c *gin.Context) {
+				if tt.claims != nil {
+					ctx := context.WithValue(c.Request.Context(), middleware.ContextKey(middleware.ClaimsKey), tt.claims)
+					c.Request = c.Request.WithContext(ctx)
+				}
+			}, middleware.AuthMiddleware())
+			var got string
+			router.GET("/test", func(c *gin.Context) {
+				got = requestctx.Principal(c.Request.Context())
+				c.Status(http.StatusOK)
+			})
+
+			req, _ := http.NewRequest(http.MethodGet, "/test", nil)
+			if tt.keyID != "" {
+				req.Header.Set(middleware.APIKeyIDHeader, tt.keyID)
+			}
+			router.ServeHTTP(httptest.NewRecorder(), req)
+
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,30 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DeprecationMiddleware announces that an API version is deprecated.
+// It sets the Deprecation header (RFC 9745), the Sunset header (RFC 8594) when a sunset date is known
+// and links the successor version when there is one.
+func DeprecationMiddleware(deprecatedAt, sunset time.Time, successor string) gin.HandlerFunc {
+	deprecation := "@" + strconv.FormatInt(deprecatedAt.Unix(), 10)
+	var sunsetHeader string
+	if !sunset.IsZero() {
+		sunsetHeader = sunset.UTC().Format(http.TimeFormat)
+	}
+	return func(c *gin.Context) {
+		c.Writer.Header().Set("Deprecation", deprecation)
+		if sunsetHeader != "" {
+			c.Writer.Header().Set("Sunset", sunsetHeader)
+		}
+		if successor != "" {
+			c.Writer.Header().Add("Link", "<"+successor+`>; rel="successor-version"`)
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,51 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rameshsunkara/go-rest-api-example/internal/middleware"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeprecationMiddleware(t *testing.T) {
+	deprecatedAt := time.Date(2026, time.January, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name       string
+		sunset     time.Time
+		successor  string
+		wantSunset string
+		wantLink   string
+	}{
+		{
+			name:       "with sunset and successor",
+			sunset:     time.Date(2026, time.July, 1, 0, 0, 0, 0, time.UTC),
+			successor:  "/ecommerce/v2",
+			wantSunset: "Wed, 01 Jul 2026 00:00:00 GMT",
+			wantLink:   `</ecommerce/v2>; rel="successor-version"`,
+		},
+		{name: "without sunset and successor"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gin.SetMode(gin.TestMode)
+			router := gin.New()
+			router.Use(middleware.DeprecationMiddleware(deprecatedAt, tt.sunset, tt.successor))
+			router.GET("/test", func(c *gin.Context) {
+				c.String(http.StatusOK, "Test")
+			})
+
+			req, _ := http.NewRequest(http.MethodGet, "/test", nil)
+			resp := httptest.NewRecorder()
+			router.ServeHTTP(resp, req)
+
+			assert.Equal(t, http.StatusOK, resp.Code)
+			assert.Equal(t, "@1767225600", resp.Header().Get("Deprecation"))
+			assert.Equal(t, tt.wantSunset, resp.Header().Get("Sunset"))
+			assert.Equal(t, tt.wantLink, resp.Header().Get("Link"))
+		})
+	}
+}
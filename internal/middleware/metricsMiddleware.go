@@ -0,0 +1,34 @@
+package middleware
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rameshsunkara/go-rest-api-example/internal/metrics"
+)
+
+// unmatchedRoute labels requests no route matched, their paths would grow the cardinality of the metrics.
+const unmatchedRoute = "unmatched"
+
+// MetricsMiddleware records the rate, errors and duration of requests per route template, method and status,
+// along with the requests in flight.
+func MetricsMiddleware(m *metrics.Metrics) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		route := c.FullPath()
+		if route == "" {
+			route = unmatchedRoute
+		}
+		method := c.Request.Method
+		inFlight := m.HTTPInFlight.WithLabelValues(route, method)
+		inFlight.Inc()
+		defer inFlight.Dec()
+		start := time.Now()
+
+		c.Next()
+
+		status := strconv.Itoa(c.Writer.Status())
+		m.HTTPRequests.WithLabelValues(route, method, status).Inc()
+		m.HTTPDuration.WithLabelValues(route, method, status).Observe(time.Since(start).Seconds())
+	}
+}
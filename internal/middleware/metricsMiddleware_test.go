@@ -0,0 +1,42 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/rameshsunkara/go-rest-api-example/internal/metrics"
+	"github.com/rameshsunkara/go-rest-api-example/internal/middleware"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetricsMiddleware(t *testing.T) {
+	m := metrics.New()
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(middleware.MetricsMiddleware(m))
+	var inFlight float64
+	r.GET("/orders/:id", func(c *gin.Context) {
+		inFlight = testutil.ToFloat64(m.HTTPInFlight.WithLabelValues("/orders/:id", http.MethodGet))
+		c.Status(http.StatusOK)
+	})
+	r.GET("/fail", func(c *gin.Context) { c.Status(http.StatusInternalServerError) })
+
+	for _, path := range []string{"/orders/1", "/orders/2", "/fail", "/unknown/1", "/unknown/2"} {
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	assert.InDelta(t, 1, inFlight, 0)
+	assert.InDelta(t, 0, testutil.ToFloat64(m.HTTPInFlight.WithLabelValues("/orders/:id", http.MethodGet)), 0)
+	assert.InDelta(t, 2, testutil.ToFloat64(m.HTTPRequests.WithLabelValues("/orders/:id", http.MethodGet, "200")), 0)
+	assert.InDelta(t, 1, testutil.ToFloat64(m.HTTPRequests.WithLabelValues("/fail", http.MethodGet, "500")), 0)
+	assert.InDelta(t, 2, testutil.ToFloat64(m.HTTPRequests.WithLabelValues("unmatched", http.MethodGet, "404")), 0)
+	// one series per route, method and status
+	assert.Equal(t, 3, testutil.CollectAndCount(m.HTTPRequests))
+	count, err := testutil.GatherAndCount(m.Registry, "http_request_duration_seconds")
+	require.NoError(t, err)
+	assert.Equal(t, 3, count)
+}
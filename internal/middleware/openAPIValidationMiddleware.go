@@ -0,0 +1,95 @@
+package middleware
+
+import (
+	"bytes"
+	"io"
+	"mime"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rameshsunkara/go-rest-api-example/internal/errors"
+	"github.com/rameshsunkara/go-rest-api-example/internal/models/external"
+	"github.com/rameshsunkara/go-rest-api-example/internal/openapi"
+	"github.com/rameshsunkara/go-rest-api-example/pkg/logger"
+)
+
+// OpenAPIValidationMiddleware rejects requests that break the OpenAPI document with a 400 listing the violations.
+// When validateResponses is set, JSON responses are checked too and violations are logged, this is meant
+// for dev and test environments. Routes missing from the document are not validated.
+func OpenAPIValidationMiddleware(lgr logger.Logger, v *openapi.Validator, validateResponses bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		op, ok := v.Operation(c.Request.Method, c.FullPath())
+		if !ok {
+			c.Next()
+			return
+		}
+		l, requestID := lgr.WithReqID(c)
+
+		var body []byte
+		if op.RequestBody != nil && c.Request.Body != nil {
+			var err error
+			if body, err = io.ReadAll(c.Request.Body); err != nil {
+				l.Error().Err(err).Msg("failed to read request body")
+				abortInvalidRequest(c, requestID, []openapi.Violation{{In: "body", Message: "could not be read"}})
+				return
+			}
+			c.Request.Body = io.NopCloser(bytes.NewReader(body))
+		}
+		if violations := v.Request(op, c.Request, c.Param, body); len(violations) > 0 {
+			l.Warn().
+				Str("method", c.Request.Method).
+				Str("path", c.FullPath()).
+				Interface("violations", violations).
+				Msg("request does not match the OpenAPI spec")
+			abortInvalidRequest(c, requestID, violations)
+			return
+		}
+
+		// sparse fieldsets leave out required fields on purpose
+		if !validateResponses || c.Query(external.FieldsQueryParam) != "" {
+			c.Next()
+			return
+		}
+		w := &bodyRecorder{ResponseWriter: c.Writer}
+		c.Writer = w
+		c.Next()
+		if ct, _, err := mime.ParseMediaType(w.Header().Get("Content-Type")); err != nil || ct != "application/json" {
+			return
+		}
+		if violations := v.Response(op, w.Status(), w.body.Bytes()); len(violations) > 0 {
+			l.Warn().
+				Str("method", c.Request.Method).
+				Str("path", c.FullPath()).
+				Int("respStatus", w.Status()).
+				Interface("violations", violations).
+				Msg("response does not match the OpenAPI spec")
+		}
+	}
+}
+
+func abortInvalidRequest(c *gin.Context, requestID string, violations []openapi.Violation) {
+	apiErr := &external.APIError{
+		HTTPStatusCode: http.StatusBadRequest,
+		ErrorCode:      errors.RequestInvalid,
+		Message:        "request does not match the API specification",
+		DebugID:        requestID,
+		Details:        violations,
+	}
+	c.AbortWithStatusJSON(apiErr.HTTPStatusCode, apiErr)
+}
+
+// bodyRecorder keeps a copy of the response body.
+type bodyRecorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *bodyRecorder) Write(b []byte) (int, error) {
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *bodyRecorder) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
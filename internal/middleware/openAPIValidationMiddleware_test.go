@@ -0,0 +1,112 @@
+package middleware_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rameshsunkara/go-rest-api-example/internal/errors"
+	"github.com/rameshsunkara/go-rest-api-example/internal/middleware"
+	"github.com/rameshsunkara/go-rest-api-example/internal/models/external"
+	"github.com/rameshsunkara/go-rest-api-example/internal/openapi"
+	"github.com/rameshsunkara/go-rest-api-example/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type validationInput struct {
+	Name string `json:"name" binding:"required"`
+}
+
+type validationOutput struct {
+	Name string `json:"name"`
+}
+
+func newValidationRouter(t *testing.T, logs io.Writer, response string) *gin.Engine {
+	t.Helper()
+	b := openapi.New(openapi.Info{Title: "Test", Version: "1"}, external.APIError{})
+	b.Add(http.MethodPost, "/items/:id", openapi.Route{
+		ID:        "createItem",
+		Params:    []openapi.Parameter{{Name: "id", In: "path", Required: true, Schema: openapi.String("^[0-9]+$")}},
+		Body:      validationInput{},
+		Responses: map[int]any{http.StatusCreated: validationOutput{}},
+	})
+	doc, err := b.Document()
+	require.NoError(t, err)
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(middleware.OpenAPIValidationMiddleware(logger.New("info", logs), openapi.NewValidator(doc), true))
+	handler := func(c *gin.Context) {
+		var in validationInput
+		if err := c.ShouldBindJSON(&in); err != nil {
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+		c.Data(http.StatusCreated, "application/json", []byte(response))
+	}
+	r.POST("/items/:id", handler)
+	r.GET("/undocumented", func(c *gin.Context) { c.Status(http.StatusOK) })
+	return r
+}
+
+func TestOpenAPIValidationMiddleware(t *testing.T) {
+	tests := []struct {
+		name          string
+		method        string
+		path          string
+		body          string
+		response      string
+		wantCode      int
+		wantViolation string
+		wantLog       bool
+	}{
+		{
+			name: "valid", method: http.MethodPost, path: "/items/1", body: `{"name":"mug"}`,
+			response: `{"name":"mug"}`, wantCode: http.StatusCreated,
+		},
+		{
+			name: "invalid path", method: http.MethodPost, path: "/items/x", body: `{"name":"mug"}`,
+			wantCode: http.StatusBadRequest, wantViolation: "id",
+		},
+		{
+			name: "invalid body", method: http.MethodPost, path: "/items/1", body: `{"name":1}`,
+			wantCode: http.StatusBadRequest, wantViolation: "name",
+		},
+		{
+			name: "response breaks the spec", method: http.MethodPost, path: "/items/1", body: `{"name":"mug"}`,
+			response: `{"name":2}`, wantCode: http.StatusCreated, wantLog: true,
+		},
+		{name: "undocumented route", method: http.MethodGet, path: "/undocumented?any=1", wantCode: http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var logs bytes.Buffer
+			r := newValidationRouter(t, &logs, tt.response)
+			req := httptest.NewRequest(tt.method, tt.path, bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			resp := httptest.NewRecorder()
+			r.ServeHTTP(resp, req)
+
+			require.Equal(t, tt.wantCode, resp.Code)
+			if tt.wantViolation != "" {
+				var apiErr struct {
+					ErrorCode string              `json:"errorCode"`
+					Details   []openapi.Violation `json:"details"`
+				}
+				require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &apiErr))
+				assert.Equal(t, errors.RequestInvalid, apiErr.ErrorCode)
+				require.Len(t, apiErr.Details, 1)
+				assert.Equal(t, tt.wantViolation, apiErr.Details[0].Name)
+			}
+			if tt.response != "" {
+				assert.JSONEq(t, tt.response, resp.Body.String(), "the response is passed through")
+			}
+			assert.Equal(t, tt.wantLog, bytes.Contains(logs.Bytes(), []byte("response does not match")))
+		})
+	}
+}
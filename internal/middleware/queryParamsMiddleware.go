@@ -0,0 +1,34 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/rameshsunkara/go-rest-api-example/internal/openapi"
+	"github.com/rameshsunkara/go-rest-api-example/internal/query"
+	"github.com/rameshsunkara/go-rest-api-example/pkg/logger"
+)
+
+// QueryParamsMiddleware parses the query parameters of a route with its schema and stores the result under
+// query.ContextKey, handlers read it with query.From. Unknown and invalid parameters are rejected with a 400
+// listing them, like the requests breaking the OpenAPI document are.
+func QueryParamsMiddleware(lgr logger.Logger, schema *query.Schema) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		parsed, violations := schema.Parse(c.Request.URL.Query())
+		if len(violations) > 0 {
+			l, requestID := lgr.WithReqID(c)
+			details := make([]openapi.Violation, 0, len(violations))
+			for _, v := range violations {
+				details = append(details, openapi.Violation{In: "query", Name: v.Name, Message: v.Message})
+			}
+			l.Warn().
+				Str("method", c.Request.Method).
+				Str("path", c.FullPath()).
+				Str("query", c.Request.URL.RawQuery).
+				Interface("violations", details).
+				Msg("request has invalid query params")
+			abortInvalidRequest(c, requestID, details)
+			return
+		}
+		c.Set(query.ContextKey, parsed)
+		c.Next()
+	}
+}
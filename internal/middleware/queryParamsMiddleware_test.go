@@ -0,0 +1,86 @@
+package middleware_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rameshsunkara/go-rest-api-example/internal/errors"
+	"github.com/rameshsunkara/go-rest-api-example/internal/middleware"
+	"github.com/rameshsunkara/go-rest-api-example/internal/models/external"
+	"github.com/rameshsunkara/go-rest-api-example/internal/openapi"
+	"github.com/rameshsunkara/go-rest-api-example/internal/query"
+	"github.com/rameshsunkara/go-rest-api-example/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueryParamsMiddleware(t *testing.T) {
+	tests := []struct {
+		name           string
+		query          string
+		expectedCode   int
+		wantQuery      external.OrderListQuery
+		wantViolations []openapi.Violation
+	}{
+		{
+			name: "defaults", expectedCode: http.StatusOK,
+			wantQuery: external.OrderListQuery{LimitQuery: external.LimitQuery{Limit: 100}},
+		},
+		{
+			name: "valid params", query: "?limit=10&offset=5&fields=orderId", expectedCode: http.StatusOK,
+			wantQuery: external.OrderListQuery{
+				LimitQuery: external.LimitQuery{Limit: 10}, Offset: 5,
+				FieldsQuery: external.FieldsQuery{Fields: "orderId"},
+			},
+		},
+		{
+			name: "unknown param", query: "?example=10", expectedCode: http.StatusBadRequest,
+			wantViolations: []openapi.Violation{{In: "query", Name: "example", Message: "is not supported"}},
+		},
+		{
+			name: "not an integer", query: "?limit=ABC", expectedCode: http.StatusBadRequest,
+			wantViolations: []openapi.Violation{{In: "query", Name: "limit", Message: "must be an integer"}},
+		},
+		{
+			name: "out of range", query: "?limit=10000&offset=-1", expectedCode: http.StatusBadRequest,
+			wantViolations: []openapi.Violation{
+				{In: "query", Name: "limit", Message: "must be at most 100"},
+				{In: "query", Name: "offset", Message: "must be at least 0"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := httptest.NewRecorder()
+			gin.SetMode(gin.TestMode)
+			_, r := gin.CreateTestContext(resp)
+			var got external.OrderListQuery
+			r.GET("/orders",
+				middleware.QueryParamsMiddleware(logger.New("info", os.Stdout), query.MustSchema(external.OrderListQuery{})),
+				func(c *gin.Context) {
+					got, _ = query.From[external.OrderListQuery](c)
+					c.Status(http.StatusOK)
+				})
+
+			req, _ := http.NewRequest(http.MethodGet, "/orders"+tt.query, nil)
+			r.ServeHTTP(resp, req)
+
+			assert.Equal(t, tt.expectedCode, resp.Code)
+			if tt.wantViolations == nil {
+				assert.Equal(t, tt.wantQuery, got)
+				return
+			}
+			var apiErr struct {
+				ErrorCode string              `json:"errorCode"`
+				Details   []openapi.Violation `json:"details"`
+			}
+			require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &apiErr))
+			assert.Equal(t, errors.RequestInvalid, apiErr.ErrorCode)
+			assert.Equal(t, tt.wantViolations, apiErr.Details)
+		})
+	}
+}
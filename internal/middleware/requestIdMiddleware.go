@@ -5,14 +5,15 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"github.com/rameshsunkara/go-rest-api-example/internal/requestctx"
 )
 
-// ContextKey is a type for context keys.
-type ContextKey string
+// ContextKey is a type for context keys, it is the logger's so request loggers find the request ID.
+type ContextKey = requestctx.ContextKey
 
 const (
 	// RequestIdentifier is the header name for request ID.
-	RequestIdentifier = "X-Request-ID"
+	RequestIdentifier = requestctx.RequestIdentifier
 )
 
 // ReqIDMiddleware injects a request ID into the context and response header, creates one if it is not present already.
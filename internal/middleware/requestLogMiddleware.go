@@ -1,9 +1,14 @@
 package middleware
 
 import (
+	"net/http"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/rameshsunkara/go-rest-api-example/internal/requestctx"
+	"github.com/rameshsunkara/go-rest-api-example/internal/tenant"
 	"github.com/rameshsunkara/go-rest-api-example/pkg/flightrecorder"
 	"github.com/rameshsunkara/go-rest-api-example/pkg/logger"
 )
@@ -11,26 +16,68 @@ import (
 const (
 	// SlowRequestThreshold defines when to capture flight recorder traces.
 	SlowRequestThreshold = 500 * time.Millisecond
+
+	// httpLogComponent names the logger of the request log lines, its level is set with logComponentLevels.
+	httpLogComponent = "http"
 )
 
-func RequestLogMiddleware(lgr logger.Logger, fr *flightrecorder.Recorder) gin.HandlerFunc {
+// RequestLogSampling logs one in Every successful requests of a route, Routes overrides Every per route
+// template. Failed and slow requests are always logged, an Every below 2 logs every request.
+type RequestLogSampling struct {
+	Every  int
+	Routes map[string]int
+}
+
+func (s RequestLogSampling) every(route string) int {
+	if every, ok := s.Routes[route]; ok {
+		return every
+	}
+	return s.Every
+}
+
+// RequestLogMiddleware attaches the request logger to the request context, see logger.FromContext, and logs
+// the request once it is handled, sampled by sampling.
+func RequestLogMiddleware(lgr logger.Logger, fr *flightrecorder.Recorder, sampling RequestLogSampling) gin.HandlerFunc {
+	var counts sync.Map // route -> *atomic.Uint64, requests of the route seen so far
 	return func(c *gin.Context) {
-		l, _ := lgr.WithReqID(c)
+		l := requestLogger(c, lgr)
+		c.Request = c.Request.WithContext(logger.NewContext(c.Request.Context(), l))
 		start := time.Now()
 
 		c.Next()
 
 		elapsed := time.Since(start)
 
+		every := sampling.every(c.FullPath())
+		sampled := every > 1 && c.Writer.Status() < http.StatusBadRequest && elapsed <= SlowRequestThreshold
+		if sampled {
+			v, _ := counts.LoadOrStore(c.FullPath(), &atomic.Uint64{})
+			if count, ok := v.(*atomic.Uint64); ok && count.Add(1)%uint64(every) != 1 {
+				return
+			}
+		}
+
 		// Log the request
-		l.Info().
+		e := l.Named(httpLogComponent).Info().
 			Str("method", c.Request.Method).
 			Str("url", c.Request.URL.String()).
 			Str("path", c.FullPath()).
 			Str("userAgent", c.Request.UserAgent()).
 			Int("respStatus", c.Writer.Status()).
-			Dur("elapsedMs", elapsed).
-			Send()
+			Dur("elapsedMs", elapsed)
+		// the tenant and principal are resolved by later middleware, c.Request carries their context once
+		// c.Next returns
+		if t, ok := tenant.FromContext(c.Request.Context()); ok {
+			e = e.Str(tenantLogKey, t.ID)
+		}
+		if p := requestctx.Principal(c.Request.Context()); p != "" {
+			e = e.Str(PrincipalKey, p)
+		}
+		if sampled {
+			// lets log queries weight the successful requests they count
+			e = e.Int("sampleEvery", every)
+		}
+		e.Send()
 
 		// Capture trace for slow requests
 		if fr != nil && elapsed > SlowRequestThreshold {
@@ -38,3 +85,16 @@ func RequestLogMiddleware(lgr logger.Logger, fr *flightrecorder.Recorder) gin.Ha
 		}
 	}
 }
+
+// requestLogger returns lgr with the request ID, trace IDs, route and principal of the request.
+func requestLogger(c *gin.Context, lgr logger.Logger) logger.Logger {
+	l, _ := lgr.WithReqID(c)
+	fields := l.With()
+	if route := c.FullPath(); route != "" {
+		fields = fields.Str("route", route)
+	}
+	if p := requestctx.Principal(c.Request.Context()); p != "" {
+		fields = fields.Str(PrincipalKey, p)
+	}
+	return fields.Logger()
+}
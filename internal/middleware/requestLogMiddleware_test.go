@@ -11,6 +11,7 @@ import (
 	"github.com/rameshsunkara/go-rest-api-example/internal/middleware"
 	"github.com/rameshsunkara/go-rest-api-example/pkg/flightrecorder"
 	"github.com/rameshsunkara/go-rest-api-example/pkg/logger"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
@@ -35,7 +36,8 @@ func TestRequestLogMiddleware(_ *testing.T) {
 	gin.SetMode(gin.TestMode)
 	c, r := gin.CreateTestContext(resp)
 	lgr := logger.New("info", os.Stdout)
-	r.Use(middleware.RequestLogMiddleware(lgr, nil)) // Pass nil for flight recorder in tests
+	// nil flight recorder in tests
+	r.Use(middleware.RequestLogMiddleware(lgr, nil, middleware.RequestLogSampling{}))
 
 	for _, tc := range testCases {
 		r.GET(tc.InputReqPath, func(ctx *gin.Context) {
@@ -61,7 +63,7 @@ func TestRequestLogMiddlewareWithSlowRequest(t *testing.T) {
 
 	gin.SetMode(gin.TestMode)
 	router := gin.New()
-	router.Use(middleware.RequestLogMiddleware(lgr, fr))
+	router.Use(middleware.RequestLogMiddleware(lgr, fr, middleware.RequestLogSampling{}))
 
 	// Add a slow endpoint
 	router.GET("/slow", func(ctx *gin.Context) {
@@ -81,3 +83,68 @@ func TestRequestLogMiddlewareWithSlowRequest(t *testing.T) {
 	require.NoError(t, err)
 	require.NotEmpty(t, entries, "at least one trace file should be created for slow request")
 }
+
+func TestRequestLogMiddlewareAttachesLogger(t *testing.T) {
+	t.Parallel()
+	rec := logger.NewRecorder()
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middleware.ReqIDMiddleware(), middleware.RequestLogMiddleware(rec, nil, middleware.RequestLogSampling{}))
+	// as on the server, the caller is authenticated after the request logger is attached
+	router.GET("/orders/:id", middleware.AuthMiddleware(), func(c *gin.Context) {
+		logger.FromContext(c.Request.Context()).Info().Msg("from handler")
+		c.Status(http.StatusOK)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/orders/1", nil)
+	req.Header.Set(middleware.RequestIdentifier, "req-1")
+	req.Header.Set(middleware.APIKeyIDHeader, "key-1")
+	router.ServeHTTP(httptest.NewRecorder(), req)
+
+	entries := rec.Entries()
+	require.Len(t, entries, 2)
+	handlerEntry, requestEntry := entries[0], entries[1]
+	assert.Equal(t, "from handler", handlerEntry.Message)
+	for _, e := range entries {
+		assert.Equal(t, "req-1", e.Fields[middleware.RequestIdentifier])
+		assert.Equal(t, "/orders/:id", e.Fields["route"])
+		assert.Equal(t, "apikey:key-1", e.Fields[middleware.PrincipalKey])
+	}
+	assert.Equal(t, "http", requestEntry.Fields[logger.ComponentKey])
+	assert.Equal(t, http.StatusOK, requestEntry.Fields["respStatus"])
+}
+
+func TestRequestLogMiddlewareSampling(t *testing.T) {
+	t.Parallel()
+	rec := logger.NewRecorder()
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middleware.RequestLogMiddleware(rec, nil, middleware.RequestLogSampling{
+		Every:  3,
+		Routes: map[string]int{"/always": 1},
+	}))
+	router.GET("/ok", func(c *gin.Context) { c.Status(http.StatusOK) })
+	router.GET("/fail", func(c *gin.Context) { c.Status(http.StatusInternalServerError) })
+	router.GET("/always", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	for _, path := range []string{"/ok", "/fail", "/always"} {
+		for range 6 {
+			router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+		}
+	}
+
+	logged := map[string]int{}
+	for _, e := range rec.Entries() {
+		path, ok := e.Fields["path"].(string)
+		require.True(t, ok)
+		logged[path]++
+		if path == "/ok" {
+			assert.Equal(t, 3, e.Fields["sampleEvery"])
+		} else {
+			assert.NotContains(t, e.Fields, "sampleEvery")
+		}
+	}
+	assert.Equal(t, map[string]int{"/ok": 2, "/fail": 6, "/always": 6}, logged)
+}
@@ -0,0 +1,89 @@
+package middleware
+
+import (
+	"net"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rameshsunkara/go-rest-api-example/internal/errors"
+	"github.com/rameshsunkara/go-rest-api-example/internal/metrics"
+	"github.com/rameshsunkara/go-rest-api-example/internal/models/external"
+	"github.com/rameshsunkara/go-rest-api-example/internal/requestctx"
+	"github.com/rameshsunkara/go-rest-api-example/internal/tenant"
+	"github.com/rameshsunkara/go-rest-api-example/pkg/logger"
+)
+
+const (
+	// TenantHeader is the header naming the tenant of a request.
+	TenantHeader = "X-Tenant-ID"
+	// ClaimsKey is the request context key (as ContextKey) holding the verified token claims as map[string]any.
+	ClaimsKey = requestctx.ClaimsKey
+	// TenantClaim is the token claim naming the tenant of a request.
+	TenantClaim = "tenant"
+
+	unresolvedTenant = "unresolved"
+	tenantLogKey     = "tenant"
+)
+
+// TenantMiddleware resolves the tenant of a request and stores it in the request context, the request logger
+// gets a tenant field.
+// The tenant comes from the token claim, then the host name, then the X-Tenant-ID header.
+// Requests without a tenant or naming an unknown one are rejected, it lets every request through when
+// no tenants are configured. Requests are counted per tenant, the rejected ones as the unresolved tenant.
+func TenantMiddleware(lgr logger.Logger, registry *tenant.Registry, m *metrics.Metrics) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !registry.Enabled() {
+			c.Next()
+			return
+		}
+		l, requestID := lgr.WithReqID(c)
+		id, source := resolveTenant(c, registry)
+		if id == "" {
+			l.Error().Str("host", c.Request.Host).Msg("request has no tenant")
+			abortTenant(c, m, http.StatusBadRequest, errors.TenantMissing, tenant.ErrMissing.Error(), requestID)
+			return
+		}
+		t, err := registry.Lookup(id)
+		if err != nil {
+			l.Error().Str("tenant", id).Str("source", source).Msg("request has an unknown tenant")
+			abortTenant(c, m, http.StatusForbidden, errors.TenantUnknown, err.Error(), requestID)
+			return
+		}
+		ctx := tenant.NewContext(c.Request.Context(), t)
+		// code logging through the request logger, see logger.FromContext, tells the tenants apart
+		ctx = logger.NewContext(ctx, logger.FromContextOr(ctx, l).With().Str(tenantLogKey, t.ID).Logger())
+		c.Request = c.Request.WithContext(ctx)
+
+		c.Next()
+
+		m.TenantRequests.WithLabelValues(t.ID, strconv.Itoa(c.Writer.Status())).Inc()
+	}
+}
+
+func resolveTenant(c *gin.Context, registry *tenant.Registry) (string, string) {
+	if claims, ok := c.Request.Context().Value(ContextKey(ClaimsKey)).(map[string]any); ok {
+		if id, isStr := claims[TenantClaim].(string); isStr && id != "" {
+			return id, "claim"
+		}
+	}
+	host, _, err := net.SplitHostPort(c.Request.Host)
+	if err != nil {
+		host = c.Request.Host
+	}
+	if id, ok := registry.ByHost(host); ok {
+		return id, "host"
+	}
+	return c.GetHeader(TenantHeader), "header"
+}
+
+func abortTenant(c *gin.Context, m *metrics.Metrics, status int, code, msg, requestID string) {
+	m.TenantRequests.WithLabelValues(unresolvedTenant, strconv.Itoa(status)).Inc()
+	apiErr := &external.APIError{
+		HTTPStatusCode: status,
+		ErrorCode:      code,
+		Message:        msg,
+		DebugID:        requestID,
+	}
+	c.AbortWithStatusJSON(apiErr.HTTPStatusCode, apiErr)
+}
@@ -0,0 +1,139 @@
+package middleware_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/rameshsunkara/go-rest-api-example/internal/errors"
+	"github.com/rameshsunkara/go-rest-api-example/internal/metrics"
+	"github.com/rameshsunkara/go-rest-api-example/internal/middleware"
+	"github.com/rameshsunkara/go-rest-api-example/internal/tenant"
+	"github.com/rameshsunkara/go-rest-api-example/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTenantMiddleware(t *testing.T) {
+	t.Parallel()
+	registry, err := tenant.NewRegistry(
+		map[string]string{"acme": "ecommerce_acme", "globex": "ecommerce_globex"},
+		map[string]string{"shop.acme.com": "acme"},
+	)
+	require.NoError(t, err)
+	m := metrics.New()
+	t.Cleanup(func() {
+		// the cleanup runs once the parallel subtests are done
+		assert.InDelta(t, 2, testutil.ToFloat64(m.TenantRequests.WithLabelValues("globex", "200")), 0)
+		assert.InDelta(t, 1, testutil.ToFloat64(m.TenantRequests.WithLabelValues("acme", "200")), 0)
+		assert.InDelta(t, 1, testutil.ToFloat64(m.TenantRequests.WithLabelValues("unresolved", "400")), 0)
+		assert.InDelta(t, 1, testutil.ToFloat64(m.TenantRequests.WithLabelValues("unresolved", "403")), 0)
+	})
+
+	tests := []struct {
+		name       string
+		host       string
+		header     string
+		claims     map[string]any
+		wantStatus int
+		wantTenant string
+		wantCode   string
+	}{
+		{name: "header", header: "globex", wantStatus: http.StatusOK, wantTenant: "globex"},
+		{name: "host with port", host: "shop.acme.com:8080", wantStatus: http.StatusOK, wantTenant: "acme"},
+		{
+			name:       "claim wins over host and header",
+			host:       "shop.acme.com",
+			header:     "acme",
+			claims:     map[string]any{middleware.TenantClaim: "globex"},
+			wantStatus: http.StatusOK,
+			wantTenant: "globex",
+		},
+		{name: "missing", wantStatus: http.StatusBadRequest, wantCode: errors.TenantMissing},
+		{name: "unknown", header: "initech", wantStatus: http.StatusForbidden, wantCode: errors.TenantUnknown},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			gin.SetMode(gin.TestMode)
+			r := gin.New()
+			r.Use(func(c *gin.Context) {
+				if tt.claims != nil {
+					ctx := context.WithValue(c.Request.Context(), middleware.ContextKey(middleware.ClaimsKey), tt.claims)
+					c.Request = c.Request.WithContext(ctx)
+				}
+			})
+			r.Use(middleware.TenantMiddleware(logger.New("info", os.Stdout), registry, m))
+			r.GET("/orders", func(c *gin.Context) {
+				got, _ := tenant.FromContext(c.Request.Context())
+				c.String(http.StatusOK, got.ID)
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/orders", nil)
+			if tt.host != "" {
+				req.Host = tt.host
+			}
+			if tt.header != "" {
+				req.Header.Set(middleware.TenantHeader, tt.header)
+			}
+			resp := httptest.NewRecorder()
+			r.ServeHTTP(resp, req)
+
+			assert.Equal(t, tt.wantStatus, resp.Code)
+			if tt.wantCode != "" {
+				assert.Contains(t, resp.Body.String(), tt.wantCode)
+				return
+			}
+			assert.Equal(t, tt.wantTenant, resp.Body.String())
+		})
+	}
+}
+
+func TestTenantMiddleware_SingleTenant(t *testing.T) {
+	t.Parallel()
+	registry, err := tenant.NewRegistry(nil, nil)
+	require.NoError(t, err)
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(middleware.TenantMiddleware(logger.New("info", os.Stdout), registry, metrics.New()))
+	r.GET("/orders", func(c *gin.Context) {
+		_, ok := tenant.FromContext(c.Request.Context())
+		assert.False(t, ok)
+		c.Status(http.StatusOK)
+	})
+
+	resp := httptest.NewRecorder()
+	r.ServeHTTP(resp, httptest.NewRequest(http.MethodGet, "/orders", nil))
+	assert.Equal(t, http.StatusOK, resp.Code)
+}
+
+func TestTenantMiddlewareEnrichesRequestLogger(t *testing.T) {
+	t.Parallel()
+	registry, err := tenant.NewRegistry(map[string]string{"acme": "ecommerce_acme"}, nil)
+	require.NoError(t, err)
+	rec := logger.NewRecorder()
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(middleware.RequestLogMiddleware(rec, nil, middleware.RequestLogSampling{}))
+	r.Use(middleware.TenantMiddleware(rec, registry, metrics.New()))
+	r.GET("/orders", func(c *gin.Context) {
+		logger.FromContext(c.Request.Context()).Info().Msg("from repository")
+		c.Status(http.StatusOK)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/orders", nil)
+	req.Header.Set(middleware.TenantHeader, "acme")
+	r.ServeHTTP(httptest.NewRecorder(), req)
+
+	entries := rec.Entries()
+	require.Len(t, entries, 2)
+	assert.Equal(t, "from repository", entries[0].Message)
+	for _, e := range entries {
+		assert.Equal(t, "acme", e.Fields["tenant"])
+	}
+}
@@ -0,0 +1,50 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.43.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const tracerName = "github.com/rameshsunkara/go-rest-api-example/internal/middleware"
+
+// TracingMiddleware starts a server span per request named after the route template,
+// e.g. "GET /ecommerce/v1/orders/:id". The span continues the trace of the caller's traceparent header and is
+// carried by c.Request's context, so the logger and the spans of Mongo commands made while handling the request
+// join it.
+func TracingMiddleware(tp trace.TracerProvider, propagator propagation.TextMapPropagator) gin.HandlerFunc {
+	tracer := tp.Tracer(tracerName)
+	return func(c *gin.Context) {
+		ctx := propagator.Extract(c.Request.Context(), propagation.HeaderCarrier(c.Request.Header))
+		// unmatched requests have no route, they are named after the method alone
+		name := c.Request.Method
+		route := c.FullPath()
+		if route != "" {
+			name += " " + route
+		}
+		ctx, span := tracer.Start(ctx, name,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				semconv.HTTPRequestMethodKey.String(c.Request.Method),
+				semconv.HTTPRoute(route),
+				semconv.URLPath(c.Request.URL.Path),
+			))
+		defer span.End()
+		c.Request = c.Request.WithContext(ctx)
+
+		c.Next()
+
+		status := c.Writer.Status()
+		span.SetAttributes(semconv.HTTPResponseStatusCode(status))
+		if status >= http.StatusInternalServerError {
+			span.SetStatus(codes.Error, http.StatusText(status))
+		}
+		if len(c.Errors) > 0 {
+			span.RecordError(c.Errors.Last())
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rameshsunkara/go-rest-api-example/internal/middleware"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestTracingMiddleware(t *testing.T) {
+	const parent = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+	tests := []struct {
+		name         string
+		path         string
+		traceparent  string
+		wantName     string
+		wantStatus   int
+		wantCode     codes.Code
+		wantParentID string
+	}{
+		{
+			name: "route template", path: "/orders/42",
+			wantName: "GET /orders/:id", wantStatus: http.StatusOK, wantCode: codes.Unset,
+		},
+		{
+			name: "continues the caller's trace", path: "/orders/42", traceparent: parent,
+			wantName: "GET /orders/:id", wantStatus: http.StatusOK, wantCode: codes.Unset,
+			wantParentID: "00f067aa0ba902b7",
+		},
+		{
+			name: "server error", path: "/fail",
+			wantName: "GET /fail", wantStatus: http.StatusInternalServerError, wantCode: codes.Error,
+		},
+		{
+			name: "unmatched route", path: "/unknown",
+			wantName: "GET", wantStatus: http.StatusNotFound, wantCode: codes.Unset,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := tracetest.NewSpanRecorder()
+			tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+			gin.SetMode(gin.TestMode)
+			r := gin.New()
+			r.Use(middleware.TracingMiddleware(tp, propagation.TraceContext{}))
+			var handlerSpan trace.SpanContext
+			r.GET("/orders/:id", func(c *gin.Context) {
+				handlerSpan = trace.SpanContextFromContext(c.Request.Context())
+				c.Status(http.StatusOK)
+			})
+			r.GET("/fail", func(c *gin.Context) { c.Status(http.StatusInternalServerError) })
+
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			if tt.traceparent != "" {
+				req.Header.Set("traceparent", tt.traceparent)
+			}
+			resp := httptest.NewRecorder()
+			r.ServeHTTP(resp, req)
+
+			require.Equal(t, tt.wantStatus, resp.Code)
+			spans := recorder.Ended()
+			require.Len(t, spans, 1)
+			span := spans[0]
+			assert.Equal(t, tt.wantName, span.Name())
+			assert.Equal(t, trace.SpanKindServer, span.SpanKind())
+			assert.Equal(t, tt.wantCode, span.Status().Code)
+			assert.Contains(t, span.Attributes(), attribute.Int("http.response.status_code", tt.wantStatus))
+			if tt.wantParentID != "" {
+				assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", span.SpanContext().TraceID().String())
+				assert.Equal(t, tt.wantParentID, span.Parent().SpanID().String())
+				assert.True(t, span.Parent().IsRemote())
+			}
+			if tt.path == "/orders/42" {
+				assert.Equal(t, span.SpanContext(), handlerSpan)
+			}
+		})
+	}
+}
@@ -0,0 +1,38 @@
+package data
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// AuditAction represents the kind of mutation recorded in the audit log.
+type AuditAction string
+
+const (
+	AuditCreate     AuditAction = "create"
+	AuditUpdate     AuditAction = "update"
+	AuditTransition AuditAction = "transition"
+	AuditDelete     AuditAction = "delete"
+	AuditRestore    AuditAction = "restore"
+	AuditImport     AuditAction = "import"
+)
+
+// AuditEntry represents a single, immutable record of a mutating order operation.
+type AuditEntry struct {
+	ID          primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	Action      AuditAction        `json:"action" bson:"action"`
+	OrderID     primitive.ObjectID `json:"orderId" bson:"orderId,omitempty"`
+	ExternalRef string             `json:"externalRef,omitempty" bson:"externalRef,omitempty"`
+	Actor       string             `json:"actor" bson:"actor"`
+	RequestID   string             `json:"requestId" bson:"requestId"`
+	Changes     []FieldChange      `json:"changes" bson:"changes"`
+	Timestamp   time.Time          `json:"timestamp" bson:"timestamp"`
+}
+
+// FieldChange captures the value of a top level order field before and after a mutation.
+type FieldChange struct {
+	Field  string      `json:"field" bson:"field"`
+	Before interface{} `json:"before" bson:"before"`
+	After  interface{} `json:"after" bson:"after"`
+}
@@ -0,0 +1,28 @@
+package data
+
+import (
+	"strings"
+	"time"
+
+	"github.com/rameshsunkara/go-rest-api-example/pkg/money"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// CatalogProduct represents a sellable product of the catalog.
+// Orders reference it by SKU and copy its name and price at the time of ordering.
+type CatalogProduct struct {
+	ID          primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	SKU         string             `json:"sku" bson:"sku"`
+	Name        string             `json:"name" bson:"name"`
+	Description string             `json:"description,omitempty" bson:"description,omitempty"`
+	Price       money.Amount       `json:"price" bson:"price"`
+	Currency    money.Currency     `json:"currency" bson:"currency"`
+	Active      bool               `json:"active" bson:"active"`
+	CreatedAt   time.Time          `json:"createdAt" bson:"createdAt"`
+	UpdatedAt   time.Time          `json:"updatedAt" bson:"updatedAt"`
+}
+
+// NormalizeSKU returns the canonical, case-insensitive form of a SKU.
+func NormalizeSKU(sku string) string {
+	return strings.ToUpper(strings.TrimSpace(sku))
+}
@@ -0,0 +1,54 @@
+package data
+
+import (
+	"strings"
+	"time"
+
+	"github.com/rameshsunkara/go-rest-api-example/pkg/money"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// DiscountKind represents how a coupon value is applied.
+type DiscountKind string
+
+const (
+	DiscountPercent DiscountKind = "percent" // Value is a percentage, e.g. 15 for 15% off
+	DiscountFixed   DiscountKind = "fixed"   // Value is an amount in the coupon currency
+)
+
+// IsValid reports whether k is one of the known discount kinds.
+func (k DiscountKind) IsValid() bool {
+	return k == DiscountPercent || k == DiscountFixed
+}
+
+// Coupon represents a redeemable discount code.
+// A coupon listing Products (SKUs or names) discounts the matching order lines, otherwise it discounts the whole order.
+// MaxUses of zero means the coupon can be redeemed any number of times.
+type Coupon struct {
+	ID         primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	Code       string             `json:"code" bson:"code"`
+	Kind       DiscountKind       `json:"kind" bson:"kind"`
+	Value      money.Amount       `json:"value" bson:"value"`
+	Currency   money.Currency     `json:"currency,omitempty" bson:"currency,omitempty"`
+	Products   []string           `json:"products,omitempty" bson:"products,omitempty"`
+	ValidFrom  time.Time          `json:"validFrom" bson:"validFrom"`
+	ValidUntil time.Time          `json:"validUntil" bson:"validUntil"`
+	MaxUses    int64              `json:"maxUses" bson:"maxUses"`
+	Uses       int64              `json:"uses" bson:"uses"`
+	CreatedAt  time.Time          `json:"createdAt" bson:"createdAt"`
+}
+
+// IsActive reports whether the coupon validity window contains t.
+func (c *Coupon) IsActive(t time.Time) bool {
+	return !t.Before(c.ValidFrom) && t.Before(c.ValidUntil)
+}
+
+// IsExhausted reports whether the coupon reached its usage cap.
+func (c *Coupon) IsExhausted() bool {
+	return c.MaxUses > 0 && c.Uses >= c.MaxUses
+}
+
+// NormalizeCouponCode returns the canonical, case-insensitive form of a coupon code.
+func NormalizeCouponCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
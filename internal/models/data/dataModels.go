@@ -1,11 +1,16 @@
 package data
 
 import (
+	"slices"
 	"time"
 
+	"github.com/rameshsunkara/go-rest-api-example/pkg/money"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
+// DefaultCurrency is used for orders that do not specify a currency.
+const DefaultCurrency = money.USD
+
 // OrderStatus represents the status of an order.
 type OrderStatus string
 
@@ -17,17 +22,87 @@ const (
 	OrderCancelled  OrderStatus = "OrderCancelled"
 )
 
+// IsValid reports whether s is one of the known order statuses.
+func (s OrderStatus) IsValid() bool {
+	switch s {
+	case OrderPending, OrderProcessing, OrderShipped, OrderDelivered, OrderCancelled:
+		return true
+	}
+	return false
+}
+
+// orderTransitions lists the statuses an order can move to from each status.
+var orderTransitions = map[OrderStatus][]OrderStatus{
+	OrderPending:    {OrderProcessing, OrderCancelled},
+	OrderProcessing: {OrderShipped, OrderCancelled},
+	OrderShipped:    {OrderDelivered},
+}
+
+// CanTransitionTo reports whether an order in status s can move to status to.
+func (s OrderStatus) CanTransitionTo(to OrderStatus) bool {
+	return slices.Contains(orderTransitions[s], to)
+}
+
+// TransitionSources returns the statuses an order can move to status to from.
+func TransitionSources(to OrderStatus) []OrderStatus {
+	var sources []OrderStatus
+	for from, targets := range orderTransitions {
+		if slices.Contains(targets, to) {
+			sources = append(sources, from)
+		}
+	}
+	slices.Sort(sources)
+	return sources
+}
+
 // Order represents the structure of an order.
+// Shipment is set when the order is shipped and completed when it is delivered.
+// StockReserved is set on orders whose products were taken from the inventory when they were placed,
+// the stock is given back when such an order is cancelled.
 type Order struct {
-	ID          primitive.ObjectID `bson:"_id,omitempty" json:"orderId"`
-	Version     int64              `json:"version" bson:"version"`
-	CreatedAt   time.Time          `json:"createdAt" bson:"createdAt"`
-	UpdatedAt   time.Time          `json:"updatedAt" bson:"updatedAt"`
-	Products    []Product          `json:"products" bson:"products"`
-	User        string             `json:"user" bson:"user"`
-	TotalAmount float64            `json:"totalAmount" bson:"totalAmount"`
-	Status      OrderStatus        `json:"status" bson:"status"`
-	Updates     []OrderUpdate      `json:"updates" bson:"updates"`
+	ID              primitive.ObjectID `bson:"_id,omitempty" json:"orderId"`
+	Version         int64              `json:"version" bson:"version"`
+	CreatedAt       time.Time          `json:"createdAt" bson:"createdAt"`
+	UpdatedAt       time.Time          `json:"updatedAt" bson:"updatedAt"`
+	Products        []Product          `json:"products" bson:"products"`
+	User            string             `json:"user" bson:"user"`
+	TotalAmount     money.Amount       `json:"totalAmount" bson:"totalAmount"`
+	Currency        money.Currency     `json:"currency" bson:"currency"`
+	Pricing         *Pricing           `json:"pricing,omitempty" bson:"pricing,omitempty"`
+	Status          OrderStatus        `json:"status" bson:"status"`
+	Customer        *Customer          `json:"customer,omitempty" bson:"customer,omitempty"`
+	ShippingAddress *Address           `json:"shippingAddress,omitempty" bson:"shippingAddress,omitempty"`
+	Shipment        *Shipment          `json:"shipment,omitempty" bson:"shipment,omitempty"`
+	Updates         []OrderUpdate      `json:"updates" bson:"updates"`
+	ExternalRef     string             `json:"externalRef,omitempty" bson:"externalRef,omitempty"`
+	DeletedAt       *time.Time         `json:"deletedAt,omitempty" bson:"deletedAt,omitempty"`
+	StockReserved   bool               `json:"-" bson:"stockReserved,omitempty"`
+}
+
+// Customer represents the contact details of the person who placed an order.
+type Customer struct {
+	Name  string `json:"name" bson:"name"`
+	Email string `json:"email,omitempty" bson:"email,omitempty"`
+	Phone string `json:"phone,omitempty" bson:"phone,omitempty"`
+}
+
+// Address represents a postal address, Country is an ISO-3166 alpha-2 code.
+type Address struct {
+	Recipient  string `json:"recipient" bson:"recipient"`
+	Line1      string `json:"line1" bson:"line1"`
+	Line2      string `json:"line2,omitempty" bson:"line2,omitempty"`
+	City       string `json:"city" bson:"city"`
+	Region     string `json:"region,omitempty" bson:"region,omitempty"`
+	PostalCode string `json:"postalCode" bson:"postalCode"`
+	Country    string `json:"country" bson:"country"`
+}
+
+// Shipment represents the fulfilment of an order by a carrier.
+type Shipment struct {
+	Carrier     string     `json:"carrier" bson:"carrier"`
+	TrackingID  string     `json:"trackingId" bson:"trackingId"`
+	ShippedAt   *time.Time `json:"shippedAt,omitempty" bson:"shippedAt,omitempty"`
+	DeliveredAt *time.Time `json:"deliveredAt,omitempty" bson:"deliveredAt,omitempty"`
 }
 
 // OrderUpdate represents the structure of an order update.
@@ -39,10 +114,23 @@ type OrderUpdate struct {
 
 // Product represents the structure of a product.
 type Product struct {
-	Name      string    `json:"name" bson:"name"`
-	UpdatedAt time.Time `json:"updatedAt" bson:"updatedAt"`
-	Price     float64   `json:"price" bson:"price"`
-	Status    string    `json:"status" bson:"status"`
-	Remarks   string    `json:"remarks" bson:"remarks"`
-	Quantity  uint64    `json:"quantity"`
+	SKU       string       `json:"sku,omitempty" bson:"sku,omitempty"`
+	Name      string       `json:"name" bson:"name"`
+	UpdatedAt time.Time    `json:"updatedAt" bson:"updatedAt"`
+	Price     money.Amount `json:"price" bson:"price"`
+	Status    string       `json:"status" bson:"status"`
+	Remarks   string       `json:"remarks" bson:"remarks"`
+	Quantity  uint64       `json:"quantity"`
+	Discount  money.Amount `json:"discount,omitempty" bson:"discount,omitempty"`
+}
+
+// Pricing is the price breakdown of an order, Total is also stored as the order TotalAmount.
+// Discount includes the line discounts recorded on the products. Imported and legacy orders have none.
+type Pricing struct {
+	Subtotal   money.Amount `json:"subtotal" bson:"subtotal"`
+	Discount   money.Amount `json:"discount" bson:"discount"`
+	Tax        money.Amount `json:"tax" bson:"tax"`
+	Total      money.Amount `json:"total" bson:"total"`
+	CouponCode string       `json:"couponCode,omitempty" bson:"couponCode,omitempty"`
+	TaxRegion  string       `json:"taxRegion,omitempty" bson:"taxRegion,omitempty"`
 }
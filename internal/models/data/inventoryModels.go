@@ -0,0 +1,23 @@
+package data
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// StockLevel represents the stock of a catalog product that is available for new orders.
+// Products without a stock level cannot be ordered.
+type StockLevel struct {
+	ID        primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	SKU       string             `json:"sku" bson:"sku"`
+	Available int64              `json:"available" bson:"available"`
+	UpdatedAt time.Time          `json:"updatedAt" bson:"updatedAt"`
+}
+
+// ShortItem describes an order line that cannot be fulfilled from the available stock.
+type ShortItem struct {
+	SKU       string `json:"sku"`
+	Requested uint64 `json:"requested"`
+	Available int64  `json:"available"`
+}
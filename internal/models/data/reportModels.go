@@ -0,0 +1,53 @@
+package data
+
+import (
+	"time"
+
+	"github.com/rameshsunkara/go-rest-api-example/pkg/money"
+)
+
+// ReportInterval is the size of the time buckets of a report.
+type ReportInterval string
+
+const (
+	IntervalDay   ReportInterval = "day"
+	IntervalWeek  ReportInterval = "week" // weeks start on Monday
+	IntervalMonth ReportInterval = "month"
+)
+
+// IsValid reports whether i is one of the known report intervals.
+func (i ReportInterval) IsValid() bool {
+	return i == IntervalDay || i == IntervalWeek || i == IntervalMonth
+}
+
+// RevenueBucket is the revenue of the orders placed in one time bucket, per currency.
+type RevenueBucket struct {
+	Start    time.Time      `json:"start" bson:"start"`
+	Currency money.Currency `json:"currency" bson:"currency"`
+	Orders   int64          `json:"orders" bson:"orders"`
+	Revenue  money.Amount   `json:"revenue" bson:"revenue"`
+}
+
+// StatusCount is the number of orders in a status.
+type StatusCount struct {
+	Status OrderStatus `json:"status" bson:"status"`
+	Orders int64       `json:"orders" bson:"orders"`
+}
+
+// BasketStats describes the average order, per currency.
+type BasketStats struct {
+	Currency     money.Currency `json:"currency" bson:"currency"`
+	Orders       int64          `json:"orders" bson:"orders"`
+	AverageTotal money.Amount   `json:"averageTotal" bson:"averageTotal"`
+	AverageItems float64        `json:"averageItems" bson:"averageItems"`
+}
+
+// ProductSales is the quantity and revenue sold of a product, per currency.
+// Products of legacy orders without a SKU are identified by their name.
+type ProductSales struct {
+	SKU      string         `json:"sku,omitempty" bson:"sku,omitempty"`
+	Name     string         `json:"name" bson:"name"`
+	Currency money.Currency `json:"currency" bson:"currency"`
+	Quantity int64          `json:"quantity" bson:"quantity"`
+	Revenue  money.Amount   `json:"revenue" bson:"revenue"`
+}
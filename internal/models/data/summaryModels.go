@@ -0,0 +1,17 @@
+package data
+
+import (
+	"time"
+
+	"github.com/rameshsunkara/go-rest-api-example/pkg/money"
+)
+
+// CustomerSummary aggregates the order history of a single user.
+// LifetimeSpend is the total of the orders that were not cancelled, per currency.
+type CustomerSummary struct {
+	User          string                          `json:"user"`
+	OrderCount    int64                           `json:"orderCount"`
+	LifetimeSpend map[money.Currency]money.Amount `json:"lifetimeSpend"`
+	LastOrderAt   *time.Time                      `json:"lastOrderAt,omitempty"`
+	StatusCounts  map[OrderStatus]int64           `json:"statusCounts"`
+}
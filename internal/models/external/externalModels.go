@@ -2,38 +2,345 @@
 package external
 
 import (
+	"errors"
+	"fmt"
+	"time"
+
 	"github.com/rameshsunkara/go-rest-api-example/internal/models/data"
+	"github.com/rameshsunkara/go-rest-api-example/pkg/money"
 )
 
 // APIError represents the structure of an API error response.
+// Details optionally carries machine-readable data about the error, e.g. the short items of an order.
 type APIError struct {
-	HTTPStatusCode int    `json:"httpStatusCode"`
-	Message        string `json:"message"`
-	DebugID        string `json:"debugId"`
-	ErrorCode      string `json:"errorCode"`
+	HTTPStatusCode int         `json:"httpStatusCode"`
+	Message        string      `json:"message"`
+	DebugID        string      `json:"debugId"`
+	ErrorCode      string      `json:"errorCode"`
+	Details        interface{} `json:"details,omitempty"`
 }
 
-// OrderInput represents the structure of input for creating or updating an order.
+// OrderInput represents the structure of input for creating an order.
+// Products reference catalog SKUs, their names and prices are resolved server-side.
+// Currency is an optional ISO-4217 code that must match the catalog currency. Region selects the tax rate.
 type OrderInput struct {
-	Products []ProductInput `json:"products" binding:"required"`
+	Products        []ProductInput `json:"products" binding:"required,min=1,dive"`
+	Currency        string         `json:"currency"`
+	Region          string         `json:"region"`
+	CouponCode      string         `json:"couponCode"`
+	Customer        *CustomerInput `json:"customer"`
+	ShippingAddress *AddressInput  `json:"shippingAddress"`
+}
+
+// CustomerInput represents the contact details of an order, Phone is in E.164 format, e.g. +14155550100.
+type CustomerInput struct {
+	Name  string `json:"name" binding:"required,max=100"`
+	Email string `json:"email" binding:"omitempty,email"`
+	Phone string `json:"phone" binding:"omitempty,e164"`
+}
+
+// ToData converts the input to its stored form.
+func (in *CustomerInput) ToData() *data.Customer {
+	if in == nil {
+		return nil
+	}
+	return &data.Customer{Name: in.Name, Email: in.Email, Phone: in.Phone}
+}
+
+// AddressInput represents a postal address, Country is an ISO-3166 alpha-2 code such as "US".
+type AddressInput struct {
+	Recipient  string `json:"recipient" binding:"required,max=100"`
+	Line1      string `json:"line1" binding:"required,max=200"`
+	Line2      string `json:"line2" binding:"max=200"`
+	City       string `json:"city" binding:"required,max=100"`
+	Region     string `json:"region" binding:"max=100"`
+	PostalCode string `json:"postalCode" binding:"required,max=20"`
+	Country    string `json:"country" binding:"required,iso3166_1_alpha2"`
 }
 
-// ProductInput represents the structure of input for creating or updating a product.
+// ToData converts the input to its stored form.
+func (in *AddressInput) ToData() *data.Address {
+	if in == nil {
+		return nil
+	}
+	return &data.Address{
+		Recipient:  in.Recipient,
+		Line1:      in.Line1,
+		Line2:      in.Line2,
+		City:       in.City,
+		Region:     in.Region,
+		PostalCode: in.PostalCode,
+		Country:    in.Country,
+	}
+}
+
+// ProductInput represents a single order line referencing a catalog product.
 type ProductInput struct {
-	Name     string  `json:"name" binding:"required"`
-	Price    float64 `json:"price" binding:"required"`
-	Quantity uint64  `json:"quantity" binding:"required"`
+	SKU      string `json:"sku" binding:"required"`
+	Quantity uint64 `json:"quantity" binding:"required,max=10000"`
+}
+
+// TransitionInput represents the structure of input for changing the status of an order.
+// Shipment is required when the order moves to data.OrderShipped and not allowed otherwise.
+type TransitionInput struct {
+	Status   data.OrderStatus `json:"status" binding:"required"`
+	Notes    string           `json:"notes"`
+	Shipment *ShipmentInput   `json:"shipment"`
+}
+
+// ShipmentInput represents the carrier details recorded when an order is shipped.
+type ShipmentInput struct {
+	Carrier    string `json:"carrier" binding:"required,max=50"`
+	TrackingID string `json:"trackingId" binding:"required,max=100"`
+}
+
+// StockInput represents the structure of input for setting the available stock of a SKU.
+type StockInput struct {
+	Available *int64 `json:"available" binding:"required,gte=0"`
+}
+
+// LogLevelsInput represents a change of the log levels. An empty level keeps the current one, components
+// replace the current component levels when present. RevertAfter is a Go duration, e.g. "15m".
+type LogLevelsInput struct {
+	Level       string            `json:"level"`
+	Components  map[string]string `json:"components"`
+	RevertAfter string            `json:"revertAfter"`
+}
+
+// CatalogProductInput represents the structure of input for creating or updating a catalog product.
+// Currency defaults to data.DefaultCurrency. Active defaults to true on creation.
+type CatalogProductInput struct {
+	SKU         string       `json:"sku"`
+	Name        string       `json:"name" binding:"required"`
+	Description string       `json:"description"`
+	Price       money.Amount `json:"price" binding:"required,gt=0"`
+	Currency    string       `json:"currency"`
+	Active      *bool        `json:"active"`
+}
+
+// ErrPriceTooPrecise is returned when a price has more fractional digits than the order currency allows.
+var ErrPriceTooPrecise = errors.New("price has more precision than the currency allows")
+
+// ResolveCurrency returns the product currency and checks that the price is expressible in it.
+func (in *CatalogProductInput) ResolveCurrency() (money.Currency, error) {
+	return resolveCurrency(in.Currency, in.Price)
+}
+
+// ImportProductInput represents a historical order line, imported orders keep their original names and prices.
+type ImportProductInput struct {
+	SKU      string       `json:"sku"`
+	Name     string       `json:"name" binding:"required"`
+	Price    money.Amount `json:"price" binding:"required,gt=0"`
+	Quantity uint64       `json:"quantity" binding:"required,max=10000"`
 }
 
 // Order represents the structure of an order.
+// ExternalRef is set on imported orders, DeletedAt on soft-deleted orders which only admins can read.
 type Order struct {
-	ID          string             `json:"orderId"`
-	Version     int64              `json:"version"`
-	CreatedAt   string             `json:"createdAt"`
-	UpdatedAt   string             `json:"updatedAt"`
-	Products    []data.Product     `json:"products"`
-	User        string             `json:"user"`
-	TotalAmount float64            `json:"totalAmount"`
-	Status      data.OrderStatus   `json:"status"`
-	Updates     []data.OrderUpdate `json:"updates"`
+	ID              string           `json:"orderId"`
+	Version         int64            `json:"version"`
+	CreatedAt       string           `json:"createdAt"`
+	UpdatedAt       string           `json:"updatedAt"`
+	Products        []Product        `json:"products"`
+	User            string           `json:"user"`
+	TotalAmount     money.Amount     `json:"totalAmount"`
+	Currency        money.Currency   `json:"currency"`
+	Pricing         *Pricing         `json:"pricing,omitempty"`
+	Status          data.OrderStatus `json:"status"`
+	Customer        *Customer        `json:"customer,omitempty"`
+	ShippingAddress *Address         `json:"shippingAddress,omitempty"`
+	Shipment        *Shipment        `json:"shipment,omitempty"`
+	Updates         []OrderUpdate    `json:"updates"`
+	ExternalRef     string           `json:"externalRef,omitempty"`
+	DeletedAt       string           `json:"deletedAt,omitempty"`
+}
+
+// Product represents a single order line.
+type Product struct {
+	SKU       string       `json:"sku,omitempty"`
+	Name      string       `json:"name"`
+	UpdatedAt string       `json:"updatedAt"`
+	Price     money.Amount `json:"price"`
+	Status    string       `json:"status"`
+	Remarks   string       `json:"remarks"`
+	Quantity  uint64       `json:"quantity"`
+	Discount  money.Amount `json:"discount,omitempty"`
+}
+
+// Pricing represents the price breakdown of an order.
+type Pricing struct {
+	Subtotal   money.Amount `json:"subtotal"`
+	Discount   money.Amount `json:"discount"`
+	Tax        money.Amount `json:"tax"`
+	Total      money.Amount `json:"total"`
+	CouponCode string       `json:"couponCode,omitempty"`
+	TaxRegion  string       `json:"taxRegion,omitempty"`
+}
+
+// Customer represents the contact details of the person who placed an order.
+type Customer struct {
+	Name  string `json:"name"`
+	Email string `json:"email,omitempty"`
+	Phone string `json:"phone,omitempty"`
+}
+
+// Address represents a postal address.
+type Address struct {
+	Recipient  string `json:"recipient"`
+	Line1      string `json:"line1"`
+	Line2      string `json:"line2,omitempty"`
+	City       string `json:"city"`
+	Region     string `json:"region,omitempty"`
+	PostalCode string `json:"postalCode"`
+	Country    string `json:"country"`
+}
+
+// Shipment represents the carrier tracking details of a shipped order.
+type Shipment struct {
+	Carrier     string `json:"carrier"`
+	TrackingID  string `json:"trackingId"`
+	ShippedAt   string `json:"shippedAt,omitempty"`
+	DeliveredAt string `json:"deliveredAt,omitempty"`
+}
+
+// OrderUpdate represents a note recorded on an order.
+type OrderUpdate struct {
+	UpdatedAt string `json:"updatedAt"`
+	Notes     string `json:"notes"`
+	HandledBy string `json:"handledBy"`
+}
+
+// CouponInput represents the structure of input for creating a coupon.
+// Currency is required for fixed amount coupons, MaxUses of zero means unlimited.
+type CouponInput struct {
+	Code       string            `json:"code" binding:"required"`
+	Kind       data.DiscountKind `json:"kind" binding:"required,oneof=percent fixed"`
+	Value      money.Amount      `json:"value" binding:"required,gt=0"`
+	Currency   string            `json:"currency"`
+	Products   []string          `json:"products"`
+	ValidFrom  time.Time         `json:"validFrom" binding:"required"`
+	ValidUntil time.Time         `json:"validUntil" binding:"required,gtfield=ValidFrom"`
+	MaxUses    int64             `json:"maxUses" binding:"gte=0"`
+}
+
+// ImportOrderInput represents a single historical order read from an import file.
+// CreatedAt and Status are optional and preserved as-is when provided.
+type ImportOrderInput struct {
+	Products    []ImportProductInput `json:"products" binding:"required"`
+	Currency    string               `json:"currency"`
+	ExternalRef string               `json:"externalRef" binding:"required"`
+	User        string               `json:"user"`
+	Status      data.OrderStatus     `json:"status"`
+	CreatedAt   string               `json:"createdAt"`
+}
+
+// ResolveCurrency returns the order currency and checks that every product price is expressible in it.
+func (in *ImportOrderInput) ResolveCurrency() (money.Currency, error) {
+	prices := make([]money.Amount, len(in.Products))
+	for i, p := range in.Products {
+		prices[i] = p.Price
+	}
+	return resolveCurrency(in.Currency, prices...)
+}
+
+// resolveCurrency parses code, defaulting to data.DefaultCurrency, and checks every price fits it.
+func resolveCurrency(code string, prices ...money.Amount) (money.Currency, error) {
+	cur := data.DefaultCurrency
+	if code != "" {
+		var err error
+		if cur, err = money.ParseCurrency(code); err != nil {
+			return "", fmt.Errorf("%w: %q", err, code)
+		}
+	}
+	for _, p := range prices {
+		if !p.FitsCurrency(cur) {
+			return "", fmt.Errorf("%w: %s %s", ErrPriceTooPrecise, p, cur)
+		}
+	}
+	return cur, nil
+}
+
+// ImportRowError describes why a single row of an import file was rejected.
+type ImportRowError struct {
+	Row         int    `json:"row"`
+	ExternalRef string `json:"externalRef,omitempty"`
+	Message     string `json:"message"`
+}
+
+// ImportReport summarizes the outcome of an order import.
+type ImportReport struct {
+	Format    string           `json:"format"`
+	DryRun    bool             `json:"dryRun"`
+	TotalRows int              `json:"totalRows"`
+	ValidRows int              `json:"validRows"`
+	Inserted  int64            `json:"inserted"`
+	Matched   int64            `json:"matched"`
+	Updated   int64            `json:"updated"`
+	Skipped   int64            `json:"skipped"`
+	Errors    []ImportRowError `json:"errors"`
+}
+
+// AuditEntry represents a single audit log record of a mutating order operation.
+type AuditEntry struct {
+	ID          string             `json:"id"`
+	Action      data.AuditAction   `json:"action"`
+	OrderID     string             `json:"orderId,omitempty"`
+	ExternalRef string             `json:"externalRef,omitempty"`
+	Actor       string             `json:"actor"`
+	RequestID   string             `json:"requestId"`
+	Changes     []data.FieldChange `json:"changes"`
+	Timestamp   string             `json:"timestamp"`
+}
+
+// CatalogProduct represents a sellable product of the catalog.
+type CatalogProduct struct {
+	ID          string         `json:"id"`
+	SKU         string         `json:"sku"`
+	Name        string         `json:"name"`
+	Description string         `json:"description,omitempty"`
+	Price       money.Amount   `json:"price"`
+	Currency    money.Currency `json:"currency"`
+	Active      bool           `json:"active"`
+	CreatedAt   string         `json:"createdAt"`
+	UpdatedAt   string         `json:"updatedAt"`
+}
+
+// StockLevel represents the stock of a SKU that is available for new orders.
+type StockLevel struct {
+	ID        string `json:"id"`
+	SKU       string `json:"sku"`
+	Available int64  `json:"available"`
+	UpdatedAt string `json:"updatedAt"`
+}
+
+// LogLevels represents the log level of the service and of its components. RevertAt is when the levels go back
+// to the configured ones, it is omitted when they do not.
+type LogLevels struct {
+	Level      string            `json:"level"`
+	Components map[string]string `json:"components"`
+	RevertAt   string            `json:"revertAt,omitempty"`
+}
+
+// Coupon represents a redeemable discount code.
+type Coupon struct {
+	ID         string            `json:"id"`
+	Code       string            `json:"code"`
+	Kind       data.DiscountKind `json:"kind"`
+	Value      money.Amount      `json:"value"`
+	Currency   money.Currency    `json:"currency,omitempty"`
+	Products   []string          `json:"products,omitempty"`
+	ValidFrom  string            `json:"validFrom"`
+	ValidUntil string            `json:"validUntil"`
+	MaxUses    int64             `json:"maxUses"`
+	Uses       int64             `json:"uses"`
+	CreatedAt  string            `json:"createdAt"`
+}
+
+// CustomerSummary represents the order history of a single user.
+type CustomerSummary struct {
+	User          string                          `json:"user"`
+	OrderCount    int64                           `json:"orderCount"`
+	LifetimeSpend map[money.Currency]money.Amount `json:"lifetimeSpend"`
+	LastOrderAt   string                          `json:"lastOrderAt,omitempty"`
+	StatusCounts  map[data.OrderStatus]int64      `json:"statusCounts"`
 }
@@ -0,0 +1,62 @@
+package external
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// FieldsQueryParam selects a sparse fieldset, e.g. ?fields=orderId,status,totalAmount.
+const FieldsQueryParam = "fields"
+
+var ErrUnknownField = errors.New("unknown field")
+
+// OrderFields maps the order fields clients can select to their stored names.
+var OrderFields = map[string]string{
+	"orderId":         "_id",
+	"version":         "version",
+	"createdAt":       "createdAt",
+	"updatedAt":       "updatedAt",
+	"products":        "products",
+	"user":            "user",
+	"totalAmount":     "totalAmount",
+	"currency":        "currency",
+	"pricing":         "pricing",
+	"status":          "status",
+	"customer":        "customer",
+	"shippingAddress": "shippingAddress",
+	"shipment":        "shipment",
+	"updates":         "updates",
+}
+
+// ParseFields splits a comma separated field list, every field must be a key of allowed.
+// Duplicates are dropped, an empty list returns nil.
+func ParseFields(s string, allowed map[string]string) ([]string, error) {
+	var fields []string
+	for _, f := range strings.Split(s, ",") {
+		f = strings.TrimSpace(f)
+		if f == "" {
+			continue
+		}
+		if _, ok := allowed[f]; !ok {
+			return nil, fmt.Errorf("%w %q", ErrUnknownField, f)
+		}
+		if !slices.Contains(fields, f) {
+			fields = append(fields, f)
+		}
+	}
+	return fields, nil
+}
+
+// StoredFields returns the stored names of fields, fields must have been parsed against allowed.
+func StoredFields(fields []string, allowed map[string]string) []string {
+	if len(fields) == 0 {
+		return nil
+	}
+	stored := make([]string, 0, len(fields))
+	for _, f := range fields {
+		stored = append(stored, allowed[f])
+	}
+	return stored
+}
@@ -0,0 +1,90 @@
+package external
+
+import "time"
+
+// The query models declare the query parameters of the routes, see package query for the tags.
+// Defaults and bounds repeat the page size constants of the handlers, their tests pin them.
+
+// LimitQuery bounds the number of results of a listing.
+type LimitQuery struct {
+	Limit int64 `form:"limit,default=100" binding:"min=1,max=100"`
+}
+
+// FieldsQuery selects a sparse fieldset.
+type FieldsQuery struct {
+	Fields string `form:"fields"`
+}
+
+// IncludeDeletedQuery lets admins read soft-deleted orders, it is only accepted on internal routes.
+type IncludeDeletedQuery struct {
+	IncludeDeleted bool `form:"includeDeleted"`
+}
+
+// OrderListQuery is the query of the v1 order listing.
+type OrderListQuery struct {
+	LimitQuery
+	Offset int64 `form:"offset" binding:"min=0,max=100000"`
+	FieldsQuery
+}
+
+// AdminOrderListQuery is the query of the internal order listing.
+type AdminOrderListQuery struct {
+	OrderListQuery
+	IncludeDeletedQuery
+}
+
+// AdminOrderQuery is the query of the internal order read.
+type AdminOrderQuery struct {
+	FieldsQuery
+	IncludeDeletedQuery
+}
+
+// OrderPageQuery is the query of the v2 order listing, it pages with the cursor of the previous page.
+type OrderPageQuery struct {
+	LimitQuery
+	Cursor string `form:"cursor"`
+}
+
+// ProductListQuery is the query of the product listing.
+type ProductListQuery struct {
+	LimitQuery
+	IncludeInactive bool `form:"includeInactive"`
+}
+
+// ReportQuery holds the query parameters shared by every report. From and to are YYYY-MM-DD dates in tz
+// or RFC3339 timestamps.
+type ReportQuery struct {
+	From   string `form:"from"`
+	To     string `form:"to"`
+	TZ     string `form:"tz,default=UTC"`
+	Format string `form:"format,default=json" binding:"oneof=json csv"`
+}
+
+// RevenueReportQuery is the query of the revenue report.
+type RevenueReportQuery struct {
+	ReportQuery
+	Interval string `form:"interval,default=day" binding:"oneof=day week month"`
+}
+
+// TopProductsReportQuery is the query of the top products report.
+type TopProductsReportQuery struct {
+	ReportQuery
+	Limit int64 `form:"limit,default=10" binding:"min=1,max=100"`
+}
+
+// AuditSearchQuery is the query of the internal audit search, from and to bound an RFC3339 [from, to) range.
+type AuditSearchQuery struct {
+	LimitQuery
+	OrderID   string    `form:"orderId"`
+	Actor     string    `form:"actor"`
+	Action    string    `form:"action"`
+	RequestID string    `form:"requestId"`
+	From      time.Time `form:"from"`
+	To        time.Time `form:"to"`
+}
+
+// ImportQuery is the query of the internal order import, format falls back to the extension of an upload.
+type ImportQuery struct {
+	DryRun bool   `form:"dryRun"`
+	Format string `form:"format"`
+}
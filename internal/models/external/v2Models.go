@@ -0,0 +1,57 @@
+package external
+
+import (
+	"github.com/rameshsunkara/go-rest-api-example/internal/models/data"
+	"github.com/rameshsunkara/go-rest-api-example/pkg/money"
+)
+
+// Money represents an amount together with its currency, v2 never exposes a bare amount.
+type Money struct {
+	Amount   money.Amount   `json:"amount"`
+	Currency money.Currency `json:"currency"`
+}
+
+// OrderV2 represents the v2 structure of an order.
+// Compared to Order, every amount is a Money, the legacy line fields are gone and Total replaces
+// totalAmount and currency.
+type OrderV2 struct {
+	ID              string           `json:"orderId"`
+	Version         int64            `json:"version"`
+	CreatedAt       string           `json:"createdAt"`
+	UpdatedAt       string           `json:"updatedAt"`
+	Status          data.OrderStatus `json:"status"`
+	User            string           `json:"user"`
+	Products        []ProductV2      `json:"products"`
+	Total           Money            `json:"total"`
+	Pricing         *PricingV2       `json:"pricing,omitempty"`
+	Customer        *Customer        `json:"customer,omitempty"`
+	ShippingAddress *Address         `json:"shippingAddress,omitempty"`
+	Shipment        *Shipment        `json:"shipment,omitempty"`
+	Updates         []OrderUpdate    `json:"updates"`
+}
+
+// ProductV2 represents the v2 structure of an order line.
+type ProductV2 struct {
+	SKU      string `json:"sku,omitempty"`
+	Name     string `json:"name"`
+	Quantity uint64 `json:"quantity"`
+	Price    Money  `json:"price"`
+	Discount *Money `json:"discount,omitempty"`
+}
+
+// PricingV2 represents the v2 structure of the price breakdown of an order.
+type PricingV2 struct {
+	Subtotal   Money  `json:"subtotal"`
+	Discount   Money  `json:"discount"`
+	Tax        Money  `json:"tax"`
+	Total      Money  `json:"total"`
+	CouponCode string `json:"couponCode,omitempty"`
+	TaxRegion  string `json:"taxRegion,omitempty"`
+}
+
+// OrderPageV2 represents a page of orders. NextCursor is set when more orders follow,
+// pass it as the cursor query parameter to read the next page.
+type OrderPageV2 struct {
+	Data       []OrderV2 `json:"data"`
+	NextCursor string    `json:"nextCursor,omitempty"`
+}
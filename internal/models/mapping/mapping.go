@@ -0,0 +1,196 @@
+// Package mapping converts data models to the external models every endpoint responds with.
+// Storage fields never reach clients directly, so changing a data model cannot silently change the API.
+package mapping
+
+import (
+	"time"
+
+	"github.com/rameshsunkara/go-rest-api-example/internal/models/data"
+	"github.com/rameshsunkara/go-rest-api-example/internal/models/external"
+	"github.com/rameshsunkara/go-rest-api-example/internal/utilities"
+)
+
+// Order converts a stored order.
+func Order(o *data.Order) external.Order {
+	ext := external.Order{
+		ID:              o.ID.Hex(),
+		Version:         o.Version,
+		CreatedAt:       utilities.FormatTimeToISO(o.CreatedAt),
+		UpdatedAt:       utilities.FormatTimeToISO(o.UpdatedAt),
+		Products:        make([]external.Product, 0, len(o.Products)),
+		User:            o.User,
+		TotalAmount:     o.TotalAmount,
+		Currency:        o.Currency,
+		Status:          o.Status,
+		Customer:        customer(o.Customer),
+		ShippingAddress: address(o.ShippingAddress),
+		Shipment:        shipment(o.Shipment),
+		Updates:         make([]external.OrderUpdate, 0, len(o.Updates)),
+		ExternalRef:     o.ExternalRef,
+		DeletedAt:       optionalTime(o.DeletedAt),
+	}
+	for _, p := range o.Products {
+		ext.Products = append(ext.Products, external.Product{
+			SKU:       p.SKU,
+			Name:      p.Name,
+			UpdatedAt: utilities.FormatTimeToISO(p.UpdatedAt),
+			Price:     p.Price,
+			Status:    p.Status,
+			Remarks:   p.Remarks,
+			Quantity:  p.Quantity,
+			Discount:  p.Discount,
+		})
+	}
+	if p := o.Pricing; p != nil {
+		ext.Pricing = &external.Pricing{
+			Subtotal:   p.Subtotal,
+			Discount:   p.Discount,
+			Tax:        p.Tax,
+			Total:      p.Total,
+			CouponCode: p.CouponCode,
+			TaxRegion:  p.TaxRegion,
+		}
+	}
+	for _, u := range o.Updates {
+		ext.Updates = append(ext.Updates, external.OrderUpdate{
+			UpdatedAt: utilities.FormatTimeToISO(u.UpdatedAt),
+			Notes:     u.Notes,
+			HandledBy: u.HandledBy,
+		})
+	}
+	return ext
+}
+
+// Orders converts stored orders, the result is never nil.
+func Orders(orders []data.Order) []external.Order {
+	ext := make([]external.Order, 0, len(orders))
+	for i := range orders {
+		ext = append(ext, Order(&orders[i]))
+	}
+	return ext
+}
+
+func customer(c *data.Customer) *external.Customer {
+	if c == nil {
+		return nil
+	}
+	return &external.Customer{Name: c.Name, Email: c.Email, Phone: c.Phone}
+}
+
+func address(a *data.Address) *external.Address {
+	if a == nil {
+		return nil
+	}
+	return &external.Address{
+		Recipient:  a.Recipient,
+		Line1:      a.Line1,
+		Line2:      a.Line2,
+		City:       a.City,
+		Region:     a.Region,
+		PostalCode: a.PostalCode,
+		Country:    a.Country,
+	}
+}
+
+func shipment(s *data.Shipment) *external.Shipment {
+	if s == nil {
+		return nil
+	}
+	return &external.Shipment{
+		Carrier:     s.Carrier,
+		TrackingID:  s.TrackingID,
+		ShippedAt:   optionalTime(s.ShippedAt),
+		DeliveredAt: optionalTime(s.DeliveredAt),
+	}
+}
+
+// AuditEntries converts stored audit entries, the result is never nil.
+func AuditEntries(entries []data.AuditEntry) []external.AuditEntry {
+	ext := make([]external.AuditEntry, 0, len(entries))
+	for _, e := range entries {
+		entry := external.AuditEntry{
+			ID:          e.ID.Hex(),
+			Action:      e.Action,
+			ExternalRef: e.ExternalRef,
+			Actor:       e.Actor,
+			RequestID:   e.RequestID,
+			Changes:     e.Changes,
+			Timestamp:   utilities.FormatTimeToISO(e.Timestamp),
+		}
+		if !e.OrderID.IsZero() {
+			entry.OrderID = e.OrderID.Hex()
+		}
+		ext = append(ext, entry)
+	}
+	return ext
+}
+
+// CatalogProduct converts a stored catalog product.
+func CatalogProduct(p *data.CatalogProduct) external.CatalogProduct {
+	return external.CatalogProduct{
+		ID:          p.ID.Hex(),
+		SKU:         p.SKU,
+		Name:        p.Name,
+		Description: p.Description,
+		Price:       p.Price,
+		Currency:    p.Currency,
+		Active:      p.Active,
+		CreatedAt:   utilities.FormatTimeToISO(p.CreatedAt),
+		UpdatedAt:   utilities.FormatTimeToISO(p.UpdatedAt),
+	}
+}
+
+// CatalogProducts converts stored catalog products, the result is never nil.
+func CatalogProducts(products []data.CatalogProduct) []external.CatalogProduct {
+	ext := make([]external.CatalogProduct, 0, len(products))
+	for i := range products {
+		ext = append(ext, CatalogProduct(&products[i]))
+	}
+	return ext
+}
+
+// StockLevel converts a stored stock level.
+func StockLevel(l *data.StockLevel) external.StockLevel {
+	return external.StockLevel{
+		ID:        l.ID.Hex(),
+		SKU:       l.SKU,
+		Available: l.Available,
+		UpdatedAt: utilities.FormatTimeToISO(l.UpdatedAt),
+	}
+}
+
+// Coupon converts a stored coupon.
+func Coupon(c *data.Coupon) external.Coupon {
+	return external.Coupon{
+		ID:         c.ID.Hex(),
+		Code:       c.Code,
+		Kind:       c.Kind,
+		Value:      c.Value,
+		Currency:   c.Currency,
+		Products:   c.Products,
+		ValidFrom:  utilities.FormatTimeToISO(c.ValidFrom),
+		ValidUntil: utilities.FormatTimeToISO(c.ValidUntil),
+		MaxUses:    c.MaxUses,
+		Uses:       c.Uses,
+		CreatedAt:  utilities.FormatTimeToISO(c.CreatedAt),
+	}
+}
+
+// CustomerSummary converts an aggregated customer summary.
+func CustomerSummary(s *data.CustomerSummary) external.CustomerSummary {
+	return external.CustomerSummary{
+		User:          s.User,
+		OrderCount:    s.OrderCount,
+		LifetimeSpend: s.LifetimeSpend,
+		LastOrderAt:   optionalTime(s.LastOrderAt),
+		StatusCounts:  s.StatusCounts,
+	}
+}
+
+// optionalTime formats t, it returns an empty string when t is nil.
+func optionalTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return utilities.FormatTimeToISO(*t)
+}
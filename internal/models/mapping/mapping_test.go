@@ -0,0 +1,192 @@
+package mapping_test
+
+import (
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/rameshsunkara/go-rest-api-example/internal/models/data"
+	"github.com/rameshsunkara/go-rest-api-example/internal/models/mapping"
+	"github.com/rameshsunkara/go-rest-api-example/pkg/money"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// update rewrites the golden files, run `go test ./internal/models/mapping -update` after an intended API change.
+var update = flag.Bool("update", false, "update golden files")
+
+var (
+	created   = time.Date(2025, time.March, 14, 9, 26, 53, 589_000_000, time.UTC)
+	updated   = created.Add(36 * time.Hour)
+	objectID  = mustObjectID("65f2c1a0e4b0a1b2c3d4e5f6")
+	orderID   = mustObjectID("65f2c1a0e4b0a1b2c3d4e5f7")
+	delivered = updated.Add(48 * time.Hour)
+)
+
+func mustObjectID(hex string) primitive.ObjectID {
+	id, err := primitive.ObjectIDFromHex(hex)
+	if err != nil {
+		panic(err)
+	}
+	return id
+}
+
+// fullOrder returns an order with every field set.
+func fullOrder() *data.Order {
+	return &data.Order{
+		ID:        orderID,
+		Version:   3,
+		CreatedAt: created,
+		UpdatedAt: updated,
+		Products: []data.Product{{
+			SKU:       "SKU-1",
+			Name:      "Espresso Machine",
+			UpdatedAt: created,
+			Price:     money.MustParse("249.99"),
+			Quantity:  2,
+			Discount:  money.MustParse("25"),
+		}},
+		User:        "jane@example.com",
+		TotalAmount: money.MustParse("486.52"),
+		Currency:    money.USD,
+		Pricing: &data.Pricing{
+			Subtotal:   money.MustParse("499.98"),
+			Discount:   money.MustParse("50"),
+			Tax:        money.MustParse("36.54"),
+			Total:      money.MustParse("486.52"),
+			CouponCode: "SPRING25",
+			TaxRegion:  "US-CA",
+		},
+		Status:   data.OrderDelivered,
+		Customer: &data.Customer{Name: "Jane Doe", Email: "jane@example.com", Phone: "+14155550100"},
+		ShippingAddress: &data.Address{
+			Recipient:  "Jane Doe",
+			Line1:      "1 Market St",
+			City:       "San Francisco",
+			Region:     "CA",
+			PostalCode: "94105",
+			Country:    "US",
+		},
+		Shipment: &data.Shipment{
+			Carrier:     "UPS",
+			TrackingID:  "1Z999AA10123456784",
+			ShippedAt:   &updated,
+			DeliveredAt: &delivered,
+		},
+		Updates:       []data.OrderUpdate{{UpdatedAt: updated, Notes: "shipped", HandledBy: "ops@example.com"}},
+		DeletedAt:     &delivered,
+		ExternalRef:   "legacy-42",
+		StockReserved: true,
+	}
+}
+
+func TestGolden(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name   string
+		golden string
+		got    any
+	}{
+		{
+			name:   "order",
+			golden: "order.json",
+			got:    mapping.Order(fullOrder()),
+		},
+		{
+			name:   "minimal order",
+			golden: "order_minimal.json",
+			got:    mapping.Order(&data.Order{ID: orderID, CreatedAt: created, UpdatedAt: created, Status: data.OrderPending}),
+		},
+		{
+			name:   "order v2",
+			golden: "order_v2.json",
+			got:    mapping.OrderV2(fullOrder()),
+		},
+		{
+			name:   "minimal order v2",
+			golden: "order_v2_minimal.json",
+			got:    mapping.OrderV2(&data.Order{ID: orderID, CreatedAt: created, UpdatedAt: created, Status: data.OrderPending}),
+		},
+		{
+			name:   "catalog product",
+			golden: "catalog_product.json",
+			got: mapping.CatalogProduct(&data.CatalogProduct{
+				ID:          objectID,
+				SKU:         "SKU-1",
+				Name:        "Espresso Machine",
+				Description: "15 bar pump",
+				Price:       money.MustParse("249.99"),
+				Currency:    money.USD,
+				Active:      true,
+				CreatedAt:   created,
+				UpdatedAt:   updated,
+			}),
+		},
+		{
+			name:   "stock level",
+			golden: "stock_level.json",
+			got:    mapping.StockLevel(&data.StockLevel{ID: objectID, SKU: "SKU-1", Available: 12, UpdatedAt: updated}),
+		},
+		{
+			name:   "coupon",
+			golden: "coupon.json",
+			got: mapping.Coupon(&data.Coupon{
+				ID:         objectID,
+				Code:       "SPRING25",
+				Kind:       data.DiscountFixed,
+				Value:      money.MustParse("25"),
+				Currency:   money.USD,
+				Products:   []string{"SKU-1"},
+				ValidFrom:  created,
+				ValidUntil: delivered,
+				MaxUses:    100,
+				Uses:       7,
+				CreatedAt:  created,
+			}),
+		},
+		{
+			name:   "customer summary",
+			golden: "customer_summary.json",
+			got: mapping.CustomerSummary(&data.CustomerSummary{
+				User:          "jane@example.com",
+				OrderCount:    3,
+				LifetimeSpend: map[money.Currency]money.Amount{money.USD: money.MustParse("486.52")},
+				LastOrderAt:   &updated,
+				StatusCounts:  map[data.OrderStatus]int64{data.OrderDelivered: 2, data.OrderCancelled: 1},
+			}),
+		},
+		{
+			name:   "audit entries",
+			golden: "audit_entries.json",
+			got: mapping.AuditEntries([]data.AuditEntry{{
+				ID:        objectID,
+				Action:    data.AuditTransition,
+				OrderID:   orderID,
+				Actor:     "ops@example.com",
+				RequestID: "req-1",
+				Changes:   []data.FieldChange{{Field: "status", Before: "OrderShipped", After: "OrderDelivered"}},
+				Timestamp: delivered,
+			}}),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, err := json.MarshalIndent(tt.got, "", "  ")
+			require.NoError(t, err)
+			got = append(got, '\n')
+
+			path := filepath.Join("testdata", tt.golden)
+			if *update {
+				require.NoError(t, os.WriteFile(path, got, 0o600))
+			}
+			want, err := os.ReadFile(path)
+			require.NoError(t, err)
+			assert.JSONEq(t, string(want), string(got))
+		})
+	}
+}
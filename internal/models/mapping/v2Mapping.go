@@ -0,0 +1,71 @@
+package mapping
+
+import (
+	"github.com/rameshsunkara/go-rest-api-example/internal/models/data"
+	"github.com/rameshsunkara/go-rest-api-example/internal/models/external"
+	"github.com/rameshsunkara/go-rest-api-example/internal/utilities"
+	"github.com/rameshsunkara/go-rest-api-example/pkg/money"
+)
+
+// OrderV2 converts a stored order to its v2 representation.
+func OrderV2(o *data.Order) external.OrderV2 {
+	cur := o.Currency
+	if cur == "" {
+		// legacy orders were written before orders had a currency
+		cur = data.DefaultCurrency
+	}
+	ext := external.OrderV2{
+		ID:              o.ID.Hex(),
+		Version:         o.Version,
+		CreatedAt:       utilities.FormatTimeToISO(o.CreatedAt),
+		UpdatedAt:       utilities.FormatTimeToISO(o.UpdatedAt),
+		Status:          o.Status,
+		User:            o.User,
+		Products:        make([]external.ProductV2, 0, len(o.Products)),
+		Total:           external.Money{Amount: o.TotalAmount, Currency: cur},
+		Customer:        customer(o.Customer),
+		ShippingAddress: address(o.ShippingAddress),
+		Shipment:        shipment(o.Shipment),
+		Updates:         make([]external.OrderUpdate, 0, len(o.Updates)),
+	}
+	for _, p := range o.Products {
+		line := external.ProductV2{
+			SKU:      p.SKU,
+			Name:     p.Name,
+			Quantity: p.Quantity,
+			Price:    external.Money{Amount: p.Price, Currency: cur},
+		}
+		if p.Discount != 0 {
+			line.Discount = &external.Money{Amount: p.Discount, Currency: cur}
+		}
+		ext.Products = append(ext.Products, line)
+	}
+	if p := o.Pricing; p != nil {
+		in := func(a money.Amount) external.Money { return external.Money{Amount: a, Currency: cur} }
+		ext.Pricing = &external.PricingV2{
+			Subtotal:   in(p.Subtotal),
+			Discount:   in(p.Discount),
+			Tax:        in(p.Tax),
+			Total:      in(p.Total),
+			CouponCode: p.CouponCode,
+			TaxRegion:  p.TaxRegion,
+		}
+	}
+	for _, u := range o.Updates {
+		ext.Updates = append(ext.Updates, external.OrderUpdate{
+			UpdatedAt: utilities.FormatTimeToISO(u.UpdatedAt),
+			Notes:     u.Notes,
+			HandledBy: u.HandledBy,
+		})
+	}
+	return ext
+}
+
+// OrdersV2 converts stored orders to their v2 representation, the result is never nil.
+func OrdersV2(orders []data.Order) []external.OrderV2 {
+	ext := make([]external.OrderV2, 0, len(orders))
+	for i := range orders {
+		ext = append(ext, OrderV2(&orders[i]))
+	}
+	return ext
+}
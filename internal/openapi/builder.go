@@ -0,0 +1,317 @@
+package openapi
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rameshsunkara/go-rest-api-example/internal/query"
+	"github.com/rameshsunkara/go-rest-api-example/pkg/money"
+)
+
+const jsonContent = "application/json"
+
+// ErrInvalidRoute is returned when a registered route cannot be described.
+var ErrInvalidRoute = errors.New("route cannot be described")
+
+// Route describes a registered route. Body and the Responses values are values of the model types,
+// their fields are not read.
+type Route struct {
+	ID         string // operationId, unique across the document
+	Summary    string
+	Tags       []string
+	Deprecated bool
+	Params     []Parameter
+	Query      *query.Schema // query parameters, Params override the ones of the same name
+	Body       any           // request body, nil when the route takes none
+	// OptionalBody marks a Body that may be omitted
+	OptionalBody bool
+	Responses    map[int]any // response bodies by status code, a nil body means no content
+}
+
+// Builder collects routes and describes them as a Document.
+type Builder struct {
+	doc       Document
+	errorBody any
+	requests  map[reflect.Type]bool // component types keyed to whether they describe a request
+	names     map[string]reflect.Type
+	err       error
+}
+
+// knownSchemas describes the types that do not encode as their Go kind.
+var knownSchemas = map[reflect.Type]Schema{
+	reflect.TypeFor[time.Time]():    {Type: "string", Format: "date-time"},
+	reflect.TypeFor[money.Amount](): {Type: "string", Format: "decimal", Pattern: `^-?[0-9]+(\.[0-9]+)?$`},
+}
+
+// New creates a Builder, errorBody is the model of the default error response of every operation.
+func New(info Info, errorBody any) *Builder {
+	return &Builder{
+		doc: Document{
+			OpenAPI:  Version,
+			Info:     info,
+			Security: []map[string][]string{{"Bearer": {}}},
+			Paths:    map[string]PathItem{},
+			Components: Components{
+				Schemas: map[string]*Schema{},
+				SecuritySchemes: map[string]SecurityScheme{
+					"Bearer": {Type: "http", Scheme: "bearer", BearerFormat: "JWT"},
+				},
+			},
+		},
+		errorBody: errorBody,
+		requests:  map[reflect.Type]bool{},
+		names:     map[string]reflect.Type{},
+	}
+}
+
+// Add describes the route registered for method on path, path uses gin syntax such as /orders/:id.
+func (b *Builder) Add(method, path string, r Route) {
+	if b.err != nil {
+		return
+	}
+	for _, p := range r.Params {
+		if p.Schema == nil {
+			b.fail(fmt.Errorf("%w: %s parameter %s of %s %s has no schema", ErrInvalidRoute, p.In, p.Name, method, path))
+			return
+		}
+	}
+	op := &Operation{
+		OperationID: r.ID,
+		Summary:     r.Summary,
+		Tags:        r.Tags,
+		Deprecated:  r.Deprecated,
+		Parameters:  b.params(r),
+		Responses:   map[string]*Response{},
+	}
+	if r.Body != nil {
+		op.RequestBody = &RequestBody{
+			Required: !r.OptionalBody,
+			Content:  map[string]MediaType{jsonContent: {Schema: b.schema(reflect.TypeOf(r.Body), true)}},
+		}
+	}
+	for status, body := range r.Responses {
+		resp := &Response{Description: http.StatusText(status)}
+		if body != nil {
+			resp.Content = map[string]MediaType{jsonContent: {Schema: b.schema(reflect.TypeOf(body), false)}}
+		}
+		op.Responses[strconv.Itoa(status)] = resp
+	}
+	op.Responses["default"] = &Response{
+		Description: "Error",
+		Content:     map[string]MediaType{jsonContent: {Schema: b.schema(reflect.TypeOf(b.errorBody), false)}},
+	}
+
+	p := Path(path)
+	item, ok := b.doc.Paths[p]
+	if !ok {
+		item = PathItem{}
+		b.doc.Paths[p] = item
+	}
+	m := strings.ToLower(method)
+	if _, dup := item[m]; dup {
+		b.fail(fmt.Errorf("%w: %s %s is registered twice", ErrInvalidRoute, method, path))
+		return
+	}
+	for _, other := range b.doc.Paths {
+		for _, o := range other {
+			if o.OperationID == r.ID {
+				b.fail(fmt.Errorf("%w: operationId %s is used twice", ErrInvalidRoute, r.ID))
+				return
+			}
+		}
+	}
+	item[m] = op
+	for _, t := range r.Tags {
+		if !slices.ContainsFunc(b.doc.Tags, func(tag Tag) bool { return tag.Name == t }) {
+			b.doc.Tags = append(b.doc.Tags, Tag{Name: t})
+		}
+	}
+}
+
+// Document returns the described API, it fails when a route or model could not be described.
+func (b *Builder) Document() (*Document, error) {
+	if b.err != nil {
+		return nil, b.err
+	}
+	return &b.doc, nil
+}
+
+// Path converts a gin path such as /orders/:id to its OpenAPI form /orders/{id}.
+func Path(ginPath string) string {
+	segments := strings.Split(ginPath, "/")
+	for i, s := range segments {
+		if strings.HasPrefix(s, ":") || strings.HasPrefix(s, "*") {
+			segments[i] = "{" + s[1:] + "}"
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+// params returns the parameters of r followed by the ones of its query schema.
+func (b *Builder) params(r Route) []Parameter {
+	params := slices.Clone(r.Params)
+	if r.Query == nil {
+		return params
+	}
+	for _, p := range r.Query.Params() {
+		if !slices.ContainsFunc(params, func(o Parameter) bool { return o.Name == p.Name && o.In == "query" }) {
+			params = append(params, b.queryParam(p))
+		}
+	}
+	return params
+}
+
+// queryParam describes a query parameter with its bounds, allowed values and default.
+func (b *Builder) queryParam(p query.Param) Parameter {
+	t := p.Type
+	if p.Repeated() {
+		t = t.Elem()
+	}
+	s := b.schema(t, true)
+	s.Enum = p.Enum
+	switch s.Type {
+	case "integer":
+		if p.Min != nil {
+			m := float64(*p.Min)
+			s.Minimum = &m
+		}
+		if p.Max != nil {
+			m := float64(*p.Max)
+			s.Maximum = &m
+		}
+		if n, err := strconv.ParseInt(p.Default, 10, 64); err == nil {
+			s.Default = n
+		}
+	case "boolean":
+		if v, err := strconv.ParseBool(p.Default); err == nil {
+			s.Default = v
+		}
+	case "string":
+		if p.Max != nil {
+			m := int(*p.Max)
+			s.MaxLength = &m
+		}
+		if p.Default != "" {
+			s.Default = p.Default
+		}
+	}
+	if p.Repeated() {
+		s = &Schema{Type: "array", Items: s}
+	}
+	return Parameter{Name: p.Name, In: "query", Required: p.Required, Schema: s}
+}
+
+func (b *Builder) fail(err error) {
+	if b.err == nil {
+		b.err = err
+	}
+}
+
+// schema describes t, named structs become components. Request models mark the fields bound as
+// required, response models the fields that are never omitted.
+func (b *Builder) schema(t reflect.Type, request bool) *Schema {
+	if s, ok := knownSchemas[t]; ok {
+		return &s
+	}
+	switch t.Kind() {
+	case reflect.Pointer:
+		return b.schema(t.Elem(), request)
+	case reflect.Struct:
+		if t.Name() == "" {
+			return b.object(t, request)
+		}
+		return b.component(t, request)
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		return &Schema{Type: "array", Items: b.schema(t.Elem(), request)}
+	case reflect.Map:
+		if t.Key().Kind() != reflect.String {
+			b.fail(fmt.Errorf("%w: map key of %s is not a string", ErrInvalidRoute, t))
+			return &Schema{}
+		}
+		return &Schema{Type: "object", AdditionalProperties: b.schema(t.Elem(), request)}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Int8, reflect.Int16, reflect.Int32:
+		return &Schema{Type: "integer", Format: "int32"}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		zero := 0.0
+		return &Schema{Type: "integer", Format: "int64", Minimum: &zero}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number", Format: "double"}
+	case reflect.Interface:
+		return &Schema{}
+	default:
+		b.fail(fmt.Errorf("%w: %s cannot be encoded as JSON", ErrInvalidRoute, t))
+		return &Schema{}
+	}
+}
+
+// component describes a named struct once and refers to it.
+func (b *Builder) component(t reflect.Type, request bool) *Schema {
+	ref := &Schema{Ref: "#/components/schemas/" + t.Name()}
+	if other, ok := b.names[t.Name()]; ok {
+		switch {
+		case other != t:
+			b.fail(fmt.Errorf("%w: %s and %s share a schema name", ErrInvalidRoute, other, t))
+		case b.requests[t] != request:
+			b.fail(fmt.Errorf("%w: %s is used by requests and responses", ErrInvalidRoute, t))
+		}
+		return ref
+	}
+	b.names[t.Name()] = t
+	b.requests[t] = request
+	b.doc.Components.Schemas[t.Name()] = b.object(t, request)
+	return ref
+}
+
+func (b *Builder) object(t reflect.Type, request bool) *Schema {
+	s := &Schema{Type: "object", Properties: map[string]*Schema{}}
+	b.fields(s, t, request)
+	return s
+}
+
+func (b *Builder) fields(s *Schema, t reflect.Type, request bool) {
+	for i := range t.NumField() {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+		if f.Anonymous && name == "" && f.Type.Kind() == reflect.Struct {
+			b.fields(s, f.Type, request)
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		s.Properties[name] = b.schema(f.Type, request)
+		if required(f, opts, request) {
+			s.Required = append(s.Required, name)
+		}
+	}
+}
+
+func required(f reflect.StructField, jsonOpts string, request bool) bool {
+	if request {
+		return slices.Contains(strings.Split(f.Tag.Get("binding"), ","), "required")
+	}
+	opts := strings.Split(jsonOpts, ",")
+	return !slices.Contains(opts, "omitempty") && !slices.Contains(opts, "omitzero")
+}
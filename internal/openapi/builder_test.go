@@ -0,0 +1,180 @@
+package openapi_test
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/rameshsunkara/go-rest-api-example/internal/openapi"
+	"github.com/rameshsunkara/go-rest-api-example/internal/query"
+	"github.com/rameshsunkara/go-rest-api-example/pkg/money"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type apiError struct {
+	Message string `json:"message"`
+}
+
+type lineInput struct {
+	SKU      string `json:"sku" binding:"required"`
+	Quantity uint64 `json:"quantity"`
+}
+
+type orderInput struct {
+	Lines []lineInput `json:"lines" binding:"required,min=1"`
+	Note  string      `json:"note"`
+}
+
+type order struct {
+	ID       string                    `json:"id"`
+	Total    money.Amount              `json:"total"`
+	Created  time.Time                 `json:"created"`
+	Tags     map[string]int64          `json:"tags,omitempty"`
+	Shipment *struct{ Carrier string } `json:"shipment,omitempty"`
+	Details  interface{}               `json:"details"`
+	Secret   string                    `json:"-"`
+}
+
+type orderListQuery struct {
+	Limit  int64    `form:"limit,default=10" binding:"min=1,max=50"`
+	Status []string `form:"status" binding:"dive,oneof=open closed"`
+	Sort   string   `form:"sort,default=asc" binding:"oneof=asc desc"`
+	User   string   `form:"user" binding:"required,max=254"`
+	Exact  bool     `form:"exact,default=true"`
+}
+
+func TestBuilder(t *testing.T) {
+	t.Parallel()
+	b := openapi.New(openapi.Info{Title: "Test", Version: "1"}, apiError{})
+	b.Add(http.MethodPost, "/orders", openapi.Route{
+		ID:        "createOrder",
+		Tags:      []string{"Orders"},
+		Body:      orderInput{},
+		Responses: map[int]any{http.StatusCreated: order{}},
+	})
+	b.Add(http.MethodGet, "/orders/:id", openapi.Route{
+		ID:        "getOrder",
+		Tags:      []string{"Orders"},
+		Params:    []openapi.Parameter{{Name: "id", In: "path", Required: true, Schema: openapi.String("^[0-9]+$")}},
+		Responses: map[int]any{http.StatusOK: &order{}, http.StatusNoContent: nil},
+	})
+	doc, err := b.Document()
+	require.NoError(t, err)
+
+	assert.Equal(t, openapi.Version, doc.OpenAPI)
+	assert.Equal(t, []openapi.Tag{{Name: "Orders"}}, doc.Tags)
+
+	create := doc.Paths["/orders"]["post"]
+	require.NotNil(t, create)
+	require.NotNil(t, create.RequestBody)
+	assert.True(t, create.RequestBody.Required)
+	assert.Equal(t, "#/components/schemas/orderInput", create.RequestBody.Content["application/json"].Schema.Ref)
+	assert.Equal(t, "#/components/schemas/order", create.Responses["201"].Content["application/json"].Schema.Ref)
+	assert.Equal(t, "#/components/schemas/apiError", create.Responses["default"].Content["application/json"].Schema.Ref)
+
+	get := doc.Paths["/orders/{id}"]["get"]
+	require.NotNil(t, get)
+	assert.Nil(t, get.RequestBody)
+	assert.Empty(t, get.Responses["204"].Content)
+	assert.Equal(t, "No Content", get.Responses["204"].Description)
+
+	schemas := doc.Components.Schemas
+	assert.Equal(t, []string{"lines"}, schemas["orderInput"].Required, "requests require bound fields")
+	assert.Equal(t, []string{"sku"}, schemas["lineInput"].Required)
+	assert.Equal(t, "#/components/schemas/lineInput", schemas["orderInput"].Properties["lines"].Items.Ref)
+
+	o := schemas["order"]
+	assert.Equal(t, []string{"id", "total", "created", "details"}, o.Required, "responses require fields never omitted")
+	assert.Equal(t, "decimal", o.Properties["total"].Format)
+	assert.Equal(t, "date-time", o.Properties["created"].Format)
+	assert.Equal(t, "int64", o.Properties["tags"].AdditionalProperties.Format)
+	assert.Equal(t, "object", o.Properties["shipment"].Type, "anonymous structs are inlined")
+	assert.Equal(t, &openapi.Schema{}, o.Properties["details"])
+	assert.NotContains(t, o.Properties, "Secret")
+}
+
+func TestBuilderQueryParams(t *testing.T) {
+	t.Parallel()
+	b := openapi.New(openapi.Info{Title: "Test", Version: "1"}, apiError{})
+	b.Add(http.MethodGet, "/orders", openapi.Route{
+		ID:        "listOrders",
+		Params:    []openapi.Parameter{{Name: "sort", In: "query", Schema: openapi.Enum("asc")}},
+		Query:     query.MustSchema(orderListQuery{}),
+		Responses: map[int]any{http.StatusOK: []order{}},
+	})
+	doc, err := b.Document()
+	require.NoError(t, err)
+
+	one, fifty, maxUser := 1.0, 50.0, 254
+	assert.Equal(t, []openapi.Parameter{
+		{Name: "sort", In: "query", Schema: openapi.Enum("asc")},
+		{Name: "limit", In: "query", Schema: &openapi.Schema{
+			Type: "integer", Format: "int64", Minimum: &one, Maximum: &fifty, Default: int64(10),
+		}},
+		{Name: "status", In: "query", Schema: &openapi.Schema{
+			Type: "array", Items: &openapi.Schema{Type: "string", Enum: []string{"open", "closed"}},
+		}},
+		{Name: "user", In: "query", Required: true, Schema: &openapi.Schema{Type: "string", MaxLength: &maxUser}},
+		{Name: "exact", In: "query", Schema: &openapi.Schema{Type: "boolean", Default: true}},
+	}, doc.Paths["/orders"]["get"].Parameters, "route params override the query schema")
+}
+
+func TestBuilderErrors(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name string
+		add  func(b *openapi.Builder)
+	}{
+		{
+			name: "duplicate route",
+			add: func(b *openapi.Builder) {
+				b.Add(http.MethodGet, "/orders", openapi.Route{ID: "a"})
+				b.Add(http.MethodGet, "/orders", openapi.Route{ID: "b"})
+			},
+		},
+		{
+			name: "duplicate operation ID",
+			add: func(b *openapi.Builder) {
+				b.Add(http.MethodGet, "/orders", openapi.Route{ID: "a"})
+				b.Add(http.MethodPost, "/orders", openapi.Route{ID: "a"})
+			},
+		},
+		{
+			name: "parameter without schema",
+			add: func(b *openapi.Builder) {
+				b.Add(http.MethodGet, "/orders", openapi.Route{ID: "a", Params: []openapi.Parameter{{Name: "limit", In: "query"}}})
+			},
+		},
+		{
+			name: "unsupported type",
+			add: func(b *openapi.Builder) {
+				b.Add(http.MethodGet, "/orders", openapi.Route{ID: "a", Responses: map[int]any{http.StatusOK: make(chan int)}})
+			},
+		},
+		{
+			name: "type used by requests and responses",
+			add: func(b *openapi.Builder) {
+				b.Add(http.MethodPost, "/orders", openapi.Route{
+					ID: "a", Body: lineInput{}, Responses: map[int]any{http.StatusOK: lineInput{}},
+				})
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			b := openapi.New(openapi.Info{Title: "Test", Version: "1"}, apiError{})
+			tt.add(b)
+			_, err := b.Document()
+			require.ErrorIs(t, err, openapi.ErrInvalidRoute)
+		})
+	}
+}
+
+func TestPath(t *testing.T) {
+	t.Parallel()
+	assert.Equal(t, "/orders/{id}/audit", openapi.Path("/orders/:id/audit"))
+	assert.Equal(t, "/files/{path}", openapi.Path("/files/*path"))
+	assert.Equal(t, "/orders", openapi.Path("/orders"))
+}
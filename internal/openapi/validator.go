@@ -0,0 +1,323 @@
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"os"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ErrInvalidDocument is returned when a document cannot be loaded.
+var ErrInvalidDocument = errors.New("invalid OpenAPI document")
+
+// Violation describes a part of a request or response that breaks the document.
+type Violation struct {
+	In      string `json:"in"` // path, query, header or body
+	Name    string `json:"name,omitempty"`
+	Message string `json:"message"`
+}
+
+// Load reads a YAML or JSON OpenAPI document.
+func Load(path string) (*Document, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var raw map[string]any
+	if err = yaml.Unmarshal(b, &raw); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidDocument, err)
+	}
+	// the document types only carry JSON tags
+	j, err := json.Marshal(raw)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidDocument, err)
+	}
+	var doc Document
+	if err = json.Unmarshal(j, &doc); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidDocument, err)
+	}
+	if doc.OpenAPI == "" || len(doc.Paths) == 0 {
+		return nil, fmt.Errorf("%w: %s has no paths", ErrInvalidDocument, path)
+	}
+	return &doc, nil
+}
+
+// Validator checks requests and responses against a document.
+// It understands the schemas this package generates, optional properties may be null.
+type Validator struct {
+	doc      *Document
+	patterns sync.Map // pattern to *regexp.Regexp
+}
+
+// NewValidator creates a Validator of doc.
+func NewValidator(doc *Document) *Validator {
+	return &Validator{doc: doc}
+}
+
+// Operation returns the operation of method on a path in gin syntax such as /orders/:id.
+func (v *Validator) Operation(method, ginPath string) (*Operation, bool) {
+	op, ok := v.doc.Paths[Path(ginPath)][strings.ToLower(method)]
+	return op, ok
+}
+
+// Request checks the parameters and body of req, pathParam returns the value of a path parameter.
+func (v *Validator) Request(op *Operation, req *http.Request, pathParam func(string) string, body []byte) []Violation {
+	var violations []Violation
+	query := req.URL.Query()
+	for _, p := range op.Parameters {
+		var raw string
+		var present bool
+		switch p.In {
+		case "path":
+			raw = pathParam(p.Name)
+			present = true
+		case "query":
+			present = query.Has(p.Name)
+			raw = query.Get(p.Name)
+		case "header":
+			raw = req.Header.Get(p.Name)
+			present = raw != ""
+		}
+		if !present {
+			if p.Required {
+				violations = append(violations, Violation{In: p.In, Name: p.Name, Message: "is required"})
+			}
+			continue
+		}
+		for _, viol := range v.check(p.Schema, parseParam(p.Schema, raw), "") {
+			violations = append(violations, Violation{In: p.In, Name: p.Name, Message: viol.Message})
+		}
+	}
+	for name := range query {
+		if !slices.ContainsFunc(op.Parameters, func(p Parameter) bool { return p.In == "query" && p.Name == name }) {
+			violations = append(violations, Violation{In: "query", Name: name, Message: "is not supported"})
+		}
+	}
+
+	if op.RequestBody == nil {
+		return violations
+	}
+	if len(bytes.TrimSpace(body)) == 0 {
+		if op.RequestBody.Required {
+			violations = append(violations, Violation{In: "body", Message: "is required"})
+		}
+		return violations
+	}
+	media, ok := op.RequestBody.Content[jsonContent]
+	if ct, _, err := mime.ParseMediaType(req.Header.Get("Content-Type")); err != nil || ct != jsonContent || !ok {
+		return append(violations, Violation{In: "header", Name: "Content-Type", Message: "must be " + jsonContent})
+	}
+	return append(violations, v.body(media.Schema, body)...)
+}
+
+// Response checks a JSON response body, statuses without their own response are checked against the default one.
+func (v *Validator) Response(op *Operation, status int, body []byte) []Violation {
+	resp, ok := op.Responses[strconv.Itoa(status)]
+	if !ok {
+		if resp, ok = op.Responses["default"]; !ok {
+			return []Violation{{In: "body", Message: fmt.Sprintf("status %d is not documented", status)}}
+		}
+	}
+	media, ok := resp.Content[jsonContent]
+	if !ok {
+		if len(bytes.TrimSpace(body)) == 0 {
+			return nil
+		}
+		return []Violation{{In: "body", Message: fmt.Sprintf("status %d is documented without a body", status)}}
+	}
+	return v.body(media.Schema, body)
+}
+
+func (v *Validator) body(s *Schema, body []byte) []Violation {
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+	var value any
+	if err := dec.Decode(&value); err != nil {
+		return []Violation{{In: "body", Message: "is not valid JSON"}}
+	}
+	violations := v.check(s, value, "")
+	for i := range violations {
+		violations[i].In = "body"
+	}
+	return violations
+}
+
+// parseParam converts a raw parameter to the JSON value its schema describes, unparsable values stay strings.
+func parseParam(s *Schema, raw string) any {
+	switch s.Type {
+	case "integer", "number":
+		if _, err := strconv.ParseFloat(raw, 64); err == nil {
+			return json.Number(raw)
+		}
+	case "boolean":
+		if b, err := strconv.ParseBool(raw); err == nil {
+			return b
+		}
+	}
+	return raw
+}
+
+// check returns the ways value breaks s, violations of nested values are named after their location.
+func (v *Validator) check(s *Schema, value any, at string) []Violation {
+	if s.Ref != "" {
+		name := strings.TrimPrefix(s.Ref, "#/components/schemas/")
+		resolved, ok := v.doc.Components.Schemas[name]
+		if !ok {
+			return []Violation{violation(at, "refers to unknown schema "+s.Ref)}
+		}
+		s = resolved
+	}
+	switch s.Type {
+	case "":
+		return nil
+	case "object":
+		return v.checkObject(s, value, at)
+	case "array":
+		items, ok := value.([]any)
+		if !ok {
+			return []Violation{violation(at, "must be an array")}
+		}
+		if s.Items == nil {
+			return nil
+		}
+		var found []Violation
+		for i, item := range items {
+			found = append(found, v.check(s.Items, item, fmt.Sprintf("%s[%d]", at, i))...)
+		}
+		return found
+	case "string":
+		return v.checkString(s, value, at)
+	case "integer", "number":
+		return checkNumber(s, value, at)
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			return []Violation{violation(at, "must be a boolean")}
+		}
+		return nil
+	default:
+		return []Violation{violation(at, "has unsupported schema type "+s.Type)}
+	}
+}
+
+func (v *Validator) checkObject(s *Schema, value any, at string) []Violation {
+	obj, ok := value.(map[string]any)
+	if !ok {
+		return []Violation{violation(at, "must be an object")}
+	}
+	var found []Violation
+	for _, name := range s.Required {
+		if val, present := obj[name]; !present || val == nil {
+			found = append(found, violation(join(at, name), "is required"))
+		}
+	}
+	keys := make([]string, 0, len(obj))
+	for k := range obj {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	for _, k := range keys {
+		val := obj[k]
+		if val == nil {
+			continue // optional properties may be null, missing required ones are reported above
+		}
+		prop, known := s.Properties[k]
+		if !known {
+			prop = s.AdditionalProperties
+		}
+		if prop != nil {
+			found = append(found, v.check(prop, val, join(at, k))...)
+		}
+	}
+	return found
+}
+
+func (v *Validator) checkString(s *Schema, value any, at string) []Violation {
+	str, ok := value.(string)
+	if !ok {
+		return []Violation{violation(at, "must be a string")}
+	}
+	var found []Violation
+	if len(s.Enum) > 0 && !slices.Contains(s.Enum, str) {
+		found = append(found, violation(at, "must be one of "+strings.Join(s.Enum, ", ")))
+	}
+	if s.MaxLength != nil && utf8.RuneCountInString(str) > *s.MaxLength {
+		found = append(found, violation(at, fmt.Sprintf("must be at most %d characters", *s.MaxLength)))
+	}
+	if s.Pattern != "" {
+		re, err := v.pattern(s.Pattern)
+		switch {
+		case err != nil:
+			found = append(found, violation(at, "has an invalid pattern in the spec"))
+		case !re.MatchString(str):
+			found = append(found, violation(at, "must match "+s.Pattern))
+		}
+	}
+	if s.Format == "date-time" {
+		if _, err := time.Parse(time.RFC3339, str); err != nil {
+			found = append(found, violation(at, "must be an RFC 3339 date-time"))
+		}
+	}
+	return found
+}
+
+func checkNumber(s *Schema, value any, at string) []Violation {
+	num, ok := value.(json.Number)
+	if !ok {
+		if s.Type == "integer" {
+			return []Violation{violation(at, "must be an integer")}
+		}
+		return []Violation{violation(at, "must be a number")}
+	}
+	f, err := num.Float64()
+	if err != nil {
+		return []Violation{violation(at, "must be a number")}
+	}
+	if s.Type == "integer" {
+		if _, err = num.Int64(); err != nil {
+			return []Violation{violation(at, "must be an integer")}
+		}
+	}
+	var found []Violation
+	if s.Minimum != nil && f < *s.Minimum {
+		found = append(found, violation(at, fmt.Sprintf("must be at least %v", *s.Minimum)))
+	}
+	if s.Maximum != nil && f > *s.Maximum {
+		found = append(found, violation(at, fmt.Sprintf("must be at most %v", *s.Maximum)))
+	}
+	return found
+}
+
+func (v *Validator) pattern(p string) (*regexp.Regexp, error) {
+	if re, ok := v.patterns.Load(p); ok {
+		return re.(*regexp.Regexp), nil
+	}
+	re, err := regexp.Compile(p)
+	if err != nil {
+		return nil, err
+	}
+	v.patterns.Store(p, re)
+	return re, nil
+}
+
+func join(at, name string) string {
+	if at == "" {
+		return name
+	}
+	return at + "." + name
+}
+
+func violation(at, msg string) Violation {
+	return Violation{Name: at, Message: msg}
+}
@@ -0,0 +1,181 @@
+package openapi_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/rameshsunkara/go-rest-api-example/internal/openapi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestValidator(t *testing.T) *openapi.Validator {
+	t.Helper()
+	b := openapi.New(openapi.Info{Title: "Test", Version: "1"}, apiError{})
+	b.Add(http.MethodGet, "/orders", openapi.Route{
+		ID: "listOrders",
+		Params: []openapi.Parameter{
+			{Name: "limit", In: "query", Schema: openapi.Int(1, 100)},
+			{Name: "format", In: "query", Schema: openapi.Enum("json", "csv")},
+			{Name: "X-Shop", In: "header", Required: true, Schema: openapi.MaxLengthString(4)},
+		},
+		Responses: map[int]any{http.StatusOK: []order{}},
+	})
+	b.Add(http.MethodPost, "/orders/:id", openapi.Route{
+		ID:        "createOrder",
+		Params:    []openapi.Parameter{{Name: "id", In: "path", Required: true, Schema: openapi.String("^[0-9]+$")}},
+		Body:      orderInput{},
+		Responses: map[int]any{http.StatusCreated: order{}, http.StatusNoContent: nil},
+	})
+	doc, err := b.Document()
+	require.NoError(t, err)
+	return openapi.NewValidator(doc)
+}
+
+func TestValidatorRequest(t *testing.T) {
+	t.Parallel()
+	v := newTestValidator(t)
+	tests := []struct {
+		name        string
+		method      string
+		target      string
+		id          string
+		contentType string
+		header      string
+		body        string
+		want        []openapi.Violation
+	}{
+		{name: "valid query", method: http.MethodGet, target: "/orders?limit=10&format=csv", header: "acme"},
+		{
+			name: "invalid query", method: http.MethodGet, target: "/orders?limit=0&format=xml&page=2", header: "acme",
+			want: []openapi.Violation{
+				{In: "query", Name: "limit", Message: "must be at least 1"},
+				{In: "query", Name: "format", Message: "must be one of json, csv"},
+				{In: "query", Name: "page", Message: "is not supported"},
+			},
+		},
+		{
+			name: "not an integer", method: http.MethodGet, target: "/orders?limit=1.5", header: "acme",
+			want: []openapi.Violation{{In: "query", Name: "limit", Message: "must be an integer"}},
+		},
+		{
+			name: "missing header", method: http.MethodGet, target: "/orders",
+			want: []openapi.Violation{{In: "header", Name: "X-Shop", Message: "is required"}},
+		},
+		{
+			name: "header too long", method: http.MethodGet, target: "/orders", header: "globex",
+			want: []openapi.Violation{{In: "header", Name: "X-Shop", Message: "must be at most 4 characters"}},
+		},
+		{
+			name: "valid body", method: http.MethodPost, target: "/orders/1", id: "1", contentType: "application/json",
+			body: `{"lines":[{"sku":"P-1","quantity":2}],"note":null}`,
+		},
+		{
+			name: "invalid body", method: http.MethodPost, target: "/orders/1", id: "1", contentType: "application/json",
+			body: `{"lines":[{"quantity":-2}],"note":5}`,
+			want: []openapi.Violation{
+				{In: "body", Name: "lines[0].sku", Message: "is required"},
+				{In: "body", Name: "lines[0].quantity", Message: "must be at least 0"},
+				{In: "body", Name: "note", Message: "must be a string"},
+			},
+		},
+		{
+			name: "invalid path", method: http.MethodPost, target: "/orders/x", id: "x", contentType: "application/json",
+			body: `{"lines":[]}`,
+			want: []openapi.Violation{{In: "path", Name: "id", Message: "must match ^[0-9]+$"}},
+		},
+		{
+			name: "missing body", method: http.MethodPost, target: "/orders/1", id: "1",
+			want: []openapi.Violation{{In: "body", Message: "is required"}},
+		},
+		{
+			name: "not JSON", method: http.MethodPost, target: "/orders/1", id: "1", contentType: "text/plain",
+			body: "lines",
+			want: []openapi.Violation{{In: "header", Name: "Content-Type", Message: "must be application/json"}},
+		},
+		{
+			name: "malformed JSON", method: http.MethodPost, target: "/orders/1", id: "1",
+			contentType: "application/json; charset=utf-8", body: "{",
+			want: []openapi.Violation{{In: "body", Message: "is not valid JSON"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			ginPath := "/orders"
+			if tt.method == http.MethodPost {
+				ginPath = "/orders/:id"
+			}
+			op, ok := v.Operation(tt.method, ginPath)
+			require.True(t, ok)
+
+			req := httptest.NewRequest(tt.method, tt.target, strings.NewReader(tt.body))
+			if tt.contentType != "" {
+				req.Header.Set("Content-Type", tt.contentType)
+			}
+			if tt.header != "" {
+				req.Header.Set("X-Shop", tt.header)
+			}
+			got := v.Request(op, req, func(string) string { return tt.id }, []byte(tt.body))
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestValidatorResponse(t *testing.T) {
+	t.Parallel()
+	v := newTestValidator(t)
+	op, ok := v.Operation(http.MethodPost, "/orders/:id")
+	require.True(t, ok)
+
+	valid := `{"id":"1","total":"10.50","created":"2025-03-14T09:26:53Z","details":null}`
+	assert.Equal(t, []openapi.Violation{{In: "body", Name: "details", Message: "is required"}},
+		v.Response(op, http.StatusCreated, []byte(valid)), "required properties must not be null")
+
+	valid = `{"id":"1","total":"10.50","created":"2025-03-14T09:26:53Z","details":{"a":1},"tags":{"x":1}}`
+	assert.Empty(t, v.Response(op, http.StatusCreated, []byte(valid)))
+	assert.Empty(t, v.Response(op, http.StatusNoContent, nil))
+	assert.Empty(t, v.Response(op, http.StatusBadRequest, []byte(`{"message":"bad"}`)), "errors use the default")
+
+	invalid := `{"id":1,"total":"ten","created":"yesterday","details":{},"tags":{"x":"y"}}`
+	assert.Equal(t, []openapi.Violation{
+		{In: "body", Name: "created", Message: "must be an RFC 3339 date-time"},
+		{In: "body", Name: "id", Message: "must be a string"},
+		{In: "body", Name: "tags.x", Message: "must be an integer"},
+		{In: "body", Name: "total", Message: `must match ^-?[0-9]+(\.[0-9]+)?$`},
+	}, v.Response(op, http.StatusCreated, []byte(invalid)))
+	assert.Len(t, v.Response(op, http.StatusNoContent, []byte(`{}`)), 1)
+}
+
+func TestLoad(t *testing.T) {
+	t.Parallel()
+	b := openapi.New(openapi.Info{Title: "Test", Version: "1"}, apiError{})
+	b.Add(http.MethodGet, "/orders/:id", openapi.Route{
+		ID:        "getOrder",
+		Params:    []openapi.Parameter{{Name: "id", In: "path", Required: true, Schema: openapi.Int(1, 10)}},
+		Responses: map[int]any{http.StatusOK: order{}},
+	})
+	want, err := b.Document()
+	require.NoError(t, err)
+	j, err := json.Marshal(want)
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "spec.json")
+	require.NoError(t, os.WriteFile(path, j, 0o600))
+	got, err := openapi.Load(path)
+	require.NoError(t, err)
+	assert.Equal(t, want, got)
+
+	_, err = openapi.Load(filepath.Join(dir, "missing.yaml"))
+	require.Error(t, err)
+	empty := filepath.Join(dir, "empty.yaml")
+	require.NoError(t, os.WriteFile(empty, []byte("openapi: 3.1.0\n"), 0o600))
+	_, err = openapi.Load(empty)
+	require.ErrorIs(t, err, openapi.ErrInvalidDocument)
+}
@@ -0,0 +1,193 @@
+// Package pricing computes the price breakdown of an order: subtotal, coupon discounts, tax and total.
+package pricing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/rameshsunkara/go-rest-api-example/internal/db"
+	"github.com/rameshsunkara/go-rest-api-example/internal/models/data"
+	"github.com/rameshsunkara/go-rest-api-example/pkg/logger"
+	"github.com/rameshsunkara/go-rest-api-example/pkg/money"
+)
+
+// ErrInvalidCoupon is returned when a coupon is unknown, expired, used up or does not apply to the order.
+var ErrInvalidCoupon = errors.New("coupon cannot be applied to this order")
+
+// Request describes the order to price. Product prices must fit the currency.
+type Request struct {
+	Products   []data.Product
+	Currency   money.Currency
+	Region     string
+	CouponCode string
+}
+
+// Quote is the result of pricing an order.
+type Quote struct {
+	Products []data.Product // the requested products with their line discounts set
+	Pricing  data.Pricing
+}
+
+// Engine prices orders and redeems the coupons applied to them.
+type Engine struct {
+	coupons db.CouponsDataService
+	tax     TaxCalculator
+	logger  logger.Logger
+	now     func() time.Time
+}
+
+// NewEngine creates a new pricing Engine.
+func NewEngine(lgr logger.Logger, coupons db.CouponsDataService, tax TaxCalculator) (*Engine, error) {
+	if lgr == nil || coupons == nil || tax == nil {
+		return nil, errors.New("missing required inputs to create pricing engine")
+	}
+	return &Engine{coupons: coupons, tax: tax, logger: lgr, now: time.Now}, nil
+}
+
+// Price computes the breakdown of an order. Discounts are applied before tax:
+//
+//	subtotal = Σ price × quantity
+//	discount = line discounts of a product coupon, or the order discount of an order coupon
+//	tax      = TaxCalculator(subtotal - discount)
+//	total    = subtotal - discount + tax
+//
+// Percentage discounts are rounded to the currency per line or per order, fixed discounts never
+// exceed the amount they apply to. Price does not redeem the coupon, see Redeem.
+func (e *Engine) Price(ctx context.Context, req Request) (*Quote, error) {
+	products := append([]data.Product(nil), req.Products...)
+	for i := range products {
+		products[i].Discount = 0
+	}
+	subtotal, err := Subtotal(products, req.Currency)
+	if err != nil {
+		return nil, err
+	}
+
+	var discount money.Amount
+	couponCode := ""
+	if req.CouponCode != "" {
+		coupon, couponErr := e.coupon(ctx, req.CouponCode, req.Currency)
+		if couponErr != nil {
+			return nil, couponErr
+		}
+		if discount, err = applyCoupon(coupon, products, subtotal, req.Currency); err != nil {
+			return nil, err
+		}
+		couponCode = coupon.Code
+	}
+
+	taxable := subtotal - discount
+	tax, err := e.tax.Tax(ctx, req.Region, taxable, req.Currency)
+	if err != nil {
+		return nil, fmt.Errorf("failed to calculate tax: %w", err)
+	}
+
+	return &Quote{
+		Products: products,
+		Pricing: data.Pricing{
+			Subtotal:   subtotal,
+			Discount:   discount,
+			Tax:        tax,
+			Total:      taxable + tax,
+			CouponCode: couponCode,
+			TaxRegion:  req.Region,
+		},
+	}, nil
+}
+
+// Redeem counts one use of the coupon, it fails with ErrInvalidCoupon when the coupon expired or
+// reached its usage cap since the order was priced.
+func (e *Engine) Redeem(ctx context.Context, code string) error {
+	err := e.coupons.Redeem(ctx, code, e.now())
+	if errors.Is(err, db.ErrCouponNotRedeemable) {
+		return fmt.Errorf("%w: %w", ErrInvalidCoupon, err)
+	}
+	return err
+}
+
+// Release gives back a use counted by Redeem.
+func (e *Engine) Release(ctx context.Context, code string) error {
+	return e.coupons.Release(ctx, code)
+}
+
+// Subtotal sums price × quantity of the products, rounded to the currency. It fails with money.ErrOverflow
+// when a line or the sum is out of range.
+func Subtotal(products []data.Product, currency money.Currency) (money.Amount, error) {
+	var total money.Amount
+	for _, product := range products {
+		line, err := product.Price.Mul(product.Quantity)
+		if err != nil {
+			return 0, fmt.Errorf("%w: %s × %d of %s", err, product.Price, product.Quantity, product.SKU)
+		}
+		if total, err = total.Add(line); err != nil {
+			return 0, fmt.Errorf("%w: order subtotal", err)
+		}
+	}
+	return total.Round(currency), nil
+}
+
+// coupon loads the coupon and checks it can be used for an order in the given currency.
+func (e *Engine) coupon(ctx context.Context, code string, currency money.Currency) (*data.Coupon, error) {
+	coupon, err := e.coupons.GetByCode(ctx, code)
+	if err != nil {
+		if errors.Is(err, db.ErrCouponNotFound) {
+			return nil, fmt.Errorf("%w: unknown code %q", ErrInvalidCoupon, code)
+		}
+		return nil, err
+	}
+	switch {
+	case !coupon.IsActive(e.now()):
+		return nil, fmt.Errorf("%w: %s is not valid at this time", ErrInvalidCoupon, coupon.Code)
+	case coupon.IsExhausted():
+		return nil, fmt.Errorf("%w: %s has reached its usage cap", ErrInvalidCoupon, coupon.Code)
+	case coupon.Kind == data.DiscountFixed && coupon.Currency != currency:
+		return nil, fmt.Errorf("%w: %s only applies to %s orders", ErrInvalidCoupon, coupon.Code, coupon.Currency)
+	}
+	return coupon, nil
+}
+
+// applyCoupon sets the line discounts of a product coupon on the products and returns the total discount.
+func applyCoupon(
+	coupon *data.Coupon,
+	products []data.Product,
+	subtotal money.Amount,
+	currency money.Currency,
+) (money.Amount, error) {
+	if len(coupon.Products) == 0 {
+		return discountOf(coupon, subtotal, currency), nil
+	}
+
+	eligible := make(map[string]bool, len(coupon.Products))
+	for _, product := range coupon.Products {
+		eligible[strings.ToLower(product)] = true
+	}
+	var total money.Amount
+	matched := false
+	for i := range products {
+		if !eligible[strings.ToLower(products[i].SKU)] && !eligible[strings.ToLower(products[i].Name)] {
+			continue
+		}
+		matched = true
+		line, err := products[i].Price.Mul(products[i].Quantity)
+		if err != nil {
+			return 0, err
+		}
+		products[i].Discount = discountOf(coupon, line, currency)
+		total += products[i].Discount
+	}
+	if !matched {
+		return 0, fmt.Errorf("%w: %s does not apply to any product of the order", ErrInvalidCoupon, coupon.Code)
+	}
+	return total, nil
+}
+
+// discountOf returns the discount of the coupon on an amount.
+func discountOf(coupon *data.Coupon, amount money.Amount, currency money.Currency) money.Amount {
+	if coupon.Kind == data.DiscountPercent {
+		return amount.Percent(coupon.Value).Round(currency)
+	}
+	return money.Min(coupon.Value, amount)
+}
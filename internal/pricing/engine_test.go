@@ -0,0 +1,259 @@
+package pricing_test
+
+import (
+	"context"
+	"errors"
+	"math"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/rameshsunkara/go-rest-api-example/internal/db"
+	"github.com/rameshsunkara/go-rest-api-example/internal/db/mocks"
+	"github.com/rameshsunkara/go-rest-api-example/internal/models/data"
+	"github.com/rameshsunkara/go-rest-api-example/internal/pricing"
+	"github.com/rameshsunkara/go-rest-api-example/pkg/logger"
+	"github.com/rameshsunkara/go-rest-api-example/pkg/money"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testLgr = logger.New("debug", os.Stdout)
+
+type failingTax struct{}
+
+func (failingTax) Tax(context.Context, string, money.Amount, money.Currency) (money.Amount, error) {
+	return 0, errors.New("tax service unavailable")
+}
+
+func couponStore(coupons ...data.Coupon) *mocks.MockCouponsDataService {
+	return &mocks.MockCouponsDataService{
+		GetByCodeFunc: func(_ context.Context, code string) (*data.Coupon, error) {
+			for i := range coupons {
+				if coupons[i].Code == data.NormalizeCouponCode(code) {
+					return &coupons[i], nil
+				}
+			}
+			return nil, db.ErrCouponNotFound
+		},
+		RedeemFunc: func(_ context.Context, code string, _ time.Time) error {
+			if code == "USEDUP" {
+				return db.ErrCouponNotRedeemable
+			}
+			return nil
+		},
+		ReleaseFunc: func(_ context.Context, _ string) error { return nil },
+	}
+}
+
+func TestNewEngine(t *testing.T) {
+	t.Parallel()
+	tax, err := pricing.NewFlatRateTax(nil, 0)
+	require.NoError(t, err)
+	_, err = pricing.NewEngine(nil, couponStore(), tax)
+	require.Error(t, err)
+	_, err = pricing.NewEngine(testLgr, nil, tax)
+	require.Error(t, err)
+	_, err = pricing.NewEngine(testLgr, couponStore(), nil)
+	require.Error(t, err)
+	e, err := pricing.NewEngine(testLgr, couponStore(), tax)
+	require.NoError(t, err)
+	assert.NotNil(t, e)
+}
+
+func TestSubtotal(t *testing.T) {
+	t.Parallel()
+	subtotal := func(products []data.Product) money.Amount {
+		total, err := pricing.Subtotal(products, money.USD)
+		require.NoError(t, err)
+		return total
+	}
+	assert.Zero(t, subtotal(nil))
+	assert.Equal(t, "85", subtotal([]data.Product{
+		{Name: "Product 1", Price: money.MustParse("10"), Quantity: 2},
+		{Name: "Product 2", Price: money.MustParse("20"), Quantity: 1},
+		{Name: "Product 3", Price: money.MustParse("15"), Quantity: 3},
+		{Name: "Product 4", Price: money.MustParse("15"), Quantity: 0},
+	}).String())
+	// amounts that drift as floats are exact
+	assert.Equal(t, "0.3", subtotal([]data.Product{
+		{Name: "Product 1", Price: money.MustParse("0.1"), Quantity: 3},
+	}).String())
+
+	// huge quantities fail instead of wrapping around
+	_, err := pricing.Subtotal([]data.Product{
+		{SKU: "P-1", Price: money.MustParse("10"), Quantity: math.MaxUint64 / 2},
+	}, money.USD)
+	require.ErrorIs(t, err, money.ErrOverflow)
+	_, err = pricing.Subtotal([]data.Product{
+		{SKU: "P-1", Price: money.MustParse("900000000000000"), Quantity: 1},
+		{SKU: "P-2", Price: money.MustParse("900000000000000"), Quantity: 1},
+	}, money.USD)
+	require.ErrorIs(t, err, money.ErrOverflow)
+}
+
+func TestPrice(t *testing.T) {
+	t.Parallel()
+	now := time.Now()
+	active := func(c data.Coupon) data.Coupon {
+		c.ValidFrom, c.ValidUntil = now.Add(-time.Hour), now.Add(time.Hour)
+		return c
+	}
+	coupons := couponStore(
+		active(data.Coupon{Code: "PCT15", Kind: data.DiscountPercent, Value: money.MustParse("15")}),
+		active(data.Coupon{Code: "FIVE", Kind: data.DiscountFixed, Value: money.MustParse("5"), Currency: money.USD}),
+		active(data.Coupon{Code: "HUGE", Kind: data.DiscountFixed, Value: money.MustParse("500"), Currency: money.USD}),
+		active(data.Coupon{
+			Code: "WIDGETS", Kind: data.DiscountPercent, Value: money.MustParse("50"), Products: []string{"widget"},
+		}),
+		active(data.Coupon{
+			Code: "BOLTS", Kind: data.DiscountFixed, Value: money.MustParse("1"), Currency: money.USD,
+			Products: []string{"bolt-10"},
+		}),
+		active(data.Coupon{
+			Code: "GADGETS", Kind: data.DiscountPercent, Value: money.MustParse("50"), Products: []string{"gadget"},
+		}),
+		active(data.Coupon{Code: "CAPPED", Kind: data.DiscountPercent, Value: money.MustParse("5"), MaxUses: 2, Uses: 2}),
+		data.Coupon{
+			Code: "LATER", Kind: data.DiscountPercent, Value: money.MustParse("5"),
+			ValidFrom: now.Add(time.Hour), ValidUntil: now.Add(2 * time.Hour),
+		},
+	)
+	tax, err := pricing.NewFlatRateTax(map[string]money.Amount{"US-CA": money.MustParse("7.25")}, 0)
+	require.NoError(t, err)
+	engine, err := pricing.NewEngine(testLgr, coupons, tax)
+	require.NoError(t, err)
+
+	products := []data.Product{
+		{Name: "Widget", Price: money.MustParse("19.99"), Quantity: 3, Discount: money.MustParse("1")},
+		{SKU: "BOLT-10", Name: "Bolt", Price: money.MustParse("0.35"), Quantity: 10},
+	}
+
+	tests := []struct {
+		name          string
+		coupon        string
+		currency      money.Currency
+		wantPricing   data.Pricing
+		wantDiscounts []string
+		wantErr       error
+	}{
+		{
+			name: "no coupon",
+			wantPricing: data.Pricing{
+				Subtotal: money.MustParse("63.47"), Tax: money.MustParse("4.6"), Total: money.MustParse("68.07"),
+			},
+			wantDiscounts: []string{"0", "0"},
+		},
+		{
+			name:   "order percent coupon",
+			coupon: "pct15",
+			wantPricing: data.Pricing{
+				Subtotal: money.MustParse("63.47"),
+				Discount: money.MustParse("9.52"), // 9.5205
+				Tax:      money.MustParse("3.91"), // 7.25% of 53.95
+				Total:    money.MustParse("57.86"),
+			},
+			wantDiscounts: []string{"0", "0"},
+		},
+		{
+			name:   "order fixed coupon",
+			coupon: "FIVE",
+			wantPricing: data.Pricing{
+				Subtotal: money.MustParse("63.47"),
+				Discount: money.MustParse("5"),
+				Tax:      money.MustParse("4.24"),
+				Total:    money.MustParse("62.71"),
+			},
+			wantDiscounts: []string{"0", "0"},
+		},
+		{
+			name:   "fixed coupon never exceeds the subtotal",
+			coupon: "HUGE",
+			wantPricing: data.Pricing{
+				Subtotal: money.MustParse("63.47"),
+				Discount: money.MustParse("63.47"),
+			},
+			wantDiscounts: []string{"0", "0"},
+		},
+		{
+			name:   "line coupon",
+			coupon: "WIDGETS",
+			wantPricing: data.Pricing{
+				Subtotal: money.MustParse("63.47"),
+				Discount: money.MustParse("29.99"), // 29.985
+				Tax:      money.MustParse("2.43"),
+				Total:    money.MustParse("35.91"),
+			},
+			wantDiscounts: []string{"29.99", "0"},
+		},
+		{
+			name:   "line coupon matching a sku",
+			coupon: "BOLTS",
+			wantPricing: data.Pricing{
+				Subtotal: money.MustParse("63.47"),
+				Discount: money.MustParse("1"),
+				Tax:      money.MustParse("4.53"), // 7.25% of 62.47
+				Total:    money.MustParse("67"),
+			},
+			wantDiscounts: []string{"0", "1"},
+		},
+		{name: "line coupon without matching product", coupon: "GADGETS", wantErr: pricing.ErrInvalidCoupon},
+		{name: "unknown coupon", coupon: "NOPE", wantErr: pricing.ErrInvalidCoupon},
+		{name: "used up coupon", coupon: "CAPPED", wantErr: pricing.ErrInvalidCoupon},
+		{name: "coupon not yet valid", coupon: "LATER", wantErr: pricing.ErrInvalidCoupon},
+		{name: "fixed coupon in another currency", coupon: "FIVE", currency: money.EUR, wantErr: pricing.ErrInvalidCoupon},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			currency := tt.currency
+			if currency == "" {
+				currency = money.USD
+			}
+			quote, priceErr := engine.Price(context.Background(), pricing.Request{
+				Products:   products,
+				Currency:   currency,
+				Region:     "US-CA",
+				CouponCode: tt.coupon,
+			})
+			if tt.wantErr != nil {
+				require.ErrorIs(t, priceErr, tt.wantErr)
+				return
+			}
+			require.NoError(t, priceErr)
+			want := tt.wantPricing
+			want.TaxRegion = "US-CA"
+			if tt.coupon != "" {
+				want.CouponCode = data.NormalizeCouponCode(tt.coupon)
+			}
+			assert.Equal(t, want, quote.Pricing)
+			require.Len(t, quote.Products, len(tt.wantDiscounts))
+			for i, d := range tt.wantDiscounts {
+				assert.Equal(t, d, quote.Products[i].Discount.String())
+			}
+		})
+	}
+	// the request products are not modified
+	assert.Equal(t, money.MustParse("1"), products[0].Discount)
+}
+
+func TestPriceTaxFailure(t *testing.T) {
+	t.Parallel()
+	engine, err := pricing.NewEngine(testLgr, couponStore(), failingTax{})
+	require.NoError(t, err)
+	_, err = engine.Price(context.Background(), pricing.Request{Currency: money.USD})
+	require.Error(t, err)
+	assert.NotErrorIs(t, err, pricing.ErrInvalidCoupon)
+}
+
+func TestRedeem(t *testing.T) {
+	t.Parallel()
+	tax, err := pricing.NewFlatRateTax(nil, 0)
+	require.NoError(t, err)
+	engine, err := pricing.NewEngine(testLgr, couponStore(), tax)
+	require.NoError(t, err)
+
+	require.NoError(t, engine.Redeem(context.Background(), "PCT15"))
+	require.ErrorIs(t, engine.Redeem(context.Background(), "USEDUP"), pricing.ErrInvalidCoupon)
+	require.NoError(t, engine.Release(context.Background(), "PCT15"))
+}
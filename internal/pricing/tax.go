@@ -0,0 +1,67 @@
+package pricing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/rameshsunkara/go-rest-api-example/pkg/money"
+)
+
+// ErrInvalidTaxRate is returned for tax rates outside of [0, 100] percent.
+var ErrInvalidTaxRate = errors.New("tax rate must be between 0 and 100 percent")
+
+// TaxCalculator computes the tax owed on the taxable amount of an order shipped to a region.
+// The returned tax must be rounded to the currency.
+type TaxCalculator interface {
+	Tax(ctx context.Context, region string, taxable money.Amount, currency money.Currency) (money.Amount, error)
+}
+
+// FlatRateTax is the built-in TaxCalculator, it charges a fixed percentage per region and
+// falls back to a default rate for regions without one. Regions are matched case-insensitively.
+type FlatRateTax struct {
+	rates       map[string]money.Amount
+	defaultRate money.Amount
+}
+
+// NewFlatRateTax creates a FlatRateTax from percentages keyed by region, e.g. {"US-CA": 7.25}.
+func NewFlatRateTax(rates map[string]money.Amount, defaultRate money.Amount) (*FlatRateTax, error) {
+	if !validRate(defaultRate) {
+		return nil, fmt.Errorf("%w: default rate %s", ErrInvalidTaxRate, defaultRate)
+	}
+	normalized := make(map[string]money.Amount, len(rates))
+	for region, rate := range rates {
+		if !validRate(rate) {
+			return nil, fmt.Errorf("%w: %s rate %s", ErrInvalidTaxRate, region, rate)
+		}
+		normalized[normalizeRegion(region)] = rate
+	}
+	return &FlatRateTax{rates: normalized, defaultRate: defaultRate}, nil
+}
+
+// Rate returns the tax percentage applied to the region.
+func (f *FlatRateTax) Rate(region string) money.Amount {
+	if rate, ok := f.rates[normalizeRegion(region)]; ok {
+		return rate
+	}
+	return f.defaultRate
+}
+
+// Tax returns the region rate of the taxable amount, rounded half away from zero to the currency.
+func (f *FlatRateTax) Tax(
+	_ context.Context,
+	region string,
+	taxable money.Amount,
+	currency money.Currency,
+) (money.Amount, error) {
+	return taxable.Percent(f.Rate(region)).Round(currency), nil
+}
+
+func validRate(rate money.Amount) bool {
+	return rate >= 0 && rate <= money.MustParse("100")
+}
+
+func normalizeRegion(region string) string {
+	return strings.ToUpper(strings.TrimSpace(region))
+}
@@ -0,0 +1,51 @@
+package pricing_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/rameshsunkara/go-rest-api-example/internal/pricing"
+	"github.com/rameshsunkara/go-rest-api-example/pkg/money"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewFlatRateTax(t *testing.T) {
+	t.Parallel()
+	_, err := pricing.NewFlatRateTax(map[string]money.Amount{"DE": money.MustParse("101")}, 0)
+	require.ErrorIs(t, err, pricing.ErrInvalidTaxRate)
+	_, err = pricing.NewFlatRateTax(nil, money.MustParse("-1"))
+	require.ErrorIs(t, err, pricing.ErrInvalidTaxRate)
+	tax, err := pricing.NewFlatRateTax(nil, 0)
+	require.NoError(t, err)
+	assert.Equal(t, money.Amount(0), tax.Rate("DE"))
+}
+
+func TestFlatRateTax(t *testing.T) {
+	t.Parallel()
+	tax, err := pricing.NewFlatRateTax(map[string]money.Amount{
+		"us-ca": money.MustParse("7.25"),
+		"DE":    money.MustParse("19"),
+	}, money.MustParse("5"))
+	require.NoError(t, err)
+
+	tests := []struct {
+		region   string
+		taxable  string
+		currency money.Currency
+		want     string
+	}{
+		{region: "US-CA", taxable: "100", currency: money.USD, want: "7.25"},
+		{region: " de ", taxable: "10.5", currency: money.EUR, want: "2"}, // 1.995 rounds half away from zero
+		{region: "FR", taxable: "19.99", currency: money.EUR, want: "1"},  // default rate, 0.9995
+		{region: "", taxable: "999", currency: money.JPY, want: "50"},     // 49.95
+	}
+	for _, tt := range tests {
+		t.Run(tt.region+tt.taxable, func(t *testing.T) {
+			t.Parallel()
+			got, taxErr := tax.Tax(context.Background(), tt.region, money.MustParse(tt.taxable), tt.currency)
+			require.NoError(t, taxErr)
+			assert.Equal(t, tt.want, got.String())
+		})
+	}
+}
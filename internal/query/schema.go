@@ -0,0 +1,315 @@
+// Package query parses the query parameters of a route into the struct the route declares.
+//
+// Every field is a parameter named by its form tag, "limit,default=10" gives it a default. Values are checked
+// with the binding tag like request bodies are, e.g. binding:"min=1,max=100" or binding:"oneof=json csv".
+// Slice fields accept repeated parameters and embedded structs add their parameters to the schema.
+package query
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"net/url"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+// ContextKey is the gin context key the parsed query of a request is stored under.
+const ContextKey = "query"
+
+var (
+	ErrInvalidSchema = errors.New("invalid query schema")
+	// ErrNotParsed is returned by handlers reached without the query they expect being parsed.
+	ErrNotParsed = errors.New("query was not parsed for the route")
+)
+
+// Param describes a query parameter.
+type Param struct {
+	Name     string
+	Type     reflect.Type // string, bool, an integer, time.Time or a slice of them
+	Default  string
+	Required bool
+	Min, Max *int64   // value bounds for integers, length bounds for strings
+	Enum     []string // allowed values
+	index    []int
+	path     string // field names from the schema struct, as validator namespaces them
+}
+
+// Repeated reports whether the parameter may be given more than once.
+func (p Param) Repeated() bool {
+	return p.Type.Kind() == reflect.Slice
+}
+
+// Violation is a query parameter that does not match its schema.
+type Violation struct {
+	Name    string
+	Message string
+}
+
+// Schema parses queries into a struct type.
+type Schema struct {
+	typ    reflect.Type
+	params []Param
+}
+
+var timeType = reflect.TypeFor[time.Time]()
+
+// NewSchema returns the schema of v, a struct whose fields are all parameters.
+func NewSchema(v any) (*Schema, error) {
+	t := reflect.TypeOf(v)
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("%w: %T is not a struct", ErrInvalidSchema, v)
+	}
+	s := &Schema{typ: t}
+	if err := s.addFields(t, nil, ""); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// MustSchema is like NewSchema and panics on error, it is meant for schemas declared with the routes.
+func MustSchema(v any) *Schema {
+	s, err := NewSchema(v)
+	if err != nil {
+		panic(err)
+	}
+	return s
+}
+
+// Params returns the parameters of the schema in field order.
+func (s *Schema) Params() []Param {
+	return slices.Clone(s.params)
+}
+
+func (s *Schema) addFields(t reflect.Type, index []int, path string) error {
+	for i := range t.NumField() {
+		f := t.Field(i)
+		fIndex := append(slices.Clone(index), i)
+		fPath := path + f.Name
+		tag, tagged := f.Tag.Lookup("form")
+		if tag == "-" {
+			continue
+		}
+		if !tagged {
+			if f.Anonymous && f.Type.Kind() == reflect.Struct {
+				if err := s.addFields(f.Type, fIndex, fPath+"."); err != nil {
+					return err
+				}
+				continue
+			}
+			return fmt.Errorf("%w: field %s of %s has no form tag", ErrInvalidSchema, f.Name, s.typ)
+		}
+		if !f.IsExported() || !supported(f.Type) {
+			return fmt.Errorf("%w: field %s of %s cannot be a parameter", ErrInvalidSchema, f.Name, s.typ)
+		}
+		p := Param{Type: f.Type, index: fIndex, path: fPath}
+		name, opts, _ := strings.Cut(tag, ",")
+		p.Name = name
+		if def, ok := strings.CutPrefix(opts, "default="); ok {
+			p.Default = def
+		}
+		if err := p.addRules(f.Tag.Get("binding")); err != nil {
+			return fmt.Errorf("%w: field %s of %s: %w", ErrInvalidSchema, f.Name, s.typ, err)
+		}
+		if p.Name == "" || slices.ContainsFunc(s.params, func(o Param) bool { return o.Name == p.Name }) {
+			return fmt.Errorf("%w: field %s of %s has a missing or duplicate name", ErrInvalidSchema, f.Name, s.typ)
+		}
+		if p.Default != "" {
+			if _, err := parseValue(p.Type, p.Default); err != nil {
+				return fmt.Errorf("%w: default of %s: %w", ErrInvalidSchema, p.Name, err)
+			}
+		}
+		s.params = append(s.params, p)
+	}
+	return nil
+}
+
+// addRules reads the binding rules that describe the parameter, the validator checks all of them.
+func (p *Param) addRules(rules string) error {
+	for _, rule := range strings.Split(rules, ",") {
+		name, arg, _ := strings.Cut(rule, "=")
+		switch name {
+		case "required":
+			p.Required = true
+		case "min", "gte", "max", "lte":
+			n, err := strconv.ParseInt(arg, 10, 64)
+			if err != nil {
+				return fmt.Errorf("bound %q: %w", rule, err)
+			}
+			if name == "min" || name == "gte" {
+				p.Min = &n
+			} else {
+				p.Max = &n
+			}
+		case "oneof":
+			p.Enum = strings.Fields(arg)
+		}
+	}
+	return nil
+}
+
+func supported(t reflect.Type) bool {
+	if t.Kind() == reflect.Slice {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.String, reflect.Bool, reflect.Int, reflect.Int32, reflect.Int64:
+		return true
+	default:
+		return t == timeType
+	}
+}
+
+// Parse returns a pointer to a new value of the schema's struct holding values, or the violations.
+// Unknown parameters are violations, empty values count as missing.
+func (s *Schema) Parse(values url.Values) (any, []Violation) {
+	var violations []Violation
+	for _, name := range slices.Sorted(maps.Keys(values)) {
+		if !slices.ContainsFunc(s.params, func(p Param) bool { return p.Name == name }) {
+			violations = append(violations, Violation{Name: name, Message: "is not supported"})
+		}
+	}
+
+	ptr := reflect.New(s.typ)
+	for _, p := range s.params {
+		given := slices.DeleteFunc(slices.Clone(values[p.Name]), func(v string) bool { return v == "" })
+		if len(given) == 0 && p.Default != "" {
+			given = []string{p.Default}
+		}
+		if len(given) == 0 {
+			continue
+		}
+		if !p.Repeated() && len(given) > 1 {
+			violations = append(violations, Violation{Name: p.Name, Message: "must not be repeated"})
+			continue
+		}
+		field := ptr.Elem().FieldByIndex(p.index)
+		for _, v := range given {
+			parsed, err := parseValue(p.Type, v)
+			if err != nil {
+				violations = append(violations, Violation{Name: p.Name, Message: err.Error()})
+				break
+			}
+			if p.Repeated() {
+				field.Set(reflect.Append(field, parsed))
+			} else {
+				field.Set(parsed)
+			}
+		}
+	}
+	if len(violations) > 0 {
+		return nil, violations
+	}
+
+	if err := binding.Validator.ValidateStruct(ptr.Interface()); err != nil {
+		var fieldErrs validator.ValidationErrors
+		if !errors.As(err, &fieldErrs) {
+			return nil, []Violation{{Message: err.Error()}}
+		}
+		for _, fe := range fieldErrs {
+			violations = append(violations, s.violation(fe))
+		}
+		return nil, violations
+	}
+	return ptr.Interface(), nil
+}
+
+func (s *Schema) violation(fe validator.FieldError) Violation {
+	path, _ := strings.CutPrefix(fe.StructNamespace(), s.typ.Name()+".")
+	path, _, _ = strings.Cut(path, "[") // elements of repeated params
+	v := Violation{Name: path}
+	if i := slices.IndexFunc(s.params, func(p Param) bool { return p.path == path }); i >= 0 {
+		v.Name = s.params[i].Name
+	}
+	numeric := fe.Kind() != reflect.String && fe.Kind() != reflect.Slice
+	switch {
+	case fe.Tag() == "required":
+		v.Message = "is required"
+	case fe.Tag() == "oneof":
+		v.Message = "must be one of " + strings.Join(strings.Fields(fe.Param()), ", ")
+	case (fe.Tag() == "min" || fe.Tag() == "gte") && numeric:
+		v.Message = "must be at least " + fe.Param()
+	case (fe.Tag() == "max" || fe.Tag() == "lte") && numeric:
+		v.Message = "must be at most " + fe.Param()
+	case fe.Tag() == "min" || fe.Tag() == "gte":
+		v.Message = "must be at least " + fe.Param() + " long"
+	case fe.Tag() == "max" || fe.Tag() == "lte":
+		v.Message = "must be at most " + fe.Param() + " long"
+	default:
+		v.Message = "does not satisfy " + fe.Tag()
+	}
+	return v
+}
+
+// parseValue parses v as a value of t, or of its elements when t is a slice.
+func parseValue(t reflect.Type, v string) (reflect.Value, error) {
+	if t.Kind() == reflect.Slice {
+		t = t.Elem()
+	}
+	if t == timeType {
+		ts, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return reflect.Value{}, errors.New("must be an RFC3339 timestamp")
+		}
+		return reflect.ValueOf(ts), nil
+	}
+	val := reflect.New(t).Elem()
+	switch t.Kind() {
+	case reflect.Bool:
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return reflect.Value{}, errors.New("must be a boolean")
+		}
+		val.SetBool(b)
+	case reflect.Int, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(v, 10, t.Bits())
+		if err != nil {
+			return reflect.Value{}, errors.New("must be an integer")
+		}
+		val.SetInt(n)
+	default:
+		val.SetString(v)
+	}
+	return val, nil
+}
+
+// From returns the query parsed for the request as a T, T is the struct the route declared or one it embeds.
+func From[T any](c *gin.Context) (T, bool) {
+	var zero T
+	v, ok := c.Get(ContextKey)
+	if !ok {
+		return zero, false
+	}
+	if q, isT := v.(*T); isT {
+		return *q, true
+	}
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.Elem().Kind() != reflect.Struct {
+		return zero, false
+	}
+	return embedded[T](rv.Elem())
+}
+
+func embedded[T any](v reflect.Value) (T, bool) {
+	for i := range v.NumField() {
+		f := v.Type().Field(i)
+		if !f.Anonymous || !f.IsExported() || f.Type.Kind() != reflect.Struct {
+			continue
+		}
+		if q, ok := v.Field(i).Interface().(T); ok {
+			return q, true
+		}
+		if q, ok := embedded[T](v.Field(i)); ok {
+			return q, true
+		}
+	}
+	var zero T
+	return zero, false
+}
@@ -0,0 +1,157 @@
+package query_test
+
+import (
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rameshsunkara/go-rest-api-example/internal/query"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type PageQuery struct {
+	Limit int64 `form:"limit,default=10" binding:"min=1,max=50"`
+}
+
+type searchQuery struct {
+	PageQuery
+	Status []string  `form:"status" binding:"dive,oneof=open closed"`
+	Since  time.Time `form:"since"`
+	Sort   string    `form:"sort,default=asc" binding:"oneof=asc desc"`
+	User   string    `form:"user" binding:"required,max=5"`
+	Exact  bool      `form:"exact"`
+}
+
+func TestMain(m *testing.M) {
+	gin.SetMode(gin.TestMode)
+	os.Exit(m.Run())
+}
+
+func TestNewSchema(t *testing.T) {
+	t.Parallel()
+	s, err := query.NewSchema(searchQuery{})
+	require.NoError(t, err)
+	params := s.Params()
+	require.Len(t, params, 6)
+	assert.Equal(t, "limit", params[0].Name)
+	assert.Equal(t, "10", params[0].Default)
+	assert.Equal(t, int64(1), *params[0].Min)
+	assert.Equal(t, int64(50), *params[0].Max)
+	assert.True(t, params[1].Repeated())
+	assert.Equal(t, []string{"asc", "desc"}, params[3].Enum)
+	assert.True(t, params[4].Required)
+
+	invalid := []any{
+		"limit",
+		struct{ Limit int }{},
+		struct {
+			Limit float64 `form:"limit"`
+		}{},
+		struct {
+			Limit int `form:"limit,default=ten"`
+		}{},
+		struct {
+			A string `form:"a"`
+			B string `form:"a"`
+		}{},
+	}
+	for _, v := range invalid {
+		_, err = query.NewSchema(v)
+		require.ErrorIs(t, err, query.ErrInvalidSchema, "%#v", v)
+	}
+}
+
+func TestSchema_Parse(t *testing.T) {
+	t.Parallel()
+	s := query.MustSchema(searchQuery{})
+	tests := []struct {
+		name           string
+		query          string
+		want           *searchQuery
+		wantViolations []query.Violation
+	}{
+		{
+			name:  "defaults",
+			query: "user=jane",
+			want:  &searchQuery{PageQuery: PageQuery{Limit: 10}, Sort: "asc", User: "jane"},
+		},
+		{
+			name:  "every param",
+			query: "user=jane&limit=5&status=open&status=closed&since=2024-03-01T10:00:00Z&sort=desc&exact=true",
+			want: &searchQuery{
+				PageQuery: PageQuery{Limit: 5}, Status: []string{"open", "closed"},
+				Since: time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC), Sort: "desc", User: "jane", Exact: true,
+			},
+		},
+		{
+			name:  "empty values are missing",
+			query: "user=jane&limit=&sort=",
+			want:  &searchQuery{PageQuery: PageQuery{Limit: 10}, Sort: "asc", User: "jane"},
+		},
+		{
+			name:  "unparsable values",
+			query: "user=jane&limit=ten&since=yesterday&exact=maybe&other=1",
+			wantViolations: []query.Violation{
+				{Name: "other", Message: "is not supported"},
+				{Name: "limit", Message: "must be an integer"},
+				{Name: "since", Message: "must be an RFC3339 timestamp"},
+				{Name: "exact", Message: "must be a boolean"},
+			},
+		},
+		{
+			name:           "repeated",
+			query:          "user=jane&limit=1&limit=2",
+			wantViolations: []query.Violation{{Name: "limit", Message: "must not be repeated"}},
+		},
+		{
+			name:  "rules",
+			query: "limit=51&status=pending&sort=up",
+			wantViolations: []query.Violation{
+				{Name: "limit", Message: "must be at most 50"},
+				{Name: "status", Message: "must be one of open, closed"},
+				{Name: "sort", Message: "must be one of asc, desc"},
+				{Name: "user", Message: "is required"},
+			},
+		},
+		{
+			name:           "string bounds",
+			query:          "user=jane.doe",
+			wantViolations: []query.Violation{{Name: "user", Message: "must be at most 5 long"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			values, err := url.ParseQuery(tt.query)
+			require.NoError(t, err)
+			got, violations := s.Parse(values)
+			assert.Equal(t, tt.wantViolations, violations)
+			if tt.want == nil {
+				assert.Nil(t, got)
+				return
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestFrom(t *testing.T) {
+	t.Parallel()
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	_, ok := query.From[PageQuery](c)
+	assert.False(t, ok)
+
+	c.Set(query.ContextKey, &searchQuery{PageQuery: PageQuery{Limit: 5}, User: "jane"})
+	search, ok := query.From[searchQuery](c)
+	require.True(t, ok)
+	assert.Equal(t, "jane", search.User)
+	page, ok := query.From[PageQuery](c)
+	require.True(t, ok)
+	assert.Equal(t, int64(5), page.Limit)
+	_, ok = query.From[struct{ Other int }](c)
+	assert.False(t, ok)
+}
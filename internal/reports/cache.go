@@ -0,0 +1,107 @@
+// Package reports caches the reporting aggregations, which scan every order in their date range.
+package reports
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/rameshsunkara/go-rest-api-example/internal/db"
+	"github.com/rameshsunkara/go-rest-api-example/internal/models/data"
+	"github.com/rameshsunkara/go-rest-api-example/pkg/logger"
+)
+
+// maxEntries bounds the memory used by the cache, expired entries are evicted first when it is full.
+const maxEntries = 1000
+
+// Cache decorates a ReportsDataService and keeps each report for the ttl.
+// Reports are a few rows each, so results are cached in memory per instance. Errors are not cached.
+type Cache struct {
+	reports db.ReportsDataService
+	logger  logger.Logger
+	ttl     time.Duration
+
+	mu      sync.Mutex
+	entries map[string]entry
+}
+
+type entry struct {
+	value   interface{}
+	expires time.Time
+}
+
+// NewCache creates a new report Cache.
+func NewCache(lgr logger.Logger, reports db.ReportsDataService, ttl time.Duration) (*Cache, error) {
+	if lgr == nil || reports == nil {
+		return nil, errors.New("missing required inputs to create report cache")
+	}
+	if ttl <= 0 {
+		return nil, errors.New("report cache ttl must be positive")
+	}
+	return &Cache{reports: reports, logger: lgr, ttl: ttl, entries: map[string]entry{}}, nil
+}
+
+// Revenue returns the cached revenue report or builds it.
+func (c *Cache) Revenue(ctx context.Context, q db.ReportQuery) ([]data.RevenueBucket, error) {
+	return cached(c, key("revenue", q), func() ([]data.RevenueBucket, error) { return c.reports.Revenue(ctx, q) })
+}
+
+// OrdersByStatus returns the cached orders by status report or builds it.
+func (c *Cache) OrdersByStatus(ctx context.Context, q db.ReportQuery) ([]data.StatusCount, error) {
+	return cached(c, key("status", q), func() ([]data.StatusCount, error) { return c.reports.OrdersByStatus(ctx, q) })
+}
+
+// BasketSize returns the cached basket size report or builds it.
+func (c *Cache) BasketSize(ctx context.Context, q db.ReportQuery) ([]data.BasketStats, error) {
+	return cached(c, key("basket", q), func() ([]data.BasketStats, error) { return c.reports.BasketSize(ctx, q) })
+}
+
+// TopProducts returns the cached top products report or builds it.
+func (c *Cache) TopProducts(ctx context.Context, q db.ReportQuery, limit int64) ([]data.ProductSales, error) {
+	return cached(c, key(fmt.Sprintf("top-%d", limit), q), func() ([]data.ProductSales, error) {
+		return c.reports.TopProducts(ctx, q, limit)
+	})
+}
+
+// cached returns the live entry stored under k, or loads, stores and returns a new one.
+// Concurrent misses on the same key may both load, the last one wins.
+func cached[T any](c *Cache, k string, load func() (T, error)) (T, error) {
+	now := time.Now()
+	c.mu.Lock()
+	e, ok := c.entries[k]
+	c.mu.Unlock()
+	if v, isT := e.value.(T); ok && isT && now.Before(e.expires) {
+		return v, nil
+	}
+
+	v, err := load()
+	if err != nil {
+		return v, err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.entries) >= maxEntries {
+		c.evict(now)
+	}
+	c.entries[k] = entry{value: v, expires: now.Add(c.ttl)}
+	return v, nil
+}
+
+// evict drops the expired entries, or every entry when none has expired. c.mu must be held.
+func (c *Cache) evict(now time.Time) {
+	for k, e := range c.entries {
+		if !now.Before(e.expires) {
+			delete(c.entries, k)
+		}
+	}
+	if len(c.entries) >= maxEntries {
+		c.logger.Info().Int("entries", len(c.entries)).Msg("report cache is full, clearing it")
+		clear(c.entries)
+	}
+}
+
+func key(report string, q db.ReportQuery) string {
+	return fmt.Sprintf("%s|%d|%d|%s|%s", report, q.From.UnixNano(), q.To.UnixNano(), q.Location, q.Interval)
+}
@@ -0,0 +1,111 @@
+package reports_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/rameshsunkara/go-rest-api-example/internal/db"
+	"github.com/rameshsunkara/go-rest-api-example/internal/db/mocks"
+	"github.com/rameshsunkara/go-rest-api-example/internal/models/data"
+	"github.com/rameshsunkara/go-rest-api-example/internal/reports"
+	"github.com/rameshsunkara/go-rest-api-example/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testLgr = logger.New("debug", os.Stdout)
+
+func query(days int) db.ReportQuery {
+	from := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	return db.ReportQuery{From: from, To: from.AddDate(0, 0, days), Location: time.UTC, Interval: data.IntervalDay}
+}
+
+func TestNewCache(t *testing.T) {
+	t.Parallel()
+	svc := &mocks.MockReportsDataService{}
+	_, err := reports.NewCache(nil, svc, time.Minute)
+	require.Error(t, err)
+	_, err = reports.NewCache(testLgr, nil, time.Minute)
+	require.Error(t, err)
+	_, err = reports.NewCache(testLgr, svc, 0)
+	require.Error(t, err)
+	c, err := reports.NewCache(testLgr, svc, time.Minute)
+	require.NoError(t, err)
+	assert.NotNil(t, c)
+}
+
+func TestCacheHitsUntilExpiry(t *testing.T) {
+	t.Parallel()
+	calls := 0
+	c, err := reports.NewCache(testLgr, &mocks.MockReportsDataService{
+		RevenueFunc: func(_ context.Context, q db.ReportQuery) ([]data.RevenueBucket, error) {
+			calls++
+			return []data.RevenueBucket{{Start: q.From, Orders: int64(calls)}}, nil
+		},
+	}, 50*time.Millisecond)
+	require.NoError(t, err)
+
+	first, err := c.Revenue(context.Background(), query(7))
+	require.NoError(t, err)
+	second, err := c.Revenue(context.Background(), query(7))
+	require.NoError(t, err)
+	assert.Equal(t, first, second)
+	assert.Equal(t, 1, calls)
+
+	_, err = c.Revenue(context.Background(), query(14))
+	require.NoError(t, err)
+	assert.Equal(t, 2, calls, "a different range is a different report")
+
+	time.Sleep(60 * time.Millisecond)
+	third, err := c.Revenue(context.Background(), query(7))
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), third[0].Orders)
+}
+
+func TestCacheSkipsErrors(t *testing.T) {
+	t.Parallel()
+	calls := 0
+	c, err := reports.NewCache(testLgr, &mocks.MockReportsDataService{
+		TopProductsFunc: func(_ context.Context, _ db.ReportQuery, limit int64) ([]data.ProductSales, error) {
+			calls++
+			if calls == 1 {
+				return nil, errors.New("db down")
+			}
+			return []data.ProductSales{{SKU: "P-1", Quantity: limit}}, nil
+		},
+	}, time.Minute)
+	require.NoError(t, err)
+
+	_, err = c.TopProducts(context.Background(), query(7), 5)
+	require.Error(t, err)
+	sales, err := c.TopProducts(context.Background(), query(7), 5)
+	require.NoError(t, err)
+	assert.Equal(t, int64(5), sales[0].Quantity)
+	sales, err = c.TopProducts(context.Background(), query(7), 3)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), sales[0].Quantity, "the limit is part of the cache key")
+	assert.Equal(t, 3, calls)
+}
+
+func TestCacheKeepsReportsApart(t *testing.T) {
+	t.Parallel()
+	c, err := reports.NewCache(testLgr, &mocks.MockReportsDataService{
+		OrdersByStatusFunc: func(context.Context, db.ReportQuery) ([]data.StatusCount, error) {
+			return []data.StatusCount{{Status: data.OrderPending, Orders: 2}}, nil
+		},
+		BasketSizeFunc: func(context.Context, db.ReportQuery) ([]data.BasketStats, error) {
+			return []data.BasketStats{{Orders: 2}}, nil
+		},
+	}, time.Minute)
+	require.NoError(t, err)
+
+	counts, err := c.OrdersByStatus(context.Background(), query(7))
+	require.NoError(t, err)
+	stats, err := c.BasketSize(context.Background(), query(7))
+	require.NoError(t, err)
+	assert.Equal(t, data.OrderPending, counts[0].Status)
+	assert.Equal(t, int64(2), stats[0].Orders)
+}
@@ -0,0 +1,42 @@
+// Package requestctx holds the keys of the request scoped values the middlewares store in the request context,
+// so the packages reading them do not depend on the middlewares.
+package requestctx
+
+import (
+	"context"
+
+	"github.com/rameshsunkara/go-rest-api-example/pkg/logger"
+)
+
+// ContextKey is a type for context keys, it is the logger's so request loggers find the request ID.
+type ContextKey = logger.ContextKey
+
+const (
+	// RequestIdentifier is the header name for request ID, and its request context key (as ContextKey).
+	RequestIdentifier = "X-Request-ID"
+	// PrincipalKey is the request context key (as ContextKey) holding the authenticated caller's identity.
+	PrincipalKey = "principal"
+	// ClaimsKey is the request context key (as ContextKey) holding the verified token claims as map[string]any.
+	ClaimsKey = "claims"
+)
+
+// RequestID returns the request ID stored in ctx, or "".
+func RequestID(ctx context.Context) string {
+	if rID, ok := ctx.Value(ContextKey(RequestIdentifier)).(string); ok {
+		return rID
+	}
+	return ""
+}
+
+// WithPrincipal returns a copy of ctx carrying the caller p, e.g. the fixed actor of a command line tool.
+func WithPrincipal(ctx context.Context, p string) context.Context {
+	return context.WithValue(ctx, ContextKey(PrincipalKey), p)
+}
+
+// Principal returns the authenticated caller stored in ctx, or "".
+func Principal(ctx context.Context) string {
+	if p, ok := ctx.Value(ContextKey(PrincipalKey)).(string); ok {
+		return p
+	}
+	return ""
+}
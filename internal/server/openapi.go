@@ -0,0 +1,42 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rameshsunkara/go-rest-api-example/internal/handlers"
+	"github.com/rameshsunkara/go-rest-api-example/internal/openapi"
+)
+
+// OpenAPIPath serves the OpenAPI document generated from the registered public routes.
+const OpenAPIPath = "/openapi.json"
+
+var openAPIInfo = openapi.Info{
+	Title:       "Ecommerce API",
+	Description: "API for managing the catalog, orders and reports of an e-commerce system",
+	Version:     "2.0.0",
+}
+
+// pathParamSchemas describes the path parameters of the public routes by name.
+var pathParamSchemas = map[string]*openapi.Schema{
+	handlers.OrderIDPath: openapi.String("^[0-9a-fA-F]{24}$"),
+	handlers.UserPath:    openapi.MaxLengthString(handlers.MaxUserLength),
+	"sku":                openapi.String(""),
+}
+
+// serveOpenAPI serves the document described by spec at OpenAPIPath.
+func serveOpenAPI(router *gin.Engine, spec *openapi.Builder) error {
+	doc, err := spec.Document()
+	if err != nil {
+		return err
+	}
+	body, err := json.Marshal(doc)
+	if err != nil {
+		return err
+	}
+	router.GET(OpenAPIPath, func(c *gin.Context) {
+		c.Data(http.StatusOK, "application/json; charset=utf-8", body)
+	})
+	return nil
+}
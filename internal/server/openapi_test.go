@@ -0,0 +1,145 @@
+package server_test
+
+import (
+	"encoding/json"
+	"flag"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/rameshsunkara/go-rest-api-example/internal/config"
+	"github.com/rameshsunkara/go-rest-api-example/internal/db/mocks"
+	"github.com/rameshsunkara/go-rest-api-example/internal/errors"
+	"github.com/rameshsunkara/go-rest-api-example/internal/metrics"
+	"github.com/rameshsunkara/go-rest-api-example/internal/openapi"
+	"github.com/rameshsunkara/go-rest-api-example/internal/server"
+	"github.com/rameshsunkara/go-rest-api-example/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+const specFile = "../../OpenApi-v1.yaml"
+
+// update rewrites the committed spec, run `go test ./internal/server -run TestOpenAPISpec -update` after an
+// intended API change.
+var update = flag.Bool("update", false, "update the committed OpenAPI spec")
+
+func TestOpenAPISpec(t *testing.T) {
+	svcInfo := &config.ServiceEnvConfig{Environment: "test", Port: "8080"}
+	router, err := server.WebRouter(svcInfo, logger.New("info", os.Stdout), &mocks.MockMongoMgr{}, metrics.New())
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodGet, server.OpenAPIPath, nil)
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	require.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, "application/json; charset=utf-8", resp.Header().Get("Content-Type"))
+
+	if *update {
+		writeSpec(t, resp.Body.Bytes())
+	}
+
+	committed, err := os.ReadFile(specFile)
+	require.NoError(t, err)
+	var spec map[string]any
+	require.NoError(t, yaml.Unmarshal(committed, &spec))
+	// compare both through JSON so numbers and maps share their types
+	want, err := json.Marshal(spec)
+	require.NoError(t, err)
+	assert.JSONEq(t, string(want), resp.Body.String(),
+		"OpenApi-v1.yaml is out of date, regenerate it with -update")
+}
+
+func TestOpenAPISpecDocumentsEveryRoute(t *testing.T) {
+	svcInfo := &config.ServiceEnvConfig{Environment: "test", Port: "8080"}
+	router, err := server.WebRouter(svcInfo, logger.New("info", os.Stdout), &mocks.MockMongoMgr{}, metrics.New())
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodGet, server.OpenAPIPath, nil)
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	var doc struct {
+		Paths map[string]map[string]json.RawMessage `json:"paths"`
+	}
+	require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &doc))
+
+	for _, route := range router.Routes() {
+		if !strings.HasPrefix(route.Path, "/ecommerce/") {
+			continue
+		}
+		ops, ok := doc.Paths[openapi.Path(route.Path)]
+		if assert.True(t, ok, "%s is not documented", route.Path) {
+			assert.Contains(t, ops, strings.ToLower(route.Method), "%s %s is not documented", route.Method, route.Path)
+		}
+	}
+}
+
+// writeSpec writes the JSON document as block style YAML, keeping the order of its keys.
+func writeSpec(t *testing.T, doc []byte) {
+	var node yaml.Node
+	require.NoError(t, yaml.Unmarshal(doc, &node))
+	var blockStyle func(n *yaml.Node)
+	blockStyle = func(n *yaml.Node) {
+		n.Style = 0
+		for _, child := range n.Content {
+			blockStyle(child)
+		}
+	}
+	blockStyle(&node)
+
+	f, err := os.Create(specFile)
+	require.NoError(t, err)
+	defer f.Close()
+	enc := yaml.NewEncoder(f)
+	enc.SetIndent(2)
+	require.NoError(t, enc.Encode(&node))
+	require.NoError(t, enc.Close())
+}
+
+func TestWebRouterValidatesRequests(t *testing.T) {
+	svcInfo := &config.ServiceEnvConfig{Environment: "test", Port: "8080", OpenAPISpec: specFile}
+	router, err := server.WebRouter(svcInfo, logger.New("info", os.Stdout), &mocks.MockMongoMgr{}, metrics.New())
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		method   string
+		path     string
+		body     string
+		wantName string
+	}{
+		{name: "limit out of range", method: http.MethodGet, path: "/ecommerce/v1/orders?limit=500", wantName: "limit"},
+		{name: "limit not a number", method: http.MethodGet, path: "/ecommerce/v2/orders?limit=ten", wantName: "limit"},
+		{name: "invalid order ID", method: http.MethodGet, path: "/ecommerce/v1/orders/42", wantName: "id"},
+		{name: "missing body", method: http.MethodPost, path: "/ecommerce/v1/orders"},
+		{
+			name: "invalid body", method: http.MethodPost, path: "/ecommerce/v2/orders",
+			body: `{"products":[{"sku":"P-1","quantity":-1}]}`, wantName: "products[0].quantity",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			resp := httptest.NewRecorder()
+			router.ServeHTTP(resp, req)
+
+			require.Equal(t, http.StatusBadRequest, resp.Code)
+			var apiErr struct {
+				ErrorCode string              `json:"errorCode"`
+				Details   []openapi.Violation `json:"details"`
+			}
+			require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &apiErr))
+			assert.Equal(t, errors.RequestInvalid, apiErr.ErrorCode)
+			require.NotEmpty(t, apiErr.Details)
+			assert.Equal(t, tt.wantName, apiErr.Details[0].Name)
+		})
+	}
+
+	svcInfo.OpenAPISpec = "missing.yaml"
+	_, err = server.WebRouter(svcInfo, logger.New("info", os.Stdout), &mocks.MockMongoMgr{}, metrics.New())
+	require.Error(t, err)
+}
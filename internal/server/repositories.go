@@ -0,0 +1,128 @@
+package server
+
+import (
+	"github.com/rameshsunkara/go-rest-api-example/internal/audit"
+	"github.com/rameshsunkara/go-rest-api-example/internal/config"
+	"github.com/rameshsunkara/go-rest-api-example/internal/db"
+	"github.com/rameshsunkara/go-rest-api-example/internal/metrics"
+	"github.com/rameshsunkara/go-rest-api-example/internal/reports"
+	"github.com/rameshsunkara/go-rest-api-example/pkg/logger"
+	"github.com/rameshsunkara/go-rest-api-example/pkg/mongodb"
+)
+
+// repositories route every call to the database of the request tenant, or to the default database
+// when the service runs single-tenant.
+type repositories struct {
+	orders        db.OrdersDataService
+	auditedOrders db.OrdersDataService // every mutation made through it is recorded in the audit log and counted
+	audit         db.AuditDataService
+	coupons       db.CouponsDataService
+	products      db.ProductsDataService
+	inventory     db.InventoryDataService
+	reports       db.ReportsDataService // cached per tenant unless ReportsCacheTTL is zero
+}
+
+func newRepositories(
+	svcEnv *config.ServiceEnvConfig,
+	lgr logger.Logger,
+	dbMgr mongodb.MongoManager,
+	requireTenant bool,
+	m *metrics.Metrics,
+) (*repositories, error) {
+	repos := &repositories{}
+
+	ordersRepos, err := db.NewRepoFactory(lgr, dbMgr, requireTenant,
+		func(d mongodb.MongoDatabase) (db.OrdersDataService, error) {
+			return db.NewOrdersRepo(lgr, d)
+		})
+	if err != nil {
+		return nil, err
+	}
+	if repos.orders, err = db.NewTenantOrdersRepo(ordersRepos); err != nil {
+		return nil, err
+	}
+
+	auditedOrdersRepos, err := db.NewRepoFactory(lgr, dbMgr, requireTenant,
+		func(d mongodb.MongoDatabase) (db.OrdersDataService, error) {
+			ordersRepo, repoErr := db.NewOrdersRepo(lgr, d)
+			if repoErr != nil {
+				return nil, repoErr
+			}
+			auditRepo, repoErr := db.NewAuditRepo(lgr, d)
+			if repoErr != nil {
+				return nil, repoErr
+			}
+			auditedOrders, svcErr := audit.NewOrdersService(lgr, ordersRepo, auditRepo)
+			if svcErr != nil {
+				return nil, svcErr
+			}
+			return metrics.NewOrdersService(m, auditedOrders)
+		})
+	if err != nil {
+		return nil, err
+	}
+	if repos.auditedOrders, err = db.NewTenantOrdersRepo(auditedOrdersRepos); err != nil {
+		return nil, err
+	}
+
+	auditRepos, err := db.NewRepoFactory(lgr, dbMgr, requireTenant,
+		func(d mongodb.MongoDatabase) (db.AuditDataService, error) {
+			return db.NewAuditRepo(lgr, d)
+		})
+	if err != nil {
+		return nil, err
+	}
+	if repos.audit, err = db.NewTenantAuditRepo(auditRepos); err != nil {
+		return nil, err
+	}
+
+	couponsRepos, err := db.NewRepoFactory(lgr, dbMgr, requireTenant,
+		func(d mongodb.MongoDatabase) (db.CouponsDataService, error) {
+			return db.NewCouponsRepo(lgr, d)
+		})
+	if err != nil {
+		return nil, err
+	}
+	if repos.coupons, err = db.NewTenantCouponsRepo(couponsRepos); err != nil {
+		return nil, err
+	}
+
+	productsRepos, err := db.NewRepoFactory(lgr, dbMgr, requireTenant,
+		func(d mongodb.MongoDatabase) (db.ProductsDataService, error) {
+			return db.NewProductsRepo(lgr, d)
+		})
+	if err != nil {
+		return nil, err
+	}
+	if repos.products, err = db.NewTenantProductsRepo(productsRepos); err != nil {
+		return nil, err
+	}
+
+	inventoryRepos, err := db.NewRepoFactory(lgr, dbMgr, requireTenant,
+		func(d mongodb.MongoDatabase) (db.InventoryDataService, error) {
+			return db.NewInventoryRepo(lgr, d)
+		})
+	if err != nil {
+		return nil, err
+	}
+	if repos.inventory, err = db.NewTenantInventoryRepo(inventoryRepos); err != nil {
+		return nil, err
+	}
+
+	// each tenant gets its own cache so cached reports never leak across tenants
+	reportsRepos, err := db.NewRepoFactory(lgr, dbMgr, requireTenant,
+		func(d mongodb.MongoDatabase) (db.ReportsDataService, error) {
+			reportsRepo, repoErr := db.NewReportsRepo(lgr, d)
+			if repoErr != nil || svcEnv.ReportsCacheTTL <= 0 {
+				return reportsRepo, repoErr
+			}
+			return reports.NewCache(lgr, reportsRepo, svcEnv.ReportsCacheTTL)
+		})
+	if err != nil {
+		return nil, err
+	}
+	if repos.reports, err = db.NewTenantReportsRepo(reportsRepos); err != nil {
+		return nil, err
+	}
+	return repos, nil
+}
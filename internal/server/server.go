@@ -12,14 +12,25 @@ import (
 	"github.com/gin-contrib/pprof"
 	"github.com/gin-gonic/gin"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/rameshsunkara/go-rest-api-example/internal/audit"
+	"github.com/rameshsunkara/go-rest-api-example/internal/catalog"
 	"github.com/rameshsunkara/go-rest-api-example/internal/config"
 	"github.com/rameshsunkara/go-rest-api-example/internal/db"
 	"github.com/rameshsunkara/go-rest-api-example/internal/handlers"
+	"github.com/rameshsunkara/go-rest-api-example/internal/importer"
+	"github.com/rameshsunkara/go-rest-api-example/internal/jobs"
+	"github.com/rameshsunkara/go-rest-api-example/internal/metrics"
 	"github.com/rameshsunkara/go-rest-api-example/internal/middleware"
+	"github.com/rameshsunkara/go-rest-api-example/internal/models/external"
+	"github.com/rameshsunkara/go-rest-api-example/internal/openapi"
+	"github.com/rameshsunkara/go-rest-api-example/internal/pricing"
+	"github.com/rameshsunkara/go-rest-api-example/internal/query"
+	"github.com/rameshsunkara/go-rest-api-example/internal/tenant"
 	"github.com/rameshsunkara/go-rest-api-example/internal/utilities"
 	"github.com/rameshsunkara/go-rest-api-example/pkg/flightrecorder"
 	"github.com/rameshsunkara/go-rest-api-example/pkg/logger"
 	"github.com/rameshsunkara/go-rest-api-example/pkg/mongodb"
+	"go.opentelemetry.io/otel"
 )
 
 const (
@@ -33,12 +44,26 @@ type Server struct {
 
 // Start manages the HTTP server lifecycle with graceful shutdown
 // This function blocks until the server shuts down or an error occurs.
-func Start(ctx context.Context, svcEnv *config.ServiceEnvConfig, lgr logger.Logger, dbMgr mongodb.MongoManager) error {
-	router, err := WebRouter(svcEnv, lgr, dbMgr)
+func Start(
+	ctx context.Context,
+	svcEnv *config.ServiceEnvConfig,
+	lgr logger.Logger,
+	dbMgr mongodb.MongoManager,
+	m *metrics.Metrics,
+) error {
+	if indexErr := ensureIndexes(ctx, svcEnv, lgr, dbMgr); indexErr != nil {
+		return indexErr
+	}
+
+	router, err := WebRouter(svcEnv, lgr, dbMgr, m)
 	if err != nil {
 		return err
 	}
 
+	if jobsErr := startJobs(ctx, svcEnv, lgr, dbMgr, m); jobsErr != nil {
+		return jobsErr
+	}
+
 	// Log registered routes
 	lgr.Info().Msg("Registered routes")
 	for _, item := range router.Routes() {
@@ -85,7 +110,13 @@ func Start(ctx context.Context, svcEnv *config.ServiceEnvConfig, lgr logger.Logg
 	}
 }
 
-func WebRouter(svcEnv *config.ServiceEnvConfig, lgr logger.Logger, dbMgr mongodb.MongoManager) (*gin.Engine, error) {
+// WebRouter registers the routes and middleware of the service, m holds the metrics served on /metrics.
+func WebRouter(
+	svcEnv *config.ServiceEnvConfig,
+	lgr logger.Logger,
+	dbMgr mongodb.MongoManager,
+	m *metrics.Metrics,
+) (*gin.Engine, error) {
 	ginMode := gin.ReleaseMode
 	if utilities.IsDevMode(svcEnv.Environment) {
 		ginMode = gin.DebugMode
@@ -93,26 +124,39 @@ func WebRouter(svcEnv *config.ServiceEnvConfig, lgr logger.Logger, dbMgr mongodb
 	}
 	gin.SetMode(ginMode)
 	gin.EnableJsonDecoderDisallowUnknownFields()
+	// the handlers, middleware and repositories of the router log through the limits of identical messages
+	lgr = rateLimited(svcEnv, lgr)
 
 	// Middleware
 	gin.DefaultWriter = io.Discard
 	router := gin.New()
+	// let handlers pass *gin.Context down to repositories while keeping request scoped values and cancellation
+	router.ContextWithFallback = true
 	router.Use(gin.Recovery())
 	router.Use(gzip.Gzip(gzip.DefaultCompression))
 	router.Use(middleware.ReqIDMiddleware())
 	router.Use(middleware.ResponseHeadersMiddleware())
+	// ahead of the request log so its lines carry the trace ID, see tracing.Setup for the globals
+	router.Use(middleware.TracingMiddleware(otel.GetTracerProvider(), otel.GetTextMapPropagator()))
+	router.Use(middleware.MetricsMiddleware(m))
 
 	// Initialize flight recorder for slow request tracing (if enabled)
 	var fr *flightrecorder.Recorder
 	if svcEnv.EnableTracing {
 		fr = flightrecorder.NewDefault(lgr)
 	}
-	router.Use(middleware.RequestLogMiddleware(lgr, fr))
+	router.Use(middleware.RequestLogMiddleware(lgr, fr, requestLogSampling(svcEnv)))
 
 	internalAPIGrp := router.Group("/internal")
 	internalAPIGrp.Use(middleware.InternalAuthMiddleware()) // use special auth middleware to handle internal employees
 	pprof.RouteRegister(internalAPIGrp, "pprof")
-	router.GET("/metrics", gin.WrapH(promhttp.Handler()))
+	logLevelHandler, logLevelHandlerErr := handlers.NewLogLevelHandler(lgr)
+	if logLevelHandlerErr != nil {
+		return nil, logLevelHandlerErr
+	}
+	internalAPIGrp.GET("/loglevel", logLevelHandler.Get)
+	internalAPIGrp.PUT("/loglevel", logLevelHandler.Set)
+	router.GET("/metrics", gin.WrapH(promhttp.HandlerFor(m.Registry, promhttp.HandlerOpts{})))
 
 	status, sHandlerErr := handlers.NewStatusHandler(lgr, dbMgr)
 	if sHandlerErr != nil {
@@ -120,33 +164,257 @@ func WebRouter(svcEnv *config.ServiceEnvConfig, lgr logger.Logger, dbMgr mongodb
 	}
 	router.GET("/healthz", status.CheckStatus)
 
-	d := dbMgr.Database()
-	ordersRepo, ordersRepoErr := db.NewOrdersRepo(lgr, d)
-	if ordersRepoErr != nil {
-		return nil, ordersRepoErr
+	registry, registryErr := tenant.NewRegistry(svcEnv.Tenants, svcEnv.TenantHosts)
+	if registryErr != nil {
+		return nil, registryErr
+	}
+	// pprof and loglevel stay reachable without a tenant, every route registered below is tenant scoped
+	tenantMiddleware := middleware.TenantMiddleware(lgr, registry, m)
+	internalAPIGrp.Use(tenantMiddleware)
+
+	repos, reposErr := newRepositories(svcEnv, lgr, dbMgr, registry.Enabled(), m)
+	if reposErr != nil {
+		return nil, reposErr
 	}
 
 	// This is a dev mode only endpoint (route) to seed the local db
 	if utilities.IsDevMode(svcEnv.Environment) {
-		if seed, seedHandlerErr := handlers.NewDataSeedHandler(lgr, ordersRepo); seedHandlerErr != nil {
+		if seed, seedHandlerErr := handlers.NewDataSeedHandler(lgr, repos.orders); seedHandlerErr != nil {
 			lgr.Error().Err(seedHandlerErr).Msg("seed-local-db endpoint will not be available")
 		} else {
 			internalAPIGrp.POST("/seed-local-db", seed.SeedDB)
 		}
 	}
 
+	orderImporter, importerErr := importer.New(lgr, repos.auditedOrders, importer.DefaultBatchSize)
+	if importerErr != nil {
+		return nil, importerErr
+	}
+	importHandler, importHandlerErr := handlers.NewImportHandler(lgr, orderImporter)
+	if importHandlerErr != nil {
+		return nil, importHandlerErr
+	}
+	internalOrdersGrp := internalAPIGrp.Group("/orders")
+	internalOrdersGrp.POST("/import",
+		middleware.QueryParamsMiddleware(lgr, query.MustSchema(external.ImportQuery{})), importHandler.Import)
+
+	auditHandler, auditHandlerErr := handlers.NewAuditHandler(lgr, repos.audit)
+	if auditHandlerErr != nil {
+		return nil, auditHandlerErr
+	}
+	internalAPIGrp.GET("/audit",
+		middleware.QueryParamsMiddleware(lgr, query.MustSchema(external.AuditSearchQuery{})), auditHandler.Search)
+
+	couponsHandler, couponsHandlerErr := handlers.NewCouponsHandler(lgr, repos.coupons)
+	if couponsHandlerErr != nil {
+		return nil, couponsHandlerErr
+	}
+	internalCouponsGrp := internalAPIGrp.Group("/coupons")
+	internalCouponsGrp.POST("", couponsHandler.Create)
+	internalCouponsGrp.GET("/:code", couponsHandler.GetByCode)
+
+	taxCalculator, taxErr := pricing.NewFlatRateTax(svcEnv.TaxRates, svcEnv.DefaultTaxRate)
+	if taxErr != nil {
+		return nil, taxErr
+	}
+	pricer, pricerErr := pricing.NewEngine(lgr, repos.coupons, taxCalculator)
+	if pricerErr != nil {
+		return nil, pricerErr
+	}
+
+	resolver, resolverErr := catalog.NewResolver(lgr, repos.products)
+	if resolverErr != nil {
+		return nil, resolverErr
+	}
+
+	inventoryHandler, inventoryHandlerErr := handlers.NewInventoryHandler(lgr, repos.inventory)
+	if inventoryHandlerErr != nil {
+		return nil, inventoryHandlerErr
+	}
+	internalInventoryGrp := internalAPIGrp.Group("/inventory")
+	internalInventoryGrp.GET("/:sku", inventoryHandler.GetBySKU)
+	internalInventoryGrp.PUT("/:sku", inventoryHandler.SetStock)
+
 	// Routes - Ecommerce
-	externalAPIGrp := router.Group("/ecommerce/v1")
-	externalAPIGrp.Use(middleware.AuthMiddleware())
-	externalAPIGrp.Use(middleware.QueryParamsCheckMiddleware(lgr))
-	ordersGroup := externalAPIGrp.Group("orders")
-	ordersHandler, ordersHandlerErr := handlers.NewOrdersHandler(lgr, ordersRepo)
+	productsHandler, productsHandlerErr := handlers.NewProductsHandler(lgr, repos.products)
+	if productsHandlerErr != nil {
+		return nil, productsHandlerErr
+	}
+	ordersHandler, ordersHandlerErr := handlers.NewOrdersHandler(
+		lgr, repos.auditedOrders, resolver, pricer, repos.inventory)
 	if ordersHandlerErr != nil {
 		return nil, ordersHandlerErr
 	}
-	ordersGroup.GET("", ordersHandler.GetAll)
-	ordersGroup.GET("/:id", ordersHandler.GetByID)
-	ordersGroup.POST("", ordersHandler.Create)
-	ordersGroup.DELETE("/:id", ordersHandler.DeleteByID)
+	ordersV2Handler, ordersV2HandlerErr := handlers.NewOrdersV2Handler(
+		lgr, repos.auditedOrders, resolver, pricer, repos.inventory)
+	if ordersV2HandlerErr != nil {
+		return nil, ordersV2HandlerErr
+	}
+	reportsHandler, reportsHandlerErr := handlers.NewReportsHandler(lgr, repos.reports)
+	if reportsHandlerErr != nil {
+		return nil, reportsHandlerErr
+	}
+	versionHandlers := &apiHandlers{
+		products: productsHandler,
+		orders:   ordersHandler,
+		ordersV2: ordersV2Handler,
+		audit:    auditHandler,
+		reports:  reportsHandler,
+	}
+	// requests are validated against the committed spec, TestOpenAPISpec keeps it in line with the routes
+	var validation gin.HandlerFunc
+	if svcEnv.OpenAPISpec != "" {
+		doc, docErr := openapi.Load(svcEnv.OpenAPISpec)
+		if docErr != nil {
+			return nil, fmt.Errorf("loading OpenAPI spec: %w", docErr)
+		}
+		validation = middleware.OpenAPIValidationMiddleware(lgr, openapi.NewValidator(doc), svcEnv.ValidateResponses)
+	}
+	spec := openapi.New(openAPIInfo, external.APIError{})
+	if versionsErr := registerAPIVersions(router, lgr, svcEnv.APIDeprecations, spec, validation, versionHandlers,
+		middleware.AuthMiddleware(), tenantMiddleware); versionsErr != nil {
+		return nil, versionsErr
+	}
+	if specErr := serveOpenAPI(router, spec); specErr != nil {
+		return nil, specErr
+	}
+
+	// Admin reads, these accept includeDeleted to look up soft-deleted orders
+	internalOrdersGrp.GET("",
+		middleware.QueryParamsMiddleware(lgr, query.MustSchema(external.AdminOrderListQuery{})), ordersHandler.GetAll)
+	internalOrdersGrp.GET("/:id",
+		middleware.QueryParamsMiddleware(lgr, query.MustSchema(external.AdminOrderQuery{})), ordersHandler.GetByID)
+	warnUnmatchedRoutes(svcEnv, lgr, router)
 	return router, nil
 }
+
+// warnUnmatchedRoutes warns about the per route settings naming no registered route template, they never apply.
+func warnUnmatchedRoutes(svcEnv *config.ServiceEnvConfig, lgr logger.Logger, router *gin.Engine) {
+	registered := map[string]bool{}
+	for _, r := range router.Routes() {
+		registered[r.Path] = true
+	}
+	settings := []struct {
+		name   string
+		routes map[string]int
+	}{
+		{name: "logRouteBurst", routes: svcEnv.LogRouteBurst},
+		{name: "requestLogRouteSampleEvery", routes: svcEnv.RequestLogRouteSampleEvery},
+	}
+	for _, s := range settings {
+		for route := range s.routes {
+			if !registered[route] {
+				// the route is part of the message, identical messages are rate limited
+				lgr.Warn().Str("setting", s.name).Str("route", route).
+					Msg(fmt.Sprintf("%s route %s matches no registered route", s.name, route))
+			}
+		}
+	}
+}
+
+// startJobs starts the background workers of every tenant database, they run until ctx is cancelled.
+func startJobs(
+	ctx context.Context,
+	svcEnv *config.ServiceEnvConfig,
+	lgr logger.Logger,
+	dbMgr mongodb.MongoManager,
+	m *metrics.Metrics,
+) error {
+	registry, err := tenant.NewRegistry(svcEnv.Tenants, svcEnv.TenantHosts)
+	if err != nil {
+		return err
+	}
+	if !registry.Enabled() {
+		return startDatabaseJobs(ctx, svcEnv, lgr, dbMgr.Database(), m)
+	}
+	for _, t := range registry.Tenants() {
+		if err = startDatabaseJobs(ctx, svcEnv, lgr, dbMgr.DatabaseByName(t.Database), m); err != nil {
+			return fmt.Errorf("starting jobs of tenant %s: %w", t.ID, err)
+		}
+	}
+	return nil
+}
+
+// ensureIndexes creates the indexes of the database of every tenant, or of the default database when the
+// service runs single-tenant.
+func ensureIndexes(
+	ctx context.Context,
+	svcEnv *config.ServiceEnvConfig,
+	lgr logger.Logger,
+	dbMgr mongodb.MongoManager,
+) error {
+	registry, err := tenant.NewRegistry(svcEnv.Tenants, svcEnv.TenantHosts)
+	if err != nil {
+		return err
+	}
+	if !registry.Enabled() {
+		return db.EnsureIndexes(ctx, dbMgr.Database())
+	}
+	for _, t := range registry.Tenants() {
+		if err = db.EnsureIndexes(ctx, dbMgr.DatabaseByName(t.Database)); err != nil {
+			return fmt.Errorf("creating indexes of tenant %s: %w", t.ID, err)
+		}
+		lgr.Info().Str("tenant", t.ID).Str("database", t.Database).Msg("ensured tenant indexes")
+	}
+	return nil
+}
+
+func startDatabaseJobs(
+	ctx context.Context,
+	svcEnv *config.ServiceEnvConfig,
+	lgr logger.Logger,
+	d mongodb.MongoDatabase,
+	m *metrics.Metrics,
+) error {
+	ordersRepo, err := db.NewOrdersRepo(lgr, d)
+	if err != nil {
+		return err
+	}
+	purger, err := jobs.NewOrderPurger(lgr, ordersRepo, svcEnv.DeletedOrderRetention, svcEnv.PurgeInterval)
+	if err != nil {
+		return err
+	}
+
+	auditRepo, err := db.NewAuditRepo(lgr, d)
+	if err != nil {
+		return err
+	}
+	// cancellations of expired orders are audited like the ones made through the API
+	auditedOrders, err := audit.NewOrdersService(lgr, ordersRepo, auditRepo)
+	if err != nil {
+		return err
+	}
+	ordersSvc, err := metrics.NewOrdersService(m, auditedOrders)
+	if err != nil {
+		return err
+	}
+	inventoryRepo, err := db.NewInventoryRepo(lgr, d)
+	if err != nil {
+		return err
+	}
+	sweeper, err := jobs.NewReservationSweeper(lgr, ordersSvc, inventoryRepo,
+		svcEnv.ReservationTTL, svcEnv.ReservationSweepInterval)
+	if err != nil {
+		return err
+	}
+
+	go purger.Run(ctx)
+	go sweeper.Run(ctx)
+	return nil
+}
+
+// rateLimited returns lgr limiting identical messages as configured by svcEnv, or lgr when nothing is limited.
+func rateLimited(svcEnv *config.ServiceEnvConfig, lgr logger.Logger) logger.Logger {
+	if svcEnv.LogBurst == 0 && len(svcEnv.LogRouteBurst) == 0 {
+		return lgr
+	}
+	routes := make(map[string]logger.RateLimit, len(svcEnv.LogRouteBurst))
+	for route, burst := range svcEnv.LogRouteBurst {
+		routes[route] = logger.RateLimit{Burst: burst, Window: svcEnv.LogBurstWindow}
+	}
+	return logger.NewRateLimited(lgr, logger.RateLimit{Burst: svcEnv.LogBurst, Window: svcEnv.LogBurstWindow}, routes)
+}
+
+func requestLogSampling(svcEnv *config.ServiceEnvConfig) middleware.RequestLogSampling {
+	return middleware.RequestLogSampling{Every: svcEnv.RequestLogSampleEvery, Routes: svcEnv.RequestLogRouteSampleEvery}
+}
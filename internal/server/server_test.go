@@ -2,12 +2,17 @@ package server_test
 
 import (
 	"net/http"
+	"net/http/httptest"
 	"os"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/rameshsunkara/go-rest-api-example/internal/config"
 	"github.com/rameshsunkara/go-rest-api-example/internal/db/mocks"
+	"github.com/rameshsunkara/go-rest-api-example/internal/metrics"
+	"github.com/rameshsunkara/go-rest-api-example/internal/middleware"
 	"github.com/rameshsunkara/go-rest-api-example/internal/server"
 	"github.com/rameshsunkara/go-rest-api-example/pkg/logger"
 	"github.com/stretchr/testify/assert"
@@ -24,7 +29,7 @@ func TestListOfRoutes(t *testing.T) {
 		DBName:               "testDB",
 	}
 	lgr := logger.New("info", os.Stdout)
-	router, err := server.WebRouter(svcInfo, lgr, &mocks.MockMongoMgr{})
+	router, err := server.WebRouter(svcInfo, lgr, &mocks.MockMongoMgr{}, metrics.New())
 	if err != nil {
 		t.Errorf("failed to create WebRouter")
 		return
@@ -63,6 +68,93 @@ func TestListOfRoutes(t *testing.T) {
 		Method: http.MethodDelete,
 		Path:   "/ecommerce/v1/orders/:id",
 	})
+
+	assertRoutePresent(t, list, gin.RouteInfo{
+		Method: http.MethodPost,
+		Path:   "/ecommerce/v1/orders/:id",
+	})
+
+	assertRoutePresent(t, list, gin.RouteInfo{
+		Method: http.MethodPost,
+		Path:   "/internal/orders/import",
+	})
+
+	assertRoutePresent(t, list, gin.RouteInfo{
+		Method: http.MethodGet,
+		Path:   "/internal/orders/:id",
+	})
+
+	assertRoutePresent(t, list, gin.RouteInfo{
+		Method: http.MethodGet,
+		Path:   "/ecommerce/v1/orders/:id/audit",
+	})
+
+	assertRoutePresent(t, list, gin.RouteInfo{
+		Method: http.MethodGet,
+		Path:   "/ecommerce/v1/users/:user/orders",
+	})
+
+	assertRoutePresent(t, list, gin.RouteInfo{
+		Method: http.MethodGet,
+		Path:   "/ecommerce/v1/users/:user/orders/summary",
+	})
+
+	for _, report := range []string{"revenue", "orders-by-status", "basket-size", "top-products"} {
+		assertRoutePresent(t, list, gin.RouteInfo{
+			Method: http.MethodGet,
+			Path:   "/ecommerce/v1/reports/" + report,
+		})
+	}
+
+	assertRoutePresent(t, list, gin.RouteInfo{
+		Method: http.MethodGet,
+		Path:   "/internal/audit",
+	})
+
+	assertRoutePresent(t, list, gin.RouteInfo{
+		Method: http.MethodPost,
+		Path:   "/internal/coupons",
+	})
+
+	assertRoutePresent(t, list, gin.RouteInfo{
+		Method: http.MethodGet,
+		Path:   "/internal/coupons/:code",
+	})
+
+	assertRoutePresent(t, list, gin.RouteInfo{
+		Method: http.MethodGet,
+		Path:   "/internal/inventory/:sku",
+	})
+
+	assertRoutePresent(t, list, gin.RouteInfo{
+		Method: http.MethodPut,
+		Path:   "/internal/inventory/:sku",
+	})
+
+	assertRoutePresent(t, list, gin.RouteInfo{
+		Method: http.MethodGet,
+		Path:   "/ecommerce/v1/products",
+	})
+
+	assertRoutePresent(t, list, gin.RouteInfo{
+		Method: http.MethodPost,
+		Path:   "/ecommerce/v1/products",
+	})
+
+	assertRoutePresent(t, list, gin.RouteInfo{
+		Method: http.MethodGet,
+		Path:   "/ecommerce/v1/products/:sku",
+	})
+
+	assertRoutePresent(t, list, gin.RouteInfo{
+		Method: http.MethodPut,
+		Path:   "/ecommerce/v1/products/:sku",
+	})
+
+	assertRoutePresent(t, list, gin.RouteInfo{
+		Method: http.MethodDelete,
+		Path:   "/ecommerce/v1/products/:sku",
+	})
 }
 
 func TestModeSpecificRoutes(t *testing.T) {
@@ -71,7 +163,7 @@ func TestModeSpecificRoutes(t *testing.T) {
 		Port:        "8080",
 	}
 	lgr := logger.New("info", os.Stdout)
-	router, err := server.WebRouter(svcInfo, lgr, &mocks.MockMongoMgr{})
+	router, err := server.WebRouter(svcInfo, lgr, &mocks.MockMongoMgr{}, metrics.New())
 	if err != nil {
 		t.Errorf("failed to create WebRouter")
 		return
@@ -98,7 +190,7 @@ func TestWebRouterWithTracingEnabled(t *testing.T) {
 		EnableTracing:        true, // Enable tracing
 	}
 	lgr := logger.New("info", os.Stdout)
-	router, err := server.WebRouter(svcInfo, lgr, &mocks.MockMongoMgr{})
+	router, err := server.WebRouter(svcInfo, lgr, &mocks.MockMongoMgr{}, metrics.New())
 
 	require.NoError(t, err)
 	assert.NotNil(t, router)
@@ -108,6 +200,118 @@ func TestWebRouterWithTracingEnabled(t *testing.T) {
 	assert.NotEmpty(t, list)
 }
 
+func TestWebRouterServesMetrics(t *testing.T) {
+	svcInfo := &config.ServiceEnvConfig{Environment: "test", Port: "8080"}
+	m := metrics.New()
+	router, err := server.WebRouter(svcInfo, logger.New("info", os.Stdout), &mocks.MockMongoMgr{}, m)
+	require.NoError(t, err)
+
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/unknown", nil))
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	require.Equal(t, http.StatusOK, resp.Code)
+	assert.Contains(t, resp.Body.String(), `http_requests_total{method="GET",route="/metrics",status="200"} 1`)
+	assert.Contains(t, resp.Body.String(), `http_requests_total{method="GET",route="unmatched",status="404"} 1`)
+	assert.Contains(t, resp.Body.String(), "go_goroutines")
+}
+
+func TestWebRouterWithTenants(t *testing.T) {
+	svcInfo := &config.ServiceEnvConfig{
+		Environment: "test",
+		Port:        "8080",
+		Tenants:     map[string]string{"acme": "ecommerce_acme"},
+		TenantHosts: map[string]string{"shop.acme.com": "acme"},
+	}
+	lgr := logger.New("info", os.Stdout)
+	router, err := server.WebRouter(svcInfo, lgr, &mocks.MockMongoMgr{}, metrics.New())
+	require.NoError(t, err)
+
+	tests := []struct {
+		name       string
+		path       string
+		tenant     string
+		wantStatus int
+	}{
+		{name: "missing tenant", path: "/ecommerce/v1/orders", wantStatus: http.StatusBadRequest},
+		{name: "unknown tenant", path: "/ecommerce/v1/orders", tenant: "globex", wantStatus: http.StatusForbidden},
+		{name: "internal routes are tenant scoped", path: "/internal/audit", wantStatus: http.StatusBadRequest},
+		{name: "pprof needs no tenant", path: "/internal/pprof/", wantStatus: http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			if tt.tenant != "" {
+				req.Header.Set(middleware.TenantHeader, tt.tenant)
+			}
+			resp := httptest.NewRecorder()
+			router.ServeHTTP(resp, req)
+			assert.Equal(t, tt.wantStatus, resp.Code)
+		})
+	}
+
+	svcInfo.TenantHosts = map[string]string{"shop.globex.com": "globex"}
+	_, err = server.WebRouter(svcInfo, lgr, &mocks.MockMongoMgr{}, metrics.New())
+	require.Error(t, err)
+}
+
+func TestWebRouterAPIVersions(t *testing.T) {
+	svcInfo := &config.ServiceEnvConfig{
+		Environment: "test",
+		Port:        "8080",
+		APIDeprecations: map[string]config.APIDeprecation{
+			"v1": {
+				DeprecatedAt: time.Date(2026, time.January, 1, 0, 0, 0, 0, time.UTC),
+				Sunset:       time.Date(2026, time.July, 1, 0, 0, 0, 0, time.UTC),
+			},
+		},
+	}
+	lgr := logger.New("info", os.Stdout)
+	router, err := server.WebRouter(svcInfo, lgr, &mocks.MockMongoMgr{}, metrics.New())
+	require.NoError(t, err)
+
+	// v2 serves every v1 route
+	list := router.Routes()
+	for _, route := range list {
+		if v2Path, isV1 := strings.CutPrefix(route.Path, "/ecommerce/v1/"); isV1 {
+			assertRoutePresent(t, list, gin.RouteInfo{Method: route.Method, Path: "/ecommerce/v2/" + v2Path})
+		}
+	}
+
+	tests := []struct {
+		name            string
+		path            string
+		wantDeprecation string
+		wantSunset      string
+		wantLink        string
+	}{
+		{
+			name:            "deprecated version",
+			path:            "/ecommerce/v1/orders?bogus=1",
+			wantDeprecation: "@1767225600",
+			wantSunset:      "Wed, 01 Jul 2026 00:00:00 GMT",
+			wantLink:        `</ecommerce/v2>; rel="successor-version"`,
+		},
+		{name: "current version", path: "/ecommerce/v2/orders?bogus=1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			resp := httptest.NewRecorder()
+			router.ServeHTTP(resp, req)
+			assert.Equal(t, http.StatusBadRequest, resp.Code)
+			assert.Equal(t, tt.wantDeprecation, resp.Header().Get("Deprecation"))
+			assert.Equal(t, tt.wantSunset, resp.Header().Get("Sunset"))
+			assert.Equal(t, tt.wantLink, resp.Header().Get("Link"))
+		})
+	}
+
+	svcInfo.APIDeprecations = map[string]config.APIDeprecation{"v0": {DeprecatedAt: time.Now()}}
+	_, err = server.WebRouter(svcInfo, lgr, &mocks.MockMongoMgr{}, metrics.New())
+	require.Error(t, err)
+}
+
 func assertRoutePresent(t *testing.T, gotRoutes gin.RoutesInfo, wantRoute gin.RouteInfo) {
 	for _, gotRoute := range gotRoutes {
 		if gotRoute.Path == wantRoute.Path && gotRoute.Method == wantRoute.Method {
@@ -124,3 +328,25 @@ func assertRouteNotPresent(t *testing.T, gotRoutes gin.RoutesInfo, wantRoute gin
 		}
 	}
 }
+
+func TestWebRouterWarnsAboutUnmatchedRoutes(t *testing.T) {
+	svcInfo := &config.ServiceEnvConfig{
+		Environment:                "test",
+		Port:                       "8080",
+		LogRouteBurst:              map[string]int{"/ecommerce/v1/orders": 5, "/api/v1/orders": 5},
+		RequestLogRouteSampleEvery: map[string]int{"/healthz": 100},
+	}
+	rec := logger.NewRecorder()
+	_, err := server.WebRouter(svcInfo, rec, &mocks.MockMongoMgr{}, metrics.New())
+	require.NoError(t, err)
+
+	var warnings []logger.Entry
+	for _, e := range rec.Entries() {
+		if e.Level == "warn" {
+			warnings = append(warnings, e)
+		}
+	}
+	require.Len(t, warnings, 1)
+	assert.Equal(t, "logRouteBurst", warnings[0].Fields["setting"])
+	assert.Equal(t, "/api/v1/orders", warnings[0].Fields["route"])
+}
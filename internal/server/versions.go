@@ -0,0 +1,271 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rameshsunkara/go-rest-api-example/internal/config"
+	"github.com/rameshsunkara/go-rest-api-example/internal/handlers"
+	"github.com/rameshsunkara/go-rest-api-example/internal/middleware"
+	"github.com/rameshsunkara/go-rest-api-example/internal/models/data"
+	"github.com/rameshsunkara/go-rest-api-example/internal/models/external"
+	"github.com/rameshsunkara/go-rest-api-example/internal/openapi"
+	"github.com/rameshsunkara/go-rest-api-example/internal/query"
+	"github.com/rameshsunkara/go-rest-api-example/pkg/logger"
+)
+
+// apiBasePath is the path every public API version is served under, e.g. /ecommerce/v1.
+const apiBasePath = "/ecommerce/"
+
+// apiHandlers holds the handlers shared by the public API versions.
+type apiHandlers struct {
+	products *handlers.ProductsHandler
+	orders   *handlers.OrdersHandler
+	ordersV2 *handlers.OrdersV2Handler
+	audit    *handlers.AuditHandler
+	reports  *handlers.ReportsHandler
+}
+
+// apiVersion registers the routes of one public API version.
+type apiVersion struct {
+	name     string
+	register func(r routes, h *apiHandlers)
+}
+
+// apiVersions lists the public API versions from oldest to newest, each one succeeds the one before it.
+var apiVersions = []apiVersion{
+	{name: "v1", register: registerV1},
+	{name: "v2", register: registerV2},
+}
+
+// createdProduct is the response of a product creation.
+type createdProduct struct {
+	ID  string `json:"id"`
+	SKU string `json:"sku"`
+}
+
+// registerAPIVersions registers every public API version, deprecated versions announce it on every response.
+// Every route is described in spec, validation checks the requests when it is not nil.
+func registerAPIVersions(
+	router *gin.Engine,
+	lgr logger.Logger,
+	deprecations map[string]config.APIDeprecation,
+	spec *openapi.Builder,
+	validation gin.HandlerFunc,
+	h *apiHandlers,
+	versionMiddleware ...gin.HandlerFunc,
+) error {
+	for name := range deprecations {
+		if !slices.ContainsFunc(apiVersions, func(v apiVersion) bool { return v.name == name }) {
+			return fmt.Errorf("unknown API version %q is configured as deprecated", name)
+		}
+	}
+
+	for i, v := range apiVersions {
+		grp := router.Group(apiBasePath + v.name)
+		grp.Use(versionMiddleware...)
+		d, deprecated := deprecations[v.name]
+		if deprecated {
+			var successor string
+			if i+1 < len(apiVersions) {
+				successor = apiBasePath + apiVersions[i+1].name
+			}
+			grp.Use(middleware.DeprecationMiddleware(d.DeprecatedAt, d.Sunset, successor))
+		}
+		if validation != nil {
+			grp.Use(validation)
+		}
+		v.register(routes{grp: grp, lgr: lgr, spec: spec, version: v.name, deprecated: deprecated}, h)
+	}
+	return nil
+}
+
+func registerV1(r routes, h *apiHandlers) {
+	registerProducts(r, h)
+
+	orders := r.group("orders")
+	orders.handle(http.MethodGet, "", h.orders.GetAll, openapi.Route{
+		ID: "listOrders", Summary: "List orders", Query: query.MustSchema(external.OrderListQuery{}),
+		Responses: map[int]any{http.StatusOK: []external.Order{}},
+	})
+	orders.handle(http.MethodGet, "/:id", h.orders.GetByID, openapi.Route{
+		ID: "getOrder", Summary: "Get an order", Query: query.MustSchema(external.FieldsQuery{}),
+		Responses: map[int]any{http.StatusOK: external.Order{}},
+	})
+	orders.handle(http.MethodPost, "", h.orders.Create, openapi.Route{
+		ID: "createOrder", Summary: "Place an order", Body: external.OrderInput{},
+		Responses: map[int]any{http.StatusCreated: external.Order{}},
+	})
+	orders.handle(http.MethodDelete, "/:id", h.orders.DeleteByID, openapi.Route{
+		ID: "deleteOrder", Summary: "Delete an order",
+		Responses: map[int]any{http.StatusNoContent: nil},
+	})
+	// custom methods, e.g. POST /orders/{id}:restore or {id}:transition
+	orders.handle(http.MethodPost, "/:id", h.orders.Action, orderActionRoute(external.Order{}))
+	orders.handle(http.MethodGet, "/:id/audit", h.audit.GetByOrderID, orderAuditRoute)
+
+	users := r.group("users")
+	users.handle(http.MethodGet, "/:user/orders", h.orders.GetByUser, openapi.Route{
+		ID: "listUserOrders", Summary: "List the most recent orders of a user", Query: limitQuery,
+		Responses: map[int]any{http.StatusOK: []external.Order{}},
+	})
+	users.handle(http.MethodGet, "/:user/orders/summary", h.orders.GetUserSummary, userSummaryRoute)
+
+	registerReports(r, h)
+}
+
+// registerV2 registers v2, it renders orders with explicit currencies and pages order listings with cursors.
+func registerV2(r routes, h *apiHandlers) {
+	registerProducts(r, h)
+
+	orders := r.group("orders")
+	orders.handle(http.MethodGet, "", h.ordersV2.GetAll, openapi.Route{
+		ID: "listOrders", Summary: "List orders, a page at a time", Query: query.MustSchema(external.OrderPageQuery{}),
+		// cursors are the base64url form of an order ID
+		Params:    []openapi.Parameter{{Name: "cursor", In: "query", Schema: openapi.String("^[A-Za-z0-9_-]{16}$")}},
+		Responses: map[int]any{http.StatusOK: external.OrderPageV2{}},
+	})
+	orders.handle(http.MethodGet, "/:id", h.ordersV2.GetByID, openapi.Route{
+		ID: "getOrder", Summary: "Get an order",
+		Responses: map[int]any{http.StatusOK: external.OrderV2{}},
+	})
+	orders.handle(http.MethodPost, "", h.ordersV2.Create, openapi.Route{
+		ID: "createOrder", Summary: "Place an order", Body: external.OrderInput{},
+		Responses: map[int]any{http.StatusCreated: external.OrderV2{}},
+	})
+	orders.handle(http.MethodDelete, "/:id", h.ordersV2.DeleteByID, openapi.Route{
+		ID: "deleteOrder", Summary: "Delete an order",
+		Responses: map[int]any{http.StatusNoContent: nil},
+	})
+	orders.handle(http.MethodPost, "/:id", h.ordersV2.Action, orderActionRoute(external.OrderV2{}))
+	orders.handle(http.MethodGet, "/:id/audit", h.audit.GetByOrderID, orderAuditRoute)
+
+	users := r.group("users")
+	users.handle(http.MethodGet, "/:user/orders", h.ordersV2.GetByUser, openapi.Route{
+		ID: "listUserOrders", Summary: "List the most recent orders of a user", Query: limitQuery,
+		Responses: map[int]any{http.StatusOK: external.OrderPageV2{}},
+	})
+	users.handle(http.MethodGet, "/:user/orders/summary", h.ordersV2.GetUserSummary, userSummaryRoute)
+
+	registerReports(r, h)
+}
+
+var (
+	noQuery     = query.MustSchema(struct{}{})
+	limitQuery  = query.MustSchema(external.LimitQuery{})
+	reportQuery = query.MustSchema(external.ReportQuery{})
+
+	orderAuditRoute = openapi.Route{
+		ID: "getOrderAudit", Summary: "List the audit trail of an order", Query: limitQuery,
+		Responses: map[int]any{http.StatusOK: []external.AuditEntry{}},
+	}
+	userSummaryRoute = openapi.Route{
+		ID: "getUserOrderSummary", Summary: "Summarize the order history of a user",
+		Responses: map[int]any{http.StatusOK: external.CustomerSummary{}},
+	}
+)
+
+// orderActionRoute describes the custom methods on an order, transitions respond with the order as order.
+func orderActionRoute(order any) openapi.Route {
+	return openapi.Route{
+		ID:      "orderAction",
+		Summary: "Restore a deleted order ({id}:restore) or change its status ({id}:transition)",
+		Params: []openapi.Parameter{{
+			Name: "id", In: "path", Required: true, Schema: openapi.String(`^[0-9a-fA-F]{24}:(restore|transition)$`),
+		}},
+		Body:         external.TransitionInput{},
+		OptionalBody: true,
+		Responses:    map[int]any{http.StatusOK: order, http.StatusNoContent: nil},
+	}
+}
+
+func registerProducts(r routes, h *apiHandlers) {
+	products := r.group("products")
+	products.handle(http.MethodGet, "", h.products.GetAll, openapi.Route{
+		ID: "listProducts", Summary: "List catalog products", Query: query.MustSchema(external.ProductListQuery{}),
+		Responses: map[int]any{http.StatusOK: []external.CatalogProduct{}},
+	})
+	products.handle(http.MethodGet, "/:sku", h.products.GetBySKU, openapi.Route{
+		ID: "getProduct", Summary: "Get a catalog product",
+		Responses: map[int]any{http.StatusOK: external.CatalogProduct{}},
+	})
+	products.handle(http.MethodPost, "", h.products.Create, openapi.Route{
+		ID: "createProduct", Summary: "Add a product to the catalog", Body: external.CatalogProductInput{},
+		Responses: map[int]any{http.StatusCreated: createdProduct{}},
+	})
+	products.handle(http.MethodPut, "/:sku", h.products.Update, openapi.Route{
+		ID: "updateProduct", Summary: "Update a catalog product", Body: external.CatalogProductInput{},
+		Responses: map[int]any{http.StatusNoContent: nil},
+	})
+	products.handle(http.MethodDelete, "/:sku", h.products.DeleteBySKU, openapi.Route{
+		ID: "deleteProduct", Summary: "Deactivate a catalog product",
+		Responses: map[int]any{http.StatusNoContent: nil},
+	})
+}
+
+func registerReports(r routes, h *apiHandlers) {
+	reports := r.group("reports")
+	reports.handle(http.MethodGet, "/revenue", h.reports.Revenue, openapi.Route{
+		ID: "revenueReport", Summary: "Revenue per time bucket and currency",
+		Query:     query.MustSchema(external.RevenueReportQuery{}),
+		Responses: map[int]any{http.StatusOK: []data.RevenueBucket{}},
+	})
+	reports.handle(http.MethodGet, "/orders-by-status", h.reports.OrdersByStatus, openapi.Route{
+		ID: "ordersByStatusReport", Summary: "Number of orders per status", Query: reportQuery,
+		Responses: map[int]any{http.StatusOK: []data.StatusCount{}},
+	})
+	reports.handle(http.MethodGet, "/basket-size", h.reports.BasketSize, openapi.Route{
+		ID: "basketSizeReport", Summary: "Average order per currency", Query: reportQuery,
+		Responses: map[int]any{http.StatusOK: []data.BasketStats{}},
+	})
+	reports.handle(http.MethodGet, "/top-products", h.reports.TopProducts, openapi.Route{
+		ID: "topProductsReport", Summary: "Best selling products",
+		Query:     query.MustSchema(external.TopProductsReportQuery{}),
+		Responses: map[int]any{http.StatusOK: []data.ProductSales{}},
+	})
+}
+
+// routes registers the routes of an API version and describes them in the OpenAPI document.
+type routes struct {
+	grp        *gin.RouterGroup
+	lgr        logger.Logger
+	spec       *openapi.Builder
+	version    string
+	tag        string
+	deprecated bool
+}
+
+// group returns the routes of a sub path, their operations are tagged with its name.
+func (r routes) group(name string) routes {
+	r.grp = r.grp.Group(name)
+	r.tag = strings.ToUpper(name[:1]) + name[1:]
+	return r
+}
+
+// handle registers handler behind the parsing of the route's query and describes it, operation IDs are prefixed
+// with the version. Routes without a query schema accept no query parameters, path parameters are described
+// unless route describes them already.
+func (r routes) handle(method, relativePath string, handler gin.HandlerFunc, route openapi.Route) {
+	if route.Query == nil {
+		route.Query = noQuery
+	}
+	r.grp.Handle(method, relativePath, middleware.QueryParamsMiddleware(r.lgr, route.Query), handler)
+	fullPath := path.Join(r.grp.BasePath(), relativePath)
+
+	route.ID = r.version + strings.ToUpper(route.ID[:1]) + route.ID[1:]
+	route.Tags = []string{r.tag}
+	route.Deprecated = r.deprecated
+	params := slices.Clone(route.Params)
+	for _, segment := range strings.Split(fullPath, "/") {
+		name, ok := strings.CutPrefix(segment, ":")
+		if ok && !slices.ContainsFunc(params, func(p openapi.Parameter) bool { return p.Name == name && p.In == "path" }) {
+			params = append(params, openapi.Parameter{Name: name, In: "path", Required: true, Schema: pathParamSchemas[name]})
+		}
+	}
+	route.Params = params
+	r.spec.Add(method, fullPath, route)
+}
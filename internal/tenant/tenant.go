@@ -0,0 +1,89 @@
+// Package tenant identifies the storefront a request belongs to. Every tenant keeps its data in its own database.
+package tenant
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+var (
+	ErrMissing = errors.New("tenant is required")
+	ErrUnknown = errors.New("unknown tenant")
+)
+
+// Tenant is a storefront and the database holding its data.
+type Tenant struct {
+	ID       string
+	Database string
+}
+
+type contextKey struct{}
+
+// NewContext returns a copy of ctx carrying t.
+func NewContext(ctx context.Context, t Tenant) context.Context {
+	return context.WithValue(ctx, contextKey{}, t)
+}
+
+// FromContext returns the tenant stored in ctx, if any.
+func FromContext(ctx context.Context) (Tenant, bool) {
+	t, ok := ctx.Value(contextKey{}).(Tenant)
+	return t, ok
+}
+
+// Registry holds the known tenants and the host names they are served on.
+// An empty registry means the service runs single-tenant on its default database.
+type Registry struct {
+	tenants map[string]Tenant
+	hosts   map[string]string
+}
+
+// NewRegistry creates a Registry from tenant IDs mapped to database names and host names mapped to tenant IDs.
+func NewRegistry(databases, hosts map[string]string) (*Registry, error) {
+	r := &Registry{tenants: map[string]Tenant{}, hosts: map[string]string{}}
+	for id, database := range databases {
+		if id == "" || database == "" {
+			return nil, fmt.Errorf("tenant %q needs an id and a database", id)
+		}
+		r.tenants[id] = Tenant{ID: id, Database: database}
+	}
+	for host, id := range hosts {
+		if _, ok := r.tenants[id]; !ok {
+			return nil, fmt.Errorf("%w %q for host %q", ErrUnknown, id, host)
+		}
+		r.hosts[strings.ToLower(host)] = id
+	}
+	return r, nil
+}
+
+// Enabled reports whether any tenant is configured.
+func (r *Registry) Enabled() bool {
+	return len(r.tenants) > 0
+}
+
+// Lookup returns the tenant with the given ID or ErrUnknown.
+func (r *Registry) Lookup(id string) (Tenant, error) {
+	t, ok := r.tenants[id]
+	if !ok {
+		return Tenant{}, ErrUnknown
+	}
+	return t, nil
+}
+
+// ByHost returns the ID of the tenant served on host, host names are case-insensitive.
+func (r *Registry) ByHost(host string) (string, bool) {
+	id, ok := r.hosts[strings.ToLower(host)]
+	return id, ok
+}
+
+// Tenants returns every tenant ordered by ID.
+func (r *Registry) Tenants() []Tenant {
+	tenants := make([]Tenant, 0, len(r.tenants))
+	for _, t := range r.tenants {
+		tenants = append(tenants, t)
+	}
+	slices.SortFunc(tenants, func(a, b Tenant) int { return strings.Compare(a.ID, b.ID) })
+	return tenants
+}
@@ -0,0 +1,79 @@
+package tenant_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/rameshsunkara/go-rest-api-example/internal/tenant"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewRegistry(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name      string
+		databases map[string]string
+		hosts     map[string]string
+		wantErr   bool
+	}{
+		{name: "single tenant"},
+		{
+			name:      "tenants with hosts",
+			databases: map[string]string{"acme": "ecommerce_acme", "globex": "ecommerce_globex"},
+			hosts:     map[string]string{"shop.acme.com": "acme"},
+		},
+		{name: "missing database", databases: map[string]string{"acme": ""}, wantErr: true},
+		{
+			name:      "host of unknown tenant",
+			databases: map[string]string{"acme": "ecommerce_acme"},
+			hosts:     map[string]string{"shop.globex.com": "globex"},
+			wantErr:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			r, err := tenant.NewRegistry(tt.databases, tt.hosts)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, len(tt.databases) > 0, r.Enabled())
+		})
+	}
+}
+
+func TestRegistryLookups(t *testing.T) {
+	t.Parallel()
+	r, err := tenant.NewRegistry(
+		map[string]string{"globex": "ecommerce_globex", "acme": "ecommerce_acme"},
+		map[string]string{"Shop.Acme.com": "acme"},
+	)
+	require.NoError(t, err)
+
+	acme, err := r.Lookup("acme")
+	require.NoError(t, err)
+	assert.Equal(t, tenant.Tenant{ID: "acme", Database: "ecommerce_acme"}, acme)
+	_, err = r.Lookup("initech")
+	require.ErrorIs(t, err, tenant.ErrUnknown)
+
+	id, ok := r.ByHost("shop.acme.COM")
+	assert.True(t, ok)
+	assert.Equal(t, "acme", id)
+	_, ok = r.ByHost("localhost")
+	assert.False(t, ok)
+
+	assert.Equal(t, []tenant.Tenant{acme, {ID: "globex", Database: "ecommerce_globex"}}, r.Tenants())
+}
+
+func TestContext(t *testing.T) {
+	t.Parallel()
+	_, ok := tenant.FromContext(context.Background())
+	assert.False(t, ok)
+	ctx := tenant.NewContext(context.Background(), tenant.Tenant{ID: "acme", Database: "ecommerce_acme"})
+	got, ok := tenant.FromContext(ctx)
+	assert.True(t, ok)
+	assert.Equal(t, "acme", got.ID)
+}
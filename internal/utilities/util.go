@@ -6,7 +6,7 @@ import (
 	"strings"
 	"time"
 
-	"github.com/rameshsunkara/go-rest-api-example/internal/models/data"
+	"github.com/rameshsunkara/go-rest-api-example/pkg/money"
 )
 
 // FormatTimeToISO returns the time in RFC3339 format.
@@ -29,22 +29,12 @@ const (
 	MaxPrice     = 1000
 )
 
-// RandomPrice - Generates a random price between 0 and 1000.
-func RandomPrice() float64 {
-	var price *big.Int
+// RandomPrice - Generates a random USD price between 0 and 1000 with cents.
+func RandomPrice() money.Amount {
+	var cents *big.Int
 	var err error
-	if price, err = rand.Int(rand.Reader, big.NewInt(MaxPrice)); err != nil {
-		price = big.NewInt(defaultPrice)
+	if cents, err = rand.Int(rand.Reader, big.NewInt(MaxPrice*100)); err != nil {
+		cents = big.NewInt(defaultPrice * 100)
 	}
-	pf, _ := price.Float64()
-	return pf
-}
-
-// CalculateTotalAmount calculates the total amount of the order based on the prices of products.
-func CalculateTotalAmount(products []data.Product) float64 {
-	var total float64
-	for _, product := range products {
-		total += product.Price * (float64(product.Quantity))
-	}
-	return total
+	return money.FromMinor(cents.Int64(), money.USD)
 }
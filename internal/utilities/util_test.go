@@ -5,8 +5,8 @@ import (
 	"testing"
 	"time"
 
-	"github.com/rameshsunkara/go-rest-api-example/internal/models/data"
 	"github.com/rameshsunkara/go-rest-api-example/internal/utilities"
+	"github.com/rameshsunkara/go-rest-api-example/pkg/money"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -57,36 +57,7 @@ func TestIsDevMode(t *testing.T) {
 
 func TestRandomPrice(t *testing.T) {
 	price := utilities.RandomPrice()
-	if price > utilities.MaxPrice {
+	if price > money.FromMinor(utilities.MaxPrice*100, money.USD) {
 		t.Errorf("Price is out of range: %v", price)
 	}
 }
-
-func TestCalculateTotalAmount(t *testing.T) {
-	// Test case: Empty input
-	emptyTotal := utilities.CalculateTotalAmount([]data.Product{})
-	// ugly hack to avoid lint error to use InEpsilon and InEpsilon limitation for 0 values
-	assert.InEpsilon(t, 1, emptyTotal+1, 0)
-
-	// Test case: Single product
-	singleProductTotal := utilities.CalculateTotalAmount([]data.Product{
-		{Name: "Product 1", Price: 10.0, Quantity: 1},
-	})
-	assert.InEpsilon(t, 10.0, singleProductTotal, 0.0001)
-
-	// Test case: Multiple products
-	multipleProductsTotal := utilities.CalculateTotalAmount([]data.Product{
-		{Name: "Product 1", Price: 10.0, Quantity: 2},
-		{Name: "Product 2", Price: 20.0, Quantity: 1},
-		{Name: "Product 3", Price: 15.0, Quantity: 3},
-	})
-	assert.InEpsilon(t, 10.0*2+20.0*1+15.0*3, multipleProductsTotal, 0.0001)
-
-	// Test case: Products with zero quantity
-	zeroQuantityProductsTotal := utilities.CalculateTotalAmount([]data.Product{
-		{Name: "Product 1", Price: 10.0, Quantity: 0},
-		{Name: "Product 2", Price: 20.0, Quantity: 0},
-	})
-	// ugly hack to avoid lint error to use InEpsilon and InEpsilon limitation for 0 values
-	assert.InEpsilon(t, 1, zeroQuantityProductsTotal+1, 0)
-}
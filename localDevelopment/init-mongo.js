@@ -21,10 +21,22 @@ db.createUser({
 
 print('✅ Created application user: ecommerce_service');
 
-// Create useful indexes for the application
+// Create useful indexes for the application, the service creates them as well for every tenant database on startup
 db.purchaseOrders.createIndex({ "user": 1 }, { background: true });
 db.purchaseOrders.createIndex({ "createdAt": -1 }, { background: true });
 db.purchaseOrders.createIndex({ "status": 1 }, { background: true });
+// Soft-deleted orders are purged by deletedAt once the retention period has passed
+db.purchaseOrders.createIndex({ "deletedAt": 1 }, { sparse: true, background: true });
+// Imported legacy orders are upserted on their external reference
+db.purchaseOrders.createIndex({ "externalRef": 1 }, { unique: true, sparse: true, background: true });
+// Coupon codes are unique and looked up on every order that applies one
+db.coupons.createIndex({ "code": 1 }, { unique: true, background: true });
+// Catalog SKUs are unique, orders resolve their lines by SKU
+db.products.createIndex({ "sku": 1 }, { unique: true, background: true });
+// One stock level per SKU, reservations decrement it by SKU
+db.inventory.createIndex({ "sku": 1 }, { unique: true, background: true });
+// The reservation sweeper looks for pending orders with reserved stock by age
+db.purchaseOrders.createIndex({ "status": 1, "stockReserved": 1, "createdAt": 1 }, { background: true });
 
 print('✅ Created performance indexes');
 
@@ -40,5 +52,24 @@ db.purchaseOrders.insertOne({
   version: 1
 });
 
+// A sample catalog product so orders can be placed locally, e.g. {"products":[{"sku":"SAMPLE-1","quantity":1}]}
+db.products.insertOne({
+  _id: ObjectId(),
+  sku: "SAMPLE-1",
+  name: "Sample Product",
+  description: "Product created by init-mongo.js",
+  price: NumberDecimal("19.99"),
+  currency: "USD",
+  active: true,
+  createdAt: new Date(),
+  updatedAt: new Date()
+});
+db.inventory.insertOne({
+  _id: ObjectId(),
+  sku: "SAMPLE-1",
+  available: NumberLong(100),
+  updatedAt: new Date()
+});
+
 print('✅ Inserted test document in database: ' + db.getName());
 print('🎉 Database initialization completed successfully!');
\ No newline at end of file
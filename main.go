@@ -5,25 +5,41 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"log/slog"
 	"os"
 	"os/signal"
 	"syscall"
 	"time"
 
 	"github.com/rameshsunkara/go-rest-api-example/internal/config"
+	"github.com/rameshsunkara/go-rest-api-example/internal/metrics"
 	"github.com/rameshsunkara/go-rest-api-example/internal/server"
 	"github.com/rameshsunkara/go-rest-api-example/internal/utilities"
 	"github.com/rameshsunkara/go-rest-api-example/pkg/logger"
 	"github.com/rameshsunkara/go-rest-api-example/pkg/mongodb"
+	"github.com/rameshsunkara/go-rest-api-example/pkg/tracing"
 	"github.com/rs/zerolog"
+	"go.opentelemetry.io/otel"
 )
 
 const (
-	serviceName = "ecommerce-orders"
+	serviceName           = "ecommerce-orders"
+	tracesShutdownTimeout = 5 * time.Second
+	// slogLogComponent names the logger of the libraries logging with log/slog, see logComponentLevels
+	slogLogComponent = "slog"
 )
 
 func main() {
-	if err := run(); err != nil {
+	runFn := run
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case importCommand:
+			runFn = func() error { return runImport(os.Args[2:], os.Stdout) }
+		case migrateMoneyCommand:
+			runFn = func() error { return runMigrateMoney(os.Args[2:], os.Stdout) }
+		}
+	}
+	if err := runFn(); err != nil {
 		fmt.Fprintf(os.Stderr, "Service %s exited with error: %v (exit code: %d)\n",
 			serviceName, err, exitCode(err))
 		os.Exit(exitCode(err))
@@ -45,10 +61,29 @@ func run() error {
 	if utilities.IsDevMode(svcEnv.Environment) {
 		logWriter = zerolog.ConsoleWriter{Out: os.Stdout}
 	}
-	lgr := logger.New(svcEnv.LogLevel, logWriter)
+	lgr := newLogger(svcEnv, logWriter)
+	// libraries logging with log/slog, or the log package, share the format and levels of the service logger
+	slog.SetDefault(slog.New(logger.NewSlogHandler(lgr.Named(slogLogComponent))))
+
+	// setup : OpenTelemetry tracing, requests still propagate traceparent when no exporter is configured
+	_, shutdownTracing, traceErr := tracing.Setup(ctx, tracing.Config{
+		ServiceName:  serviceName,
+		Environment:  svcEnv.Environment,
+		Exporter:     svcEnv.TraceExporter,
+		OTLPEndpoint: svcEnv.OTLPEndpoint,
+		File:         svcEnv.TraceFile,
+		SampleRatio:  svcEnv.TraceSampleRatio,
+	})
+	if traceErr != nil {
+		return traceErr
+	}
+	defer shutdownTraces(lgr, shutdownTracing)
+
+	// setup : metrics, served on /metrics along with the ones of the database client
+	m := metrics.New()
 
 	// setup : database connection
-	dbConnMgr, dbErr := setupDB(svcEnv)
+	dbConnMgr, dbErr := setupDB(svcEnv, lgr, mongodb.WithMetrics(m.Registry))
 	if dbErr != nil {
 		return dbErr
 	}
@@ -60,7 +95,7 @@ func run() error {
 		Msg("Starting the service")
 
 	// Start server - this blocks until shutdown or error
-	err := server.Start(ctx, svcEnv, lgr, dbConnMgr)
+	err := server.Start(ctx, svcEnv, lgr, dbConnMgr, m)
 
 	// Cleanup after server stops
 	cleanup(lgr, dbConnMgr)
@@ -74,18 +109,46 @@ func run() error {
 	return err
 }
 
-func setupDB(svcEnv *config.ServiceEnvConfig) (*mongodb.ConnectionManager, error) {
+// newLogger returns the service logger, its levels can be changed at runtime through /internal/loglevel.
+// Invalid levels fall back to info, with a warning, rather than failing startup.
+func newLogger(svcEnv *config.ServiceEnvConfig, writer io.Writer) logger.Logger {
+	levels, err := logger.NewLevels(svcEnv.LogLevel, svcEnv.LogComponentLevels)
+	if err != nil {
+		lgr := logger.New(config.DefaultLogLevel, writer)
+		lgr.Warn().Err(err).Msg("invalid log levels, logging at " + config.DefaultLogLevel)
+		return lgr
+	}
+	return logger.NewWithLevels(levels, writer)
+}
+
+// setupDB connects to the database, extra options are added to the ones of svcEnv.
+func setupDB(
+	svcEnv *config.ServiceEnvConfig,
+	lgr logger.Logger,
+	extra ...mongodb.Option,
+) (*mongodb.ConnectionManager, error) {
 	dbCredentials, err := mongodb.CredentialFromSideCar(svcEnv.DBCredentialsSideCar)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch DB credentials : %w", err)
 	}
 
+	opts := []mongodb.Option{
+		mongodb.WithLogger(lgr.Named("mongodb")),
+		mongodb.WithQueryLogging(svcEnv.DBLogQueries),
+		mongodb.WithSlowQueryThreshold(svcEnv.DBSlowQueryThreshold),
+		mongodb.WithRedactedFields(svcEnv.DBRedactFields...),
+		// mongodb.WithReplicaSet(svcEnv.ReplicaSet) added to demonstrate functional options
+	}
+	if svcEnv.TraceExporter != "" {
+		// tracing.Setup installed the provider, commands become children of the request spans
+		opts = append(opts, mongodb.WithTracing(otel.GetTracerProvider()))
+	}
+	opts = append(opts, extra...)
 	dbConnMgr, dbErr := mongodb.NewMongoManager(
 		svcEnv.DBHosts,
 		svcEnv.DBName,
 		dbCredentials,
-		mongodb.WithQueryLogging(svcEnv.DBLogQueries),
-		// mongodb.WithReplicaSet(svcEnv.ReplicaSet) added to demonstrate functional options
+		opts...,
 	)
 	if dbErr != nil {
 		return nil, fmt.Errorf("unable to initialize DB connection: %w", dbErr)
@@ -101,6 +164,15 @@ func cleanup(lgr logger.Logger, dbConnMgr *mongodb.ConnectionManager) {
 	}
 }
 
+// shutdownTraces flushes the spans that are not exported yet.
+func shutdownTraces(lgr logger.Logger, shutdown func(context.Context) error) {
+	ctx, cancel := context.WithTimeout(context.Background(), tracesShutdownTimeout)
+	defer cancel()
+	if err := shutdown(ctx); err != nil {
+		lgr.Error().Err(err).Msg("failed to flush traces")
+	}
+}
+
 func exitCode(err error) int {
 	if err == nil || errors.Is(err, context.Canceled) {
 		return 0
@@ -1,12 +1,19 @@
 package main
 
 import (
+	"bytes"
 	"context"
 	"errors"
+	"io"
 	"os"
+	"strings"
 	"testing"
 
 	"github.com/rameshsunkara/go-rest-api-example/internal/config"
+	"github.com/rameshsunkara/go-rest-api-example/internal/db/mocks"
+	"github.com/rameshsunkara/go-rest-api-example/internal/tenant"
+	"github.com/rameshsunkara/go-rest-api-example/pkg/logger"
+	"github.com/rameshsunkara/go-rest-api-example/pkg/money"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -25,7 +32,7 @@ func TestSetupDBFail(t *testing.T) {
 		DBName:               "db",
 		DBCredentialsSideCar: "/notfound",
 	}
-	_, err := setupDB(svcEnv)
+	_, err := setupDB(svcEnv, logger.New("info", io.Discard))
 	require.Error(t, err)
 }
 
@@ -36,6 +43,37 @@ func TestServiceName(t *testing.T) {
 	assert.Equal(t, "ecommerce-orders", serviceName)
 }
 
+func TestNewLogger(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name       string
+		level      string
+		components map[string]string
+		wantWarn   bool
+		wantDebug  bool
+	}{
+		{name: "valid level", level: "debug", wantDebug: true},
+		{name: "invalid level falls back to info", level: "verbose", wantWarn: true},
+		{
+			name:       "invalid component level falls back to info",
+			level:      "debug",
+			components: map[string]string{"mongodb": "loud"},
+			wantWarn:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			var buf bytes.Buffer
+			lgr := newLogger(&config.ServiceEnvConfig{LogLevel: tt.level, LogComponentLevels: tt.components}, &buf)
+			lgr.Debug().Msg("debug message")
+
+			assert.Equal(t, tt.wantWarn, strings.Contains(buf.String(), "invalid log levels, logging at info"))
+			assert.Equal(t, tt.wantDebug, strings.Contains(buf.String(), "debug message"))
+		})
+	}
+}
+
 func TestSetupDBSuccess(t *testing.T) {
 	t.Parallel()
 
@@ -57,9 +95,94 @@ func TestSetupDBSuccess(t *testing.T) {
 	}
 
 	// This should fail due to invalid host, but test the credential loading part works
-	_, err = setupDB(svcEnv)
+	_, err = setupDB(svcEnv, logger.New("info", io.Discard))
 	require.Error(t, err)
 
 	// Ensure error is about connection, not credential loading
 	assert.Contains(t, err.Error(), "unable to initialize DB connection")
 }
+
+func TestParseImportArgs(t *testing.T) {
+	t.Parallel()
+
+	ia, err := parseImportArgs([]string{"-file", "orders.csv", "-dry-run"})
+	require.NoError(t, err)
+	assert.Equal(t, "orders.csv", ia.file)
+	assert.Equal(t, "csv", ia.format)
+	assert.True(t, ia.dryRun)
+	assert.Equal(t, 500, ia.batchSize)
+
+	ia, err = parseImportArgs([]string{"-file", "orders.txt", "-format", "ndjson", "-batch-size", "10"})
+	require.NoError(t, err)
+	assert.Equal(t, "ndjson", ia.format)
+	assert.Equal(t, 10, ia.batchSize)
+
+	ia, err = parseImportArgs([]string{"-file", "orders.csv", "-tenant", "acme"})
+	require.NoError(t, err)
+	assert.Equal(t, "acme", ia.tenant)
+
+	_, err = parseImportArgs([]string{})
+	require.Error(t, err)
+}
+
+func TestParseMigrateMoneyArgs(t *testing.T) {
+	t.Parallel()
+
+	ma, err := parseMigrateMoneyArgs([]string{})
+	require.NoError(t, err)
+	assert.Equal(t, money.USD, ma.currency)
+
+	ma, err = parseMigrateMoneyArgs([]string{"-currency", "eur"})
+	require.NoError(t, err)
+	assert.Equal(t, money.EUR, ma.currency)
+
+	ma, err = parseMigrateMoneyArgs([]string{"-all-tenants"})
+	require.NoError(t, err)
+	assert.True(t, ma.allTenants)
+
+	_, err = parseMigrateMoneyArgs([]string{"-currency", "XYZ"})
+	require.ErrorIs(t, err, money.ErrUnsupportedCurrency)
+
+	_, err = parseMigrateMoneyArgs([]string{"-tenant", "acme", "-all-tenants"})
+	require.ErrorIs(t, err, errTenantsExclusive)
+}
+
+func TestTenantDatabases(t *testing.T) {
+	t.Parallel()
+	tenants := map[string]string{"acme": "acme_db", "globex": "globex_db"}
+	tests := []struct {
+		name     string
+		tenants  map[string]string
+		tenantID string
+		all      bool
+		want     []string // tenant:database of the resolved databases
+		wantErr  error
+	}{
+		{name: "single tenant", want: []string{":"}},
+		{name: "single tenant names a tenant", tenantID: "acme", wantErr: errTenantsDisabled},
+		{name: "tenant", tenants: tenants, tenantID: "acme", want: []string{"acme:acme_db"}},
+		{name: "all tenants", tenants: tenants, all: true, want: []string{"acme:acme_db", "globex:globex_db"}},
+		{name: "unknown tenant", tenants: tenants, tenantID: "initech", wantErr: tenant.ErrUnknown},
+		{name: "tenant required", tenants: tenants, wantErr: errTenantRequired},
+		{name: "exclusive", tenants: tenants, tenantID: "acme", all: true, wantErr: errTenantsExclusive},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			svcEnv := &config.ServiceEnvConfig{Tenants: tt.tenants}
+			dbs, err := tenantDatabases(svcEnv, &mocks.MockMongoMgr{}, tt.tenantID, tt.all)
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			got := make([]string, 0, len(dbs))
+			for _, d := range dbs {
+				mockDB, ok := d.db.(*mocks.MockMongoDataBase)
+				require.True(t, ok)
+				got = append(got, d.tenant+":"+mockDB.Name)
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/rameshsunkara/go-rest-api-example/internal/config"
+	"github.com/rameshsunkara/go-rest-api-example/internal/db"
+	"github.com/rameshsunkara/go-rest-api-example/internal/models/data"
+	"github.com/rameshsunkara/go-rest-api-example/pkg/logger"
+	"github.com/rameshsunkara/go-rest-api-example/pkg/money"
+)
+
+const (
+	migrateMoneyCommand = "migrate-money"
+)
+
+// migrateMoneyArgs holds the parsed command line flags of the migrate-money subcommand.
+type migrateMoneyArgs struct {
+	currency   money.Currency
+	tenant     string
+	allTenants bool
+}
+
+// migrateMoneyReport is the outcome of a money migration, Tenants holds the migrated orders per tenant.
+type migrateMoneyReport struct {
+	Migrated int64            `json:"migrated"`
+	Tenants  map[string]int64 `json:"tenants,omitempty"`
+}
+
+func parseMigrateMoneyArgs(args []string) (*migrateMoneyArgs, error) {
+	fs := flag.NewFlagSet(migrateMoneyCommand, flag.ContinueOnError)
+	code := fs.String("currency", string(data.DefaultCurrency), "ISO-4217 currency assigned to orders without one")
+	ma := &migrateMoneyArgs{}
+	fs.StringVar(&ma.tenant, "tenant", "", "tenant whose database is migrated")
+	fs.BoolVar(&ma.allTenants, "all-tenants", false, "migrate the database of every tenant")
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+	if ma.tenant != "" && ma.allTenants {
+		return nil, errTenantsExclusive
+	}
+	currency, err := money.ParseCurrency(*code)
+	if err != nil {
+		return nil, err
+	}
+	ma.currency = currency
+	return ma, nil
+}
+
+// runMigrateMoney converts orders stored with float amounts to decimal amounts and writes
+// the number of migrated orders as JSON to out.
+//
+// Usage: ecommerce-orders migrate-money [-currency USD] [-tenant id | -all-tenants].
+func runMigrateMoney(args []string, out io.Writer) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM, syscall.SIGINT)
+	defer stop()
+
+	ma, argsErr := parseMigrateMoneyArgs(args)
+	if argsErr != nil {
+		return argsErr
+	}
+
+	svcEnv, envErr := config.Load()
+	if envErr != nil {
+		return envErr
+	}
+	lgr := newLogger(svcEnv, os.Stderr)
+
+	dbConnMgr, dbErr := setupDB(svcEnv, lgr)
+	if dbErr != nil {
+		return dbErr
+	}
+	defer cleanup(lgr, dbConnMgr)
+
+	dbs, tenantErr := tenantDatabases(svcEnv, dbConnMgr, ma.tenant, ma.allTenants)
+	if tenantErr != nil {
+		return tenantErr
+	}
+	report := &migrateMoneyReport{}
+	for _, d := range dbs {
+		migrated, migrateErr := migrateMoney(ctx, lgr, d, ma.currency)
+		if migrateErr != nil {
+			return migrateErr
+		}
+		report.Migrated += migrated
+		if d.tenant != "" {
+			if report.Tenants == nil {
+				report.Tenants = map[string]int64{}
+			}
+			report.Tenants[d.tenant] = migrated
+		}
+	}
+	return json.NewEncoder(out).Encode(report)
+}
+
+func migrateMoney(ctx context.Context, lgr logger.Logger, d tenantDatabase, currency money.Currency) (int64, error) {
+	if d.tenant != "" {
+		lgr = lgr.With().Str("tenant", d.tenant).Logger()
+	}
+	ordersRepo, err := db.NewOrdersRepo(lgr, d.db)
+	if err != nil {
+		return 0, err
+	}
+	migrated, err := ordersRepo.MigrateMoney(ctx, currency)
+	if err != nil && d.tenant != "" {
+		return 0, fmt.Errorf("migrating tenant %s: %w", d.tenant, err)
+	}
+	return migrated, err
+}
@@ -67,7 +67,7 @@ func New(lgr logger.Logger, traceDir string, minAge time.Duration, maxBytes uint
 
 	lgr.Info().
 		Dur("minAge", minAge).
-		Interface("maxBytes", maxBytes).
+		Uint64("maxBytes", maxBytes).
 		Str("traceDir", traceDir).
 		Msg("Flight recorder initialized")
 
@@ -0,0 +1,35 @@
+package logger
+
+import (
+	"context"
+	"io"
+)
+
+// ContextKey is the type of the request context keys request scoped values are stored under, the request ID
+// is under ContextKey(DefaultRequestIDKey).
+type ContextKey string
+
+// loggerKey is the context key the request logger is stored under.
+type loggerKey struct{}
+
+// discard is returned by FromContext for contexts without a logger.
+var discard = New("fatal", io.Discard)
+
+// NewContext returns a copy of ctx carrying lgr, code receiving ctx logs through it with FromContext.
+func NewContext(ctx context.Context, lgr Logger) context.Context {
+	return context.WithValue(ctx, loggerKey{}, lgr)
+}
+
+// FromContext returns the logger attached to ctx by NewContext, or a logger discarding every event.
+func FromContext(ctx context.Context) Logger {
+	return FromContextOr(ctx, discard)
+}
+
+// FromContextOr returns the logger attached to ctx by NewContext, or fallback when there is none, e.g. in
+// background jobs.
+func FromContextOr(ctx context.Context, fallback Logger) Logger {
+	if lgr, ok := ctx.Value(loggerKey{}).(Logger); ok && lgr != nil {
+		return lgr
+	}
+	return fallback
+}
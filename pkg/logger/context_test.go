@@ -0,0 +1,44 @@
+package logger_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rameshsunkara/go-rest-api-example/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFromContext(t *testing.T) {
+	rec := logger.NewRecorder()
+	ctx := logger.NewContext(context.Background(), rec)
+
+	assert.Equal(t, rec, logger.FromContext(ctx))
+	assert.Equal(t, rec, logger.FromContextOr(ctx, logger.NewRecorder()))
+
+	fallback := logger.NewRecorder()
+	assert.Equal(t, fallback, logger.FromContextOr(context.Background(), fallback))
+	// contexts without a logger get one discarding the events
+	discard := logger.FromContext(context.Background())
+	require.NotNil(t, discard)
+	discard.Error().Msg("dropped")
+}
+
+func TestWithReqIDFromRequestContext(t *testing.T) {
+	rec := logger.NewRecorder()
+
+	gin.SetMode(gin.TestMode)
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	ctx := context.WithValue(context.Background(), logger.ContextKey(logger.DefaultRequestIDKey), "req-1")
+	c.Request = httptest.NewRequest(http.MethodGet, "/test", nil).WithContext(ctx)
+
+	l, reqID := rec.WithReqID(c)
+	assert.Equal(t, "req-1", reqID)
+	l.Info().Msg("with request ID")
+	entries := rec.Entries()
+	require.Len(t, entries, 1)
+	assert.Equal(t, "req-1", entries[0].Fields[logger.DefaultRequestIDKey])
+}
@@ -0,0 +1,15 @@
+package logger
+
+// MaxWindows exposes maxWindows to the tests.
+const MaxWindows = maxWindows
+
+// Windows returns the number of messages tracked by the limiter of lgr, a logger returned by NewRateLimited.
+func Windows(lgr Logger) int {
+	l, ok := lgr.(*rateLimitedLogger)
+	if !ok {
+		return 0
+	}
+	l.limiter.mu.Lock()
+	defer l.limiter.mu.Unlock()
+	return len(l.limiter.windows)
+}
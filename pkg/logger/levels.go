@@ -0,0 +1,164 @@
+package logger
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// ErrInvalidLevel is returned for levels other than trace, debug, info, warn, error and fatal.
+var ErrInvalidLevel = errors.New("invalid log level")
+
+// Levels holds the level of a logger and the levels of its named children, see AppLogger.Named. Every logger
+// derived from the same one shares its Levels, a change applies to all of them at once.
+type Levels struct {
+	current atomic.Pointer[levelSet]
+	initial *levelSet
+
+	mu       sync.Mutex // serializes changes with the revert timer
+	revert   *time.Timer
+	revertAt time.Time
+	changes  uint64 // a timer that fires after another change must not revert it
+}
+
+// levelSet is replaced as a whole so loggers never see a global level and components of different changes.
+type levelSet struct {
+	global     zerolog.Level
+	components map[string]zerolog.Level
+}
+
+// NewLevels returns the levels of a logger logging at level, components holds the levels of named loggers
+// that differ from it, e.g. {"mongodb": "debug"}.
+func NewLevels(level string, components map[string]string) (*Levels, error) {
+	set, err := newLevelSet(nil, level, components)
+	if err != nil {
+		return nil, err
+	}
+	return newLevels(set), nil
+}
+
+func newLevels(set *levelSet) *Levels {
+	l := &Levels{initial: set}
+	l.current.Store(set)
+	return l
+}
+
+func newLevelSet(base *levelSet, level string, components map[string]string) (*levelSet, error) {
+	set := &levelSet{}
+	if base != nil {
+		*set = *base
+	}
+	if level != "" || base == nil {
+		lvl, err := parseLevel(level)
+		if err != nil {
+			return nil, err
+		}
+		set.global = lvl
+	}
+	if components != nil || base == nil {
+		set.components = make(map[string]zerolog.Level, len(components))
+		for name, cLevel := range components {
+			lvl, err := parseLevel(cLevel)
+			if err != nil {
+				return nil, fmt.Errorf("component %s: %w", name, err)
+			}
+			set.components[name] = lvl
+		}
+	}
+	return set, nil
+}
+
+// Set changes the levels, an empty level keeps the current one and nil components keep the current ones.
+// Once revertAfter elapses, when it is positive, the levels go back to the ones the logger started with.
+func (l *Levels) Set(level string, components map[string]string, revertAfter time.Duration) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	set, err := newLevelSet(l.current.Load(), level, components)
+	if err != nil {
+		return err
+	}
+	l.store(set)
+	if revertAfter > 0 {
+		change := l.changes
+		l.revertAt = time.Now().Add(revertAfter)
+		l.revert = time.AfterFunc(revertAfter, func() {
+			l.mu.Lock()
+			defer l.mu.Unlock()
+			if l.changes == change {
+				l.store(l.initial)
+			}
+		})
+	}
+	return nil
+}
+
+// Reset restores the levels the logger started with.
+func (l *Levels) Reset() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.store(l.initial)
+}
+
+// store replaces the levels and cancels a pending revert, l.mu must be held.
+func (l *Levels) store(set *levelSet) {
+	l.current.Store(set)
+	l.changes++
+	if l.revert != nil {
+		l.revert.Stop()
+		l.revert = nil
+	}
+	l.revertAt = time.Time{}
+}
+
+// Settings returns the current levels, and when they revert to the initial ones, zero when they do not.
+func (l *Levels) Settings() (string, map[string]string, time.Time) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	set := l.current.Load()
+	components := make(map[string]string, len(set.components))
+	for name, lvl := range set.components {
+		components[name] = lvl.String()
+	}
+	return set.global.String(), components, l.revertAt
+}
+
+// enabled reports whether a logger of the component logs at lvl. Components inherit the level of their
+// closest dotted parent, "mongodb.pool" logs at the level of "mongodb" unless it has its own.
+func (l *Levels) enabled(component string, lvl zerolog.Level) bool {
+	set := l.current.Load()
+	for name := component; name != ""; {
+		if cLvl, ok := set.components[name]; ok {
+			return lvl >= cLvl
+		}
+		i := strings.LastIndexByte(name, '.')
+		if i < 0 {
+			break
+		}
+		name = name[:i]
+	}
+	return lvl >= set.global
+}
+
+// parseLevel parses the name of a level, case-insensitively.
+func parseLevel(level string) (zerolog.Level, error) {
+	switch strings.ToLower(level) {
+	case "trace":
+		return zerolog.TraceLevel, nil
+	case "debug":
+		return zerolog.DebugLevel, nil
+	case "info":
+		return zerolog.InfoLevel, nil
+	case "warn":
+		return zerolog.WarnLevel, nil
+	case "error":
+		return zerolog.ErrorLevel, nil
+	case "fatal":
+		return zerolog.FatalLevel, nil
+	}
+	return zerolog.NoLevel, fmt.Errorf("%w %q, expected trace, debug, info, warn, error or fatal", ErrInvalidLevel, level)
+}
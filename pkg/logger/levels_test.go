@@ -0,0 +1,100 @@
+package logger_test
+
+import (
+	"bytes"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/rameshsunkara/go-rest-api-example/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewLevels(t *testing.T) {
+	_, err := logger.NewLevels("verbose", nil)
+	require.ErrorIs(t, err, logger.ErrInvalidLevel)
+	_, err = logger.NewLevels("info", map[string]string{"mongodb": "verbose"})
+	require.ErrorIs(t, err, logger.ErrInvalidLevel)
+
+	levels, err := logger.NewLevels("INFO", map[string]string{"mongodb": "debug"})
+	require.NoError(t, err)
+	level, components, revertAt := levels.Settings()
+	assert.Equal(t, "info", level)
+	assert.Equal(t, map[string]string{"mongodb": "debug"}, components)
+	assert.True(t, revertAt.IsZero())
+}
+
+func TestNamedLoggerLevels(t *testing.T) {
+	levels, err := logger.NewLevels("info", map[string]string{"mongodb": "debug", "http": "error"})
+	require.NoError(t, err)
+	buf := &bytes.Buffer{}
+	lgr := logger.NewWithLevels(levels, buf)
+
+	lgr.Debug().Msg("root debug")
+	lgr.Named("http").Info().Msg("http info")
+	lgr.Named("mongodb").Debug().Msg("mongodb debug")
+	// children inherit the level of their closest parent
+	lgr.Named("mongodb").Named("pool").Debug().Msg("pool debug")
+	lgr.Named("jobs").Info().Msg("jobs info")
+
+	output := buf.String()
+	assert.NotContains(t, output, "root debug")
+	assert.NotContains(t, output, "http info")
+	assert.Contains(t, output, "mongodb debug")
+	assert.Contains(t, output, `"component":"mongodb.pool"`)
+	assert.Contains(t, output, "jobs info")
+}
+
+func TestLevelsSet(t *testing.T) {
+	levels, err := logger.NewLevels("info", map[string]string{"mongodb": "error"})
+	require.NoError(t, err)
+	buf := &bytes.Buffer{}
+	lgr := logger.NewWithLevels(levels, buf)
+	child := lgr.Named("http")
+
+	require.ErrorIs(t, levels.Set("verbose", nil, 0), logger.ErrInvalidLevel)
+
+	// loggers created before the change follow it
+	require.NoError(t, levels.Set("debug", nil, 0))
+	child.Debug().Msg("after change")
+	assert.Contains(t, buf.String(), "after change")
+	_, components, _ := levels.Settings()
+	assert.Equal(t, map[string]string{"mongodb": "error"}, components, "nil components are kept")
+
+	require.NoError(t, levels.Set("", map[string]string{}, 0))
+	level, components, _ := levels.Settings()
+	assert.Equal(t, "debug", level, "an empty level is kept")
+	assert.Empty(t, components)
+
+	levels.Reset()
+	level, components, _ = levels.Settings()
+	assert.Equal(t, "info", level)
+	assert.Equal(t, map[string]string{"mongodb": "error"}, components)
+}
+
+func TestLevelsRevert(t *testing.T) {
+	levels, err := logger.NewLevels("info", nil)
+	require.NoError(t, err)
+
+	require.NoError(t, levels.Set("debug", nil, time.Hour))
+	_, _, revertAt := levels.Settings()
+	assert.WithinDuration(t, time.Now().Add(time.Hour), revertAt, time.Minute)
+
+	// a later change replaces the pending revert
+	require.NoError(t, levels.Set("error", nil, 0))
+	_, _, revertAt = levels.Settings()
+	assert.True(t, revertAt.IsZero())
+
+	require.NoError(t, levels.Set("debug", nil, 10*time.Millisecond))
+	assert.Eventually(t, func() bool {
+		level, _, at := levels.Settings()
+		return level == "info" && at.IsZero()
+	}, time.Second, 5*time.Millisecond)
+}
+
+func TestNewFallsBackToInfo(t *testing.T) {
+	lgr := logger.New("verbose", io.Discard)
+	level, _, _ := lgr.Levels().Settings()
+	assert.Equal(t, "info", level)
+}
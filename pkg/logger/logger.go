@@ -2,53 +2,123 @@ package logger
 
 import (
 	"io"
-	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/pkgerrors"
+	"go.opentelemetry.io/otel/trace"
 )
 
 const (
 	DefaultRequestIDKey = "X-Request-ID"
+
+	// ComponentKey names the field holding the name of a named logger.
+	ComponentKey = "component"
+
+	// TraceIDKey and SpanIDKey name the fields holding the IDs of the span of a traced request.
+	TraceIDKey = "trace_id"
+	SpanIDKey  = "span_id"
 )
 
 // Logger defines the logging interface with method chaining support.
 type Logger interface {
+	Trace() Event
 	Debug() Event
 	Info() Event
+	Warn() Event
 	Error() Event
 	Fatal() Event
+	With() Context
 	WithReqID(ctx *gin.Context) (Logger, string)
 	WithReqIDCustom(ctx *gin.Context, identifier string) (Logger, string)
+	Named(component string) Logger
+	Levels() *Levels
 }
 
 // Event defines the interface for log event building with method chaining.
 type Event interface {
 	Str(key, val string) Event
+	Strs(key string, vals []string) Event
 	Int(key string, val int) Event
-	Interface(key string, val interface{}) Event
+	Int64(key string, val int64) Event
+	Uint64(key string, val uint64) Event
+	Float64(key string, val float64) Event
+	Bool(key string, val bool) Event
+	Time(key string, val time.Time) Event
 	Dur(key string, val time.Duration) Event
+	Interface(key string, val interface{}) Event
+	// Dict adds the fields set by fields under key, e.g. Dict("order", func(d Event) { d.Str("id", id) }).
+	Dict(key string, fields func(d Event)) Event
 	Err(err error) Event
 	Msg(msg string)
 	Send()
 }
 
+// Context builds a child logger adding its fields to every event, e.g. lgr.With().Str("job", name).Logger().
+type Context interface {
+	Str(key, val string) Context
+	Strs(key string, vals []string) Context
+	Int(key string, val int) Context
+	Int64(key string, val int64) Context
+	Uint64(key string, val uint64) Context
+	Float64(key string, val float64) Context
+	Bool(key string, val bool) Context
+	Time(key string, val time.Time) Context
+	Dur(key string, val time.Duration) Context
+	Interface(key string, val interface{}) Context
+	Logger() Logger
+}
+
 // AppLogger is a zerolog-based implementation of Logger.
 type AppLogger struct {
-	zLogger zerolog.Logger
+	zLogger   zerolog.Logger
+	levels    *Levels
+	component string
 }
 
+// New returns a logger logging at logLevel, unknown levels fall back to info.
 func New(logLevel string, writer io.Writer) Logger {
-	lvl := parseZerologLevel(logLevel)
+	return NewWithLevels(newLevels(&levelSet{global: parseZerologLevel(logLevel)}), writer)
+}
+
+// NewWithLevels returns a logger whose level, and the levels of its named children, can change at runtime.
+func NewWithLevels(levels *Levels, writer io.Writer) Logger {
 	zerolog.ErrorStackMarshaler = pkgerrors.MarshalStack
 	zerolog.TimeFieldFormat = time.RFC3339Nano
+	// levels filters the events, the zerolog logger lets every level through
+	return &AppLogger{
+		zLogger: zerolog.New(writer).With().Caller().Timestamp().Logger(),
+		levels:  levels,
+	}
+}
+
+// Named returns a child logger of the component, it logs at the level of the component when it has one.
+// Names of nested children are dotted, e.g. "mongodb.pool".
+func (l *AppLogger) Named(component string) Logger {
+	component = childComponent(l.component, component)
 	return &AppLogger{
-		zLogger: zerolog.New(writer).With().Caller().Timestamp().Logger().Level(lvl),
+		zLogger:   l.zLogger.With().Str(ComponentKey, component).Logger(),
+		levels:    l.levels,
+		component: component,
 	}
 }
 
+// Levels returns the levels shared by the logger and the loggers derived from it.
+func (l *AppLogger) Levels() *Levels {
+	return l.levels
+}
+
+// With returns a builder of a child logger with persistent fields.
+func (l *AppLogger) With() Context {
+	return &zerologContext{lgr: l, ctx: l.zLogger.With()}
+}
+
+// with returns a logger of the same component and levels writing through zLogger.
+func (l *AppLogger) with(zLogger zerolog.Logger) *AppLogger {
+	return &AppLogger{zLogger: zLogger, levels: l.levels, component: l.component}
+}
+
 // WithReqID returns a logger with request ID using DefaultRequestIDKey.
 func (l *AppLogger) WithReqID(ctx *gin.Context) (Logger, string) {
 	return l.WithReqIDCustom(ctx, DefaultRequestIDKey)
@@ -56,16 +126,30 @@ func (l *AppLogger) WithReqID(ctx *gin.Context) (Logger, string) {
 
 // WithReqIDCustom returns a logger with request ID using a custom identifier key.
 func (l *AppLogger) WithReqIDCustom(ctx *gin.Context, identifier string) (Logger, string) {
-	idKey := identifier
-
-	type contextKey string
-	if rID := ctx.Request.Context().Value(contextKey(idKey)); rID != nil {
-		if reqID, ok := rID.(string); ok {
-			return &AppLogger{zLogger: l.zLogger.With().Str(idKey, reqID).Logger()}, reqID
-		}
-		return l, ""
+	return withRequest(l, ctx, identifier)
+}
+
+// withRequest adds the trace and request IDs of the request to lgr, it is shared by the Logger implementations.
+func withRequest(lgr Logger, ctx *gin.Context, identifier string) (Logger, string) {
+	// traced requests carry their span, its IDs lead from a log line to the trace and back
+	if sc := trace.SpanContextFromContext(ctx.Request.Context()); sc.IsValid() {
+		lgr = lgr.With().
+			Str(TraceIDKey, sc.TraceID().String()).
+			Str(SpanIDKey, sc.SpanID().String()).
+			Logger()
 	}
-	return l, ""
+
+	if reqID, ok := ctx.Request.Context().Value(ContextKey(identifier)).(string); ok && reqID != "" {
+		return lgr.With().Str(identifier, reqID).Logger(), reqID
+	}
+	return lgr, ""
+}
+
+func childComponent(parent, component string) string {
+	if parent == "" {
+		return component
+	}
+	return parent + "." + component
 }
 
 // zerologEvent wraps zerolog.Event to implement the Event interface.
@@ -78,18 +162,51 @@ func (e *zerologEvent) Str(key, val string) Event {
 	return &zerologEvent{event: e.event.Str(key, val)}
 }
 
+func (e *zerologEvent) Strs(key string, vals []string) Event {
+	return &zerologEvent{event: e.event.Strs(key, vals)}
+}
+
 func (e *zerologEvent) Int(key string, val int) Event {
 	return &zerologEvent{event: e.event.Int(key, val)}
 }
 
-func (e *zerologEvent) Interface(key string, val interface{}) Event {
-	return &zerologEvent{event: e.event.Interface(key, val)}
+func (e *zerologEvent) Int64(key string, val int64) Event {
+	return &zerologEvent{event: e.event.Int64(key, val)}
+}
+
+func (e *zerologEvent) Uint64(key string, val uint64) Event {
+	return &zerologEvent{event: e.event.Uint64(key, val)}
+}
+
+func (e *zerologEvent) Float64(key string, val float64) Event {
+	return &zerologEvent{event: e.event.Float64(key, val)}
+}
+
+func (e *zerologEvent) Bool(key string, val bool) Event {
+	return &zerologEvent{event: e.event.Bool(key, val)}
+}
+
+func (e *zerologEvent) Time(key string, val time.Time) Event {
+	return &zerologEvent{event: e.event.Time(key, val)}
 }
 
 func (e *zerologEvent) Dur(key string, val time.Duration) Event {
 	return &zerologEvent{event: e.event.Dur(key, val)}
 }
 
+func (e *zerologEvent) Interface(key string, val interface{}) Event {
+	return &zerologEvent{event: e.event.Interface(key, val)}
+}
+
+func (e *zerologEvent) Dict(key string, fields func(d Event)) Event {
+	if e.event == nil {
+		return e
+	}
+	dict := zerolog.Dict()
+	fields(&zerologEvent{event: dict})
+	return &zerologEvent{event: e.event.Dict(key, dict)}
+}
+
 func (e *zerologEvent) Err(err error) Event {
 	return &zerologEvent{event: e.event.Err(err)}
 }
@@ -102,6 +219,56 @@ func (e *zerologEvent) Send() {
 	e.event.Send()
 }
 
+// zerologContext wraps zerolog.Context to implement the Context interface.
+type zerologContext struct {
+	lgr *AppLogger
+	ctx zerolog.Context
+}
+
+func (c *zerologContext) Str(key, val string) Context {
+	return &zerologContext{lgr: c.lgr, ctx: c.ctx.Str(key, val)}
+}
+
+func (c *zerologContext) Strs(key string, vals []string) Context {
+	return &zerologContext{lgr: c.lgr, ctx: c.ctx.Strs(key, vals)}
+}
+
+func (c *zerologContext) Int(key string, val int) Context {
+	return &zerologContext{lgr: c.lgr, ctx: c.ctx.Int(key, val)}
+}
+
+func (c *zerologContext) Int64(key string, val int64) Context {
+	return &zerologContext{lgr: c.lgr, ctx: c.ctx.Int64(key, val)}
+}
+
+func (c *zerologContext) Uint64(key string, val uint64) Context {
+	return &zerologContext{lgr: c.lgr, ctx: c.ctx.Uint64(key, val)}
+}
+
+func (c *zerologContext) Float64(key string, val float64) Context {
+	return &zerologContext{lgr: c.lgr, ctx: c.ctx.Float64(key, val)}
+}
+
+func (c *zerologContext) Bool(key string, val bool) Context {
+	return &zerologContext{lgr: c.lgr, ctx: c.ctx.Bool(key, val)}
+}
+
+func (c *zerologContext) Time(key string, val time.Time) Context {
+	return &zerologContext{lgr: c.lgr, ctx: c.ctx.Time(key, val)}
+}
+
+func (c *zerologContext) Dur(key string, val time.Duration) Context {
+	return &zerologContext{lgr: c.lgr, ctx: c.ctx.Dur(key, val)}
+}
+
+func (c *zerologContext) Interface(key string, val interface{}) Context {
+	return &zerologContext{lgr: c.lgr, ctx: c.ctx.Interface(key, val)}
+}
+
+func (c *zerologContext) Logger() Logger {
+	return c.lgr.with(c.ctx.Logger())
+}
+
 // Fatal logs a message with fatal level and exits the program.
 func (l *AppLogger) Fatal() Event {
 	return &zerologEvent{event: l.zLogger.Fatal()}
@@ -109,31 +276,47 @@ func (l *AppLogger) Fatal() Event {
 
 // Error logs a message with error level.
 func (l *AppLogger) Error() Event {
-	return &zerologEvent{event: l.zLogger.Error()}
+	return l.event(zerolog.ErrorLevel, l.zLogger.Error)
+}
+
+// Warn logs a message with warn level.
+func (l *AppLogger) Warn() Event {
+	return l.event(zerolog.WarnLevel, l.zLogger.Warn)
 }
 
 // Info logs a message with info level.
 func (l *AppLogger) Info() Event {
-	return &zerologEvent{event: l.zLogger.Info()}
+	return l.event(zerolog.InfoLevel, l.zLogger.Info)
 }
 
 // Debug logs a message with debug level.
 func (l *AppLogger) Debug() Event {
-	return &zerologEvent{event: l.zLogger.Debug()}
+	return l.event(zerolog.DebugLevel, l.zLogger.Debug)
+}
+
+// Trace logs a message with trace level.
+func (l *AppLogger) Trace() Event {
+	return l.event(zerolog.TraceLevel, l.zLogger.Trace)
 }
 
-// parseZerologLevel parses a string log level to zerolog.Level.
+// event starts an event at lvl, or a disabled one when the logger does not log at lvl.
+func (l *AppLogger) event(lvl zerolog.Level, start func() *zerolog.Event) Event {
+	if !l.enabled(lvl) {
+		// zerolog treats nil events as disabled
+		return &zerologEvent{}
+	}
+	return &zerologEvent{event: start()}
+}
+
+func (l *AppLogger) enabled(lvl zerolog.Level) bool {
+	return l.levels.enabled(l.component, lvl)
+}
+
+// parseZerologLevel parses a string log level to zerolog.Level, unknown levels fall back to info.
 func parseZerologLevel(level string) zerolog.Level {
-	switch strings.ToLower(level) {
-	case "debug":
-		return zerolog.DebugLevel
-	case "info":
-		return zerolog.InfoLevel
-	case "error":
-		return zerolog.ErrorLevel
-	case "fatal":
-		return zerolog.FatalLevel
-	default:
+	lvl, err := parseLevel(level)
+	if err != nil {
 		return zerolog.InfoLevel
 	}
+	return lvl
 }
@@ -2,15 +2,19 @@ package logger_test
 
 import (
 	"bytes"
+	"context"
 	"errors"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/rameshsunkara/go-rest-api-example/pkg/logger"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace"
 )
 
 func TestNew(t *testing.T) {
@@ -140,3 +144,79 @@ func TestWithReqIDCustomBasic(t *testing.T) {
 	// Without proper context setup, reqID will be empty, but method doesn't panic
 	assert.Empty(t, reqID)
 }
+
+func TestWithReqIDTraceIDs(t *testing.T) {
+	buf := &bytes.Buffer{}
+	log := logger.New("info", buf)
+
+	gin.SetMode(gin.TestMode)
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	sc := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    trace.TraceID{0x4b, 0xf9, 0x2f, 0x35},
+		SpanID:     trace.SpanID{0x00, 0xf0, 0x67, 0xaa},
+		TraceFlags: trace.FlagsSampled,
+	})
+	ctx := trace.ContextWithSpanContext(context.Background(), sc)
+	c.Request = httptest.NewRequest(http.MethodGet, "/test", nil).WithContext(ctx)
+
+	l, _ := log.WithReqID(c)
+	l.Info().Msg("traced")
+	output := buf.String()
+	assert.Contains(t, output, `"trace_id":"`+sc.TraceID().String()+`"`)
+	assert.Contains(t, output, `"span_id":"`+sc.SpanID().String()+`"`)
+}
+
+func TestWarnAndTraceLevels(t *testing.T) {
+	buf := &bytes.Buffer{}
+	log := logger.New("warn", buf)
+
+	log.Info().Msg("info message")
+	log.Warn().Msg("warn message")
+	assert.NotContains(t, buf.String(), "info message")
+	assert.Contains(t, buf.String(), `"level":"warn"`)
+
+	buf.Reset()
+	log = logger.New("trace", buf)
+	log.Trace().Msg("trace message")
+	assert.Contains(t, buf.String(), `"level":"trace"`)
+}
+
+func TestTypedFields(t *testing.T) {
+	buf := &bytes.Buffer{}
+	log := logger.New("info", buf)
+
+	at := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	log.Info().
+		Bool("ok", true).
+		Int64("offset", -7).
+		Uint64("maxBytes", 1<<40).
+		Float64("ratio", 0.25).
+		Time("at", at).
+		Strs("skus", []string{"MUG-1", "CUP-2"}).
+		Dict("order", func(d logger.Event) { d.Str("id", "o-1").Int("items", 2) }).
+		Msg("test")
+	output := buf.String()
+	assert.Contains(t, output, `"ok":true`)
+	assert.Contains(t, output, `"offset":-7`)
+	assert.Contains(t, output, `"maxBytes":1099511627776`)
+	assert.Contains(t, output, `"ratio":0.25`)
+	assert.Contains(t, output, `"at":"2026-01-02T03:04:05Z"`)
+	assert.Contains(t, output, `"skus":["MUG-1","CUP-2"]`)
+	assert.Contains(t, output, `"order":{"id":"o-1","items":2}`)
+}
+
+func TestWith(t *testing.T) {
+	buf := &bytes.Buffer{}
+	log := logger.New("info", buf)
+
+	child := log.With().Str("job", "purger").Int("batch", 100).Logger()
+	child.Info().Msg("first")
+	child.Debug().Msg("filtered")
+	log.Info().Msg("parent")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 2)
+	assert.Contains(t, lines[0], `"job":"purger"`)
+	assert.Contains(t, lines[0], `"batch":100`)
+	assert.NotContains(t, lines[1], "purger")
+}
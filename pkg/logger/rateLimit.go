@@ -0,0 +1,253 @@
+package logger
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog"
+)
+
+// RateLimit lets Burst identical messages through per Window, the rest are suppressed and counted in a
+// "suppressed N messages" warning once the window closes. A zero Burst does not limit messages, neither are
+// events without a message, e.g. the request log lines, which are sampled instead.
+type RateLimit struct {
+	Burst  int
+	Window time.Duration
+}
+
+// NewRateLimited returns a logger logging through lgr that limits identical messages, same level and message,
+// to limit. Request loggers derived with WithReqID use the limit of the route of the request in routes, keyed
+// by route template, and share it with the other requests of the route.
+func NewRateLimited(lgr Logger, limit RateLimit, routes map[string]RateLimit) Logger {
+	limiters := &routeLimiters{def: newLimiter(lgr, limit, ""), routes: make(map[string]*limiter, len(routes))}
+	for route, rl := range routes {
+		limiters.routes[route] = newLimiter(lgr, rl, route)
+	}
+	return &rateLimitedLogger{lgr: lgr, limiter: limiters.def, limiters: limiters}
+}
+
+type routeLimiters struct {
+	def    *limiter
+	routes map[string]*limiter
+}
+
+func (r *routeLimiters) forRoute(route string) *limiter {
+	if l, ok := r.routes[route]; ok {
+		return l
+	}
+	return r.def
+}
+
+// maxWindows caps the messages a limiter tracks, messages carry request data so clients can make them unique.
+// Messages beyond it are not limited until expired windows are swept.
+const maxWindows = 10000
+
+// limiter counts the identical messages of a window.
+type limiter struct {
+	report Logger // logs the summaries
+	limit  RateLimit
+	route  string
+
+	mu        sync.Mutex
+	windows   map[string]*window // level and message -> its current window
+	lastSweep time.Time
+}
+
+type window struct {
+	start      time.Time
+	count      int
+	suppressed int
+}
+
+func newLimiter(report Logger, limit RateLimit, route string) *limiter {
+	return &limiter{report: report, limit: limit, route: route, windows: map[string]*window{}}
+}
+
+// allow reports whether the message is logged, the first suppressed one of a window schedules its summary.
+func (l *limiter) allow(level, msg string) bool {
+	if msg == "" || l.limit.Burst <= 0 || l.limit.Window <= 0 {
+		return true
+	}
+	key := level + "\x00" + msg
+	now := time.Now()
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	w, ok := l.windows[key]
+	if !ok || now.Sub(w.start) >= l.limit.Window {
+		if !ok {
+			l.sweep(now)
+			if len(l.windows) >= maxWindows {
+				return true
+			}
+		}
+		w = &window{start: now}
+		l.windows[key] = w
+	}
+	w.count++
+	if w.count <= l.limit.Burst {
+		return true
+	}
+	if w.suppressed == 0 {
+		time.AfterFunc(w.start.Add(l.limit.Window).Sub(now), func() { l.summarize(key, w, level, msg) })
+	}
+	w.suppressed++
+	return false
+}
+
+// sweep drops the expired windows at most once per window, the ones with suppressed messages are dropped
+// by their summary.
+func (l *limiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < l.limit.Window {
+		return
+	}
+	l.lastSweep = now
+	for key, w := range l.windows {
+		if w.suppressed == 0 && now.Sub(w.start) >= l.limit.Window {
+			delete(l.windows, key)
+		}
+	}
+}
+
+func (l *limiter) summarize(key string, w *window, level, msg string) {
+	l.mu.Lock()
+	suppressed := w.suppressed
+	if l.windows[key] == w {
+		delete(l.windows, key)
+	}
+	l.mu.Unlock()
+
+	e := l.report.Warn().
+		Str("suppressedLevel", level).
+		Str("suppressedMessage", msg).
+		Int("suppressed", suppressed).
+		Dur("window", l.limit.Window)
+	if l.route != "" {
+		e = e.Str("route", l.route)
+	}
+	e.Msg(fmt.Sprintf("suppressed %d messages", suppressed))
+}
+
+// rateLimitedLogger wraps the events of lgr so their messages go through limiter, fatal events are never limited.
+type rateLimitedLogger struct {
+	lgr      Logger
+	limiter  *limiter
+	limiters *routeLimiters
+}
+
+func (l *rateLimitedLogger) wrap(lgr Logger, lim *limiter) Logger {
+	return &rateLimitedLogger{lgr: lgr, limiter: lim, limiters: l.limiters}
+}
+
+func (l *rateLimitedLogger) event(e Event, level string) Event {
+	return &limitedEvent{event: e, level: level, limiter: l.limiter}
+}
+
+func (l *rateLimitedLogger) Trace() Event { return l.event(l.lgr.Trace(), "trace") }
+func (l *rateLimitedLogger) Debug() Event { return l.event(l.lgr.Debug(), "debug") }
+func (l *rateLimitedLogger) Info() Event  { return l.event(l.lgr.Info(), "info") }
+func (l *rateLimitedLogger) Warn() Event  { return l.event(l.lgr.Warn(), "warn") }
+func (l *rateLimitedLogger) Error() Event { return l.event(l.lgr.Error(), "error") }
+func (l *rateLimitedLogger) Fatal() Event { return l.lgr.Fatal() }
+
+func (l *rateLimitedLogger) With() Context {
+	return &limitedContext{ctx: l.lgr.With(), lgr: l}
+}
+
+func (l *rateLimitedLogger) WithReqID(ctx *gin.Context) (Logger, string) {
+	return l.WithReqIDCustom(ctx, DefaultRequestIDKey)
+}
+
+// WithReqIDCustom returns the request logger limited by the limit of the route of the request.
+func (l *rateLimitedLogger) WithReqIDCustom(ctx *gin.Context, identifier string) (Logger, string) {
+	lgr, reqID := l.lgr.WithReqIDCustom(ctx, identifier)
+	return l.wrap(lgr, l.limiters.forRoute(ctx.FullPath())), reqID
+}
+
+func (l *rateLimitedLogger) Named(component string) Logger {
+	return l.wrap(l.lgr.Named(component), l.limiter)
+}
+
+func (l *rateLimitedLogger) Levels() *Levels {
+	return l.lgr.Levels()
+}
+
+// enabled forwards to lgr so a SlogHandler on the limited logger skips the records lgr filters out.
+func (l *rateLimitedLogger) enabled(lvl zerolog.Level) bool {
+	if e, ok := l.lgr.(levelEnabler); ok {
+		return e.enabled(lvl)
+	}
+	return true
+}
+
+// limitedEvent holds back the event until its message is known.
+type limitedEvent struct {
+	event   Event
+	level   string
+	limiter *limiter
+}
+
+func (e *limitedEvent) set(event Event) Event {
+	e.event = event
+	return e
+}
+
+func (e *limitedEvent) Str(key, val string) Event            { return e.set(e.event.Str(key, val)) }
+func (e *limitedEvent) Strs(key string, vals []string) Event { return e.set(e.event.Strs(key, vals)) }
+func (e *limitedEvent) Int(key string, val int) Event        { return e.set(e.event.Int(key, val)) }
+func (e *limitedEvent) Int64(key string, val int64) Event    { return e.set(e.event.Int64(key, val)) }
+func (e *limitedEvent) Uint64(key string, val uint64) Event  { return e.set(e.event.Uint64(key, val)) }
+func (e *limitedEvent) Float64(key string, val float64) Event {
+	return e.set(e.event.Float64(key, val))
+}
+func (e *limitedEvent) Bool(key string, val bool) Event         { return e.set(e.event.Bool(key, val)) }
+func (e *limitedEvent) Time(key string, val time.Time) Event    { return e.set(e.event.Time(key, val)) }
+func (e *limitedEvent) Dur(key string, val time.Duration) Event { return e.set(e.event.Dur(key, val)) }
+func (e *limitedEvent) Dict(key string, f func(d Event)) Event  { return e.set(e.event.Dict(key, f)) }
+func (e *limitedEvent) Err(err error) Event                     { return e.set(e.event.Err(err)) }
+func (e *limitedEvent) Interface(key string, val interface{}) Event {
+	return e.set(e.event.Interface(key, val))
+}
+
+func (e *limitedEvent) Msg(msg string) {
+	if e.limiter.allow(e.level, msg) {
+		e.event.Msg(msg)
+	}
+}
+
+func (e *limitedEvent) Send() {
+	e.Msg("")
+}
+
+// limitedContext builds a child logger limited like lgr.
+type limitedContext struct {
+	ctx Context
+	lgr *rateLimitedLogger
+}
+
+func (c *limitedContext) set(ctx Context) Context {
+	return &limitedContext{ctx: ctx, lgr: c.lgr}
+}
+
+func (c *limitedContext) Str(key, val string) Context            { return c.set(c.ctx.Str(key, val)) }
+func (c *limitedContext) Strs(key string, vals []string) Context { return c.set(c.ctx.Strs(key, vals)) }
+func (c *limitedContext) Int(key string, val int) Context        { return c.set(c.ctx.Int(key, val)) }
+func (c *limitedContext) Int64(key string, val int64) Context    { return c.set(c.ctx.Int64(key, val)) }
+func (c *limitedContext) Uint64(key string, val uint64) Context  { return c.set(c.ctx.Uint64(key, val)) }
+func (c *limitedContext) Float64(key string, val float64) Context {
+	return c.set(c.ctx.Float64(key, val))
+}
+func (c *limitedContext) Bool(key string, val bool) Context      { return c.set(c.ctx.Bool(key, val)) }
+func (c *limitedContext) Time(key string, val time.Time) Context { return c.set(c.ctx.Time(key, val)) }
+func (c *limitedContext) Dur(key string, val time.Duration) Context {
+	return c.set(c.ctx.Dur(key, val))
+}
+
+func (c *limitedContext) Interface(key string, val interface{}) Context {
+	return c.set(c.ctx.Interface(key, val))
+}
+
+func (c *limitedContext) Logger() Logger {
+	return c.lgr.wrap(c.ctx.Logger(), c.lgr.limiter)
+}
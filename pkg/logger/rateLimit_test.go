@@ -0,0 +1,103 @@
+package logger_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rameshsunkara/go-rest-api-example/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRateLimited(t *testing.T) {
+	t.Parallel()
+	rec := logger.NewRecorder()
+	window := 100 * time.Millisecond
+	lgr := logger.NewRateLimited(rec, logger.RateLimit{Burst: 2, Window: window}, map[string]logger.RateLimit{
+		"/orders/:id": {Burst: 1, Window: window},
+	})
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/orders/:id", func(c *gin.Context) {
+		l, _ := lgr.WithReqID(c)
+		l.Warn().Msg("order not found")
+		c.Status(http.StatusNotFound)
+	})
+
+	named := lgr.Named("mongodb").With().Str("db", "ecommerce").Logger()
+	for range 5 {
+		named.Warn().Msg("retrying")
+		named.Info().Int("attempt", 1).Send()
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/orders/1", nil))
+	}
+	named.Error().Msg("retrying")
+
+	messages := map[string]int{}
+	for _, e := range rec.Entries() {
+		messages[e.Level+" "+e.Message]++
+	}
+	assert.Equal(t, map[string]int{"warn retrying": 2, "info ": 5, "warn order not found": 1, "error retrying": 1},
+		messages)
+
+	summaries := func() []logger.Entry {
+		var s []logger.Entry
+		for _, e := range rec.Entries() {
+			if _, ok := e.Fields["suppressed"]; ok {
+				s = append(s, e)
+			}
+		}
+		return s
+	}
+	require.Eventually(t, func() bool { return len(summaries()) == 2 }, time.Second, 10*time.Millisecond)
+	for _, s := range summaries() {
+		assert.Equal(t, "warn", s.Level)
+		if s.Fields["route"] == "/orders/:id" {
+			assert.Equal(t, "suppressed 4 messages", s.Message)
+			assert.Equal(t, "order not found", s.Fields["suppressedMessage"])
+			continue
+		}
+		assert.Equal(t, "suppressed 3 messages", s.Message)
+		assert.Equal(t, "retrying", s.Fields["suppressedMessage"])
+		assert.Equal(t, "warn", s.Fields["suppressedLevel"])
+	}
+
+	// the next window logs the message again
+	named.Warn().Msg("retrying")
+	assert.Equal(t, "retrying", rec.Entries()[len(rec.Entries())-1].Message)
+}
+
+func TestRateLimitedWithoutBurst(t *testing.T) {
+	t.Parallel()
+	rec := logger.NewRecorder()
+	lgr := logger.NewRateLimited(rec, logger.RateLimit{}, nil)
+
+	for range 10 {
+		lgr.Info().Msg("same")
+	}
+	assert.Len(t, rec.Entries(), 10)
+	assert.Same(t, rec.Levels(), lgr.Levels())
+}
+
+func TestRateLimitedBoundsTrackedMessages(t *testing.T) {
+	t.Parallel()
+	rec := logger.NewRecorder()
+	window := 50 * time.Millisecond
+	lgr := logger.NewRateLimited(rec, logger.RateLimit{Burst: 1, Window: window}, nil)
+
+	distinct := logger.MaxWindows + 100
+	for i := range distinct {
+		lgr.Warn().Msg(fmt.Sprintf("coupon C-%d not found", i))
+	}
+	assert.LessOrEqual(t, logger.Windows(lgr), logger.MaxWindows)
+	// untracked messages are let through
+	assert.Len(t, rec.Entries(), distinct)
+
+	time.Sleep(window)
+	lgr.Warn().Msg("coupon C-new not found")
+	assert.Equal(t, 1, logger.Windows(lgr))
+}
@@ -0,0 +1,173 @@
+package logger
+
+import (
+	"maps"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog"
+)
+
+// Entry is an event logged through a Recorder, Fields holds the values as they were passed to the event.
+type Entry struct {
+	Level   string
+	Message string
+	Fields  map[string]any
+}
+
+// Recorder is a Logger keeping its events in memory so tests can assert on them. It filters events through
+// its Levels like AppLogger does, and records fatal events without exiting.
+type Recorder struct {
+	log       *recording // shared by the loggers derived from the recorder
+	fields    map[string]any
+	levels    *Levels
+	component string
+}
+
+type recording struct {
+	mu      sync.Mutex
+	entries []Entry
+}
+
+// NewRecorder returns a Recorder recording every level.
+func NewRecorder() *Recorder {
+	return &Recorder{
+		log:    &recording{},
+		levels: newLevels(&levelSet{global: zerolog.TraceLevel}),
+	}
+}
+
+// Entries returns the events recorded by the recorder and the loggers derived from it, in logging order.
+func (r *Recorder) Entries() []Entry {
+	r.log.mu.Lock()
+	defer r.log.mu.Unlock()
+	return append([]Entry(nil), r.log.entries...)
+}
+
+func (r *Recorder) Trace() Event { return r.event(zerolog.TraceLevel) }
+func (r *Recorder) Debug() Event { return r.event(zerolog.DebugLevel) }
+func (r *Recorder) Info() Event  { return r.event(zerolog.InfoLevel) }
+func (r *Recorder) Warn() Event  { return r.event(zerolog.WarnLevel) }
+func (r *Recorder) Error() Event { return r.event(zerolog.ErrorLevel) }
+func (r *Recorder) Fatal() Event { return r.event(zerolog.FatalLevel) }
+
+func (r *Recorder) event(lvl zerolog.Level) Event {
+	if !r.enabled(lvl) {
+		return &recordedEvent{}
+	}
+	return &recordedEvent{log: r.log, level: lvl.String(), fields: maps.Clone(r.fields)}
+}
+
+func (r *Recorder) enabled(lvl zerolog.Level) bool {
+	return r.levels.enabled(r.component, lvl)
+}
+
+// With returns a builder of a child recorder with persistent fields.
+func (r *Recorder) With() Context {
+	return &recordedContext{rec: r.child(r.component, nil)}
+}
+
+func (r *Recorder) WithReqID(ctx *gin.Context) (Logger, string) {
+	return r.WithReqIDCustom(ctx, DefaultRequestIDKey)
+}
+
+func (r *Recorder) WithReqIDCustom(ctx *gin.Context, identifier string) (Logger, string) {
+	return withRequest(r, ctx, identifier)
+}
+
+// Named returns a child recorder of the component, see AppLogger.Named.
+func (r *Recorder) Named(component string) Logger {
+	component = childComponent(r.component, component)
+	return r.child(component, map[string]any{ComponentKey: component})
+}
+
+// Levels returns the levels shared by the recorder and the loggers derived from it.
+func (r *Recorder) Levels() *Levels {
+	return r.levels
+}
+
+func (r *Recorder) child(component string, fields map[string]any) *Recorder {
+	child := &Recorder{log: r.log, fields: maps.Clone(r.fields), levels: r.levels, component: component}
+	if child.fields == nil {
+		child.fields = map[string]any{}
+	}
+	maps.Copy(child.fields, fields)
+	return child
+}
+
+// recordedEvent collects the fields of an event, events without a log are disabled or dictionaries.
+type recordedEvent struct {
+	log    *recording
+	level  string
+	fields map[string]any
+}
+
+func (e *recordedEvent) set(key string, val any) Event {
+	if e.fields == nil {
+		e.fields = map[string]any{}
+	}
+	e.fields[key] = val
+	return e
+}
+
+func (e *recordedEvent) Str(key, val string) Event                   { return e.set(key, val) }
+func (e *recordedEvent) Strs(key string, vals []string) Event        { return e.set(key, vals) }
+func (e *recordedEvent) Int(key string, val int) Event               { return e.set(key, val) }
+func (e *recordedEvent) Int64(key string, val int64) Event           { return e.set(key, val) }
+func (e *recordedEvent) Uint64(key string, val uint64) Event         { return e.set(key, val) }
+func (e *recordedEvent) Float64(key string, val float64) Event       { return e.set(key, val) }
+func (e *recordedEvent) Bool(key string, val bool) Event             { return e.set(key, val) }
+func (e *recordedEvent) Time(key string, val time.Time) Event        { return e.set(key, val) }
+func (e *recordedEvent) Dur(key string, val time.Duration) Event     { return e.set(key, val) }
+func (e *recordedEvent) Interface(key string, val interface{}) Event { return e.set(key, val) }
+
+func (e *recordedEvent) Dict(key string, fields func(d Event)) Event {
+	dict := &recordedEvent{fields: map[string]any{}}
+	fields(dict)
+	return e.set(key, dict.fields)
+}
+
+func (e *recordedEvent) Err(err error) Event {
+	if err == nil {
+		return e
+	}
+	return e.set(zerolog.ErrorFieldName, err)
+}
+
+func (e *recordedEvent) Msg(msg string) {
+	if e.log == nil {
+		return
+	}
+	e.log.mu.Lock()
+	defer e.log.mu.Unlock()
+	e.log.entries = append(e.log.entries, Entry{Level: e.level, Message: msg, Fields: e.fields})
+}
+
+func (e *recordedEvent) Send() {
+	e.Msg("")
+}
+
+// recordedContext collects the persistent fields of a child recorder.
+type recordedContext struct {
+	rec *Recorder
+}
+
+func (c *recordedContext) set(key string, val any) Context {
+	return &recordedContext{rec: c.rec.child(c.rec.component, map[string]any{key: val})}
+}
+
+func (c *recordedContext) Str(key, val string) Context                   { return c.set(key, val) }
+func (c *recordedContext) Strs(key string, vals []string) Context        { return c.set(key, vals) }
+func (c *recordedContext) Int(key string, val int) Context               { return c.set(key, val) }
+func (c *recordedContext) Int64(key string, val int64) Context           { return c.set(key, val) }
+func (c *recordedContext) Uint64(key string, val uint64) Context         { return c.set(key, val) }
+func (c *recordedContext) Float64(key string, val float64) Context       { return c.set(key, val) }
+func (c *recordedContext) Bool(key string, val bool) Context             { return c.set(key, val) }
+func (c *recordedContext) Time(key string, val time.Time) Context        { return c.set(key, val) }
+func (c *recordedContext) Dur(key string, val time.Duration) Context     { return c.set(key, val) }
+func (c *recordedContext) Interface(key string, val interface{}) Context { return c.set(key, val) }
+
+func (c *recordedContext) Logger() Logger {
+	return c.rec
+}
@@ -0,0 +1,79 @@
+package logger_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rameshsunkara/go-rest-api-example/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestRecorder(t *testing.T) {
+	rec := logger.NewRecorder()
+	var lgr logger.Logger = rec
+
+	failure := errors.New("boom")
+	lgr.Trace().Str("step", "start").Send()
+	lgr.Named("mongodb").With().Str("db", "ecommerce").Logger().
+		Warn().
+		Err(failure).
+		Uint64("maxBytes", 10).
+		Dict("order", func(d logger.Event) { d.Str("id", "o-1") }).
+		Msg("slow command")
+
+	entries := rec.Entries()
+	require.Len(t, entries, 2)
+	assert.Equal(t, logger.Entry{Level: "trace", Fields: map[string]any{"step": "start"}}, entries[0])
+	assert.Equal(t, logger.Entry{
+		Level:   "warn",
+		Message: "slow command",
+		Fields: map[string]any{
+			logger.ComponentKey: "mongodb",
+			"db":                "ecommerce",
+			"error":             failure,
+			"maxBytes":          uint64(10),
+			"order":             map[string]any{"id": "o-1"},
+		},
+	}, entries[1])
+}
+
+func TestRecorderLevels(t *testing.T) {
+	rec := logger.NewRecorder()
+	require.NoError(t, rec.Levels().Set("info", map[string]string{"http": "error"}, 0))
+
+	rec.Debug().Msg("debug")
+	rec.Info().Msg("info")
+	rec.Named("http").Info().Msg("http info")
+	rec.Fatal().Msg("fatal")
+
+	entries := rec.Entries()
+	require.Len(t, entries, 2)
+	assert.Equal(t, "info", entries[0].Message)
+	assert.Equal(t, "fatal", entries[1].Level)
+}
+
+func TestRecorderWithReqID(t *testing.T) {
+	rec := logger.NewRecorder()
+
+	gin.SetMode(gin.TestMode)
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	sc := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID: trace.TraceID{0x4b, 0xf9, 0x2f, 0x35},
+		SpanID:  trace.SpanID{0x00, 0xf0, 0x67, 0xaa},
+	})
+	ctx := trace.ContextWithSpanContext(context.Background(), sc)
+	c.Request = httptest.NewRequest(http.MethodGet, "/test", nil).WithContext(ctx)
+
+	l, _ := rec.WithReqID(c)
+	l.Info().Msg("traced")
+	entries := rec.Entries()
+	require.Len(t, entries, 1)
+	assert.Equal(t, sc.TraceID().String(), entries[0].Fields[logger.TraceIDKey])
+	assert.Equal(t, sc.SpanID().String(), entries[0].Fields[logger.SpanIDKey])
+}
@@ -0,0 +1,169 @@
+package logger
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// SlogHandler is a slog.Handler logging through a Logger, libraries logging with log/slog share the format
+// and the levels of the service logger once it is installed with slog.SetDefault.
+type SlogHandler struct {
+	lgr    Logger
+	prefix string // dotted path of the groups opened with WithGroup, keys of later attributes are prefixed with it
+}
+
+// NewSlogHandler returns a slog.Handler logging through lgr. Groups are flattened into dotted keys.
+func NewSlogHandler(lgr Logger) *SlogHandler {
+	return &SlogHandler{lgr: lgr}
+}
+
+// levelEnabler is implemented by the loggers of this package, it lets Enabled skip records they filter out.
+type levelEnabler interface {
+	enabled(lvl zerolog.Level) bool
+}
+
+// Enabled reports whether the logger logs records of level.
+func (h *SlogHandler) Enabled(_ context.Context, level slog.Level) bool {
+	if e, ok := h.lgr.(levelEnabler); ok {
+		return e.enabled(zerologLevel(level))
+	}
+	return true
+}
+
+// Handle logs the record, its time and source are replaced by the ones of the logger.
+func (h *SlogHandler) Handle(_ context.Context, r slog.Record) error {
+	e := h.event(r.Level)
+	r.Attrs(func(a slog.Attr) bool {
+		e = addAttr(e, h.prefix, a)
+		return true
+	})
+	e.Msg(r.Message)
+	return nil
+}
+
+func (h *SlogHandler) event(level slog.Level) Event {
+	switch zerologLevel(level) {
+	case zerolog.TraceLevel:
+		return h.lgr.Trace()
+	case zerolog.DebugLevel:
+		return h.lgr.Debug()
+	case zerolog.InfoLevel:
+		return h.lgr.Info()
+	case zerolog.WarnLevel:
+		return h.lgr.Warn()
+	case zerolog.ErrorLevel, zerolog.FatalLevel, zerolog.PanicLevel, zerolog.NoLevel, zerolog.Disabled:
+	}
+	return h.lgr.Error()
+}
+
+// WithAttrs returns a handler adding attrs to every record.
+func (h *SlogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return h
+	}
+	c := h.lgr.With()
+	for _, a := range attrs {
+		c = addAttr(c, h.prefix, a)
+	}
+	return &SlogHandler{lgr: c.Logger(), prefix: h.prefix}
+}
+
+// WithGroup returns a handler nesting the keys of later attributes under name.
+func (h *SlogHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	return &SlogHandler{lgr: h.lgr, prefix: h.prefix + name + "."}
+}
+
+// fields is implemented by Event and Context.
+type fields[T any] interface {
+	Str(key, val string) T
+	Int64(key string, val int64) T
+	Uint64(key string, val uint64) T
+	Float64(key string, val float64) T
+	Bool(key string, val bool) T
+	Time(key string, val time.Time) T
+	Dur(key string, val time.Duration) T
+	Interface(key string, val interface{}) T
+}
+
+// addAttr adds a to f under prefix, following the rules of slog.Handler: empty attributes are ignored and
+// the attributes of groups without a key are inlined.
+func addAttr[T fields[T]](f T, prefix string, a slog.Attr) T {
+	a.Value = a.Value.Resolve()
+	if a.Equal(slog.Attr{}) {
+		return f
+	}
+	key := prefix + a.Key
+	switch a.Value.Kind() {
+	case slog.KindGroup:
+		if a.Key != "" {
+			prefix = key + "."
+		}
+		for _, ga := range a.Value.Group() {
+			f = addAttr(f, prefix, ga)
+		}
+		return f
+	case slog.KindString:
+		return f.Str(key, a.Value.String())
+	case slog.KindInt64:
+		return f.Int64(key, a.Value.Int64())
+	case slog.KindUint64:
+		return f.Uint64(key, a.Value.Uint64())
+	case slog.KindFloat64:
+		return f.Float64(key, a.Value.Float64())
+	case slog.KindBool:
+		return f.Bool(key, a.Value.Bool())
+	case slog.KindTime:
+		return f.Time(key, a.Value.Time())
+	case slog.KindDuration:
+		return f.Dur(key, a.Value.Duration())
+	case slog.KindAny, slog.KindLogValuer:
+	}
+	if err, ok := a.Value.Any().(error); ok {
+		// errors marshal to {} as JSON
+		return f.Str(key, err.Error())
+	}
+	return f.Interface(key, a.Value.Any())
+}
+
+// zerologLevel maps slog levels to the closest zerolog level at or below them, slog has no fatal level.
+func zerologLevel(level slog.Level) zerolog.Level {
+	switch {
+	case level < slog.LevelDebug:
+		return zerolog.TraceLevel
+	case level < slog.LevelInfo:
+		return zerolog.DebugLevel
+	case level < slog.LevelWarn:
+		return zerolog.InfoLevel
+	case level < slog.LevelError:
+		return zerolog.WarnLevel
+	default:
+		return zerolog.ErrorLevel
+	}
+}
+
+// slogLevel maps zerolog levels to slog levels, trace and fatal sit 4 below debug and 4 above error.
+func slogLevel(lvl zerolog.Level) slog.Level {
+	switch lvl {
+	case zerolog.TraceLevel:
+		return slog.LevelDebug - 4
+	case zerolog.DebugLevel:
+		return slog.LevelDebug
+	case zerolog.InfoLevel:
+		return slog.LevelInfo
+	case zerolog.WarnLevel:
+		return slog.LevelWarn
+	case zerolog.ErrorLevel:
+		return slog.LevelError
+	case zerolog.FatalLevel, zerolog.PanicLevel, zerolog.NoLevel, zerolog.Disabled:
+	}
+	if lvl < zerolog.TraceLevel {
+		return slog.LevelDebug - 4
+	}
+	return slog.LevelError + 4
+}
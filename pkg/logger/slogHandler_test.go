@@ -0,0 +1,87 @@
+package logger_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/rameshsunkara/go-rest-api-example/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type userID string
+
+func (u userID) LogValue() slog.Value { return slog.StringValue("user-" + string(u)) }
+
+func TestSlogHandler(t *testing.T) {
+	rec := logger.NewRecorder()
+	slgr := slog.New(logger.NewSlogHandler(rec)).With("lib", "mongo").WithGroup("req")
+
+	failure := errors.New("boom")
+	slgr.Warn("retrying",
+		"attempt", 2,
+		"delay", time.Second,
+		"ok", false,
+		"user", userID("42"),
+		"err", failure,
+		slog.Group("pool", "size", uint64(10)),
+		slog.Group("", "inlined", 1.5),
+		slog.Attr{},
+	)
+
+	entries := rec.Entries()
+	require.Len(t, entries, 1)
+	assert.Equal(t, logger.Entry{
+		Level:   "warn",
+		Message: "retrying",
+		Fields: map[string]any{
+			"lib":           "mongo",
+			"req.attempt":   int64(2),
+			"req.delay":     time.Second,
+			"req.ok":        false,
+			"req.user":      "user-42",
+			"req.err":       "boom",
+			"req.pool.size": uint64(10),
+			"req.inlined":   1.5,
+		},
+	}, entries[0])
+}
+
+func TestSlogHandlerLevels(t *testing.T) {
+	rec := logger.NewRecorder()
+	require.NoError(t, rec.Levels().Set("info", map[string]string{"lib": "warn"}, 0))
+	h := logger.NewSlogHandler(rec.Named("lib"))
+
+	assert.False(t, h.Enabled(context.Background(), slog.LevelInfo))
+	assert.True(t, h.Enabled(context.Background(), slog.LevelWarn))
+
+	slgr := slog.New(h)
+	slgr.Info("dropped")
+	slgr.Log(context.Background(), slog.LevelError+4, "above error")
+	entries := rec.Entries()
+	require.Len(t, entries, 1)
+	assert.Equal(t, "error", entries[0].Level)
+}
+
+func TestSlogHandlerRateLimitedLevels(t *testing.T) {
+	rec := logger.NewRecorder()
+	require.NoError(t, rec.Levels().Set("warn", nil, 0))
+	h := logger.NewSlogHandler(logger.NewRateLimited(rec, logger.RateLimit{Burst: 1, Window: time.Minute}, nil))
+
+	assert.False(t, h.Enabled(context.Background(), slog.LevelInfo))
+	assert.True(t, h.Enabled(context.Background(), slog.LevelWarn))
+}
+
+func TestSlogHandlerJSON(t *testing.T) {
+	buf := &bytes.Buffer{}
+	slgr := slog.New(logger.NewSlogHandler(logger.New("debug", buf)))
+
+	slgr.Debug("from a library", "key", "value")
+	assert.Contains(t, buf.String(), `"level":"debug"`)
+	assert.Contains(t, buf.String(), `"key":"value"`)
+	assert.Contains(t, buf.String(), `"message":"from a library"`)
+}
@@ -0,0 +1,154 @@
+package logger
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog"
+)
+
+// SlogLogger is a Logger writing through a slog.Handler, for output in the format of the handler. Its levels
+// filter events before the handler does, fatal events exit the program once handled like AppLogger.
+type SlogLogger struct {
+	handler   slog.Handler
+	levels    *Levels
+	component string
+}
+
+// NewSlogLogger returns a logger writing through handler at levels.
+func NewSlogLogger(handler slog.Handler, levels *Levels) *SlogLogger {
+	return &SlogLogger{handler: handler, levels: levels}
+}
+
+func (l *SlogLogger) Trace() Event { return l.event(zerolog.TraceLevel) }
+func (l *SlogLogger) Debug() Event { return l.event(zerolog.DebugLevel) }
+func (l *SlogLogger) Info() Event  { return l.event(zerolog.InfoLevel) }
+func (l *SlogLogger) Warn() Event  { return l.event(zerolog.WarnLevel) }
+func (l *SlogLogger) Error() Event { return l.event(zerolog.ErrorLevel) }
+func (l *SlogLogger) Fatal() Event { return l.event(zerolog.FatalLevel) }
+
+func (l *SlogLogger) event(lvl zerolog.Level) Event {
+	if !l.enabled(lvl) {
+		return &slogEvent{}
+	}
+	return &slogEvent{handler: l.handler, level: lvl}
+}
+
+func (l *SlogLogger) enabled(lvl zerolog.Level) bool {
+	return l.levels.enabled(l.component, lvl) && l.handler.Enabled(context.Background(), slogLevel(lvl))
+}
+
+// With returns a builder of a child logger with persistent fields.
+func (l *SlogLogger) With() Context {
+	return &slogContext{lgr: l}
+}
+
+func (l *SlogLogger) WithReqID(ctx *gin.Context) (Logger, string) {
+	return l.WithReqIDCustom(ctx, DefaultRequestIDKey)
+}
+
+func (l *SlogLogger) WithReqIDCustom(ctx *gin.Context, identifier string) (Logger, string) {
+	return withRequest(l, ctx, identifier)
+}
+
+// Named returns a child logger of the component, see AppLogger.Named.
+func (l *SlogLogger) Named(component string) Logger {
+	component = childComponent(l.component, component)
+	return &SlogLogger{
+		handler:   l.handler.WithAttrs([]slog.Attr{slog.String(ComponentKey, component)}),
+		levels:    l.levels,
+		component: component,
+	}
+}
+
+// Levels returns the levels shared by the logger and the loggers derived from it.
+func (l *SlogLogger) Levels() *Levels {
+	return l.levels
+}
+
+// slogEvent collects the attributes of a record, events without a handler are disabled or dictionaries.
+type slogEvent struct {
+	handler slog.Handler
+	level   zerolog.Level
+	attrs   []slog.Attr
+}
+
+func (e *slogEvent) add(a slog.Attr) Event {
+	e.attrs = append(e.attrs, a)
+	return e
+}
+
+func (e *slogEvent) Str(key, val string) Event               { return e.add(slog.String(key, val)) }
+func (e *slogEvent) Strs(key string, vals []string) Event    { return e.add(slog.Any(key, vals)) }
+func (e *slogEvent) Int(key string, val int) Event           { return e.add(slog.Int(key, val)) }
+func (e *slogEvent) Int64(key string, val int64) Event       { return e.add(slog.Int64(key, val)) }
+func (e *slogEvent) Uint64(key string, val uint64) Event     { return e.add(slog.Uint64(key, val)) }
+func (e *slogEvent) Float64(key string, val float64) Event   { return e.add(slog.Float64(key, val)) }
+func (e *slogEvent) Bool(key string, val bool) Event         { return e.add(slog.Bool(key, val)) }
+func (e *slogEvent) Time(key string, val time.Time) Event    { return e.add(slog.Time(key, val)) }
+func (e *slogEvent) Dur(key string, val time.Duration) Event { return e.add(slog.Duration(key, val)) }
+
+func (e *slogEvent) Interface(key string, val interface{}) Event { return e.add(slog.Any(key, val)) }
+
+func (e *slogEvent) Dict(key string, fields func(d Event)) Event {
+	dict := &slogEvent{}
+	fields(dict)
+	return e.add(slog.Attr{Key: key, Value: slog.GroupValue(dict.attrs...)})
+}
+
+func (e *slogEvent) Err(err error) Event {
+	if err == nil {
+		return e
+	}
+	return e.add(slog.String(zerolog.ErrorFieldName, err.Error()))
+}
+
+func (e *slogEvent) Msg(msg string) {
+	if e.handler == nil {
+		return
+	}
+	r := slog.NewRecord(time.Now(), slogLevel(e.level), msg, 0)
+	r.AddAttrs(e.attrs...)
+	// a logger has nowhere to report its own failures
+	_ = e.handler.Handle(context.Background(), r)
+	if e.level == zerolog.FatalLevel {
+		os.Exit(1)
+	}
+}
+
+func (e *slogEvent) Send() {
+	e.Msg("")
+}
+
+// slogContext collects the attributes of a child logger.
+type slogContext struct {
+	lgr   *SlogLogger
+	attrs []slog.Attr
+}
+
+func (c *slogContext) add(a slog.Attr) Context {
+	return &slogContext{lgr: c.lgr, attrs: append(append([]slog.Attr(nil), c.attrs...), a)}
+}
+
+func (c *slogContext) Str(key, val string) Context             { return c.add(slog.String(key, val)) }
+func (c *slogContext) Strs(key string, vals []string) Context  { return c.add(slog.Any(key, vals)) }
+func (c *slogContext) Int(key string, val int) Context         { return c.add(slog.Int(key, val)) }
+func (c *slogContext) Int64(key string, val int64) Context     { return c.add(slog.Int64(key, val)) }
+func (c *slogContext) Uint64(key string, val uint64) Context   { return c.add(slog.Uint64(key, val)) }
+func (c *slogContext) Float64(key string, val float64) Context { return c.add(slog.Float64(key, val)) }
+func (c *slogContext) Bool(key string, val bool) Context       { return c.add(slog.Bool(key, val)) }
+func (c *slogContext) Time(key string, val time.Time) Context  { return c.add(slog.Time(key, val)) }
+func (c *slogContext) Dur(key string, val time.Duration) Context {
+	return c.add(slog.Duration(key, val))
+}
+
+func (c *slogContext) Interface(key string, val interface{}) Context {
+	return c.add(slog.Any(key, val))
+}
+
+func (c *slogContext) Logger() Logger {
+	return &SlogLogger{handler: c.lgr.handler.WithAttrs(c.attrs), levels: c.lgr.levels, component: c.lgr.component}
+}
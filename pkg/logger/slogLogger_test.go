@@ -0,0 +1,48 @@
+package logger_test
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"testing"
+
+	"github.com/rameshsunkara/go-rest-api-example/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSlogLogger(t *testing.T) {
+	buf := &bytes.Buffer{}
+	levels, err := logger.NewLevels("info", map[string]string{"jobs": "debug"})
+	require.NoError(t, err)
+	h := slog.NewTextHandler(buf, &slog.HandlerOptions{Level: slog.LevelDebug - 4})
+	var lgr logger.Logger = logger.NewSlogLogger(h, levels)
+
+	lgr.Debug().Msg("filtered by the levels")
+	lgr.Named("jobs").With().Str("job", "purger").Logger().
+		Debug().
+		Int("purged", 3).
+		Err(errors.New("boom")).
+		Dict("batch", func(d logger.Event) { d.Int64("size", 100) }).
+		Msg("purged orders")
+	lgr.Warn().Strs("skus", []string{"MUG-1"}).Send()
+
+	output := buf.String()
+	assert.NotContains(t, output, "filtered by the levels")
+	assert.Contains(t, output,
+		`level=DEBUG msg="purged orders" component=jobs job=purger purged=3 error=boom batch.size=100`)
+	assert.Contains(t, output, `level=WARN msg="" skus=[MUG-1]`)
+}
+
+func TestSlogLoggerHandlerLevel(t *testing.T) {
+	buf := &bytes.Buffer{}
+	levels, err := logger.NewLevels("trace", nil)
+	require.NoError(t, err)
+	// the handler filters below warn on its own
+	lgr := logger.NewSlogLogger(slog.NewJSONHandler(buf, &slog.HandlerOptions{Level: slog.LevelWarn}), levels)
+
+	lgr.Info().Msg("info")
+	lgr.Error().Msg("error")
+	assert.NotContains(t, buf.String(), `"msg":"info"`)
+	assert.Contains(t, buf.String(), `"level":"ERROR"`)
+}
@@ -0,0 +1,302 @@
+// Package money provides exact decimal arithmetic for monetary amounts and ISO-4217 currencies.
+//
+// An Amount is a fixed-point decimal holding Scale fractional digits, so sums and line totals
+// never drift the way float64 does (0.1 * 3 is exactly 0.3). Amounts are stored in MongoDB as
+// Decimal128 and serialized to JSON as strings. Rounding to a currency always rounds half away
+// from zero, e.g. 0.125 USD becomes 0.13 and -0.125 USD becomes -0.13.
+package money
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/x/bsonx/bsoncore"
+)
+
+const (
+	// Scale is the number of fractional digits held by an Amount, enough for every supported
+	// currency and for intermediate results such as percentages before they are rounded.
+	Scale = 4
+
+	scaleFactor = 10000
+)
+
+var (
+	ErrInvalidAmount = errors.New("invalid amount")
+	ErrTooPrecise    = fmt.Errorf("amount has more than %d fractional digits", Scale)
+	ErrOverflow      = errors.New("amount out of range")
+)
+
+// Amount is a decimal money value in units of 10^-Scale. The zero value is zero.
+// Amounts must stay within roughly ±922 trillion, Mul and Add check for overflow, the operators do not.
+type Amount int64
+
+// Parse parses a plain decimal string such as "12", "-0.5" or "19.99".
+func Parse(s string) (Amount, error) {
+	s = strings.TrimSpace(s)
+	neg := strings.HasPrefix(s, "-")
+	digits := strings.TrimLeft(s, "+-")
+	if len(s)-len(digits) > 1 {
+		return 0, ErrInvalidAmount
+	}
+	intPart, fracPart, _ := strings.Cut(digits, ".")
+	if (intPart == "" && fracPart == "") || !isDigits(intPart) || !isDigits(fracPart) {
+		return 0, ErrInvalidAmount
+	}
+	if len(fracPart) > Scale {
+		return 0, ErrTooPrecise
+	}
+	fracPart += strings.Repeat("0", Scale-len(fracPart))
+	v, err := strconv.ParseInt(intPart+fracPart, 10, 64)
+	if err != nil {
+		return 0, ErrOverflow
+	}
+	if neg {
+		v = -v
+	}
+	return Amount(v), nil
+}
+
+// MustParse is like Parse but panics on invalid input, it is meant for constants and tests.
+func MustParse(s string) Amount {
+	a, err := Parse(s)
+	if err != nil {
+		panic(fmt.Sprintf("money: MustParse(%q): %v", s, err))
+	}
+	return a
+}
+
+// FromMinor returns the amount for a number of minor units of c, e.g. FromMinor(1999, USD) is 19.99.
+func FromMinor(minor int64, c Currency) Amount {
+	return Amount(minor * pow10(Scale-c.Exponent()))
+}
+
+// FromFloat converts a float64, rounding to Scale digits. It exists to read legacy float data
+// and must not be used for arithmetic.
+func FromFloat(f float64) Amount {
+	return Amount(math.Round(f * scaleFactor))
+}
+
+// Mul returns the amount multiplied by a quantity, the result is exact. It fails with ErrOverflow when the
+// result is out of range, quantities come from clients.
+func (a Amount) Mul(quantity uint64) (Amount, error) {
+	if a == 0 || quantity == 0 {
+		return 0, nil
+	}
+	abs := uint64(a)
+	if a < 0 {
+		abs = uint64(-int64(a)) //nolint:gosec // two's complement negation is exact for MinInt64 too
+	}
+	if quantity > math.MaxInt64/abs {
+		return 0, ErrOverflow
+	}
+	return a * Amount(quantity), nil //nolint:gosec // quantity is at most MaxInt64 here
+}
+
+// Add returns the sum of the amounts, it fails with ErrOverflow when the sum is out of range.
+func (a Amount) Add(b Amount) (Amount, error) {
+	sum := a + b
+	if (b > 0 && sum < a) || (b < 0 && sum > a) {
+		return 0, ErrOverflow
+	}
+	return sum, nil
+}
+
+// Percent returns rate percent of the amount rounded half away from zero to Scale digits,
+// e.g. 200 at 8.25 percent is 16.5. Round the result to a currency before charging it.
+func (a Amount) Percent(rate Amount) Amount {
+	n := new(big.Int).Mul(big.NewInt(int64(a)), big.NewInt(int64(rate)))
+	return Amount(roundQuo(n, big.NewInt(100*scaleFactor)).Int64())
+}
+
+// Min returns the smaller of a and b.
+func Min(a, b Amount) Amount {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+// Round rounds the amount to the minor unit of c, half away from zero.
+func (a Amount) Round(c Currency) Amount {
+	exp := c.Exponent()
+	if exp >= Scale {
+		return a
+	}
+	step := pow10(Scale - exp)
+	return Amount(roundDiv(int64(a), step) * step)
+}
+
+// FitsCurrency reports whether the amount can be expressed in minor units of c without rounding.
+func (a Amount) FitsCurrency(c Currency) bool {
+	return a.Round(c) == a
+}
+
+// Float64 returns an approximation of the amount, meant for metrics and display only.
+func (a Amount) Float64() float64 {
+	return float64(a) / scaleFactor
+}
+
+// String returns the shortest exact decimal representation, e.g. "19.99", "20" or "-0.5".
+func (a Amount) String() string {
+	sign := ""
+	abs := uint64(a)
+	if a < 0 {
+		sign = "-"
+		abs = uint64(-int64(a)) //nolint:gosec // two's complement negation is exact for MinInt64 too
+	}
+	whole := strconv.FormatUint(abs/scaleFactor, 10)
+	frac := strings.TrimRight(fmt.Sprintf("%0*d", Scale, abs%scaleFactor), "0")
+	if frac == "" {
+		return sign + whole
+	}
+	return sign + whole + "." + frac
+}
+
+// MarshalJSON encodes the amount as a JSON string to keep it exact in every client.
+func (a Amount) MarshalJSON() ([]byte, error) {
+	return json.Marshal(a.String())
+}
+
+// UnmarshalJSON accepts a decimal string or, for backward compatibility, a plain JSON number.
+// The number is parsed from its literal text, it never goes through float64.
+func (a *Amount) UnmarshalJSON(b []byte) error {
+	s := string(b)
+	if s == "null" {
+		return nil
+	}
+	if strings.HasPrefix(s, `"`) {
+		if err := json.Unmarshal(b, &s); err != nil {
+			return err
+		}
+	}
+	v, err := Parse(s)
+	if err != nil {
+		return fmt.Errorf("%w: %s", err, s)
+	}
+	*a = v
+	return nil
+}
+
+// MarshalBSONValue stores the amount as Decimal128.
+func (a Amount) MarshalBSONValue() (bsontype.Type, []byte, error) {
+	d, err := primitive.ParseDecimal128(a.String())
+	if err != nil {
+		return 0, nil, err
+	}
+	return bson.MarshalValue(d)
+}
+
+// UnmarshalBSONValue reads Decimal128 values and, to support documents written before amounts
+// were decimal, doubles, integers and strings.
+func (a *Amount) UnmarshalBSONValue(t bsontype.Type, data []byte) error {
+	var ok bool
+	switch t {
+	case bsontype.Decimal128:
+		var d primitive.Decimal128
+		if d, _, ok = bsoncore.ReadDecimal128(data); ok {
+			return a.fromDecimal128(d)
+		}
+	case bsontype.Double:
+		var f float64
+		if f, _, ok = bsoncore.ReadDouble(data); ok {
+			*a = FromFloat(f)
+			return nil
+		}
+	case bsontype.Int32:
+		var i int32
+		if i, _, ok = bsoncore.ReadInt32(data); ok {
+			*a = Amount(int64(i) * scaleFactor)
+			return nil
+		}
+	case bsontype.Int64:
+		var i int64
+		if i, _, ok = bsoncore.ReadInt64(data); ok {
+			*a = Amount(i * scaleFactor)
+			return nil
+		}
+	case bsontype.String:
+		var s string
+		if s, _, ok = bsoncore.ReadString(data); ok {
+			v, err := Parse(s)
+			*a = v
+			return err
+		}
+	case bsontype.Null, bsontype.Undefined:
+		*a = 0
+		return nil
+	default:
+		return fmt.Errorf("%w: cannot decode BSON %s into an amount", ErrInvalidAmount, t)
+	}
+	return fmt.Errorf("%w: malformed BSON %s", ErrInvalidAmount, t)
+}
+
+func (a *Amount) fromDecimal128(d primitive.Decimal128) error {
+	coefficient, exp, err := d.BigInt()
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidAmount, err)
+	}
+	shift := exp + Scale
+	scaled := new(big.Int).Set(coefficient)
+	if shift >= 0 {
+		scaled.Mul(scaled, new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(shift)), nil))
+	} else {
+		// more digits than an Amount holds
+		scaled = roundQuo(scaled, new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(-shift)), nil))
+	}
+	if !scaled.IsInt64() {
+		return ErrOverflow
+	}
+	*a = Amount(scaled.Int64())
+	return nil
+}
+
+// roundQuo divides n by d (d > 0), rounding half away from zero.
+func roundQuo(n, d *big.Int) *big.Int {
+	q, r := new(big.Int).QuoRem(n, d, new(big.Int))
+	if r.Mul(r.Abs(r), big.NewInt(2)).Cmp(d) >= 0 {
+		q.Add(q, big.NewInt(int64(n.Sign())))
+	}
+	return q
+}
+
+// roundDiv divides v by d (d > 0), rounding half away from zero.
+func roundDiv(v, d int64) int64 {
+	q, r := v/d, v%d
+	if r < 0 {
+		r = -r
+	}
+	if 2*r >= d {
+		if v < 0 {
+			q--
+		} else {
+			q++
+		}
+	}
+	return q
+}
+
+func pow10(n int) int64 {
+	p := int64(1)
+	for range n {
+		p *= 10
+	}
+	return p
+}
+
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,210 @@
+package money_test
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+
+	"github.com/rameshsunkara/go-rest-api-example/pkg/money"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestParse(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		input   string
+		want    string
+		wantErr error
+	}{
+		{input: "12", want: "12"},
+		{input: "19.99", want: "19.99"},
+		{input: "-0.5", want: "-0.5"},
+		{input: "+3.1000", want: "3.1"},
+		{input: ".25", want: "0.25"},
+		{input: " 7 ", want: "7"},
+		{input: "0.00001", wantErr: money.ErrTooPrecise},
+		{input: "99999999999999999", wantErr: money.ErrOverflow},
+		{input: "", wantErr: money.ErrInvalidAmount},
+		{input: "-", wantErr: money.ErrInvalidAmount},
+		{input: "1e3", wantErr: money.ErrInvalidAmount},
+		{input: "--1", wantErr: money.ErrInvalidAmount},
+		{input: "1.2.3", wantErr: money.ErrInvalidAmount},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			t.Parallel()
+			got, err := money.Parse(tt.input)
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got.String())
+		})
+	}
+	assert.Panics(t, func() { money.MustParse("abc") })
+}
+
+func TestArithmetic(t *testing.T) {
+	t.Parallel()
+	product, err := money.MustParse("0.1").Mul(3)
+	require.NoError(t, err)
+	assert.Equal(t, "0.3", product.String())
+	assert.Equal(t, "0.3", (money.MustParse("0.1") + money.MustParse("0.2")).String())
+	assert.Equal(t, "19.99", money.FromMinor(1999, money.USD).String())
+	assert.Equal(t, "500", money.FromMinor(500, money.JPY).String())
+	assert.Equal(t, "1.234", money.FromMinor(1234, money.KWD).String())
+	assert.Equal(t, "0.3", money.FromFloat(0.1*3).String())
+	assert.Equal(t, "16.5", money.MustParse("200").Percent(money.MustParse("8.25")).String())
+	assert.Equal(t, "0.0001", money.MustParse("0.01").Percent(money.MustParse("0.5")).String())
+	assert.Equal(t, "-0.0001", money.MustParse("-0.01").Percent(money.MustParse("0.5")).String())
+	assert.Equal(t, "0", money.MustParse("0.01").Percent(money.MustParse("0.4")).String())
+	assert.Equal(t, money.MustParse("1"), money.Min(money.MustParse("1"), money.MustParse("2")))
+	assert.InDelta(t, 19.99, money.MustParse("19.99").Float64(), 1e-9)
+}
+
+func TestCheckedArithmetic(t *testing.T) {
+	t.Parallel()
+	maxAmount := money.Amount(math.MaxInt64)
+	tests := []struct {
+		name    string
+		got     func() (money.Amount, error)
+		want    money.Amount
+		wantErr error
+	}{
+		{name: "mul zero", got: func() (money.Amount, error) { return money.Amount(0).Mul(math.MaxUint64) }},
+		{name: "mul negative", got: func() (money.Amount, error) { return money.MustParse("-1.5").Mul(2) },
+			want: money.MustParse("-3")},
+		{name: "mul max", got: func() (money.Amount, error) { return money.Amount(1).Mul(math.MaxInt64) },
+			want: maxAmount},
+		{name: "mul overflow", got: func() (money.Amount, error) { return money.MustParse("10").Mul(math.MaxInt64) },
+			wantErr: money.ErrOverflow},
+		{name: "mul wraps negative", got: func() (money.Amount, error) { return money.Amount(2).Mul(1 << 62) },
+			wantErr: money.ErrOverflow},
+		{name: "mul negative overflow", got: func() (money.Amount, error) { return money.MustParse("-1").Mul(1 << 60) },
+			wantErr: money.ErrOverflow},
+		{name: "add", got: func() (money.Amount, error) { return money.MustParse("0.1").Add(money.MustParse("0.2")) },
+			want: money.MustParse("0.3")},
+		{name: "add overflow", got: func() (money.Amount, error) { return maxAmount.Add(1) },
+			wantErr: money.ErrOverflow},
+		{name: "add negative overflow", got: func() (money.Amount, error) { return (-maxAmount).Add(-2) },
+			wantErr: money.ErrOverflow},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, err := tt.got()
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestRound(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		amount   string
+		currency money.Currency
+		want     string
+	}{
+		{amount: "0.125", currency: money.USD, want: "0.13"},
+		{amount: "-0.125", currency: money.USD, want: "-0.13"},
+		{amount: "0.1249", currency: money.USD, want: "0.12"},
+		{amount: "10.5", currency: money.JPY, want: "11"},
+		{amount: "-10.4", currency: money.JPY, want: "-10"},
+		{amount: "1.2345", currency: money.KWD, want: "1.235"},
+		{amount: "1.2345", currency: money.Currency("XXX"), want: "1.2345"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.amount+string(tt.currency), func(t *testing.T) {
+			t.Parallel()
+			a := money.MustParse(tt.amount)
+			assert.Equal(t, tt.want, a.Round(tt.currency).String())
+			assert.Equal(t, tt.want == tt.amount, a.FitsCurrency(tt.currency))
+		})
+	}
+}
+
+func TestJSON(t *testing.T) {
+	t.Parallel()
+	type line struct {
+		Price money.Amount `json:"price"`
+	}
+	b, err := json.Marshal(line{Price: money.MustParse("10.50")})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"price":"10.5"}`, string(b))
+
+	var l line
+	require.NoError(t, json.Unmarshal([]byte(`{"price":"0.1"}`), &l))
+	assert.Equal(t, money.MustParse("0.1"), l.Price)
+	require.NoError(t, json.Unmarshal([]byte(`{"price":36.75}`), &l))
+	assert.Equal(t, money.MustParse("36.75"), l.Price)
+	require.NoError(t, json.Unmarshal([]byte(`{"price":null}`), &l))
+	assert.Equal(t, money.MustParse("36.75"), l.Price)
+	require.ErrorIs(t, json.Unmarshal([]byte(`{"price":"ten"}`), &l), money.ErrInvalidAmount)
+}
+
+func TestBSON(t *testing.T) {
+	t.Parallel()
+	type line struct {
+		Price money.Amount `bson:"price"`
+	}
+
+	raw, err := bson.Marshal(line{Price: money.MustParse("19.99")})
+	require.NoError(t, err)
+	stored := bson.Raw(raw).Lookup("price")
+	require.Equal(t, bson.TypeDecimal128, stored.Type)
+	assert.Equal(t, "19.99", stored.Decimal128().String())
+
+	var l line
+	require.NoError(t, bson.Unmarshal(raw, &l))
+	assert.Equal(t, money.MustParse("19.99"), l.Price)
+
+	// documents written before amounts were decimal
+	legacy := []struct {
+		name  string
+		value interface{}
+		want  string
+	}{
+		{name: "double", value: 0.30000000000000004, want: "0.3"},
+		{name: "int32", value: int32(5), want: "5"},
+		{name: "int64", value: int64(7), want: "7"},
+		{name: "string", value: "1.25", want: "1.25"},
+		{name: "null", value: nil, want: "0"},
+		{name: "exponent decimal", value: mustDecimal(t, "1.5E+3"), want: "1500"},
+		{name: "negative decimal", value: mustDecimal(t, "-2.00005"), want: "-2.0001"},
+	}
+	for _, tt := range legacy {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			doc, mErr := bson.Marshal(bson.M{"price": tt.value})
+			require.NoError(t, mErr)
+			var got line
+			require.NoError(t, bson.Unmarshal(doc, &got))
+			assert.Equal(t, tt.want, got.Price.String())
+		})
+	}
+
+	doc, err := bson.Marshal(bson.M{"price": mustDecimal(t, "2.000050")})
+	require.NoError(t, err)
+	require.NoError(t, bson.Unmarshal(doc, &l))
+	assert.Equal(t, "2.0001", l.Price.String())
+
+	doc, err = bson.Marshal(bson.M{"price": true})
+	require.NoError(t, err)
+	require.ErrorIs(t, bson.Unmarshal(doc, &l), money.ErrInvalidAmount)
+}
+
+func mustDecimal(t *testing.T, s string) primitive.Decimal128 {
+	t.Helper()
+	d, err := primitive.ParseDecimal128(s)
+	require.NoError(t, err)
+	return d
+}
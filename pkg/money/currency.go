@@ -0,0 +1,61 @@
+package money
+
+import (
+	"errors"
+	"strings"
+)
+
+// Currency is an ISO-4217 alphabetic currency code.
+type Currency string
+
+const (
+	USD Currency = "USD"
+	EUR Currency = "EUR"
+	GBP Currency = "GBP"
+	INR Currency = "INR"
+	CAD Currency = "CAD"
+	AUD Currency = "AUD"
+	CHF Currency = "CHF"
+	JPY Currency = "JPY"
+	KWD Currency = "KWD"
+)
+
+// ErrUnsupportedCurrency is returned for codes that are not ISO-4217 or not supported by this service.
+var ErrUnsupportedCurrency = errors.New("unsupported currency")
+
+// exponents holds the number of minor unit digits of every supported currency.
+var exponents = map[Currency]int{
+	USD: 2,
+	EUR: 2,
+	GBP: 2,
+	INR: 2,
+	CAD: 2,
+	AUD: 2,
+	CHF: 2,
+	JPY: 0,
+	KWD: 3,
+}
+
+// ParseCurrency returns the Currency for a case-insensitive ISO-4217 code.
+func ParseCurrency(code string) (Currency, error) {
+	c := Currency(strings.ToUpper(strings.TrimSpace(code)))
+	if !c.IsValid() {
+		return "", ErrUnsupportedCurrency
+	}
+	return c, nil
+}
+
+// IsValid reports whether c is a supported currency.
+func (c Currency) IsValid() bool {
+	_, ok := exponents[c]
+	return ok
+}
+
+// Exponent returns the number of minor unit digits of c, e.g. 2 for USD (cents) and 0 for JPY.
+// Unknown currencies use the full precision of an Amount.
+func (c Currency) Exponent() int {
+	if exp, ok := exponents[c]; ok {
+		return exp
+	}
+	return Scale
+}
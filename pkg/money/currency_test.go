@@ -0,0 +1,30 @@
+package money_test
+
+import (
+	"testing"
+
+	"github.com/rameshsunkara/go-rest-api-example/pkg/money"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseCurrency(t *testing.T) {
+	t.Parallel()
+	c, err := money.ParseCurrency(" eur ")
+	require.NoError(t, err)
+	assert.Equal(t, money.EUR, c)
+
+	_, err = money.ParseCurrency("XYZ")
+	require.ErrorIs(t, err, money.ErrUnsupportedCurrency)
+	_, err = money.ParseCurrency("")
+	require.ErrorIs(t, err, money.ErrUnsupportedCurrency)
+}
+
+func TestExponent(t *testing.T) {
+	t.Parallel()
+	assert.Equal(t, 2, money.USD.Exponent())
+	assert.Equal(t, 0, money.JPY.Exponent())
+	assert.Equal(t, 3, money.KWD.Exponent())
+	assert.Equal(t, money.Scale, money.Currency("XYZ").Exponent())
+	assert.False(t, money.Currency("usd").IsValid())
+}
@@ -0,0 +1,117 @@
+package mongodb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"go.mongodb.org/mongo-driver/event"
+)
+
+// clientMetrics records the latency and errors of commands per collection and operation, and the
+// connections of the pool per server address.
+type clientMetrics struct {
+	commandDuration  *prometheus.HistogramVec
+	commandErrors    *prometheus.CounterVec
+	openConns        *prometheus.GaugeVec
+	inUseConns       *prometheus.GaugeVec
+	checkoutFailures *prometheus.CounterVec
+	collections      sync.Map // request ID of the command -> collection it runs on
+}
+
+func newClientMetrics(reg prometheus.Registerer) (*clientMetrics, error) {
+	m := &clientMetrics{
+		commandDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "mongodb_command_duration_seconds",
+			Help:    "Time taken by MongoDB commands per collection and operation.",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"collection", "operation"}),
+		commandErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "mongodb_command_errors_total",
+			Help: "MongoDB commands that failed per collection and operation.",
+		}, []string{"collection", "operation"}),
+		openConns: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "mongodb_pool_open_connections",
+			Help: "Connections open in the pool of each server.",
+		}, []string{"address"}),
+		inUseConns: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "mongodb_pool_in_use_connections",
+			Help: "Connections checked out of the pool of each server.",
+		}, []string{"address"}),
+		checkoutFailures: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "mongodb_pool_checkout_failures_total",
+			Help: "Connections that could not be checked out of the pool per server and reason.",
+		}, []string{"address", "reason"}),
+	}
+	var err error
+	if m.commandDuration, err = register(reg, m.commandDuration); err != nil {
+		return nil, err
+	}
+	if m.commandErrors, err = register(reg, m.commandErrors); err != nil {
+		return nil, err
+	}
+	if m.openConns, err = register(reg, m.openConns); err != nil {
+		return nil, err
+	}
+	if m.inUseConns, err = register(reg, m.inUseConns); err != nil {
+		return nil, err
+	}
+	if m.checkoutFailures, err = register(reg, m.checkoutFailures); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// register registers c, or returns the collector registered by another client of the same registry.
+func register[T prometheus.Collector](reg prometheus.Registerer, c T) (T, error) {
+	err := reg.Register(c)
+	if err == nil {
+		return c, nil
+	}
+	var registered prometheus.AlreadyRegisteredError
+	if errors.As(err, &registered) {
+		if existing, ok := registered.ExistingCollector.(T); ok {
+			return existing, nil
+		}
+	}
+	return c, fmt.Errorf("registering MongoDB metrics: %w", err)
+}
+
+func (m *clientMetrics) started(_ context.Context, evt *event.CommandStartedEvent) {
+	m.collections.Store(evt.RequestID, commandCollection(evt))
+}
+
+func (m *clientMetrics) succeeded(_ context.Context, evt *event.CommandSucceededEvent) {
+	collection := m.collection(evt.RequestID)
+	m.commandDuration.WithLabelValues(collection, evt.CommandName).Observe(evt.Duration.Seconds())
+}
+
+func (m *clientMetrics) failed(_ context.Context, evt *event.CommandFailedEvent) {
+	collection := m.collection(evt.RequestID)
+	m.commandDuration.WithLabelValues(collection, evt.CommandName).Observe(evt.Duration.Seconds())
+	m.commandErrors.WithLabelValues(collection, evt.CommandName).Inc()
+}
+
+// collection removes the collection of a finished command, commands without one are labelled with "".
+func (m *clientMetrics) collection(requestID int64) string {
+	v, _ := m.collections.LoadAndDelete(requestID)
+	collection, _ := v.(string)
+	return collection
+}
+
+func (m *clientMetrics) poolEvent(evt *event.PoolEvent) {
+	switch evt.Type {
+	case event.ConnectionCreated:
+		m.openConns.WithLabelValues(evt.Address).Inc()
+	case event.ConnectionClosed:
+		m.openConns.WithLabelValues(evt.Address).Dec()
+	case event.GetSucceeded:
+		m.inUseConns.WithLabelValues(evt.Address).Inc()
+	case event.ConnectionReturned:
+		m.inUseConns.WithLabelValues(evt.Address).Dec()
+	case event.GetFailed:
+		m.checkoutFailures.WithLabelValues(evt.Address, evt.Reason).Inc()
+	}
+}
@@ -0,0 +1,96 @@
+package mongodb_test
+
+import (
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/rameshsunkara/go-rest-api-example/pkg/logger"
+	"github.com/rameshsunkara/go-rest-api-example/pkg/mongodb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/event"
+)
+
+func TestNewMonitors(t *testing.T) {
+	monitor, pool, err := mongodb.NewMonitors(&mongodb.MongoOptions{})
+	require.NoError(t, err)
+	assert.Nil(t, monitor)
+	assert.Nil(t, pool)
+
+	_, _, err = mongodb.NewMonitors(&mongodb.MongoOptions{QueryLogging: true})
+	require.ErrorIs(t, err, mongodb.ErrMissingLogger)
+
+	lgr := logger.New("info", io.Discard)
+	monitor, pool, err = mongodb.NewMonitors(&mongodb.MongoOptions{QueryLogging: true, Logger: lgr})
+	require.NoError(t, err)
+	assert.NotNil(t, monitor)
+	assert.Nil(t, pool)
+
+	// clients sharing a registry share the metrics
+	reg := prometheus.NewRegistry()
+	for range 2 {
+		monitor, pool, err = mongodb.NewMonitors(&mongodb.MongoOptions{MetricsRegisterer: reg})
+		require.NoError(t, err)
+		assert.NotNil(t, monitor)
+		assert.NotNil(t, pool)
+	}
+
+	// a collector of the same name with other labels is a conflict
+	conflicting := prometheus.NewCounter(prometheus.CounterOpts{Name: "mongodb_command_errors_total", Help: "."})
+	reg = prometheus.NewRegistry()
+	reg.MustRegister(conflicting)
+	_, _, err = mongodb.NewMonitors(&mongodb.MongoOptions{MetricsRegisterer: reg})
+	require.Error(t, err)
+}
+
+func TestNewMonitors_Metrics(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	monitor, pool, err := mongodb.NewMonitors(&mongodb.MongoOptions{MetricsRegisterer: reg})
+	require.NoError(t, err)
+
+	ctx := t.Context()
+	find, err := bson.Marshal(bson.D{{Key: "find", Value: "orders"}})
+	require.NoError(t, err)
+	insert, err := bson.Marshal(bson.D{{Key: "insert", Value: "orders"}})
+	require.NoError(t, err)
+	monitor.Started(ctx, &event.CommandStartedEvent{Command: find, CommandName: "find", RequestID: 1})
+	monitor.Started(ctx, &event.CommandStartedEvent{Command: insert, CommandName: "insert", RequestID: 2})
+	monitor.Succeeded(ctx, &event.CommandSucceededEvent{CommandFinishedEvent: event.CommandFinishedEvent{
+		CommandName: "find", RequestID: 1, Duration: 20 * time.Millisecond,
+	}})
+	monitor.Failed(ctx, &event.CommandFailedEvent{CommandFinishedEvent: event.CommandFinishedEvent{
+		CommandName: "insert", RequestID: 2, Duration: 5 * time.Millisecond,
+	}, Failure: "duplicate key"})
+
+	for _, typ := range []string{
+		event.ConnectionCreated, event.ConnectionCreated, event.ConnectionClosed,
+		event.GetSucceeded, event.GetSucceeded, event.ConnectionReturned,
+	} {
+		pool.Event(&event.PoolEvent{Type: typ, Address: "db:27017"})
+	}
+	pool.Event(&event.PoolEvent{Type: event.GetFailed, Address: "db:27017", Reason: event.ReasonTimedOut})
+
+	count, err := testutil.GatherAndCount(reg, "mongodb_command_duration_seconds")
+	require.NoError(t, err)
+	assert.Equal(t, 2, count)
+	require.NoError(t, testutil.GatherAndCompare(reg, strings.NewReader(`
+# HELP mongodb_command_errors_total MongoDB commands that failed per collection and operation.
+# TYPE mongodb_command_errors_total counter
+mongodb_command_errors_total{collection="orders",operation="insert"} 1
+# HELP mongodb_pool_checkout_failures_total Connections that could not be checked out of the pool per server and reason.
+# TYPE mongodb_pool_checkout_failures_total counter
+mongodb_pool_checkout_failures_total{address="db:27017",reason="timeout"} 1
+# HELP mongodb_pool_in_use_connections Connections checked out of the pool of each server.
+# TYPE mongodb_pool_in_use_connections gauge
+mongodb_pool_in_use_connections{address="db:27017"} 1
+# HELP mongodb_pool_open_connections Connections open in the pool of each server.
+# TYPE mongodb_pool_open_connections gauge
+mongodb_pool_open_connections{address="db:27017"} 1
+`), "mongodb_command_errors_total", "mongodb_pool_checkout_failures_total",
+		"mongodb_pool_in_use_connections", "mongodb_pool_open_connections"))
+}
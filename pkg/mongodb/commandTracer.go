@@ -0,0 +1,63 @@
+package mongodb
+
+import (
+	"context"
+	"sync"
+
+	"go.mongodb.org/mongo-driver/event"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.43.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const tracerName = "github.com/rameshsunkara/go-rest-api-example/pkg/mongodb"
+
+// commandTracer starts a client span for every command, as a child of the span in the command's context.
+type commandTracer struct {
+	tracer trace.Tracer
+	spans  sync.Map // request ID of the command -> trace.Span
+}
+
+func newCommandTracer(tp trace.TracerProvider) *commandTracer {
+	return &commandTracer{tracer: tp.Tracer(tracerName)}
+}
+
+func (t *commandTracer) started(ctx context.Context, evt *event.CommandStartedEvent) {
+	collection := commandCollection(evt)
+	name := evt.CommandName
+	attrs := []attribute.KeyValue{
+		semconv.DBSystemNameMongoDB,
+		semconv.DBOperationName(evt.CommandName),
+		semconv.DBNamespace(evt.DatabaseName),
+	}
+	if collection != "" {
+		name += " " + evt.DatabaseName + "." + collection
+		attrs = append(attrs, semconv.DBCollectionName(collection))
+	}
+	_, span := t.tracer.Start(ctx, name, trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(attrs...))
+	t.spans.Store(evt.RequestID, span)
+}
+
+func (t *commandTracer) succeeded(_ context.Context, evt *event.CommandSucceededEvent) {
+	if span, ok := t.span(evt.RequestID); ok {
+		span.End()
+	}
+}
+
+func (t *commandTracer) failed(_ context.Context, evt *event.CommandFailedEvent) {
+	if span, ok := t.span(evt.RequestID); ok {
+		span.SetStatus(codes.Error, evt.Failure)
+		span.End()
+	}
+}
+
+// span removes the span of a finished command.
+func (t *commandTracer) span(requestID int64) (trace.Span, bool) {
+	v, ok := t.spans.LoadAndDelete(requestID)
+	if !ok {
+		return nil, false
+	}
+	span, ok := v.(trace.Span)
+	return span, ok
+}
@@ -0,0 +1,61 @@
+package mongodb_test
+
+import (
+	"testing"
+
+	"github.com/rameshsunkara/go-rest-api-example/pkg/mongodb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/event"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestNewMonitors_Tracing(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	monitor, pool, err := mongodb.NewMonitors(&mongodb.MongoOptions{TracerProvider: tp})
+	require.NoError(t, err)
+	require.NotNil(t, monitor)
+	assert.Nil(t, pool)
+
+	ctx, parent := tp.Tracer("test").Start(t.Context(), "GET /api/v1/orders")
+	find, err := bson.Marshal(bson.D{{Key: "find", Value: "orders"}, {Key: "filter", Value: bson.D{}}})
+	require.NoError(t, err)
+	monitor.Started(ctx, &event.CommandStartedEvent{
+		Command: find, DatabaseName: "ecommerce", CommandName: "find", RequestID: 1,
+	})
+	ping, err := bson.Marshal(bson.D{{Key: "ping", Value: 1}})
+	require.NoError(t, err)
+	monitor.Started(ctx, &event.CommandStartedEvent{
+		Command: ping, DatabaseName: "admin", CommandName: "ping", RequestID: 2,
+	})
+	monitor.Failed(ctx, &event.CommandFailedEvent{
+		CommandFinishedEvent: event.CommandFinishedEvent{CommandName: "ping", RequestID: 2}, Failure: "timeout",
+	})
+	monitor.Succeeded(ctx, &event.CommandSucceededEvent{
+		CommandFinishedEvent: event.CommandFinishedEvent{CommandName: "find", RequestID: 1},
+	})
+	parent.End()
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 3)
+	failed, found := spans[0], spans[1]
+
+	assert.Equal(t, "find ecommerce.orders", found.Name())
+	assert.Equal(t, trace.SpanKindClient, found.SpanKind())
+	assert.Equal(t, parent.SpanContext().SpanID(), found.Parent().SpanID())
+	assert.Equal(t, parent.SpanContext().TraceID(), found.SpanContext().TraceID())
+	assert.Contains(t, found.Attributes(), attribute.String("db.system.name", "mongodb"))
+	assert.Contains(t, found.Attributes(), attribute.String("db.collection.name", "orders"))
+	assert.Contains(t, found.Attributes(), attribute.String("db.namespace", "ecommerce"))
+	assert.Equal(t, codes.Unset, found.Status().Code)
+
+	assert.Equal(t, "ping", failed.Name())
+	assert.Equal(t, codes.Error, failed.Status().Code)
+	assert.Equal(t, "timeout", failed.Status().Description)
+}
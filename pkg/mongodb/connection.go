@@ -3,10 +3,8 @@ package mongodb
 import (
 	"context"
 	"errors"
-	"fmt"
 	"time"
 
-	"go.mongodb.org/mongo-driver/event"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 	"go.mongodb.org/mongo-driver/mongo/readpref"
@@ -70,17 +68,14 @@ func NewMongoManager(
 
 // newClient creates a new Mongo Client to connect DB.
 func (c *ConnectionManager) newClient() (*mongo.Client, error) {
-	var cmdMonitor *event.CommandMonitor
-	if c.options.QueryLogging {
-		cmdMonitor = &event.CommandMonitor{
-			Started: func(_ context.Context, evt *event.CommandStartedEvent) {
-				// Debug logging for MongoDB queries
-				//nolint:forbidigo // Debug logging for MongoDB queries
-				fmt.Printf("MongoDB: database query: %s\n", evt.Command.String())
-			},
-		}
+	cmdMonitor, poolMonitor, err := NewMonitors(c.options)
+	if err != nil {
+		return nil, err
 	}
 	clientOptions := options.Client().ApplyURI(c.connectionURL).SetMonitor(cmdMonitor)
+	if poolMonitor != nil {
+		clientOptions.SetPoolMonitor(poolMonitor)
+	}
 	ctx, cancel := context.WithTimeout(context.Background(), DefaultClientConnectTimeout)
 	defer cancel()
 	client, err := mongo.Connect(ctx, clientOptions)
@@ -0,0 +1,74 @@
+package mongodb
+
+import (
+	"context"
+	"errors"
+
+	"go.mongodb.org/mongo-driver/event"
+)
+
+// ErrMissingLogger is returned when query logging is enabled without a logger, see WithLogger.
+var ErrMissingLogger = errors.New("query logging requires a logger")
+
+// NewMonitors returns the command and pool monitors of the query logging, tracing and metrics enabled in opts,
+// each is nil when nothing enabled needs it.
+func NewMonitors(opts *MongoOptions) (*event.CommandMonitor, *event.PoolMonitor, error) {
+	var (
+		started   []func(context.Context, *event.CommandStartedEvent)
+		succeeded []func(context.Context, *event.CommandSucceededEvent)
+		failed    []func(context.Context, *event.CommandFailedEvent)
+		pool      *event.PoolMonitor
+	)
+	if opts.QueryLogging || opts.SlowQueryThreshold > 0 {
+		if opts.Logger == nil {
+			return nil, nil, ErrMissingLogger
+		}
+		queries := newQueryLogger(opts)
+		started = append(started, queries.started)
+		succeeded = append(succeeded, queries.succeeded)
+		failed = append(failed, queries.failed)
+	}
+	if opts.TracerProvider != nil {
+		tracer := newCommandTracer(opts.TracerProvider)
+		started = append(started, tracer.started)
+		succeeded = append(succeeded, tracer.succeeded)
+		failed = append(failed, tracer.failed)
+	}
+	if opts.MetricsRegisterer != nil {
+		m, err := newClientMetrics(opts.MetricsRegisterer)
+		if err != nil {
+			return nil, nil, err
+		}
+		started = append(started, m.started)
+		succeeded = append(succeeded, m.succeeded)
+		failed = append(failed, m.failed)
+		pool = &event.PoolMonitor{Event: m.poolEvent}
+	}
+	if len(started) == 0 {
+		return nil, pool, nil
+	}
+	return &event.CommandMonitor{
+		Started: func(ctx context.Context, evt *event.CommandStartedEvent) {
+			for _, f := range started {
+				f(ctx, evt)
+			}
+		},
+		Succeeded: func(ctx context.Context, evt *event.CommandSucceededEvent) {
+			for _, f := range succeeded {
+				f(ctx, evt)
+			}
+		},
+		Failed: func(ctx context.Context, evt *event.CommandFailedEvent) {
+			for _, f := range failed {
+				f(ctx, evt)
+			}
+		},
+	}, pool, nil
+}
+
+// commandCollection returns the collection a command runs on, commands on a collection name it as their
+// first element, e.g. {"find": "orders", ...}.
+func commandCollection(evt *event.CommandStartedEvent) string {
+	collection, _ := evt.Command.Lookup(evt.CommandName).StringValueOK()
+	return collection
+}
@@ -1,5 +1,13 @@
 package mongodb
 
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/rameshsunkara/go-rest-api-example/pkg/logger"
+	"go.opentelemetry.io/otel/trace"
+)
+
 // Valid MongoDB read preferences - controls WHERE to read from (server selection).
 var validReadPreferences = []string{
 	"primary",            // Read only from primary server
@@ -77,13 +85,52 @@ func WithAuthSource(authSource string) Option {
 	}
 }
 
-// WithQueryLogging sets MongoDB query logging for debugging.
+// WithQueryLogging logs every MongoDB command at debug level for debugging, it requires WithLogger.
 func WithQueryLogging(enabled bool) Option {
 	return func(opts *MongoOptions) {
 		opts.QueryLogging = enabled
 	}
 }
 
+// WithLogger sets the logger of the query logging and the slow query logging.
+func WithLogger(lgr logger.Logger) Option {
+	return func(opts *MongoOptions) {
+		opts.Logger = lgr
+	}
+}
+
+// WithSlowQueryThreshold logs the commands that take at least threshold once they finish, along with their
+// duration and whether they succeeded. It requires WithLogger, zero disables it.
+func WithSlowQueryThreshold(threshold time.Duration) Option {
+	return func(opts *MongoOptions) {
+		opts.SlowQueryThreshold = threshold
+	}
+}
+
+// WithRedactedFields masks the values of the fields at the dotted paths in logged commands. A path matches the
+// end of the path of a field and arrays are transparent, "customer.email" masks filter.customer.email as well
+// as the customer emails of every inserted document.
+func WithRedactedFields(paths ...string) Option {
+	return func(opts *MongoOptions) {
+		opts.RedactedFields = paths
+	}
+}
+
+// WithTracing starts a span for every command, as a child of the span in the context of the operation.
+func WithTracing(tp trace.TracerProvider) Option {
+	return func(opts *MongoOptions) {
+		opts.TracerProvider = tp
+	}
+}
+
+// WithMetrics registers the latency and errors of commands per collection and operation, and the connection
+// pool gauges on reg. Clients sharing a registry share the metrics.
+func WithMetrics(reg prometheus.Registerer) Option {
+	return func(opts *MongoOptions) {
+		opts.MetricsRegisterer = reg
+	}
+}
+
 // IsValidReadPreference checks if the read preference is valid.
 func IsValidReadPreference(pref string) bool {
 	for _, valid := range validReadPreferences {
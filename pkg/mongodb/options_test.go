@@ -1,8 +1,11 @@
 package mongodb_test
 
 import (
+	"io"
 	"testing"
+	"time"
 
+	"github.com/rameshsunkara/go-rest-api-example/pkg/logger"
 	"github.com/rameshsunkara/go-rest-api-example/pkg/mongodb"
 	"github.com/stretchr/testify/assert"
 )
@@ -89,6 +92,19 @@ func TestWithQueryLogging(t *testing.T) {
 	}
 }
 
+func TestQueryLoggingOptions(t *testing.T) {
+	lgr := logger.New("info", io.Discard)
+	opts := &mongodb.MongoOptions{}
+
+	mongodb.WithLogger(lgr)(opts)
+	mongodb.WithSlowQueryThreshold(200 * time.Millisecond)(opts)
+	mongodb.WithRedactedFields("customer.email", "user")(opts)
+
+	assert.Equal(t, lgr, opts.Logger)
+	assert.Equal(t, 200*time.Millisecond, opts.SlowQueryThreshold)
+	assert.Equal(t, []string{"customer.email", "user"}, opts.RedactedFields)
+}
+
 func TestIsValidReadPreference(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -0,0 +1,149 @@
+package mongodb
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rameshsunkara/go-rest-api-example/pkg/logger"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/event"
+)
+
+// RedactedValue replaces the values of redacted fields in logged commands.
+const RedactedValue = "[REDACTED]"
+
+// queryLogger logs commands through the logger with the redacted fields masked. It logs every command when
+// query logging is on, and the commands slower than the threshold once they finish when one is set.
+type queryLogger struct {
+	lgr       logger.Logger
+	all       bool
+	threshold time.Duration
+	redact    [][]string // dotted field paths split on "."
+	commands  sync.Map   // request ID of the command -> its body, kept for the slow query log
+}
+
+func newQueryLogger(opts *MongoOptions) *queryLogger {
+	l := &queryLogger{lgr: opts.Logger, all: opts.QueryLogging, threshold: opts.SlowQueryThreshold}
+	for _, path := range opts.RedactedFields {
+		l.redact = append(l.redact, strings.Split(path, "."))
+	}
+	return l
+}
+
+func (l *queryLogger) started(_ context.Context, evt *event.CommandStartedEvent) {
+	if l.all {
+		l.lgr.Debug().
+			Str("database", evt.DatabaseName).
+			Str("operation", evt.CommandName).
+			Str("command", l.redacted(evt.Command)).
+			Msg("MongoDB command")
+	}
+	if l.threshold > 0 {
+		// the driver may reuse the buffer of the command once the event is handled
+		l.commands.Store(evt.RequestID, bson.Raw(slices.Clone(evt.Command)))
+	}
+}
+
+func (l *queryLogger) succeeded(_ context.Context, evt *event.CommandSucceededEvent) {
+	command, slow := l.finished(&evt.CommandFinishedEvent)
+	if slow {
+		l.slowQuery(l.lgr.Info(), &evt.CommandFinishedEvent, command, "succeeded").Msg("slow MongoDB command")
+	}
+}
+
+func (l *queryLogger) failed(_ context.Context, evt *event.CommandFailedEvent) {
+	command, slow := l.finished(&evt.CommandFinishedEvent)
+	if slow {
+		l.slowQuery(l.lgr.Error(), &evt.CommandFinishedEvent, command, "failed").
+			Str("failure", evt.Failure).
+			Msg("slow MongoDB command")
+	}
+}
+
+// finished removes the body of a finished command and reports whether it was slower than the threshold.
+func (l *queryLogger) finished(evt *event.CommandFinishedEvent) (bson.Raw, bool) {
+	if l.threshold <= 0 {
+		return nil, false
+	}
+	v, _ := l.commands.LoadAndDelete(evt.RequestID)
+	command, _ := v.(bson.Raw)
+	return command, evt.Duration >= l.threshold
+}
+
+func (l *queryLogger) slowQuery(
+	e logger.Event,
+	evt *event.CommandFinishedEvent,
+	command bson.Raw,
+	outcome string,
+) logger.Event {
+	return e.Str("database", evt.DatabaseName).
+		Str("operation", evt.CommandName).
+		Str("event", outcome).
+		Dur("duration", evt.Duration).
+		Dur("threshold", l.threshold).
+		Str("command", l.redacted(command))
+}
+
+// redacted returns the command as extended JSON with the values of the redacted fields replaced.
+func (l *queryLogger) redacted(command bson.Raw) string {
+	if len(l.redact) == 0 {
+		return command.String()
+	}
+	out, err := bson.MarshalExtJSON(redactDocument(command, nil, l.redact), false, false)
+	if err != nil {
+		// never fall back to the raw command, it is what redaction protects
+		return RedactedValue
+	}
+	return string(out)
+}
+
+// redactDocument copies doc, replacing the values of the fields whose path ends with one of paths. Arrays are
+// transparent, "customer.email" matches documents[i].customer.email as well as filter.customer.email.
+func redactDocument(doc bson.Raw, parent []string, paths [][]string) bson.D {
+	elems, err := doc.Elements()
+	if err != nil {
+		return bson.D{{Key: "invalid", Value: RedactedValue}}
+	}
+	out := make(bson.D, 0, len(elems))
+	for _, elem := range elems {
+		// keys of update operators may be dotted themselves, e.g. {"$set": {"customer.email": ...}}
+		path := append(append([]string{}, parent...), strings.Split(elem.Key(), ".")...)
+		out = append(out, bson.E{Key: elem.Key(), Value: redactValue(elem.Value(), path, paths)})
+	}
+	return out
+}
+
+func redactValue(v bson.RawValue, path []string, paths [][]string) any {
+	for _, p := range paths {
+		if len(path) >= len(p) && equalFold(path[len(path)-len(p):], p) {
+			return RedactedValue
+		}
+	}
+	if v.Type == bson.TypeEmbeddedDocument {
+		return redactDocument(v.Document(), path, paths)
+	}
+	if v.Type != bson.TypeArray {
+		return v
+	}
+	values, err := v.Array().Values()
+	if err != nil {
+		return RedactedValue
+	}
+	out := make(bson.A, 0, len(values))
+	for _, item := range values {
+		out = append(out, redactValue(item, path, paths))
+	}
+	return out
+}
+
+func equalFold(a, b []string) bool {
+	for i := range a {
+		if !strings.EqualFold(a[i], b[i]) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,119 @@
+package mongodb_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rameshsunkara/go-rest-api-example/pkg/logger"
+	"github.com/rameshsunkara/go-rest-api-example/pkg/mongodb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/event"
+)
+
+func logEntries(t *testing.T, buf *bytes.Buffer) []map[string]any {
+	t.Helper()
+	var entries []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		var entry map[string]any
+		require.NoError(t, json.Unmarshal([]byte(line), &entry))
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+func TestNewMonitors_QueryLogging(t *testing.T) {
+	var buf bytes.Buffer
+	monitor, _, err := mongodb.NewMonitors(&mongodb.MongoOptions{
+		QueryLogging:   true,
+		Logger:         logger.New("debug", &buf),
+		RedactedFields: []string{"customer.email", "user"},
+	})
+	require.NoError(t, err)
+
+	insert, err := bson.Marshal(bson.D{
+		{Key: "insert", Value: "orders"},
+		{Key: "documents", Value: bson.A{bson.D{
+			{Key: "customer", Value: bson.D{{Key: "email", Value: "jane@example.com"}, {Key: "tier", Value: "gold"}}},
+			{Key: "user", Value: "jane"},
+		}}},
+	})
+	require.NoError(t, err)
+	monitor.Started(t.Context(), &event.CommandStartedEvent{
+		Command: insert, DatabaseName: "ecommerce", CommandName: "insert", RequestID: 1,
+	})
+
+	entries := logEntries(t, &buf)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "debug", entries[0]["level"])
+	assert.Equal(t, "insert", entries[0]["operation"])
+	command, ok := entries[0]["command"].(string)
+	require.True(t, ok)
+	assert.NotContains(t, command, "jane")
+	assert.Contains(t, command, mongodb.RedactedValue)
+	assert.Contains(t, command, "gold")
+}
+
+func TestNewMonitors_SlowQueries(t *testing.T) {
+	var buf bytes.Buffer
+	monitor, _, err := mongodb.NewMonitors(&mongodb.MongoOptions{
+		SlowQueryThreshold: 100 * time.Millisecond,
+		Logger:             logger.New("info", &buf),
+		RedactedFields:     []string{"user"},
+	})
+	require.NoError(t, err)
+
+	find, err := bson.Marshal(bson.D{
+		{Key: "find", Value: "orders"},
+		{Key: "filter", Value: bson.D{{Key: "user", Value: "jane"}}},
+	})
+	require.NoError(t, err)
+	for id := range int64(3) {
+		monitor.Started(t.Context(), &event.CommandStartedEvent{
+			Command: find, DatabaseName: "ecommerce", CommandName: "find", RequestID: id,
+		})
+	}
+	monitor.Succeeded(t.Context(), &event.CommandSucceededEvent{
+		CommandFinishedEvent: event.CommandFinishedEvent{
+			CommandName: "find", DatabaseName: "ecommerce", RequestID: 0, Duration: 10 * time.Millisecond,
+		},
+	})
+	monitor.Succeeded(t.Context(), &event.CommandSucceededEvent{
+		CommandFinishedEvent: event.CommandFinishedEvent{
+			CommandName: "find", DatabaseName: "ecommerce", RequestID: 1, Duration: 150 * time.Millisecond,
+		},
+	})
+	monitor.Failed(t.Context(), &event.CommandFailedEvent{
+		CommandFinishedEvent: event.CommandFinishedEvent{
+			CommandName: "find", DatabaseName: "ecommerce", RequestID: 2, Duration: time.Second,
+		},
+		Failure: "operation exceeded time limit",
+	})
+
+	// only the commands slower than the threshold are logged
+	entries := logEntries(t, &buf)
+	require.Len(t, entries, 2)
+	succeeded, failed := entries[0], entries[1]
+
+	assert.Equal(t, "info", succeeded["level"])
+	assert.Equal(t, "succeeded", succeeded["event"])
+	assert.Equal(t, "find", succeeded["operation"])
+	assert.Equal(t, "ecommerce", succeeded["database"])
+	assert.InDelta(t, 150, succeeded["duration"], 0)
+	assert.InDelta(t, 100, succeeded["threshold"], 0)
+	command, ok := succeeded["command"].(string)
+	require.True(t, ok)
+	assert.Contains(t, command, "orders")
+	assert.NotContains(t, command, "jane")
+
+	assert.Equal(t, "error", failed["level"])
+	assert.Equal(t, "failed", failed["event"])
+	assert.Equal(t, "operation exceeded time limit", failed["failure"])
+}
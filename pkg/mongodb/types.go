@@ -4,8 +4,13 @@
 package mongodb
 
 import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/rameshsunkara/go-rest-api-example/pkg/logger"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // MongoDatabase defines the interface for MongoDB database operations.
@@ -37,7 +42,16 @@ type MongoOptions struct {
 	WriteConcern   string `json:"writeConcern,omitempty"`   // Write concern level
 	WTimeoutMS     int    `json:"wtimeoutMS,omitempty"`     // Write timeout in milliseconds
 	AuthSource     string `json:"authSource,omitempty"`     // Authentication database (default: admin for root users)
-	QueryLogging   bool   `json:"queryLogging,omitempty"`   // Enable MongoDB query logging
+	QueryLogging   bool   `json:"queryLogging,omitempty"`   // Log every command at debug level
+
+	// Commands taking at least SlowQueryThreshold are logged once they finish, zero disables it
+	SlowQueryThreshold time.Duration `json:"slowQueryThreshold,omitempty"`
+	// Dotted paths of the fields masked in logged commands, e.g. "customer.email"
+	RedactedFields []string      `json:"redactedFields,omitempty"`
+	Logger         logger.Logger `json:"-"` // Required by query logging and slow query logging
+
+	TracerProvider    trace.TracerProvider  `json:"-"` // Traces every command when set
+	MetricsRegisterer prometheus.Registerer `json:"-"` // Records command and pool metrics when set
 }
 
 // Option is a functional option for configuring MongoDB connection.
@@ -0,0 +1,106 @@
+// Package tracing sets up OpenTelemetry tracing, spans are exported over OTLP or written as JSON for local use.
+package tracing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.43.0"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+// Exporters spans can be sent to.
+const (
+	ExporterOTLP   = "otlp"   // OTLP over HTTP, to a collector or a tracing backend
+	ExporterStdout = "stdout" // JSON lines on stdout or in a file
+)
+
+var ErrUnknownExporter = errors.New("unknown trace exporter")
+
+// Config describes where spans go, an empty Exporter disables tracing.
+type Config struct {
+	ServiceName  string
+	Environment  string
+	Exporter     string
+	OTLPEndpoint string  // e.g. http://localhost:4318, the OTEL_EXPORTER_OTLP_* variables apply when empty
+	File         string  // file the stdout exporter appends to, empty writes to stdout
+	SampleRatio  float64 // share of new traces recorded, requests keep the decision of their caller
+}
+
+// Setup installs the tracer provider described by cfg and the W3C trace context propagator as the
+// OpenTelemetry globals. It returns the provider and the func flushing pending spans on shutdown.
+func Setup(ctx context.Context, cfg Config) (trace.TracerProvider, func(context.Context) error, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{}, propagation.Baggage{}))
+	if cfg.Exporter == "" {
+		tp := noop.NewTracerProvider()
+		otel.SetTracerProvider(tp)
+		return tp, func(context.Context) error { return nil }, nil
+	}
+
+	exporter, closeOutput, err := newExporter(ctx, cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+	res, err := resource.Merge(resource.Default(), resource.NewWithAttributes(semconv.SchemaURL,
+		semconv.ServiceName(cfg.ServiceName),
+		semconv.DeploymentEnvironmentNameKey.String(cfg.Environment),
+	))
+	if err != nil {
+		return nil, nil, errors.Join(fmt.Errorf("tracing resource: %w", err), closeOutput())
+	}
+	tp := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.SampleRatio))),
+	)
+	otel.SetTracerProvider(tp)
+	shutdown := func(shutdownCtx context.Context) error {
+		return errors.Join(tp.Shutdown(shutdownCtx), closeOutput())
+	}
+	return tp, shutdown, nil
+}
+
+// newExporter returns the exporter of cfg and the func closing the file it writes to, if any.
+func newExporter(ctx context.Context, cfg Config) (sdktrace.SpanExporter, func() error, error) {
+	noClose := func() error { return nil }
+	switch cfg.Exporter {
+	case ExporterOTLP:
+		var opts []otlptracehttp.Option
+		if cfg.OTLPEndpoint != "" {
+			opts = append(opts, otlptracehttp.WithEndpointURL(cfg.OTLPEndpoint))
+		}
+		exporter, err := otlptracehttp.New(ctx, opts...)
+		if err != nil {
+			return nil, nil, fmt.Errorf("creating OTLP trace exporter: %w", err)
+		}
+		return exporter, noClose, nil
+	case ExporterStdout:
+		var out io.Writer = os.Stdout
+		closeOutput := noClose
+		if cfg.File != "" {
+			f, err := os.OpenFile(cfg.File, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+			if err != nil {
+				return nil, nil, fmt.Errorf("opening trace file: %w", err)
+			}
+			out, closeOutput = f, f.Close
+		}
+		exporter, err := stdouttrace.New(stdouttrace.WithWriter(out))
+		if err != nil {
+			return nil, nil, errors.Join(fmt.Errorf("creating stdout trace exporter: %w", err), closeOutput())
+		}
+		return exporter, closeOutput, nil
+	default:
+		return nil, nil, fmt.Errorf("%w: %q", ErrUnknownExporter, cfg.Exporter)
+	}
+}
@@ -0,0 +1,45 @@
+package tracing_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/rameshsunkara/go-rest-api-example/pkg/tracing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetup(t *testing.T) {
+	t.Run("disabled", func(t *testing.T) {
+		tp, shutdown, err := tracing.Setup(t.Context(), tracing.Config{})
+		require.NoError(t, err)
+		_, span := tp.Tracer("test").Start(t.Context(), "op")
+		span.End()
+		assert.False(t, span.SpanContext().IsValid())
+		require.NoError(t, shutdown(t.Context()))
+	})
+
+	t.Run("stdout exporter writes to the file", func(t *testing.T) {
+		file := filepath.Join(t.TempDir(), "traces.json")
+		tp, shutdown, err := tracing.Setup(t.Context(), tracing.Config{
+			ServiceName: "orders", Environment: "test", Exporter: tracing.ExporterStdout, File: file, SampleRatio: 1,
+		})
+		require.NoError(t, err)
+		_, span := tp.Tracer("test").Start(t.Context(), "GET /api/v1/orders")
+		span.End()
+		require.NoError(t, shutdown(context.Background()))
+
+		out, err := os.ReadFile(file)
+		require.NoError(t, err)
+		assert.Contains(t, string(out), "GET /api/v1/orders")
+		assert.Contains(t, string(out), span.SpanContext().TraceID().String())
+		assert.Contains(t, string(out), `"Value":"orders"`)
+	})
+
+	t.Run("unknown exporter", func(t *testing.T) {
+		_, _, err := tracing.Setup(t.Context(), tracing.Config{Exporter: "zipkin"})
+		require.ErrorIs(t, err, tracing.ErrUnknownExporter)
+	})
+}
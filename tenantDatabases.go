@@ -0,0 +1,62 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/rameshsunkara/go-rest-api-example/internal/config"
+	"github.com/rameshsunkara/go-rest-api-example/internal/tenant"
+	"github.com/rameshsunkara/go-rest-api-example/pkg/mongodb"
+)
+
+var (
+	errTenantRequired   = errors.New("-tenant is required when tenants are configured")
+	errTenantsDisabled  = errors.New("no tenants are configured")
+	errTenantsExclusive = errors.New("-tenant and -all-tenants are mutually exclusive")
+)
+
+// tenantDatabase is a database a command runs on, tenant is empty for the default database.
+type tenantDatabase struct {
+	tenant string
+	db     mongodb.MongoDatabase
+}
+
+// tenantDatabases resolves the databases a command runs on through the tenant registry: the database of tenantID,
+// the ones of every tenant with all, or the default database when the service runs single-tenant.
+// Unknown tenants are rejected, so are commands naming no tenant while tenants are configured.
+func tenantDatabases(
+	svcEnv *config.ServiceEnvConfig,
+	dbMgr mongodb.MongoManager,
+	tenantID string,
+	all bool,
+) ([]tenantDatabase, error) {
+	if tenantID != "" && all {
+		return nil, errTenantsExclusive
+	}
+	registry, err := tenant.NewRegistry(svcEnv.Tenants, svcEnv.TenantHosts)
+	if err != nil {
+		return nil, err
+	}
+	if !registry.Enabled() {
+		if tenantID != "" || all {
+			return nil, errTenantsDisabled
+		}
+		return []tenantDatabase{{db: dbMgr.Database()}}, nil
+	}
+	if all {
+		tenants := registry.Tenants()
+		dbs := make([]tenantDatabase, 0, len(tenants))
+		for _, t := range tenants {
+			dbs = append(dbs, tenantDatabase{tenant: t.ID, db: dbMgr.DatabaseByName(t.Database)})
+		}
+		return dbs, nil
+	}
+	if tenantID == "" {
+		return nil, errTenantRequired
+	}
+	t, err := registry.Lookup(tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("%w %q", err, tenantID)
+	}
+	return []tenantDatabase{{tenant: t.ID, db: dbMgr.DatabaseByName(t.Database)}}, nil
+}